@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func main() {
+	// Parse command line flags
+	file := flag.String("file", "data/nutrients/common_foods.csv", "CSV file of nutrient values per 100 g")
+	source := flag.String("source", "usda", "Source recorded against imported foods")
+	flag.Parse()
+
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		log.Fatal("DATABASE_URL environment variable is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", *file, err)
+	}
+	defer f.Close()
+
+	foods, err := service.ParseFoodCSV(f, *source)
+	if err != nil {
+		log.Fatalf("Failed to parse %s: %v", *file, err)
+	}
+
+	// Only the database is needed to import foods
+	nutritionService := service.NewNutritionService(db, nil)
+	count, err := nutritionService.ImportFoods(context.Background(), foods)
+	if err != nil {
+		log.Fatalf("Failed to import foods: %v", err)
+	}
+
+	log.Printf("Successfully imported %d foods from %s", count, *file)
+}
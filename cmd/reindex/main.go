@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func main() {
+	// Parse command line flags
+	batchSize := flag.Int("batch", service.DefaultReindexBatchSize, "Recipes scanned per batch")
+	rpm := flag.Int("rpm", 60, "Maximum embedding requests per minute (0 for no limit)")
+	force := flag.Bool("force", false, "Re-embed every recipe, not only missing or stale ones")
+	checkpoint := flag.String("checkpoint", ".reindex-checkpoint", "File recording the last recipe processed")
+	resume := flag.Bool("resume", false, "Resume after the recipe in the checkpoint file")
+	flag.Parse()
+
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		log.Fatal("DATABASE_URL environment variable is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	embeddingService, err := service.NewEmbeddingService()
+	if err != nil {
+		log.Fatalf("Failed to create embedding service: %v", err)
+	}
+
+	log.Printf("Embedding with %s (%d dimensions)", embeddingService.Model(), embeddingService.Dimensions())
+
+	opts := service.ReindexOptions{
+		BatchSize:         *batchSize,
+		RequestsPerMinute: *rpm,
+		Force:             *force,
+		Progress: func(p service.ReindexProgress) {
+			log.Printf("Scanned %d, embedded %d, failed %d, last recipe %s", p.Scanned, p.Embedded, p.Failed, p.LastID)
+			if !p.Done {
+				if err := os.WriteFile(*checkpoint, []byte(p.LastID.String()+"\n"), 0o644); err != nil {
+					log.Printf("Failed to write checkpoint: %v", err)
+				}
+			}
+		},
+	}
+	if *resume {
+		data, err := os.ReadFile(*checkpoint)
+		if err != nil {
+			log.Fatalf("Failed to read checkpoint: %v", err)
+		}
+		if opts.After, err = uuid.Parse(strings.TrimSpace(string(data))); err != nil {
+			log.Fatalf("Invalid checkpoint %s: %v", *checkpoint, err)
+		}
+		log.Printf("Resuming after recipe %s", opts.After)
+	}
+
+	// Stop cleanly on interrupt so the run can be resumed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	progress, err := service.NewEmbeddingReindexer(db, embeddingService).Run(ctx, opts)
+	if errors.Is(err, context.Canceled) {
+		log.Printf("Interrupted after recipe %s; rerun with -resume to continue", progress.LastID)
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatalf("Reindex failed: %v", err)
+	}
+
+	os.Remove(*checkpoint)
+	log.Printf("Successfully reindexed: scanned %d, embedded %d, failed %d", progress.Scanned, progress.Embedded, progress.Failed)
+}
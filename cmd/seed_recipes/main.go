@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -103,6 +104,8 @@ func main() {
 		log.Fatalf("Failed to create LLM service: %v", err)
 	}
 
+	nutritionService := service.NewNutritionService(db, llmService)
+
 	embeddingService, err := service.NewEmbeddingService()
 	if err != nil {
 		log.Fatalf("Failed to create embedding service: %v", err)
@@ -170,12 +173,14 @@ func main() {
 				continue
 			}
 
-			// Calculate macros
-			macros, err := llmService.CalculateMacros(recipeData.Ingredients)
+			// Calculate macros from the nutrient database
+			servings := service.ParseServings(recipeData.Servings)
+			nutrition, err := nutritionService.CalculateNutrition(context.Background(), recipeData.Ingredients, servings)
 			if err != nil {
 				log.Printf("Failed to calculate macros: %v", err)
 				continue
 			}
+			macros := nutrition.PerServing
 
 			// Generate embedding
 			embedding, err := embeddingService.GenerateEmbeddingFromRecipe(
@@ -192,21 +197,23 @@ func main() {
 
 			// Create recipe record
 			recipe := models.Recipe{
-				ID:           uuid.New(),
-				Name:         recipeData.Name,
-				Description:  recipeData.Description,
-				Category:     recipeData.Category,
-				Ingredients:  models.JSONBStringArray(recipeData.Ingredients),
-				Instructions: models.JSONBStringArray(recipeData.Instructions),
-				Calories:     macros.Calories,
-				Protein:      macros.Protein,
-				Carbs:        macros.Carbs,
-				Fat:          macros.Fat,
-				Embedding:    embedding,
-				UserID:       userID,
-				CreatedAt:    time.Now(),
-				UpdatedAt:    time.Now(),
-				Tags:         models.JSONBStringArray([]string{recipeData.Category, "dietary_preference", "meal_type"}),
+				ID:             uuid.New(),
+				Name:           recipeData.Name,
+				Description:    recipeData.Description,
+				Category:       recipeData.Category,
+				Ingredients:    models.JSONBStringArray(recipeData.Ingredients),
+				Instructions:   models.JSONBStringArray(recipeData.Instructions),
+				Calories:       macros.Calories,
+				Protein:        macros.Protein,
+				Carbs:          macros.Carbs,
+				Fat:            macros.Fat,
+				Servings:       servings,
+				Micronutrients: nutrition.PerServing.Micronutrients,
+				Embedding:      embedding,
+				UserID:         userID,
+				CreatedAt:      time.Now(),
+				UpdatedAt:      time.Now(),
+				Tags:           models.JSONBStringArray([]string{recipeData.Category, "dietary_preference", "meal_type"}),
 			}
 
 			if err := db.Create(&recipe).Error; err != nil {
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const usage = `Usage: vectorindex <command> [flags]
+
+Commands:
+  create  Build an HNSW or IVFFlat index for an embedding model
+  list    List the vector indexes on recipes
+  drop    Drop a vector index
+  bench   Compare index recall and latency with exact search
+
+Run vectorindex <command> -h for the command's flags.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	model := flags.String("model", "text-embedding-ada-002", "Embedding model the index covers")
+	dims := flags.Int("dims", 1536, "Dimensions of the model's embeddings")
+
+	ctx := context.Background()
+	switch os.Args[1] {
+	case "create":
+		indexType := flags.String("type", service.IndexHNSW, "Index type: hnsw or ivfflat")
+		m := flags.Int("m", 0, "HNSW connections per node (default 16)")
+		efConstruction := flags.Int("ef-construction", 0, "HNSW candidate list size while building (default 64)")
+		lists := flags.Int("lists", 0, "IVFFlat lists (default rows/1000, or sqrt(rows) above a million rows)")
+		flags.Parse(os.Args[2:])
+
+		name, err := service.NewVectorIndexManager(openDB()).Create(ctx, service.VectorIndexOptions{
+			Type:           *indexType,
+			Model:          *model,
+			Dimensions:     *dims,
+			M:              *m,
+			EfConstruction: *efConstruction,
+			Lists:          *lists,
+		})
+		if err != nil {
+			log.Fatalf("Failed to create index: %v", err)
+		}
+		log.Printf("Successfully created index %s", name)
+
+	case "list":
+		flags.Parse(os.Args[2:])
+		indexes, err := service.NewVectorIndexManager(openDB()).List(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSIZE\tDEFINITION")
+		for _, index := range indexes {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", index.Name, index.Size, index.Definition)
+		}
+		w.Flush()
+
+	case "drop":
+		name := flags.String("name", "", "Index to drop")
+		flags.Parse(os.Args[2:])
+		if *name == "" {
+			log.Fatal("-name is required")
+		}
+		if err := service.NewVectorIndexManager(openDB()).Drop(ctx, *name); err != nil {
+			log.Fatalf("Failed to drop index: %v", err)
+		}
+		log.Printf("Successfully dropped index %s", *name)
+
+	case "bench":
+		queries := flags.Int("queries", 100, "Recipes whose embeddings are used as queries")
+		k := flags.Int("k", 10, "Neighbours per query")
+		efSearch := flags.String("ef-search", "", "Comma-separated hnsw.ef_search values to compare")
+		probes := flags.String("probes", "", "Comma-separated ivfflat.probes values to compare")
+		flags.Parse(os.Args[2:])
+
+		opts := service.BenchmarkOptions{Model: *model, Dimensions: *dims, Queries: *queries, K: *k}
+		var err error
+		if opts.EfSearch, err = parseInts(*efSearch); err != nil {
+			log.Fatalf("Invalid -ef-search: %v", err)
+		}
+		if opts.Probes, err = parseInts(*probes); err != nil {
+			log.Fatalf("Invalid -probes: %v", err)
+		}
+		results, err := service.NewVectorIndexManager(openDB()).Benchmark(ctx, opts)
+		if err != nil {
+			log.Fatalf("Benchmark failed: %v", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "SETTING\tRECALL@%d\tP50\tP95\tMEAN\n", *k)
+		for _, r := range results {
+			fmt.Fprintf(w, "%s\t%.3f\t%s\t%s\t%s\n", r.Setting, r.Recall, r.P50, r.P95, r.Mean)
+		}
+		w.Flush()
+
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// openDB connects to DATABASE_URL
+func openDB() *gorm.DB {
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		log.Fatal("DATABASE_URL environment variable is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	return db
+}
+
+// parseInts parses a comma-separated list of positive integers
+func parseInts(s string) ([]int, error) {
+	var values []int
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("%q is not a positive integer", part)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -32,8 +33,22 @@ type Config struct {
 
 	// New fields
 	RedisURL string
+
+	// Vector search tuning, applied to every database session.
+	// VectorEfSearch is the HNSW candidate list size (hnsw.ef_search) and
+	// VectorProbes the IVFFlat lists searched (ivfflat.probes); higher values
+	// trade speed for recall.
+	VectorEfSearch int
+	VectorProbes   int
 }
 
+// Vector search defaults. ef_search also caps how many rows an HNSW scan
+// returns, so it is at least the hybrid search candidate depth of 100.
+const (
+	DefaultVectorEfSearch = 100
+	DefaultVectorProbes   = 10
+)
+
 // LoadConfig creates a new Config instance with values from environment variables or secrets
 func LoadConfig() (*Config, error) {
 	env := GetEnvironment()
@@ -57,6 +72,10 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("unknown environment: %s", env)
 	}
 
+	if err := loadVectorSearchConfig(cfg); err != nil {
+		return nil, err
+	}
+
 	// Validate the configuration
 	if err := ValidateConfig(cfg); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
@@ -162,6 +181,33 @@ func loadProdConfig(cfg *Config) error {
 	return nil
 }
 
+// loadVectorSearchConfig reads the vector search tuning from
+// VECTOR_EF_SEARCH and VECTOR_PROBES. These are tuning knobs rather than
+// secrets, so they come from the environment in every environment.
+func loadVectorSearchConfig(cfg *Config) error {
+	cfg.VectorEfSearch = DefaultVectorEfSearch
+	cfg.VectorProbes = DefaultVectorProbes
+	settings := []struct {
+		name  string
+		value *int
+	}{
+		{"VECTOR_EF_SEARCH", &cfg.VectorEfSearch},
+		{"VECTOR_PROBES", &cfg.VectorProbes},
+	}
+	for _, setting := range settings {
+		v := os.Getenv(setting.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return fmt.Errorf("%s must be a positive integer, got %q", setting.name, v)
+		}
+		*setting.value = n
+	}
+	return nil
+}
+
 // readSecret reads a Docker secret from the secrets directory
 func readSecret(name string) string {
 	secretsDir := os.Getenv("SECRETS_DIR")
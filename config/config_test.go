@@ -128,3 +128,19 @@ func TestLoadConfigWithDefaults(t *testing.T) {
 		assert.Equal(t, "test-redis-pass", cfg.RedisPassword)
 	})
 }
+
+func TestLoadVectorSearchConfig(t *testing.T) {
+	cfg := &Config{}
+	assert.NoError(t, loadVectorSearchConfig(cfg))
+	assert.Equal(t, DefaultVectorEfSearch, cfg.VectorEfSearch)
+	assert.Equal(t, DefaultVectorProbes, cfg.VectorProbes)
+
+	t.Setenv("VECTOR_EF_SEARCH", "200")
+	t.Setenv("VECTOR_PROBES", "4")
+	assert.NoError(t, loadVectorSearchConfig(cfg))
+	assert.Equal(t, 200, cfg.VectorEfSearch)
+	assert.Equal(t, 4, cfg.VectorProbes)
+
+	t.Setenv("VECTOR_PROBES", "none")
+	assert.Error(t, loadVectorSearchConfig(cfg))
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+)
+
+// ActivityHandler handles activity feeds
+type ActivityHandler struct {
+	activityService service.IActivityService
+	authService     service.IAuthService
+}
+
+// NewActivityHandler creates a new ActivityHandler
+func NewActivityHandler(activityService service.IActivityService, authService service.IAuthService) *ActivityHandler {
+	return &ActivityHandler{
+		activityService: activityService,
+		authService:     authService,
+	}
+}
+
+// RegisterRoutes registers the activity routes
+func (h *ActivityHandler) RegisterRoutes(router *gin.RouterGroup) {
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(h.authService))
+	{
+		protected.GET("/feed", h.Feed)
+		protected.GET("/users/:username/activity", h.ListUserActivity)
+	}
+}
+
+// Feed returns one page of the activities of the users the current user
+// follows, newest first
+func (h *ActivityHandler) Feed(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	activities, info, err := h.activityService.Feed(c.Request.Context(), userID, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("activities", activities, info))
+}
+
+// ListUserActivity returns one page of a user's activities, newest first
+func (h *ActivityHandler) ListUserActivity(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	activities, info, err := h.activityService.ListUserActivity(c.Request.Context(), userID, c.Param("username"), page)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("activities", activities, info))
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+)
+
+// AnalyticsHandler handles creator analytics
+type AnalyticsHandler struct {
+	analyticsService service.IAnalyticsService
+	authService      service.IAuthService
+}
+
+// NewAnalyticsHandler creates a new AnalyticsHandler
+func NewAnalyticsHandler(analyticsService service.IAnalyticsService, authService service.IAuthService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: analyticsService,
+		authService:      authService,
+	}
+}
+
+// RegisterRoutes registers the analytics routes
+func (h *AnalyticsHandler) RegisterRoutes(router *gin.RouterGroup) {
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(h.authService))
+	{
+		protected.GET("/me/analytics", h.GetAnalytics)
+	}
+}
+
+// GetAnalytics returns daily views, unique viewers, favorites, forks and
+// share links for the current user's recipes. days sets the range and
+// recipe_id limits it to one recipe.
+func (h *AnalyticsHandler) GetAnalytics(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	days := service.DefaultAnalyticsDays
+	if v := c.Query("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > service.MaxAnalyticsDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and " + strconv.Itoa(service.MaxAnalyticsDays)})
+			return
+		}
+		days = n
+	}
+
+	recipeID := uuid.Nil
+	if v := c.Query("recipe_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe ID format"})
+			return
+		}
+		recipeID = id
+	}
+
+	analytics, err := h.analyticsService.CreatorAnalytics(c.Request.Context(), userID, recipeID, days, time.Now())
+	if err != nil {
+		if errors.Is(err, service.ErrRecipeNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
@@ -66,7 +66,7 @@ func TestLLMQueryModifyRecipe(t *testing.T) {
 
 func setupLLMTestRouter(t *testing.T, testDB *TestDB) *gin.Engine {
 	println("[DEBUG] setupLLMTestRouter called")
-	llmHandler := NewLLMHandler(testDB.DB, testDB.AuthService, NewMockLLMService())
+	llmHandler := NewLLMHandler(testDB.DB, testDB.AuthService, NewMockLLMService(), nil)
 
 	router := gin.New()
 	router.Use(gin.Recovery())
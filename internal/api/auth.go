@@ -79,6 +79,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		UserID:          user.ID,
 		Username:        req.Username,
 		IsEmailVerified: user.EmailVerified,
+		Role:            user.Role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(user.CreatedAt.Add(3 * 3600 * 1e9)), // 3 hours for improved security
 			IssuedAt:  jwt.NewNumericDate(user.CreatedAt),
@@ -119,6 +120,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		UserID:          user.ID,
 		Username:        profile.Username,
 		IsEmailVerified: user.EmailVerified,
+		Role:            user.Role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(user.CreatedAt.Add(24 * 3600 * 1e9)),
 			IssuedAt:  jwt.NewNumericDate(user.CreatedAt),
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"gorm.io/gorm"
+)
+
+// CollectionHandler handles recipe collections
+type CollectionHandler struct {
+	db                *gorm.DB
+	collectionService service.ICollectionService
+	authService       service.IAuthService
+}
+
+// NewCollectionHandler creates a new CollectionHandler
+func NewCollectionHandler(db *gorm.DB, collectionService service.ICollectionService, authService service.IAuthService) *CollectionHandler {
+	return &CollectionHandler{
+		db:                db,
+		collectionService: collectionService,
+		authService:       authService,
+	}
+}
+
+// RegisterRoutes registers the collection routes. Collections shared by link
+// can be read without signing in.
+func (h *CollectionHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/collections/shared/:token", h.GetSharedCollection)
+
+	protected := router.Group("/collections")
+	protected.Use(middleware.AuthMiddleware(h.authService))
+	{
+		protected.GET("", h.ListCollections)
+		protected.POST("", h.CreateCollection)
+		protected.GET("/:id", h.GetCollection)
+		protected.PUT("/:id", h.UpdateCollection)
+		protected.DELETE("/:id", h.DeleteCollection)
+		protected.GET("/:id/export", h.ExportCollection)
+		protected.POST("/:id/recipes", h.AddRecipes)
+		protected.DELETE("/:id/recipes", h.RemoveRecipes)
+		protected.PUT("/:id/recipes/order", h.ReorderRecipes)
+	}
+}
+
+// ListCollections returns the current user's collections
+func (h *CollectionHandler) ListCollections(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	collections, err := h.collectionService.ListCollections(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"collections": collections})
+}
+
+// CreateCollection creates a collection for the current user
+func (h *CollectionHandler) CreateCollection(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var req types.CreateCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection, err := h.collectionService.CreateCollection(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"collection": collection})
+}
+
+// GetCollection returns one of the current user's collections, or a public
+// one, with its recipes in order
+func (h *CollectionHandler) GetCollection(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	collection, ok := h.loadCollection(c, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"collection": collection})
+}
+
+// GetSharedCollection returns the collection a share link points at
+func (h *CollectionHandler) GetSharedCollection(c *gin.Context) {
+	collection, err := h.collectionService.GetSharedCollection(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	collection.Recipes = localizeRecipes(collection.Recipes, requestedUnitSystem(c, ""))
+
+	c.JSON(http.StatusOK, gin.H{"collection": collection})
+}
+
+// UpdateCollection updates one of the current user's collections
+func (h *CollectionHandler) UpdateCollection(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	id, ok := parseIDParam(c, "id", "invalid collection ID format")
+	if !ok {
+		return
+	}
+
+	var req types.UpdateCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection, err := h.collectionService.UpdateCollection(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"collection": collection})
+}
+
+// DeleteCollection deletes one of the current user's collections
+func (h *CollectionHandler) DeleteCollection(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	id, ok := parseIDParam(c, "id", "invalid collection ID format")
+	if !ok {
+		return
+	}
+
+	if err := h.collectionService.DeleteCollection(c.Request.Context(), userID, id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "collection deleted"})
+}
+
+// ExportCollection returns a collection as Markdown (default) or JSON
+func (h *CollectionHandler) ExportCollection(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	format := c.DefaultQuery("format", "markdown")
+	if format != "markdown" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be markdown or json"})
+		return
+	}
+	collection, ok := h.loadCollection(c, userID)
+	if !ok {
+		return
+	}
+
+	if format == "json" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="collection-%s.json"`, collection.ID))
+		c.JSON(http.StatusOK, gin.H{"collection": collection, "exported_at": time.Now().UTC()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="collection-%s.md"`, collection.ID))
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(service.FormatCollectionMarkdown(collection)))
+}
+
+// AddRecipes adds recipes to the end of one of the current user's collections
+func (h *CollectionHandler) AddRecipes(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	id, req, ok := h.bindRecipes(c)
+	if !ok {
+		return
+	}
+
+	added, err := h.collectionService.AddRecipes(c.Request.Context(), userID, id, req.RecipeIDs)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"added": added})
+}
+
+// RemoveRecipes removes recipes from one of the current user's collections
+func (h *CollectionHandler) RemoveRecipes(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	id, req, ok := h.bindRecipes(c)
+	if !ok {
+		return
+	}
+
+	removed, err := h.collectionService.RemoveRecipes(c.Request.Context(), userID, id, req.RecipeIDs)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"removed": removed})
+}
+
+// ReorderRecipes sets the order of the recipes in one of the current user's
+// collections
+func (h *CollectionHandler) ReorderRecipes(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	id, req, ok := h.bindRecipes(c)
+	if !ok {
+		return
+	}
+
+	if err := h.collectionService.ReorderRecipes(c.Request.Context(), userID, id, req.RecipeIDs); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "collection reordered"})
+}
+
+// loadCollection loads the collection named by the id parameter with its
+// recipes in the viewer's unit system, writing an error response on failure
+func (h *CollectionHandler) loadCollection(c *gin.Context, userID uuid.UUID) (*models.Collection, bool) {
+	id, ok := parseIDParam(c, "id", "invalid collection ID format")
+	if !ok {
+		return nil, false
+	}
+
+	collection, err := h.collectionService.GetCollection(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err)
+		return nil, false
+	}
+	collection.Recipes = localizeRecipes(collection.Recipes, resolveUnitSystem(c, h.db, userID))
+	return collection, true
+}
+
+// bindRecipes parses the collection ID and the list of recipes to change
+func (h *CollectionHandler) bindRecipes(c *gin.Context) (uuid.UUID, *types.CollectionRecipesRequest, bool) {
+	id, ok := parseIDParam(c, "id", "invalid collection ID format")
+	if !ok {
+		return uuid.Nil, nil, false
+	}
+
+	var req types.CollectionRecipesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return uuid.Nil, nil, false
+	}
+	return id, &req, true
+}
+
+// handleError maps collection service errors to responses
+func (h *CollectionHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrCollectionNotFound), errors.Is(err, service.ErrRecipeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidCollection):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCollectionExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
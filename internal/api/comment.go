@@ -0,0 +1,174 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"gorm.io/gorm"
+)
+
+// CommentHandler handles threaded recipe comments
+type CommentHandler struct {
+	db             *gorm.DB
+	commentService service.ICommentService
+	authService    service.IAuthService
+	commentLimiter *middleware.RateLimiter
+}
+
+// NewCommentHandler creates a new CommentHandler. commentLimiter may be nil,
+// which leaves posting comments unlimited.
+func NewCommentHandler(db *gorm.DB, commentService service.ICommentService, authService service.IAuthService, commentLimiter *middleware.RateLimiter) *CommentHandler {
+	return &CommentHandler{
+		db:             db,
+		commentService: commentService,
+		authService:    authService,
+		commentLimiter: commentLimiter,
+	}
+}
+
+// RegisterRoutes registers the comment routes. Writing comments requires a
+// verified email, and posting them is rate limited.
+func (h *CommentHandler) RegisterRoutes(router *gin.RouterGroup) {
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(h.authService))
+	{
+		protected.GET("/recipes/:id/comments", h.ListComments)
+		protected.GET("/comments/:id/history", h.GetCommentHistory)
+	}
+
+	verified := router.Group("")
+	verified.Use(middleware.AuthMiddleware(h.authService))
+	verified.Use(middleware.RequireEmailVerification(h.db))
+	{
+		postGroup := verified.Group("")
+		if h.commentLimiter != nil {
+			postGroup.Use(h.commentLimiter.RateLimitMiddleware())
+		}
+		postGroup.POST("/recipes/:id/comments", h.CreateComment)
+
+		verified.PUT("/comments/:id", h.UpdateComment)
+		verified.DELETE("/comments/:id", h.DeleteComment)
+	}
+}
+
+// ListComments returns one page of a recipe's comment threads
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	recipeID, ok := parseIDParam(c, "id", "invalid recipe ID format")
+	if !ok {
+		return
+	}
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	comments, info, err := h.commentService.ListComments(c.Request.Context(), userID, recipeID, page)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("comments", comments, info))
+}
+
+// CreateComment comments on a recipe or replies to a comment
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	recipeID, ok := parseIDParam(c, "id", "invalid recipe ID format")
+	if !ok {
+		return
+	}
+
+	var req types.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.commentService.CreateComment(c.Request.Context(), userID, recipeID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"comment": comment})
+}
+
+// UpdateComment edits one of the current user's comments
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	commentID, ok := parseIDParam(c, "id", "invalid comment ID format")
+	if !ok {
+		return
+	}
+
+	var req types.UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.commentService.UpdateComment(c.Request.Context(), userID, commentID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"comment": comment})
+}
+
+// DeleteComment deletes a comment. Authors delete their own comments and
+// admins can delete any.
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	commentID, ok := parseIDParam(c, "id", "invalid comment ID format")
+	if !ok {
+		return
+	}
+
+	moderator := c.GetString("role") == models.RoleAdmin
+	if err := h.commentService.DeleteComment(c.Request.Context(), userID, commentID, moderator); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "comment deleted"})
+}
+
+// GetCommentHistory returns the earlier versions of an edited comment
+func (h *CommentHandler) GetCommentHistory(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	commentID, ok := parseIDParam(c, "id", "invalid comment ID format")
+	if !ok {
+		return
+	}
+
+	edits, err := h.commentService.CommentHistory(c.Request.Context(), userID, commentID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"edits": edits})
+}
+
+// handleError maps comment service errors to responses
+func (h *CommentHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRecipeNotFound), errors.Is(err, service.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidComment):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCommentForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
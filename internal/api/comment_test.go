@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// moderationCommentService records how DeleteComment was called and refuses
+// non-moderators, as CommentService does for other users' comments
+type moderationCommentService struct {
+	service.ICommentService
+	moderator bool
+}
+
+func (s *moderationCommentService) DeleteComment(ctx context.Context, userID, commentID uuid.UUID, moderator bool) error {
+	s.moderator = moderator
+	if !moderator {
+		return service.ErrCommentForbidden
+	}
+	return nil
+}
+
+func TestDeleteCommentModeratorFromRoleClaim(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for role, want := range map[string]int{
+		models.RoleAdmin: http.StatusOK,
+		models.RoleUser:  http.StatusForbidden,
+		"":               http.StatusForbidden,
+	} {
+		comments := &moderationCommentService{}
+		handler := NewCommentHandler(nil, comments, nil, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodDelete, "/comments/x", nil)
+		c.Params = gin.Params{{Key: "id", Value: uuid.New().String()}}
+		c.Set("user_id", uuid.New())
+		if role != "" {
+			c.Set("role", role)
+		}
+
+		handler.DeleteComment(c)
+
+		assert.Equal(t, want, w.Code, "role %q", role)
+		assert.Equal(t, role == models.RoleAdmin, comments.moderator, "role %q", role)
+	}
+}
@@ -1,24 +1,37 @@
 package api
 
 import (
+	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/pageza/alchemorsel-v2/backend/internal/service"
 	"gorm.io/gorm"
 )
 
+// defaultRecentFavoritesLimit caps /dashboard/favorites/recent results when
+// no limit is given
+const defaultRecentFavoritesLimit = 5
+
+// maxRecentFavoritesLimit is the largest limit /dashboard/favorites/recent
+// accepts
+const maxRecentFavoritesLimit = 20
+
 // DashboardHandler handles dashboard-related requests
 type DashboardHandler struct {
-	db          *gorm.DB
-	authService service.IAuthService
+	db               *gorm.DB
+	authService      service.IAuthService
+	dashboardService service.IDashboardService
 }
 
 // NewDashboardHandler creates a new DashboardHandler
-func NewDashboardHandler(db *gorm.DB, authService service.IAuthService) *DashboardHandler {
+func NewDashboardHandler(db *gorm.DB, authService service.IAuthService, dashboardService service.IDashboardService) *DashboardHandler {
 	return &DashboardHandler{
-		db:          db,
-		authService: authService,
+		db:               db,
+		authService:      authService,
+		dashboardService: dashboardService,
 	}
 }
 
@@ -31,43 +44,69 @@ func (h *DashboardHandler) RegisterRoutes(router *gin.RouterGroup) {
 	}
 }
 
-// DashboardStats represents dashboard statistics
-type DashboardStats struct {
-	RecipesGenerated int    `json:"recipesGenerated"`
-	Favorites        int    `json:"favorites"`
-	ThisWeek         int    `json:"thisWeek"`
-	PrimaryDiet      string `json:"primaryDiet"`
+// InvalidateStats returns middleware that drops the user's cached dashboard
+// stats after any successful request that may have changed their data. It
+// reads user_id after the request runs, so it works in front of routes that
+// authenticate in their own groups.
+func (h *DashboardHandler) InvalidateStats() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead || c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+		value, _ := c.Get("user_id")
+		userID, ok := value.(uuid.UUID)
+		if !ok {
+			return
+		}
+		if err := h.dashboardService.InvalidateStats(c.Request.Context(), userID); err != nil {
+			log.Printf("Failed to invalidate dashboard stats for user %s: %v", userID, err)
+		}
+	}
 }
 
 // GetStats returns dashboard statistics for the current user
 func (h *DashboardHandler) GetStats(c *gin.Context) {
-	_, exists := c.Get("user_id")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
 		return
 	}
 
-	// TODO: Implement actual statistics calculation
-	// For now, return mock data to prevent frontend errors
-	stats := DashboardStats{
-		RecipesGenerated: 12,
-		Favorites:        8,
-		ThisWeek:         3,
-		PrimaryDiet:      "Mediterranean",
+	stats, err := h.dashboardService.GetStats(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
 	c.JSON(http.StatusOK, stats)
 }
 
-// GetRecentFavorites returns recent favorite recipes for the current user
+// GetRecentFavorites returns the current user's most recently favorited
+// recipes. Optional query parameter: limit caps the results.
 func (h *DashboardHandler) GetRecentFavorites(c *gin.Context) {
-	_, exists := c.Get("user_id")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
 		return
 	}
 
-	// TODO: Implement actual favorites query
-	// For now, return empty array to prevent frontend errors
-	c.JSON(http.StatusOK, []interface{}{})
-}
\ No newline at end of file
+	limit := defaultRecentFavoritesLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxRecentFavoritesLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxRecentFavoritesLimit)})
+			return
+		}
+		limit = n
+	}
+
+	recipes, err := h.dashboardService.RecentFavorites(c.Request.Context(), userID.(uuid.UUID), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, localizeRecipes(recipes, resolveUnitSystem(c, h.db, userID.(uuid.UUID))))
+}
@@ -3,7 +3,6 @@ package api
 import (
 	"fmt"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -89,19 +88,13 @@ func (h *FeedbackHandler) ListFeedback(c *gin.Context) {
 	if userIDParam := c.Query("user_id"); userIDParam != "" {
 		filters.UserID = userIDParam
 	}
-	if limitParam := c.Query("limit"); limitParam != "" {
-		if limit, err := strconv.Atoi(limitParam); err == nil && limit > 0 {
-			filters.Limit = limit
-		}
-	}
-	if offsetParam := c.Query("offset"); offsetParam != "" {
-		if offset, err := strconv.Atoi(offsetParam); err == nil && offset >= 0 {
-			filters.Offset = offset
-		}
+	page, ok := parsePage(c)
+	if !ok {
+		return
 	}
 
 	// Get feedback list
-	feedbackList, err := h.feedbackService.ListFeedback(c.Request.Context(), filters)
+	feedbackList, info, err := h.feedbackService.ListFeedback(c.Request.Context(), filters, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list feedback"})
 		return
@@ -113,7 +106,7 @@ func (h *FeedbackHandler) ListFeedback(c *gin.Context) {
 		responses[i] = h.feedbackToResponse(feedback)
 	}
 
-	c.JSON(http.StatusOK, responses)
+	c.JSON(http.StatusOK, pageResponse("feedback", responses, info))
 }
 
 // GetFeedback gets a specific feedback item (admin only)
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+	"gorm.io/gorm"
+)
+
+// FollowHandler handles following users
+type FollowHandler struct {
+	db            *gorm.DB
+	followService service.IFollowService
+	authService   service.IAuthService
+}
+
+// NewFollowHandler creates a new FollowHandler
+func NewFollowHandler(db *gorm.DB, followService service.IFollowService, authService service.IAuthService) *FollowHandler {
+	return &FollowHandler{
+		db:            db,
+		followService: followService,
+		authService:   authService,
+	}
+}
+
+// RegisterRoutes registers the follow routes. Following someone requires a
+// verified email; unfollowing does not.
+func (h *FollowHandler) RegisterRoutes(router *gin.RouterGroup) {
+	protected := router.Group("/users")
+	protected.Use(middleware.AuthMiddleware(h.authService))
+	{
+		protected.DELETE("/:username/follow", h.Unfollow)
+		protected.GET("/:username/followers", h.ListFollowers)
+		protected.GET("/:username/following", h.ListFollowing)
+	}
+
+	verified := router.Group("/users")
+	verified.Use(middleware.AuthMiddleware(h.authService))
+	verified.Use(middleware.RequireEmailVerification(h.db))
+	{
+		verified.POST("/:username/follow", h.Follow)
+	}
+}
+
+// Follow makes the current user follow a user
+func (h *FollowHandler) Follow(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.followService.Follow(c.Request.Context(), userID, c.Param("username")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"following": true})
+}
+
+// Unfollow stops the current user following a user
+func (h *FollowHandler) Unfollow(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.followService.Unfollow(c.Request.Context(), userID, c.Param("username")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"following": false})
+}
+
+// ListFollowers returns one page of a user's followers, most recent first
+func (h *FollowHandler) ListFollowers(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	users, info, err := h.followService.ListFollowers(c.Request.Context(), userID, c.Param("username"), page)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("followers", users, info))
+}
+
+// ListFollowing returns one page of the users a user follows, most recent
+// first
+func (h *FollowHandler) ListFollowing(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	users, info, err := h.followService.ListFollowing(c.Request.Context(), userID, c.Param("username"), page)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("following", users, info))
+}
+
+// handleError maps follow service errors to responses
+func (h *FollowHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidFollow):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -38,32 +38,73 @@ func RegisterRoutes(router *gin.Engine, db *gorm.DB, authService service.IAuthSe
 	// Create rate limiters
 	var recipeCreationLimiter *middleware.RateLimiter
 	var recipeModificationLimiter *middleware.RateLimiter
+	var commentLimiter *middleware.RateLimiter
 	
 	if redisClient != nil {
 		recipeCreationLimiter = middleware.NewRecipeCreationRateLimiter(redisClient)
 		recipeModificationLimiter = middleware.NewRecipeModificationRateLimiter(redisClient)
+		commentLimiter = middleware.NewCommentRateLimiter(redisClient)
 	}
 
 	// Create email and feedback services
 	emailService := service.NewEmailService()
 	feedbackService := service.NewFeedbackService(db, emailService)
+	nutritionService := service.NewNutritionService(db, llmService)
+	mealPlanService := service.NewMealPlanService(db, service.NewRecipeService(db, embeddingService))
+	pantryService := service.NewPantryService(db, service.NewRecipeService(db, embeddingService))
 	
 	// Create handlers
 	authHandler := NewAuthHandler(authService, emailService, db)
+	trendingService := service.NewTrendingService(db, redisClient)
 	recipeHandler := NewRecipeHandlerWithRateLimit(service.NewRecipeService(db, embeddingService), authService, llmService, embeddingService, db, recipeCreationLimiter, recipeModificationLimiter)
+	analyticsService := service.NewAnalyticsService(db, redisClient)
+	recipeHandler.SetAnalyticsService(analyticsService)
 	llmHandler := NewLLMHandlerWithRateLimit(db, authService.(*service.AuthService), llmService, service.NewRecipeService(db, embeddingService), recipeCreationLimiter)
+	llmHandler.SetNutritionService(nutritionService)
+	llmHandler.SetPantryService(pantryService)
 	profileHandler := NewProfileHandler(service.NewProfileService(db), authService)
-	dashboardHandler := NewDashboardHandler(db, authService)
+	dashboardHandler := NewDashboardHandler(db, authService, service.NewDashboardService(db, mealPlanService, redisClient))
 	feedbackHandler := NewFeedbackHandler(feedbackService, db)
+	nutritionHandler := NewNutritionHandler(nutritionService, service.NewRecipeService(db, embeddingService), authService)
+	substitutionHandler := NewSubstitutionHandler(db, service.NewSubstitutionService(llmService), service.NewRecipeService(db, embeddingService), llmService, nutritionService, authService)
+	mealPlanHandler := NewMealPlanHandler(mealPlanService, authService)
+	pantryHandler := NewPantryHandler(pantryService, authService)
+	reviewHandler := NewReviewHandler(db, service.NewReviewService(db), authService)
+	commentHandler := NewCommentHandler(db, service.NewCommentService(db), authService, commentLimiter)
+	notificationHandler := NewNotificationHandler(service.NewNotificationService(db), authService)
+	collectionHandler := NewCollectionHandler(db, service.NewCollectionService(db), authService)
+	shareHandler := NewShareHandler(db, service.NewShareService(db, []byte(cfg.JWTSecret)), authService)
+	followHandler := NewFollowHandler(db, service.NewFollowService(db), authService)
+	activityHandler := NewActivityHandler(service.NewActivityService(db), authService)
+	trendingHandler := NewTrendingHandler(db, trendingService, authService)
+	analyticsHandler := NewAnalyticsHandler(analyticsService, authService)
+	recommendationHandler := NewRecommendationHandler(db, service.NewRecommendationService(db, embeddingService), authService)
+	shoppingListHandler := NewShoppingListHandler(db, service.NewShoppingListService(db, service.NewRecipeService(db, embeddingService), mealPlanService), authService)
 	
 	fmt.Println("DEBUG: Feedback handler created successfully")
 
 	// Register routes
 	v1 := router.Group("/api/v1")
+	v1.Use(dashboardHandler.InvalidateStats())
 	authHandler.RegisterRoutes(v1)
 	recipeHandler.RegisterRoutes(v1)
 	llmHandler.RegisterRoutes(v1)
 	profileHandler.RegisterRoutes(v1)
+	nutritionHandler.RegisterRoutes(v1)
+	substitutionHandler.RegisterRoutes(v1)
+	mealPlanHandler.RegisterRoutes(v1)
+	shoppingListHandler.RegisterRoutes(v1)
+	pantryHandler.RegisterRoutes(v1)
+	recommendationHandler.RegisterRoutes(v1)
+	reviewHandler.RegisterRoutes(v1)
+	commentHandler.RegisterRoutes(v1)
+	notificationHandler.RegisterRoutes(v1)
+	collectionHandler.RegisterRoutes(v1)
+	shareHandler.RegisterRoutes(v1)
+	followHandler.RegisterRoutes(v1)
+	activityHandler.RegisterRoutes(v1)
+	trendingHandler.RegisterRoutes(v1)
+	analyticsHandler.RegisterRoutes(v1)
 	
 	// Feedback routes (supports both authenticated and anonymous)
 	fmt.Println("DEBUG: Registering feedback routes")
@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
 	"github.com/pageza/alchemorsel-v2/backend/internal/service"
 )
 
@@ -20,6 +22,8 @@ type LLMHandler struct {
 	llmService       service.LLMServiceInterface
 	authService      *service.AuthService
 	recipeService    service.IRecipeService
+	nutritionService service.INutritionService
+	pantryService    service.IPantryService
 	creationLimiter  *middleware.RateLimiter
 }
 
@@ -64,11 +68,84 @@ func NewLLMHandlerWithRateLimit(db *gorm.DB, authService *service.AuthService, l
 	}
 }
 
+// recordGeneration records a generated draft for the dashboard. A failure is
+// logged rather than failing the request, since the draft is already saved.
+func (h *LLMHandler) recordGeneration(c *gin.Context, userID uuid.UUID, draftID, intent string) {
+	if h.db == nil {
+		return
+	}
+	generation := models.RecipeGeneration{UserID: userID, DraftID: draftID, Intent: intent}
+	if err := h.db.WithContext(c.Request.Context()).Create(&generation).Error; err != nil {
+		fmt.Printf("[LLMHandler] Failed to record generation of draft %s: %v\n", draftID, err)
+	}
+}
+
 // SetLLMService sets the LLM service (used for testing)
 func (h *LLMHandler) SetLLMService(service service.LLMServiceInterface) {
 	h.llmService = service
 }
 
+// SetNutritionService sets the service used to recalculate draft macros
+func (h *LLMHandler) SetNutritionService(nutritionService service.INutritionService) {
+	h.nutritionService = nutritionService
+}
+
+// SetPantryService sets the service used for pantry-based generation
+func (h *LLMHandler) SetPantryService(pantryService service.IPantryService) {
+	h.pantryService = pantryService
+}
+
+// pantryQuery adds the user's pantry to a generation query, listing items
+// that are about to expire first. The query is returned unchanged when the
+// pantry is empty or cannot be loaded.
+func (h *LLMHandler) pantryQuery(c *gin.Context, userID uuid.UUID, query string) string {
+	if h.pantryService == nil {
+		return query
+	}
+	items, err := h.pantryService.ListPantryItems(c.Request.Context(), userID)
+	if err != nil {
+		fmt.Printf("[LLMHandler] Failed to load pantry: %v\n", err)
+		return query
+	}
+	prompt := service.BuildPantryPrompt(items, time.Now())
+	if prompt == "" {
+		return query
+	}
+	return query + "\n\n" + prompt
+}
+
+// applyNutrition replaces the LLM's macro estimates on a draft with values
+// calculated from the nutrient database when enough ingredients match
+func (h *LLMHandler) applyNutrition(c *gin.Context, draft *service.RecipeDraft) {
+	applyDraftNutrition(c, h.nutritionService, draft)
+}
+
+// applyDraftNutrition recalculates a draft's nutrition, keeping the existing
+// values when the calculation fails or is not confident enough
+func applyDraftNutrition(c *gin.Context, nutritionService service.INutritionService, draft *service.RecipeDraft) {
+	if nutritionService == nil {
+		return
+	}
+	nutrition, err := nutritionService.CalculateNutrition(c.Request.Context(), draft.Ingredients, service.ParseServings(draft.Servings.Value))
+	if err != nil {
+		fmt.Printf("[LLMHandler] Nutrition calculation failed: %v\n", err)
+		return
+	}
+	if nutrition.Confidence < minDraftNutritionConfidence {
+		fmt.Printf("[LLMHandler] Keeping existing macros, nutrition confidence %.2f\n", nutrition.Confidence)
+		return
+	}
+	draft.Calories = nutrition.PerServing.Calories
+	draft.Protein = nutrition.PerServing.Protein
+	draft.Carbs = nutrition.PerServing.Carbs
+	draft.Fat = nutrition.PerServing.Fat
+	draft.Micronutrients = nutrition.PerServing.Micronutrients
+}
+
+// minDraftNutritionConfidence is the confidence needed before calculated
+// macros replace the ones generated with a draft
+const minDraftNutritionConfidence = 0.5
+
 // RegisterRoutes registers the LLM routes
 func (h *LLMHandler) RegisterRoutes(router *gin.RouterGroup) {
 	llm := router.Group("/llm")
@@ -82,12 +159,15 @@ func (h *LLMHandler) RegisterRoutes(router *gin.RouterGroup) {
 	}
 }
 
-// QueryRequest represents a request to query the LLM
+// QueryRequest represents a request to query the LLM. UsePantry asks a
+// generation to cook from the user's pantry, using items that are about to
+// expire first.
 type QueryRequest struct {
-	Query    string `json:"query" binding:"required"`
-	Intent   string `json:"intent" binding:"required"`
-	DraftID  string `json:"draft_id,omitempty"`
-	RecipeID string `json:"recipe_id,omitempty"`
+	Query     string `json:"query" binding:"required"`
+	Intent    string `json:"intent" binding:"required"`
+	DraftID   string `json:"draft_id,omitempty"`
+	RecipeID  string `json:"recipe_id,omitempty"`
+	UsePantry bool   `json:"use_pantry,omitempty"`
 }
 
 // Query handles recipe generation and modification requests
@@ -157,7 +237,7 @@ func (h *LLMHandler) Query(c *gin.Context) {
 			return
 		}
 		
-		originalRecipe, err := h.recipeService.GetRecipe(c.Request.Context(), recipeUUID)
+		originalRecipe, err := h.recipeService.GetRecipe(c.Request.Context(), userID, recipeUUID)
 		if err != nil {
 			fmt.Printf("[LLMHandler] Error getting original recipe: %v\n", err)
 			c.JSON(http.StatusNotFound, gin.H{"error": "original recipe not found"})
@@ -201,6 +281,8 @@ func (h *LLMHandler) Query(c *gin.Context) {
 		}
 		
 		newRecipe.UserID = userID.String()
+		newRecipe.ForkedFromID = originalRecipe.ID.String()
+		h.applyNutrition(c, &newRecipe)
 		if err := h.llmService.SaveDraft(c.Request.Context(), &newRecipe); err != nil {
 			fmt.Printf("[LLMHandler] Error saving forked draft: %v\n", err)
 			// Don't increment rate limit counter on save failure
@@ -208,6 +290,8 @@ func (h *LLMHandler) Query(c *gin.Context) {
 			return
 		}
 		
+		h.recordGeneration(c, userID, newRecipe.ID, "fork")
+		
 		// Only increment rate limit counter on successful fork generation and save
 		if h.creationLimiter != nil {
 			if err := h.creationLimiter.IncrementUsage(c.Request.Context(), userID.String()); err != nil {
@@ -219,7 +303,7 @@ func (h *LLMHandler) Query(c *gin.Context) {
 		
 		fmt.Printf("[LLMHandler] Successfully forked recipe. New Draft ID: %s\n", newRecipe.ID)
 		c.JSON(http.StatusOK, gin.H{
-			"recipe":   newRecipe,
+			"recipe":   localizeDraft(&newRecipe, resolveUnitSystem(c, h.db, userID)),
 			"draft_id": newRecipe.ID,
 		})
 		fmt.Println("[LLMHandler] Responded 200 OK with forked recipe and draft_id.")
@@ -244,7 +328,11 @@ func (h *LLMHandler) Query(c *gin.Context) {
 			}
 		}
 		
-		recipeJSON, err := h.llmService.GenerateRecipe(req.Query, []string{}, []string{}, nil)
+		query := req.Query
+		if req.UsePantry {
+			query = h.pantryQuery(c, userID, query)
+		}
+		recipeJSON, err := h.llmService.GenerateRecipe(query, []string{}, []string{}, nil)
 		if err != nil {
 			fmt.Printf("[LLMHandler] Error generating recipe: %v\n", err)
 			// Don't increment rate limit counter on generation failure
@@ -267,6 +355,7 @@ func (h *LLMHandler) Query(c *gin.Context) {
 			return
 		}
 		recipe.UserID = userID.String()
+		h.applyNutrition(c, &recipe)
 		if err := h.llmService.SaveDraft(c.Request.Context(), &recipe); err != nil {
 			fmt.Printf("[LLMHandler] Error saving draft: %v\n", err)
 			// Don't increment rate limit counter on save failure
@@ -274,6 +363,8 @@ func (h *LLMHandler) Query(c *gin.Context) {
 			return
 		}
 		
+		h.recordGeneration(c, userID, recipe.ID, "generate")
+		
 		// Only increment rate limit counter on successful generation and save
 		if h.creationLimiter != nil {
 			if err := h.creationLimiter.IncrementUsage(c.Request.Context(), userID.String()); err != nil {
@@ -285,7 +376,7 @@ func (h *LLMHandler) Query(c *gin.Context) {
 		
 		fmt.Printf("[LLMHandler] Successfully generated and saved draft. Recipe ID: %s\n", recipe.ID)
 		c.JSON(http.StatusOK, gin.H{
-			"recipe":   recipe,
+			"recipe":   localizeDraft(&recipe, resolveUnitSystem(c, h.db, userID)),
 			"draft_id": recipe.ID,
 		})
 		fmt.Println("[LLMHandler] Responded 200 OK with recipe and draft_id.")
@@ -352,6 +443,7 @@ func (h *LLMHandler) Query(c *gin.Context) {
 		draft.Protein = updatedRecipe.Protein
 		draft.Carbs = updatedRecipe.Carbs
 		draft.Fat = updatedRecipe.Fat
+		h.applyNutrition(c, draft)
 		if err := h.llmService.UpdateDraft(c.Request.Context(), draft); err != nil {
 			fmt.Printf("[LLMHandler] Error updating draft: %v\n", err)
 			// Don't increment rate limit counter on save failure
@@ -370,7 +462,7 @@ func (h *LLMHandler) Query(c *gin.Context) {
 		
 		fmt.Printf("[LLMHandler] Successfully modified and updated draft. Draft ID: %s\n", draft.ID)
 		c.JSON(http.StatusOK, gin.H{
-			"recipe":   draft,
+			"recipe":   localizeDraft(draft, resolveUnitSystem(c, h.db, userID)),
 			"draft_id": draft.ID,
 		})
 		fmt.Println("[LLMHandler] Responded 200 OK with modified recipe and draft_id.")
@@ -412,7 +504,7 @@ func (h *LLMHandler) GetDraft(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"draft": draft})
+	c.JSON(http.StatusOK, gin.H{"draft": localizeDraft(draft, resolveUnitSystem(c, h.db, userID))})
 }
 
 // DeleteDraft removes a recipe draft
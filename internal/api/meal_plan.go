@@ -0,0 +1,232 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+)
+
+// MealPlanHandler handles meal plan requests
+type MealPlanHandler struct {
+	mealPlanService service.IMealPlanService
+	authService     service.IAuthService
+}
+
+// NewMealPlanHandler creates a new MealPlanHandler
+func NewMealPlanHandler(mealPlanService service.IMealPlanService, authService service.IAuthService) *MealPlanHandler {
+	return &MealPlanHandler{
+		mealPlanService: mealPlanService,
+		authService:     authService,
+	}
+}
+
+// RegisterRoutes registers the meal plan routes
+func (h *MealPlanHandler) RegisterRoutes(router *gin.RouterGroup) {
+	plans := router.Group("/meal-plans")
+	plans.Use(middleware.AuthMiddleware(h.authService))
+	{
+		plans.GET("", h.ListMealPlans)
+		plans.POST("", h.CreateMealPlan)
+		plans.GET("/:id", h.GetMealPlan)
+		plans.PUT("/:id", h.UpdateMealPlan)
+		plans.DELETE("/:id", h.DeleteMealPlan)
+		plans.POST("/:id/entries", h.SetEntry)
+		plans.DELETE("/:id/entries/:entry_id", h.DeleteEntry)
+		plans.POST("/:id/auto-plan", h.AutoPlan)
+	}
+}
+
+// ListMealPlans returns the current user's meal plans
+func (h *MealPlanHandler) ListMealPlans(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	plans, err := h.mealPlanService.ListMealPlans(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"meal_plans": plans})
+}
+
+// CreateMealPlan creates a meal plan
+func (h *MealPlanHandler) CreateMealPlan(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var req types.CreateMealPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.mealPlanService.CreateMealPlan(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, mealPlanResponse(plan))
+}
+
+// GetMealPlan returns a meal plan with its entries grouped by day
+func (h *MealPlanHandler) GetMealPlan(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	planID, ok := parseIDParam(c, "id", "invalid meal plan ID format")
+	if !ok {
+		return
+	}
+
+	plan, err := h.mealPlanService.GetMealPlan(c.Request.Context(), userID, planID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mealPlanResponse(plan))
+}
+
+// UpdateMealPlan updates a meal plan
+func (h *MealPlanHandler) UpdateMealPlan(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	planID, ok := parseIDParam(c, "id", "invalid meal plan ID format")
+	if !ok {
+		return
+	}
+
+	var req types.UpdateMealPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.mealPlanService.UpdateMealPlan(c.Request.Context(), userID, planID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mealPlanResponse(plan))
+}
+
+// DeleteMealPlan deletes a meal plan
+func (h *MealPlanHandler) DeleteMealPlan(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	planID, ok := parseIDParam(c, "id", "invalid meal plan ID format")
+	if !ok {
+		return
+	}
+
+	if err := h.mealPlanService.DeleteMealPlan(c.Request.Context(), userID, planID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "meal plan deleted"})
+}
+
+// SetEntry places a recipe in a slot of a meal plan
+func (h *MealPlanHandler) SetEntry(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	planID, ok := parseIDParam(c, "id", "invalid meal plan ID format")
+	if !ok {
+		return
+	}
+
+	var req types.MealPlanEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := h.mealPlanService.SetEntry(c.Request.Context(), userID, planID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entry": entry})
+}
+
+// DeleteEntry removes an entry from a meal plan
+func (h *MealPlanHandler) DeleteEntry(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	planID, ok := parseIDParam(c, "id", "invalid meal plan ID format")
+	if !ok {
+		return
+	}
+	entryID, ok := parseIDParam(c, "entry_id", "invalid entry ID format")
+	if !ok {
+		return
+	}
+
+	if err := h.mealPlanService.DeleteEntry(c.Request.Context(), userID, planID, entryID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "entry deleted"})
+}
+
+// AutoPlan fills a meal plan from the user's favorites and other recipes that
+// match their dietary preferences, allergens and targets
+func (h *MealPlanHandler) AutoPlan(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	planID, ok := parseIDParam(c, "id", "invalid meal plan ID format")
+	if !ok {
+		return
+	}
+
+	var req types.AutoPlanRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	plan, err := h.mealPlanService.AutoPlan(c.Request.Context(), userID, planID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mealPlanResponse(plan))
+}
+
+// handleError maps meal plan service errors to responses
+func (h *MealPlanHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrMealPlanNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidMealPlan):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNoMealCandidates):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func mealPlanResponse(plan *models.MealPlan) gin.H {
+	return gin.H{
+		"meal_plan": plan,
+		"days":      service.SummarizeMealPlan(plan),
+	}
+}
+
+// parseIDParam parses a UUID path parameter, writing a bad request response
+// and returning false when it is malformed
+func parseIDParam(c *gin.Context, name, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+)
+
+// NotificationHandler handles the current user's notifications
+type NotificationHandler struct {
+	notificationService service.INotificationService
+	authService         service.IAuthService
+}
+
+// NewNotificationHandler creates a new NotificationHandler
+func NewNotificationHandler(notificationService service.INotificationService, authService service.IAuthService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		authService:         authService,
+	}
+}
+
+// RegisterRoutes registers the notification routes
+func (h *NotificationHandler) RegisterRoutes(router *gin.RouterGroup) {
+	protected := router.Group("/notifications")
+	protected.Use(middleware.AuthMiddleware(h.authService))
+	{
+		protected.GET("", h.ListNotifications)
+		protected.POST("/read", h.MarkAllRead)
+		protected.POST("/:id/read", h.MarkRead)
+	}
+}
+
+// ListNotifications returns one page of the current user's notifications,
+// newest first, with the unread count. Optional query parameter: unread=true
+// lists only unread notifications.
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	notifications, info, err := h.notificationService.ListNotifications(c.Request.Context(), userID, c.Query("unread") == "true", page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	unread, err := h.notificationService.UnreadCount(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := pageResponse("notifications", notifications, info)
+	resp["unread"] = unread
+	c.JSON(http.StatusOK, resp)
+}
+
+// MarkRead marks one notification as read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	id, ok := parseIDParam(c, "id", "invalid notification ID format")
+	if !ok {
+		return
+	}
+
+	if err := h.notificationService.MarkRead(c.Request.Context(), userID, id); err != nil {
+		if errors.Is(err, service.ErrNotificationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "notification marked as read"})
+}
+
+// MarkAllRead marks all of the current user's notifications as read
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	marked, err := h.notificationService.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"marked": marked})
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+	"gorm.io/gorm"
+)
+
+// NutritionHandler handles nutrition-related requests
+type NutritionHandler struct {
+	nutritionService service.INutritionService
+	recipeService    service.IRecipeService
+	authService      service.IAuthService
+}
+
+// NewNutritionHandler creates a new NutritionHandler
+func NewNutritionHandler(nutritionService service.INutritionService, recipeService service.IRecipeService, authService service.IAuthService) *NutritionHandler {
+	return &NutritionHandler{
+		nutritionService: nutritionService,
+		recipeService:    recipeService,
+		authService:      authService,
+	}
+}
+
+// RegisterRoutes registers the nutrition routes
+func (h *NutritionHandler) RegisterRoutes(router *gin.RouterGroup) {
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(h.authService))
+	{
+		protected.GET("/recipes/:id/nutrition", h.GetRecipeNutrition)
+		protected.GET("/recipes/:id/nutrition-label", h.GetNutritionLabel)
+		protected.POST("/nutrition/calculate", h.Calculate)
+	}
+}
+
+// GetRecipeNutrition calculates nutrition for a stored recipe
+func (h *NutritionHandler) GetRecipeNutrition(c *gin.Context) {
+	recipe, nutrition, ok := h.recipeNutrition(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recipe_id": recipe.ID,
+		"nutrition": nutrition,
+	})
+}
+
+// GetNutritionLabel returns a per-serving nutrition facts label for a recipe.
+// The format query parameter selects json (default), svg or html.
+func (h *NutritionHandler) GetNutritionLabel(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "svg" && format != "html" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json, svg or html"})
+		return
+	}
+
+	recipe, nutrition, ok := h.recipeNutrition(c)
+	if !ok {
+		return
+	}
+	label := service.BuildNutritionLabel(nutrition)
+
+	switch format {
+	case "svg":
+		svg, err := service.RenderNutritionLabelSVG(label)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "image/svg+xml; charset=utf-8", []byte(svg))
+	case "html":
+		html, err := service.RenderNutritionLabelHTML(label)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"recipe_id": recipe.ID,
+			"label":     label,
+		})
+	}
+}
+
+// recipeNutrition loads the recipe named in the path and calculates its
+// nutrition, writing an error response and returning false on failure
+func (h *NutritionHandler) recipeNutrition(c *gin.Context) (*models.Recipe, *service.RecipeNutrition, bool) {
+	recipeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe ID format"})
+		return nil, nil, false
+	}
+
+	recipe, err := h.recipeService.GetRecipe(c.Request.Context(), c.MustGet("user_id").(uuid.UUID), recipeID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "recipe not found"})
+			return nil, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+
+	nutrition, err := h.nutritionService.CalculateRecipeNutrition(c.Request.Context(), recipe)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+	return recipe, nutrition, true
+}
+
+// Calculate calculates nutrition for an arbitrary ingredient list
+func (h *NutritionHandler) Calculate(c *gin.Context) {
+	var req struct {
+		Ingredients []string `json:"ingredients" binding:"required"`
+		Servings    int      `json:"servings"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	nutrition, err := h.nutritionService.CalculateNutrition(c.Request.Context(), req.Ingredients, req.Servings)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"nutrition": nutrition})
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+)
+
+// parsePage reads the limit and cursor query parameters, writing a 400
+// response and returning false if either is invalid. offset, which lists took
+// before cursors, is still accepted in place of a cursor.
+func parsePage(c *gin.Context) (pagination.Page, bool) {
+	var page pagination.Page
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > pagination.MaxLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(pagination.MaxLimit)})
+			return page, false
+		}
+		page.Limit = n
+	}
+	if v := c.Query("cursor"); v != "" {
+		cursor, err := pagination.Decode(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return page, false
+		}
+		page.After = cursor
+	} else if v := c.Query("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative number"})
+			return page, false
+		}
+		page.After = &pagination.Cursor{Offset: n}
+	}
+	return page, true
+}
+
+// pageResponse builds a list response holding items under key alongside the
+// page's limit, next cursor and, when known, total
+func pageResponse(key string, items interface{}, info *pagination.Info) gin.H {
+	resp := gin.H{key: items, "limit": info.Limit}
+	if info.NextCursor != "" {
+		resp["next_cursor"] = info.NextCursor
+	}
+	if info.Total != nil {
+		resp["total"] = *info.Total
+	}
+	return resp
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	parse := func(query string) (pagination.Page, int) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+		page, ok := parsePage(c)
+		if !ok {
+			return page, w.Code
+		}
+		return page, http.StatusOK
+	}
+
+	page, code := parse("limit=10")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, pagination.Page{Limit: 10}, page)
+
+	// The offset parameter lists took before cursors still works
+	page, code = parse("limit=10&offset=30")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, &pagination.Cursor{Offset: 30}, page.After)
+
+	// A cursor takes precedence over an offset
+	page, code = parse("offset=30&cursor=" + pagination.Cursor{Offset: 5}.Encode())
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 5, page.After.Offset)
+
+	for _, bad := range []string{"limit=0", "limit=101", "offset=-1", "offset=ten", "cursor=nope!"} {
+		_, code := parse(bad)
+		assert.Equal(t, http.StatusBadRequest, code, bad)
+	}
+}
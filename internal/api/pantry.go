@@ -0,0 +1,160 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+)
+
+// defaultCookableLimit caps /recipes/cookable results when no limit is given
+const defaultCookableLimit = 20
+
+// PantryHandler handles pantry requests and pantry-based recipe search
+type PantryHandler struct {
+	pantryService service.IPantryService
+	authService   service.IAuthService
+}
+
+// NewPantryHandler creates a new PantryHandler
+func NewPantryHandler(pantryService service.IPantryService, authService service.IAuthService) *PantryHandler {
+	return &PantryHandler{
+		pantryService: pantryService,
+		authService:   authService,
+	}
+}
+
+// RegisterRoutes registers the pantry routes
+func (h *PantryHandler) RegisterRoutes(router *gin.RouterGroup) {
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(h.authService))
+	{
+		protected.GET("/pantry", h.ListPantryItems)
+		protected.POST("/pantry", h.AddPantryItem)
+		protected.PUT("/pantry/:id", h.UpdatePantryItem)
+		protected.DELETE("/pantry/:id", h.DeletePantryItem)
+		protected.GET("/recipes/cookable", h.CookableRecipes)
+	}
+}
+
+// ListPantryItems returns the current user's pantry
+func (h *PantryHandler) ListPantryItems(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	items, err := h.pantryService.ListPantryItems(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// AddPantryItem adds an ingredient to the pantry
+func (h *PantryHandler) AddPantryItem(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var req types.CreatePantryItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := h.pantryService.AddPantryItem(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"item": item})
+}
+
+// UpdatePantryItem updates a pantry item
+func (h *PantryHandler) UpdatePantryItem(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	itemID, ok := parseIDParam(c, "id", "invalid pantry item ID format")
+	if !ok {
+		return
+	}
+
+	var req types.UpdatePantryItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := h.pantryService.UpdatePantryItem(c.Request.Context(), userID, itemID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"item": item})
+}
+
+// DeletePantryItem removes an item from the pantry
+func (h *PantryHandler) DeletePantryItem(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	itemID, ok := parseIDParam(c, "id", "invalid pantry item ID format")
+	if !ok {
+		return
+	}
+
+	if err := h.pantryService.DeletePantryItem(c.Request.Context(), userID, itemID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "pantry item deleted"})
+}
+
+// CookableRecipes ranks recipes by how much of them the pantry covers.
+// Optional query parameters: q narrows the recipes searched, max_missing
+// drops recipes missing more ingredients and limit caps the results.
+func (h *PantryHandler) CookableRecipes(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	maxMissing := -1
+	if v := c.Query("max_missing"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "max_missing must be a non-negative integer"})
+			return
+		}
+		maxMissing = n
+	}
+	limit := defaultCookableLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+			return
+		}
+		limit = n
+	}
+
+	results, err := h.pantryService.CookableRecipes(c.Request.Context(), userID, c.Query("q"), maxMissing, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recipes": results})
+}
+
+// handleError maps pantry service errors to responses
+func (h *PantryHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrPantryItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidPantryItem):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,11 +1,13 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
 	"github.com/pageza/alchemorsel-v2/backend/internal/service"
 	"github.com/pageza/alchemorsel-v2/backend/internal/types"
 )
@@ -39,6 +41,10 @@ func (h *ProfileHandler) RegisterRoutes(router *gin.RouterGroup) {
 		profile.GET("/recipes", h.GetUserRecipes)
 		profile.GET("/history", h.GetProfileHistory)
 	}
+
+	// Public profiles can be read without signing in
+	router.GET("/users/:username", h.GetUserProfile)
+	router.GET("/users/:username/recipes", h.GetPublicRecipes)
 }
 
 func (h *ProfileHandler) GetProfile(c *gin.Context) {
@@ -58,11 +64,13 @@ func (h *ProfileHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	recipes, err := h.profileService.GetUserRecipes(c.Request.Context(), userID)
+	// The first page of recipes; later pages come from /profile/recipes
+	recipes, info, err := h.profileService.GetUserRecipes(c.Request.Context(), userID, pagination.Page{})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	recipes = localizeRecipes(recipes, requestedUnitSystem(c, profile.UnitSystem))
 
 	// Combine user and profile data
 	profileData := gin.H{
@@ -75,15 +83,20 @@ func (h *ProfileHandler) GetProfile(c *gin.Context) {
 		"bio":                 profile.Bio,
 		"profile_picture_url": profile.ProfilePictureURL,
 		"privacy_level":       profile.PrivacyLevel,
+		"unit_system":         profile.UnitSystem,
 		"created_at":          user.CreatedAt,
 		"updated_at":          user.UpdatedAt,
 	}
 
 
-	c.JSON(http.StatusOK, gin.H{
+	resp := gin.H{
 		"profile": profileData,
 		"recipes": recipes,
-	})
+	}
+	if info.NextCursor != "" {
+		resp["recipes_next_cursor"] = info.NextCursor
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
@@ -96,6 +109,10 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 	userID := c.MustGet("user_id").(uuid.UUID)
 	profile, err := h.profileService.UpdateProfile(c.Request.Context(), userID, &req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidUnitSystem) || errors.Is(err, service.ErrInvalidPrivacyLevel) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -113,12 +130,20 @@ func (h *ProfileHandler) Logout(c *gin.Context) {
 
 func (h *ProfileHandler) GetUserRecipes(c *gin.Context) {
 	userID := c.MustGet("user_id").(uuid.UUID)
-	recipes, err := h.profileService.GetUserRecipes(c.Request.Context(), userID)
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+	recipes, info, err := h.profileService.GetUserRecipes(c.Request.Context(), userID, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, recipes)
+	preference := ""
+	if profile, err := h.profileService.GetProfile(c.Request.Context(), userID); err == nil {
+		preference = profile.UnitSystem
+	}
+	c.JSON(http.StatusOK, pageResponse("recipes", localizeRecipes(recipes, requestedUnitSystem(c, preference)), info))
 }
 
 func (h *ProfileHandler) GetProfileHistory(c *gin.Context) {
@@ -131,6 +156,40 @@ func (h *ProfileHandler) GetProfileHistory(c *gin.Context) {
 	c.JSON(http.StatusOK, history)
 }
 
+// GetUserProfile returns a user's public profile with their first page of
+// public recipes, public collections and recent activity
+func (h *ProfileHandler) GetUserProfile(c *gin.Context) {
+	profile, err := h.profileService.GetUserProfile(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	profile.Recipes = localizeRecipes(profile.Recipes, requestedUnitSystem(c, ""))
+	c.JSON(http.StatusOK, gin.H{"profile": profile})
+}
+
+// GetPublicRecipes returns one page of a user's public recipes, newest first
+func (h *ProfileHandler) GetPublicRecipes(c *gin.Context) {
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+	recipes, info, err := h.profileService.GetPublicRecipes(c.Request.Context(), c.Param("username"), page)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, pageResponse("recipes", localizeRecipes(recipes, requestedUnitSystem(c, "")), info))
+}
+
 // RegisterProfileRoutes registers the profile API routes
 func RegisterProfileRoutes(router *gin.Engine, profileService service.IProfileService, authService service.IAuthService) {
 	handler := NewProfileHandler(profileService, authService)
@@ -11,6 +11,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
 	"github.com/pageza/alchemorsel-v2/backend/internal/testhelpers/mocks"
 	"github.com/pageza/alchemorsel-v2/backend/internal/types"
 	"github.com/stretchr/testify/assert"
@@ -48,8 +49,9 @@ func TestGetProfile(t *testing.T) {
 	}
 
 	// Setup mock expectations
+	mockAuthService.On("GetUserByID", context.Background(), testUUID).Return(&models.User{ID: testUUID}, nil)
 	mockProfileService.On("GetProfile", context.Background(), testUUID).Return(expectedProfile, nil)
-	mockProfileService.On("GetUserRecipes", context.Background(), testUUID).Return(expectedRecipes, nil)
+	mockProfileService.On("GetUserRecipes", context.Background(), testUUID, pagination.Page{}).Return(expectedRecipes, &pagination.Info{Limit: pagination.DefaultLimit}, nil)
 
 	// Create test request
 	w := httptest.NewRecorder()
@@ -135,3 +137,21 @@ func TestUpdateProfile(t *testing.T) {
 	// Verify mock expectations
 	mockProfileService.AssertExpectations(t)
 }
+
+func TestUpdateProfileRejectsInvalidPrivacyLevel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockAuthService := new(mocks.MockAuthService)
+	mockProfileService := new(mocks.MockProfileService)
+	profileHandler := NewProfileHandler(mockProfileService, mockAuthService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPut, "/profile", bytes.NewBufferString(`{"privacy_level": "friends"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("user_id", uuid.New())
+
+	profileHandler.UpdateProfile(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockProfileService.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -1,14 +1,16 @@
 package api
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
 	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
 	"github.com/pageza/alchemorsel-v2/backend/internal/service"
 	pgvector "github.com/pgvector/pgvector-go"
 	"gorm.io/gorm"
@@ -23,6 +25,7 @@ type RecipeHandler struct {
 	db                       *gorm.DB
 	creationRateLimiter      *middleware.RateLimiter
 	modificationRateLimiter  *middleware.RateLimiter
+	analyticsService         service.IAnalyticsService
 }
 
 // NewRecipeHandler creates a new RecipeHandler
@@ -49,12 +52,16 @@ func NewRecipeHandlerWithRateLimit(recipeService service.IRecipeService, authSer
 	}
 }
 
+// SetAnalyticsService sets the service that counts recipe views
+func (h *RecipeHandler) SetAnalyticsService(analyticsService service.IAnalyticsService) {
+	h.analyticsService = analyticsService
+}
+
 // RegisterRoutes registers the recipe routes
 func (h *RecipeHandler) RegisterRoutes(router *gin.RouterGroup) {
 	recipes := router.Group("/recipes")
 	
-	// Public routes (no authentication required) - ONLY for landing page
-	recipes.GET("/featured", h.GetFeaturedRecipes)
+	// Featured recipes for the landing page are served by TrendingHandler
 	
 	// Protected routes (authentication required) - recipe browsing
 	protected := recipes.Group("")
@@ -62,7 +69,9 @@ func (h *RecipeHandler) RegisterRoutes(router *gin.RouterGroup) {
 	{
 		protected.GET("", h.ListRecipes)
 		protected.GET("/search", h.SearchRecipes)
+		protected.GET("/favorites", h.ListFavoriteRecipes)
 		protected.GET("/:id", h.GetRecipe)
+		protected.GET("/:id/similar", h.GetSimilarRecipes)
 	}
 	
 	// Email verification required routes (authentication + email verification)
@@ -110,20 +119,26 @@ func (h *RecipeHandler) CreateRecipe(c *gin.Context) {
 	}
 	fmt.Printf("[DEBUG] user_id: %s\n", userID.String())
 	var req struct {
-		Name               string    `json:"name" binding:"required"`
-		Description        string    `json:"description" binding:"required"`
-		Category           string    `json:"category" binding:"required"`
-		Cuisine            string    `json:"cuisine"`
-		ImageURL           string    `json:"image_url"`
-		Ingredients        []string  `json:"ingredients" binding:"required"`
-		Instructions       []string  `json:"instructions" binding:"required"`
-		Calories           float64   `json:"calories"`
-		Protein            float64   `json:"protein"`
-		Carbs              float64   `json:"carbs"`
-		Fat                float64   `json:"fat"`
-		DietaryPreferences []string  `json:"dietary_preferences"`
-		Tags               []string  `json:"tags"`
-		Embedding          []float32 `json:"embedding"`
+		Name               string     `json:"name" binding:"required"`
+		Description        string     `json:"description" binding:"required"`
+		Category           string     `json:"category" binding:"required"`
+		Cuisine            string     `json:"cuisine"`
+		ImageURL           string     `json:"image_url"`
+		Ingredients        []string   `json:"ingredients" binding:"required"`
+		Instructions       []string   `json:"instructions" binding:"required"`
+		Calories           float64    `json:"calories"`
+		Protein            float64    `json:"protein"`
+		Carbs              float64    `json:"carbs"`
+		Fat                float64    `json:"fat"`
+		Servings           int        `json:"servings"`
+		PrepTime           int        `json:"prep_time_minutes"`
+		CookTime           int        `json:"cook_time_minutes"`
+		DietaryPreferences []string   `json:"dietary_preferences"`
+		Tags               []string   `json:"tags"`
+		Embedding          []float32  `json:"embedding"`
+		ForkedFromID       *uuid.UUID `json:"forked_from_id"`
+		Visibility         string     `json:"visibility" binding:"omitempty,oneof=private unlisted public"`
+		models.Micronutrients
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -132,6 +147,18 @@ func (h *RecipeHandler) CreateRecipe(c *gin.Context) {
 		return
 	}
 
+	// A fork can only name a recipe the user is allowed to see
+	if req.ForkedFromID != nil {
+		if _, err := h.recipeService.GetRecipe(c.Request.Context(), userID, *req.ForkedFromID); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "recipe not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	// Create a copy of the request for logging without the full embedding
 	logReq := req
 	if len(logReq.Embedding) > 0 {
@@ -143,8 +170,11 @@ func (h *RecipeHandler) CreateRecipe(c *gin.Context) {
 		logReq.Ingredients, logReq.Instructions, logReq.Calories, logReq.Protein, logReq.Carbs, logReq.Fat,
 		logReq.DietaryPreferences, logReq.Tags, logReq.Embedding)
 
-	// Create embedding vector if not provided
+	// Create embedding vector if not provided. A provided vector's model is
+	// unknown, so the reindex worker replaces it with the current model's.
 	var embedding pgvector.Vector
+	var embeddingModel string
+	var embeddingDims int
 	if len(req.Embedding) > 0 {
 		embedding = pgvector.NewVector(req.Embedding)
 	} else {
@@ -162,6 +192,8 @@ func (h *RecipeHandler) CreateRecipe(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate embedding"})
 			return
 		}
+		embeddingModel = h.embeddingService.Model()
+		embeddingDims = h.embeddingService.Dimensions()
 	}
 
 	recipe := &models.Recipe{
@@ -176,10 +208,18 @@ func (h *RecipeHandler) CreateRecipe(c *gin.Context) {
 		Protein:            req.Protein,
 		Carbs:              req.Carbs,
 		Fat:                req.Fat,
+		Servings:           req.Servings,
+		PrepTime:           req.PrepTime,
+		CookTime:           req.CookTime,
+		Micronutrients:     req.Micronutrients,
 		DietaryPreferences: models.JSONBStringArray(req.DietaryPreferences),
 		Tags:               models.JSONBStringArray(req.Tags),
 		UserID:             userID,
 		Embedding:          embedding,
+		EmbeddingModel:     embeddingModel,
+		EmbeddingDims:      embeddingDims,
+		ForkedFromID:       req.ForkedFromID,
+		Visibility:         req.Visibility,
 	}
 
 	createdRecipe, err := h.recipeService.CreateRecipe(c.Request.Context(), recipe)
@@ -216,7 +256,7 @@ func (h *RecipeHandler) GetRecipe(c *gin.Context) {
 		return
 	}
 
-	recipe, err := h.recipeService.GetRecipe(c.Request.Context(), recipeID)
+	recipe, err := h.recipeService.GetRecipe(c.Request.Context(), userID, recipeID)
 	if err != nil {
 		fmt.Printf("[DEBUG] Error getting recipe: %v\n", err)
 		if err == gorm.ErrRecordNotFound {
@@ -233,25 +273,22 @@ func (h *RecipeHandler) GetRecipe(c *gin.Context) {
 		recipe.Ingredients, recipe.Instructions, recipe.Calories, recipe.Protein, recipe.Carbs, recipe.Fat,
 		recipe.UserID, recipe.DietaryPreferences, recipe.Tags)
 
-	// Add favorite status
-	favoriteRecipes, err := h.recipeService.GetFavoriteRecipes(c.Request.Context(), userID)
-	isFavorite := false
-	if err == nil {
-		for _, fav := range favoriteRecipes {
-			if fav.ID == recipe.ID {
-				isFavorite = true
-				break
-			}
+	// Count views by other users for trending and creator analytics
+	if h.analyticsService != nil && recipe.UserID != userID {
+		if err := h.analyticsService.RecordView(c.Request.Context(), recipe.ID, userID); err != nil {
+			log.Printf("Failed to record recipe view: %v", err)
 		}
 	}
-	
+
+	// Render quantities in the user's preferred unit system
+	recipe = localizeRecipe(recipe, resolveUnitSystem(c, h.db, userID))
+
+	// Add favorite status
+	favorites, err := h.recipeService.FavoriteRecipeIDs(c.Request.Context(), userID, []uuid.UUID{recipe.ID})
+	isFavorite := err == nil && favorites[recipe.ID]
+
 	// Return recipe with favorite status
-	type RecipeResponse struct {
-		*models.Recipe
-		IsFavorite bool `json:"is_favorite"`
-	}
-	
-	c.JSON(http.StatusOK, gin.H{"recipe": RecipeResponse{
+	c.JSON(http.StatusOK, gin.H{"recipe": recipeWithFavorite{
 		Recipe:     recipe,
 		IsFavorite: isFavorite,
 	}})
@@ -283,8 +320,13 @@ func (h *RecipeHandler) UpdateRecipe(c *gin.Context) {
 		Protein            float64  `json:"protein"`
 		Carbs              float64  `json:"carbs"`
 		Fat                float64  `json:"fat"`
+		Servings           int      `json:"servings"`
+		PrepTime           int      `json:"prep_time_minutes"`
+		CookTime           int      `json:"cook_time_minutes"`
 		DietaryPreferences []string `json:"dietary_preferences"`
 		Tags               []string `json:"tags"`
+		Visibility         string   `json:"visibility" binding:"omitempty,oneof=private unlisted public"`
+		models.Micronutrients
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -304,12 +346,22 @@ func (h *RecipeHandler) UpdateRecipe(c *gin.Context) {
 		Protein:            req.Protein,
 		Carbs:              req.Carbs,
 		Fat:                req.Fat,
+		Servings:           req.Servings,
+		PrepTime:           req.PrepTime,
+		CookTime:           req.CookTime,
+		Micronutrients:     req.Micronutrients,
 		DietaryPreferences: models.JSONBStringArray(req.DietaryPreferences),
 		Tags:               models.JSONBStringArray(req.Tags),
+		Visibility:         req.Visibility,
 	}
 
-	updatedRecipe, err := h.recipeService.UpdateRecipe(c.Request.Context(), recipeID, recipe)
+	userID := c.MustGet("user_id").(uuid.UUID)
+	updatedRecipe, err := h.recipeService.UpdateRecipe(c.Request.Context(), userID, recipeID, recipe)
 	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "recipe not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -331,7 +383,8 @@ func (h *RecipeHandler) DeleteRecipe(c *gin.Context) {
 		return
 	}
 
-	err = h.recipeService.DeleteRecipe(c.Request.Context(), recipeID)
+	userID := c.MustGet("user_id").(uuid.UUID)
+	err = h.recipeService.DeleteRecipe(c.Request.Context(), userID, recipeID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "recipe not found"})
@@ -345,152 +398,190 @@ func (h *RecipeHandler) DeleteRecipe(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-// ListRecipes handles listing recipes for authenticated users
+// ListRecipes handles listing recipes for authenticated users: their own by
+// default, or with all=true every recipe listed to them. Results are
+// paginated with the limit and cursor query parameters.
 func (h *RecipeHandler) ListRecipes(c *gin.Context) {
 	all := c.DefaultQuery("all", "false") == "true"
-	var recipes []*models.Recipe
-	var err error
-	
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
 	// User is always authenticated due to middleware
 	userIDValue := c.MustGet("user_id")
 	userID := userIDValue.(uuid.UUID)
-	
+
+	var recipes []*models.Recipe
+	var info *pagination.Info
+	var err error
 	if all {
 		// Return all recipes if explicitly requested
-		recipes, err = h.recipeService.ListRecipes(c.Request.Context(), nil)
+		recipes, info, err = h.recipeService.ListRecipes(c.Request.Context(), userID, nil, page)
 	} else {
 		// Return user's recipes by default
-		recipes, err = h.recipeService.ListRecipes(c.Request.Context(), &userID)
+		recipes, info, err = h.recipeService.ListRecipes(c.Request.Context(), userID, &userID, page)
 	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	
-	// Add favorite status to each recipe
-	favoriteRecipes, err := h.recipeService.GetFavoriteRecipes(c.Request.Context(), userID)
-	if err == nil {
-		// Create a map for quick lookup
-		favoriteMap := make(map[uuid.UUID]bool)
-		for _, fav := range favoriteRecipes {
-			favoriteMap[fav.ID] = true
-		}
-		
-		// Create response with favorite status
-		type RecipeResponse struct {
-			*models.Recipe
-			IsFavorite bool `json:"is_favorite"`
-		}
-		
-		recipesWithFavorites := make([]RecipeResponse, len(recipes))
-		for i, recipe := range recipes {
-			recipesWithFavorites[i] = RecipeResponse{
-				Recipe:     recipe,
-				IsFavorite: favoriteMap[recipe.ID],
-			}
-		}
-		
-		c.JSON(http.StatusOK, gin.H{"recipes": recipesWithFavorites})
+	recipes = localizeRecipes(recipes, resolveUnitSystem(c, h.db, userID))
+
+	c.JSON(http.StatusOK, pageResponse("recipes", h.withFavoriteStatus(c, userID, recipes), info))
+}
+
+// ListFavoriteRecipes handles listing the current user's favorite recipes,
+// paginated like ListRecipes
+func (h *RecipeHandler) ListFavoriteRecipes(c *gin.Context) {
+	page, ok := parsePage(c)
+	if !ok {
 		return
 	}
-	
-	// If error getting favorites, return recipes without favorite status
-	c.JSON(http.StatusOK, gin.H{"recipes": recipes})
-}
+	userID := c.MustGet("user_id").(uuid.UUID)
 
-// GetFeaturedRecipes handles getting featured recipes for the landing page
-func (h *RecipeHandler) GetFeaturedRecipes(c *gin.Context) {
-	// Get the last 6 recipes as featured recipes (for landing page)
-	var recipes []models.Recipe
-	if err := h.db.Order("created_at desc").Limit(6).Find(&recipes).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch featured recipes"})
+	recipes, info, err := h.recipeService.GetFavoriteRecipes(c.Request.Context(), userID, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	recipes = localizeRecipes(recipes, resolveUnitSystem(c, h.db, userID))
+
+	c.JSON(http.StatusOK, pageResponse("recipes", recipes, info))
+}
 
-	// Convert to pointers for consistency with other endpoints
-	featuredRecipes := make([]*models.Recipe, len(recipes))
-	for i := range recipes {
-		featuredRecipes[i] = &recipes[i]
+// recipeWithFavorite is a recipe annotated with the current user's favorite
+// status and, for debug searches, its score explanation
+type recipeWithFavorite struct {
+	*models.Recipe
+	IsFavorite  bool                      `json:"is_favorite"`
+	Explanation *service.ScoreExplanation `json:"explanation,omitempty"`
+}
+
+// withFavoriteStatus annotates recipes with whether the user has favorited
+// them. If the lookup fails no recipe is marked as a favorite.
+func (h *RecipeHandler) withFavoriteStatus(c *gin.Context, userID uuid.UUID, recipes []*models.Recipe) []recipeWithFavorite {
+	ids := make([]uuid.UUID, len(recipes))
+	for i, recipe := range recipes {
+		ids[i] = recipe.ID
+	}
+	favorites, err := h.recipeService.FavoriteRecipeIDs(c.Request.Context(), userID, ids)
+	if err != nil {
+		fmt.Printf("[DEBUG] Error getting favorite status: %v\n", err)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"recipes": featuredRecipes})
+	recipesWithFavorites := make([]recipeWithFavorite, len(recipes))
+	for i, recipe := range recipes {
+		recipesWithFavorites[i] = recipeWithFavorite{
+			Recipe:     recipe,
+			IsFavorite: favorites[recipe.ID],
+		}
+	}
+	return recipesWithFavorites
 }
 
-// SearchRecipes handles searching recipes for authenticated users
+// SearchRecipes handles faceted recipe search for authenticated users.
+// Filters, sorting and pagination are applied in the database; the user's
+// saved allergens are excluded unless ignore_allergens=true. The response
+// includes facet counts for the matching recipes and, with debug=true, each
+// result's hybrid search score explanation.
 func (h *RecipeHandler) SearchRecipes(c *gin.Context) {
-	query := c.Query("q")
-	category := c.Query("category")
-	sortBy := c.DefaultQuery("sort", "newest")
-	
-	fmt.Printf("[DEBUG] SearchRecipes called with query=%s, category=%s, sort=%s\n", query, category, sortBy)
-	
+	search, err := parseRecipeSearch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	fmt.Printf("[DEBUG] SearchRecipes called with query=%s, categories=%v, sort=%s\n", search.Query, search.Categories, search.Sort)
+
 	// User is always authenticated due to middleware
 	userIDValue := c.MustGet("user_id")
 	userID := userIDValue.(uuid.UUID)
-	
-	var recipes []*models.Recipe
-	var err error
-	
-	if query != "" {
-		// Use semantic search if query is provided
-		recipes, err = h.recipeService.SearchRecipes(c.Request.Context(), query)
-	} else {
-		// Fall back to listing all recipes if no query
-		recipes, err = h.recipeService.ListRecipes(c.Request.Context(), nil)
+	search.ViewerID = userID
+
+	if h.db != nil && c.Query("ignore_allergens") != "true" {
+		allergens, err := service.LoadAllergens(c.Request.Context(), h.db, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		search.Allergens = allergens
 	}
-	
+
+	result, err := h.recipeService.FacetedSearch(c.Request.Context(), search, page)
 	if err != nil {
 		fmt.Printf("[DEBUG] Error searching recipes: %v\n", err)
+		if errors.Is(err, service.ErrInvalidSearch) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	
-	// Filter by category if specified
-	if category != "" && category != "All" {
-		filtered := make([]*models.Recipe, 0)
-		for _, recipe := range recipes {
-			if strings.EqualFold(recipe.Category, category) {
-				filtered = append(filtered, recipe)
+
+	fmt.Printf("[DEBUG] Found %d recipes\n", len(result.Recipes))
+	recipes := h.withFavoriteStatus(c, userID, localizeRecipes(result.Recipes, resolveUnitSystem(c, h.db, userID)))
+	if c.Query("debug") == "true" {
+		for i := range recipes {
+			if explanation, ok := result.Explanations[recipes[i].ID]; ok {
+				recipes[i].Explanation = &explanation
 			}
 		}
-		recipes = filtered
 	}
-	
-	// Sort results
-	// Note: For now we'll keep the database ordering, but this could be enhanced
-	// to support different sorting options like popularity, rating, etc.
-	
-	fmt.Printf("[DEBUG] Found %d recipes after filtering\n", len(recipes))
-	
-	// Add favorite status to each recipe
-	favoriteRecipes, err := h.recipeService.GetFavoriteRecipes(c.Request.Context(), userID)
-	if err == nil {
-		// Create a map for quick lookup
-		favoriteMap := make(map[uuid.UUID]bool)
-		for _, fav := range favoriteRecipes {
-			favoriteMap[fav.ID] = true
+
+	resp := pageResponse("recipes", recipes, result.Info)
+	resp["facets"] = result.Facets
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetSimilarRecipes handles "more like this": the recipes nearest to a
+// recipe by embedding, under the same filters as search. The user's saved
+// dietary preferences and allergens are excluded unless ignore_dietary=true,
+// and exclude_forks=true leaves out the recipe's forks and originals.
+func (h *RecipeHandler) GetSimilarRecipes(c *gin.Context) {
+	recipeID, ok := parseIDParam(c, "id", "invalid recipe ID format")
+	if !ok {
+		return
+	}
+	search, err := parseRecipeSearch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+	userID := c.MustGet("user_id").(uuid.UUID)
+	search.ViewerID = userID
+
+	if h.db != nil && c.Query("ignore_dietary") != "true" {
+		if search.Restrictions, err = service.LoadDietaryPreferences(c.Request.Context(), h.db, userID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
-		
-		// Create response with favorite status
-		type RecipeResponse struct {
-			*models.Recipe
-			IsFavorite bool `json:"is_favorite"`
+		if search.Allergens, err = service.LoadAllergens(c.Request.Context(), h.db, userID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
-		
-		recipesWithFavorites := make([]RecipeResponse, len(recipes))
-		for i, recipe := range recipes {
-			recipesWithFavorites[i] = RecipeResponse{
-				Recipe:     recipe,
-				IsFavorite: favoriteMap[recipe.ID],
-			}
+	}
+
+	recipes, info, err := h.recipeService.SimilarRecipes(c.Request.Context(), recipeID, search, c.Query("exclude_forks") == "true", page)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "recipe not found"})
+			return
 		}
-		
-		c.JSON(http.StatusOK, gin.H{"recipes": recipesWithFavorites})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	
-	// If error getting favorites, return recipes without favorite status
-	c.JSON(http.StatusOK, gin.H{"recipes": recipes})
+	recipes = localizeRecipes(recipes, resolveUnitSystem(c, h.db, userID))
+
+	c.JSON(http.StatusOK, pageResponse("recipes", h.withFavoriteStatus(c, userID, recipes), info))
 }
 
 // FavoriteRecipe handles favoriting a recipe
@@ -520,8 +611,8 @@ func (h *RecipeHandler) FavoriteRecipe(c *gin.Context) {
 		return
 	}
 
-	// Check if recipe exists
-	_, err = h.recipeService.GetRecipe(c.Request.Context(), recipeID)
+	// Check if recipe exists and is visible to the user
+	_, err = h.recipeService.GetRecipe(c.Request.Context(), userID, recipeID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "recipe not found"})
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+)
+
+// parseRecipeSearch reads the faceted search query parameters. List values
+// are comma-separated; ranges use min_ and max_ prefixes.
+func parseRecipeSearch(c *gin.Context) (*service.RecipeSearch, error) {
+	search := &service.RecipeSearch{
+		Query:      strings.TrimSpace(c.Query("q")),
+		Categories: splitList(c.Query("category")),
+		Cuisines:   splitList(c.Query("cuisine")),
+		Tags:       splitList(c.Query("tags")),
+		Dietary:    splitList(c.Query("dietary")),
+		Include:    splitList(c.Query("include")),
+		Exclude:    splitList(c.Query("exclude")),
+		Sort:       c.Query("sort"),
+	}
+	// "All" is the frontend's unfiltered category
+	if len(search.Categories) == 1 && strings.EqualFold(search.Categories[0], "All") {
+		search.Categories = nil
+	}
+
+	ranges := []struct {
+		name string
+		r    *service.NumberRange
+	}{
+		{"calories", &search.Calories},
+		{"protein", &search.Protein},
+		{"carbs", &search.Carbs},
+		{"fat", &search.Fat},
+	}
+	for _, p := range ranges {
+		var err error
+		if p.r.Min, err = floatQuery(c, "min_"+p.name); err != nil {
+			return nil, err
+		}
+		if p.r.Max, err = floatQuery(c, "max_"+p.name); err != nil {
+			return nil, err
+		}
+	}
+
+	if v := c.Query("max_time"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("%w: max_time must be a positive number of minutes", service.ErrInvalidSearch)
+		}
+		search.MaxTotalTime = n
+	}
+	return search, nil
+}
+
+// floatQuery parses an optional non-negative number query parameter
+func floatQuery(c *gin.Context, name string) (*float64, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 {
+		return nil, fmt.Errorf("%w: %s must be a non-negative number", service.ErrInvalidSearch, name)
+	}
+	return &f, nil
+}
+
+// splitList splits a comma-separated query value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
 	"github.com/pageza/alchemorsel-v2/backend/internal/service"
 	"github.com/stretchr/testify/assert"
@@ -260,6 +261,116 @@ func TestDeleteRecipe(t *testing.T) {
 	assert.Equal(t, 404, w.Code)
 }
 
+func TestOnlyAuthorsUpdateOrDeleteRecipes(t *testing.T) {
+	router, testDB := setupRecipeTestRouter(t)
+
+	_, authorToken := CreateTestUserAndToken(t, testDB)
+	_, otherToken := CreateTestUserAndToken(t, testDB)
+
+	defaultEmbedding := make([]float32, 1536)
+	for i := range defaultEmbedding {
+		defaultEmbedding[i] = float32(i) / 1536.0
+	}
+
+	for _, visibility := range []string{"private", "public"} {
+		jsonData, err := json.Marshal(map[string]interface{}{
+			"name":         "Author Recipe",
+			"description":  "Test Description",
+			"category":     "Test Category",
+			"ingredients":  []string{"ingredient1"},
+			"instructions": []string{"step1"},
+			"embedding":    defaultEmbedding,
+			"visibility":   visibility,
+		})
+		if err != nil {
+			t.Fatalf("Failed to marshal recipe: %v", err)
+		}
+		req := httptest.NewRequest("POST", "/api/v1/recipes", bytes.NewBuffer(jsonData))
+		req.Header.Set("Authorization", "Bearer "+authorToken)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, 201, w.Code)
+
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		recipeID := response["recipe"].(map[string]interface{})["id"].(string)
+
+		// Another user's update neither changes nor returns the recipe
+		req = httptest.NewRequest("PUT", "/api/v1/recipes/"+recipeID, bytes.NewBufferString(`{"visibility": "public"}`))
+		req.Header.Set("Authorization", "Bearer "+otherToken)
+		req.Header.Set("Content-Type", "application/json")
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, 404, w.Code, "%s recipe", visibility)
+		assert.NotContains(t, w.Body.String(), "Author Recipe")
+
+		req = httptest.NewRequest("DELETE", "/api/v1/recipes/"+recipeID, nil)
+		req.Header.Set("Authorization", "Bearer "+otherToken)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, 404, w.Code, "%s recipe", visibility)
+
+		req = httptest.NewRequest("GET", "/api/v1/recipes/"+recipeID, nil)
+		req.Header.Set("Authorization", "Bearer "+authorToken)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, 200, w.Code)
+		assert.Contains(t, w.Body.String(), `"visibility":"`+visibility+`"`)
+	}
+}
+
+func TestForkRequiresVisibleSource(t *testing.T) {
+	router, testDB := setupRecipeTestRouter(t)
+
+	_, authorToken := CreateTestUserAndToken(t, testDB)
+	_, otherToken := CreateTestUserAndToken(t, testDB)
+
+	defaultEmbedding := make([]float32, 1536)
+	for i := range defaultEmbedding {
+		defaultEmbedding[i] = float32(i) / 1536.0
+	}
+
+	create := func(token string, fields map[string]interface{}) *httptest.ResponseRecorder {
+		body := map[string]interface{}{
+			"name":         "Fork Source",
+			"description":  "Test Description",
+			"category":     "Test Category",
+			"ingredients":  []string{"ingredient1"},
+			"instructions": []string{"step1"},
+			"embedding":    defaultEmbedding,
+		}
+		for k, v := range fields {
+			body[k] = v
+		}
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("Failed to marshal recipe: %v", err)
+		}
+		req := httptest.NewRequest("POST", "/api/v1/recipes", bytes.NewBuffer(jsonData))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	ids := map[string]string{}
+	for _, visibility := range []string{"private", "public"} {
+		w := create(authorToken, map[string]interface{}{"visibility": visibility})
+		assert.Equal(t, 201, w.Code)
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		ids[visibility] = response["recipe"].(map[string]interface{})["id"].(string)
+	}
+
+	// Other users can fork public recipes but not private or unknown ones
+	assert.Equal(t, 201, create(otherToken, map[string]interface{}{"forked_from_id": ids["public"]}).Code)
+	assert.Equal(t, 404, create(otherToken, map[string]interface{}{"forked_from_id": ids["private"]}).Code)
+	assert.Equal(t, 404, create(otherToken, map[string]interface{}{"forked_from_id": uuid.New().String()}).Code)
+	assert.Equal(t, 201, create(authorToken, map[string]interface{}{"forked_from_id": ids["private"]}).Code)
+}
+
 func TestListRecipes(t *testing.T) {
 	router, testDB := setupRecipeTestRouter(t)
 
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+	"gorm.io/gorm"
+)
+
+// defaultRecommendationLimit caps /recommendations results when no limit is
+// given
+const defaultRecommendationLimit = 10
+
+// maxRecommendationLimit is the largest limit /recommendations accepts
+const maxRecommendationLimit = 50
+
+// RecommendationHandler handles personalized recipe recommendations
+type RecommendationHandler struct {
+	db                    *gorm.DB
+	recommendationService service.IRecommendationService
+	authService           service.IAuthService
+}
+
+// NewRecommendationHandler creates a new RecommendationHandler
+func NewRecommendationHandler(db *gorm.DB, recommendationService service.IRecommendationService, authService service.IAuthService) *RecommendationHandler {
+	return &RecommendationHandler{
+		db:                    db,
+		recommendationService: recommendationService,
+		authService:           authService,
+	}
+}
+
+// RegisterRoutes registers the recommendation routes
+func (h *RecommendationHandler) RegisterRoutes(router *gin.RouterGroup) {
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(h.authService))
+	{
+		protected.GET("/recommendations", h.GetRecommendations)
+	}
+}
+
+// GetRecommendations returns recipes the user has not seen, ranked by
+// similarity to their favorites, published recipes and cooking history.
+// Optional query parameter: limit caps the results.
+func (h *RecommendationHandler) GetRecommendations(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	limit := defaultRecommendationLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxRecommendationLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxRecommendationLimit)})
+			return
+		}
+		limit = n
+	}
+
+	recommendations, err := h.recommendationService.Recommend(c.Request.Context(), userID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	system := resolveUnitSystem(c, h.db, userID)
+	for i := range recommendations {
+		recommendations[i].Recipe = localizeRecipe(recommendations[i].Recipe, system)
+	}
+	c.JSON(http.StatusOK, gin.H{"recommendations": recommendations})
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"gorm.io/gorm"
+)
+
+// ReviewHandler handles recipe ratings and reviews
+type ReviewHandler struct {
+	db            *gorm.DB
+	reviewService service.IReviewService
+	authService   service.IAuthService
+}
+
+// NewReviewHandler creates a new ReviewHandler
+func NewReviewHandler(db *gorm.DB, reviewService service.IReviewService, authService service.IAuthService) *ReviewHandler {
+	return &ReviewHandler{
+		db:            db,
+		reviewService: reviewService,
+		authService:   authService,
+	}
+}
+
+// RegisterRoutes registers the review routes. Writing reviews requires a
+// verified email, like the other recipe writes.
+func (h *ReviewHandler) RegisterRoutes(router *gin.RouterGroup) {
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(h.authService))
+	{
+		protected.GET("/recipes/:id/reviews", h.ListReviews)
+	}
+
+	verified := router.Group("")
+	verified.Use(middleware.AuthMiddleware(h.authService))
+	verified.Use(middleware.RequireEmailVerification(h.db))
+	{
+		verified.POST("/recipes/:id/reviews", h.CreateReview)
+		verified.PUT("/reviews/:id", h.UpdateReview)
+		verified.DELETE("/reviews/:id", h.DeleteReview)
+	}
+}
+
+// ListReviews returns one page of a recipe's reviews, newest first, with the
+// recipe's rating summary
+func (h *ReviewHandler) ListReviews(c *gin.Context) {
+	recipeID, ok := parseIDParam(c, "id", "invalid recipe ID format")
+	if !ok {
+		return
+	}
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+	reviews, info, err := h.reviewService.ListReviews(c.Request.Context(), userID, recipeID, page)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	summary, err := h.reviewService.RatingSummary(c.Request.Context(), recipeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := pageResponse("reviews", reviews, info)
+	resp["summary"] = summary
+	c.JSON(http.StatusOK, resp)
+}
+
+// CreateReview rates and reviews a recipe
+func (h *ReviewHandler) CreateReview(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	recipeID, ok := parseIDParam(c, "id", "invalid recipe ID format")
+	if !ok {
+		return
+	}
+
+	var req types.CreateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review, err := h.reviewService.CreateReview(c.Request.Context(), userID, recipeID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"review": review})
+}
+
+// UpdateReview edits one of the current user's reviews
+func (h *ReviewHandler) UpdateReview(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	reviewID, ok := parseIDParam(c, "id", "invalid review ID format")
+	if !ok {
+		return
+	}
+
+	var req types.UpdateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review, err := h.reviewService.UpdateReview(c.Request.Context(), userID, reviewID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"review": review})
+}
+
+// DeleteReview deletes one of the current user's reviews
+func (h *ReviewHandler) DeleteReview(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	reviewID, ok := parseIDParam(c, "id", "invalid review ID format")
+	if !ok {
+		return
+	}
+
+	if err := h.reviewService.DeleteReview(c.Request.Context(), userID, reviewID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "review deleted"})
+}
+
+// handleError maps review service errors to responses
+func (h *ReviewHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRecipeNotFound), errors.Is(err, service.ErrReviewNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidReview):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrReviewExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"gorm.io/gorm"
+)
+
+// ShareHandler handles recipe share links
+type ShareHandler struct {
+	db           *gorm.DB
+	shareService service.IShareService
+	authService  service.IAuthService
+}
+
+// NewShareHandler creates a new ShareHandler
+func NewShareHandler(db *gorm.DB, shareService service.IShareService, authService service.IAuthService) *ShareHandler {
+	return &ShareHandler{
+		db:           db,
+		shareService: shareService,
+		authService:  authService,
+	}
+}
+
+// RegisterRoutes registers the share link routes. Shared recipes can be read
+// without signing in; creating links requires a verified email, like the
+// other recipe writes.
+func (h *ShareHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/shared/recipes/:token", h.GetSharedRecipe)
+
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(h.authService))
+	{
+		protected.GET("/recipes/:id/shares", h.ListRecipeShares)
+		protected.DELETE("/recipes/:id/shares/:share_id", h.RevokeRecipeShare)
+	}
+
+	verified := router.Group("")
+	verified.Use(middleware.AuthMiddleware(h.authService))
+	verified.Use(middleware.RequireEmailVerification(h.db))
+	{
+		verified.POST("/recipes/:id/share", h.CreateRecipeShare)
+	}
+}
+
+// CreateRecipeShare creates a share link to a recipe
+func (h *ShareHandler) CreateRecipeShare(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	recipeID, ok := parseIDParam(c, "id", "invalid recipe ID format")
+	if !ok {
+		return
+	}
+
+	var req types.CreateRecipeShareRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	share, err := h.shareService.CreateRecipeShare(c.Request.Context(), userID, recipeID, time.Duration(req.ExpiresInHours)*time.Hour)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"share": share})
+}
+
+// ListRecipeShares returns the share links to a recipe the current user can
+// manage, newest first
+func (h *ShareHandler) ListRecipeShares(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	recipeID, ok := parseIDParam(c, "id", "invalid recipe ID format")
+	if !ok {
+		return
+	}
+
+	shares, err := h.shareService.ListRecipeShares(c.Request.Context(), userID, recipeID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"shares": shares})
+}
+
+// RevokeRecipeShare revokes a share link to a recipe
+func (h *ShareHandler) RevokeRecipeShare(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	recipeID, ok := parseIDParam(c, "id", "invalid recipe ID format")
+	if !ok {
+		return
+	}
+	shareID, ok := parseIDParam(c, "share_id", "invalid share ID format")
+	if !ok {
+		return
+	}
+
+	if err := h.shareService.RevokeRecipeShare(c.Request.Context(), userID, recipeID, shareID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "share link revoked"})
+}
+
+// GetSharedRecipe returns the recipe a share link points at, read-only
+func (h *ShareHandler) GetSharedRecipe(c *gin.Context) {
+	recipe, err := h.shareService.GetSharedRecipe(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recipe": localizeRecipe(recipe, requestedUnitSystem(c, ""))})
+}
+
+// handleError maps share service errors to responses
+func (h *ShareHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRecipeNotFound), errors.Is(err, service.ErrShareNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
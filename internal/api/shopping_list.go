@@ -0,0 +1,206 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"gorm.io/gorm"
+)
+
+// ShoppingListHandler handles shopping list requests
+type ShoppingListHandler struct {
+	db                  *gorm.DB
+	shoppingListService service.IShoppingListService
+	authService         service.IAuthService
+}
+
+// NewShoppingListHandler creates a new ShoppingListHandler
+func NewShoppingListHandler(db *gorm.DB, shoppingListService service.IShoppingListService, authService service.IAuthService) *ShoppingListHandler {
+	return &ShoppingListHandler{
+		db:                  db,
+		shoppingListService: shoppingListService,
+		authService:         authService,
+	}
+}
+
+// RegisterRoutes registers the shopping list routes
+func (h *ShoppingListHandler) RegisterRoutes(router *gin.RouterGroup) {
+	lists := router.Group("/shopping-lists")
+	lists.Use(middleware.AuthMiddleware(h.authService))
+	{
+		lists.GET("", h.ListShoppingLists)
+		lists.POST("", h.CreateShoppingList)
+		lists.GET("/:id", h.GetShoppingList)
+		lists.DELETE("/:id", h.DeleteShoppingList)
+		lists.GET("/:id/export", h.ExportShoppingList)
+		lists.POST("/:id/pantry", h.SubtractPantry)
+		lists.PATCH("/:id/items/:item_id", h.UpdateItem)
+	}
+}
+
+// ListShoppingLists returns the current user's shopping lists
+func (h *ShoppingListHandler) ListShoppingLists(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	lists, err := h.shoppingListService.ListShoppingLists(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"shopping_lists": lists})
+}
+
+// CreateShoppingList generates a shopping list from recipes or a meal plan.
+// Quantities are rendered in the user's unit system.
+func (h *ShoppingListHandler) CreateShoppingList(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var req types.CreateShoppingListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := h.shoppingListService.CreateShoppingList(c.Request.Context(), userID, &req, resolveUnitSystem(c, h.db, userID))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"shopping_list": list})
+}
+
+// GetShoppingList returns a shopping list with its items
+func (h *ShoppingListHandler) GetShoppingList(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	listID, ok := parseIDParam(c, "id", "invalid shopping list ID format")
+	if !ok {
+		return
+	}
+
+	list, err := h.shoppingListService.GetShoppingList(c.Request.Context(), userID, listID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"shopping_list": list})
+}
+
+// DeleteShoppingList deletes a shopping list
+func (h *ShoppingListHandler) DeleteShoppingList(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	listID, ok := parseIDParam(c, "id", "invalid shopping list ID format")
+	if !ok {
+		return
+	}
+
+	if err := h.shoppingListService.DeleteShoppingList(c.Request.Context(), userID, listID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "shopping list deleted"})
+}
+
+// ExportShoppingList returns a shopping list as plain text (default) or CSV
+func (h *ShoppingListHandler) ExportShoppingList(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	format := c.DefaultQuery("format", "text")
+	if format != "text" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be text or csv"})
+		return
+	}
+	listID, ok := parseIDParam(c, "id", "invalid shopping list ID format")
+	if !ok {
+		return
+	}
+
+	list, err := h.shoppingListService.GetShoppingList(c.Request.Context(), userID, listID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	if format == "csv" {
+		var buf bytes.Buffer
+		if err := service.WriteShoppingListCSV(&buf, list); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="shopping-list-%s.csv"`, list.ID))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+		return
+	}
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(service.FormatShoppingListText(list)))
+}
+
+// SubtractPantry removes ingredients already on hand from a shopping list
+func (h *ShoppingListHandler) SubtractPantry(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	listID, ok := parseIDParam(c, "id", "invalid shopping list ID format")
+	if !ok {
+		return
+	}
+
+	var req types.SubtractPantryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := h.shoppingListService.SubtractPantry(c.Request.Context(), userID, listID, req.Pantry, resolveUnitSystem(c, h.db, userID))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"shopping_list": list})
+}
+
+// UpdateItem checks off or unchecks a shopping list item
+func (h *ShoppingListHandler) UpdateItem(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	listID, ok := parseIDParam(c, "id", "invalid shopping list ID format")
+	if !ok {
+		return
+	}
+	itemID, ok := parseIDParam(c, "item_id", "invalid item ID format")
+	if !ok {
+		return
+	}
+
+	var req types.UpdateShoppingListItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := h.shoppingListService.SetItemChecked(c.Request.Context(), userID, listID, itemID, req.Checked)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"item": item})
+}
+
+// handleError maps shopping list service errors to responses
+func (h *ShoppingListHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrShoppingListNotFound), errors.Is(err, service.ErrMealPlanNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidShoppingList):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
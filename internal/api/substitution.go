@@ -0,0 +1,142 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+	"gorm.io/gorm"
+)
+
+// SubstitutionHandler handles ingredient substitution requests
+type SubstitutionHandler struct {
+	db                  *gorm.DB
+	substitutionService service.ISubstitutionService
+	recipeService       service.IRecipeService
+	llmService          service.LLMServiceInterface
+	nutritionService    service.INutritionService
+	authService         service.IAuthService
+}
+
+// NewSubstitutionHandler creates a new SubstitutionHandler
+func NewSubstitutionHandler(db *gorm.DB, substitutionService service.ISubstitutionService, recipeService service.IRecipeService, llmService service.LLMServiceInterface, nutritionService service.INutritionService, authService service.IAuthService) *SubstitutionHandler {
+	return &SubstitutionHandler{
+		db:                  db,
+		substitutionService: substitutionService,
+		recipeService:       recipeService,
+		llmService:          llmService,
+		nutritionService:    nutritionService,
+		authService:         authService,
+	}
+}
+
+// RegisterRoutes registers the substitution routes
+func (h *SubstitutionHandler) RegisterRoutes(router *gin.RouterGroup) {
+	protected := router.Group("/recipes/:id/substitutions")
+	protected.Use(middleware.AuthMiddleware(h.authService))
+	{
+		protected.POST("", h.SuggestSubstitutions)
+		protected.POST("/apply", h.ApplySubstitutions)
+	}
+}
+
+// SuggestSubstitutions returns ranked substitutes for an ingredient or for
+// every ingredient that breaks a constraint such as "no eggs"
+func (h *SubstitutionHandler) SuggestSubstitutions(c *gin.Context) {
+	var req struct {
+		Ingredient string `json:"ingredient"`
+		Constraint string `json:"constraint"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recipe, ok := h.loadRecipe(c)
+	if !ok {
+		return
+	}
+
+	suggestions, err := h.substitutionService.SuggestSubstitutions(c.Request.Context(), recipe, req.Ingredient, req.Constraint)
+	if err != nil {
+		if errors.Is(err, service.ErrSubstitutionRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recipe_id":   recipe.ID,
+		"suggestions": suggestions,
+	})
+}
+
+// ApplySubstitutions applies chosen substitutes to a recipe and saves the
+// result as a draft without regenerating the recipe
+func (h *SubstitutionHandler) ApplySubstitutions(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var req struct {
+		Substitutions []service.SubstitutionChoice `json:"substitutions" binding:"required,min=1,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recipe, ok := h.loadRecipe(c)
+	if !ok {
+		return
+	}
+
+	draft, err := h.substitutionService.ApplySubstitutions(c.Request.Context(), recipe, req.Substitutions)
+	if err != nil {
+		if errors.Is(err, service.ErrSubstitutionNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	draft.UserID = userID.String()
+	applyDraftNutrition(c, h.nutritionService, draft)
+	if err := h.llmService.SaveDraft(c.Request.Context(), draft); err != nil {
+		fmt.Printf("[SubstitutionHandler] Error saving draft: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recipe":   localizeDraft(draft, resolveUnitSystem(c, h.db, userID)),
+		"draft_id": draft.ID,
+	})
+}
+
+// loadRecipe loads the recipe named in the path, writing an error response
+// and returning false on failure
+func (h *SubstitutionHandler) loadRecipe(c *gin.Context) (*models.Recipe, bool) {
+	recipeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe ID format"})
+		return nil, false
+	}
+
+	recipe, err := h.recipeService.GetRecipe(c.Request.Context(), c.MustGet("user_id").(uuid.UUID), recipeID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "recipe not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return recipe, true
+}
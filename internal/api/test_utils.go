@@ -337,6 +337,10 @@ func (m *MockLLMService) GenerateRecipesBatch(prompts []string) ([]string, error
 	return []string{`{"name":"Test Recipe","description":"Desc","category":"Cat","ingredients":["i1"],"instructions":["s1"],"calories":100,"protein":10,"carbs":20,"fat":5}`}, nil
 }
 
+func (m *MockLLMService) SuggestSubstitutions(recipeName string, ingredients []string, constraint string) ([]service.SubstitutionSuggestion, error) {
+	return []service.SubstitutionSuggestion{}, nil
+}
+
 // NewMockLLMHandler creates a mock LLM handler for testing
 func NewMockLLMHandler() *LLMHandler {
 	return &LLMHandler{
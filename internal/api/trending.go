@@ -0,0 +1,151 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"gorm.io/gorm"
+)
+
+// TrendingHandler handles trending and featured recipes and the admin
+// curation of featured recipes
+type TrendingHandler struct {
+	db              *gorm.DB
+	trendingService service.ITrendingService
+	authService     service.IAuthService
+}
+
+// NewTrendingHandler creates a new TrendingHandler
+func NewTrendingHandler(db *gorm.DB, trendingService service.ITrendingService, authService service.IAuthService) *TrendingHandler {
+	return &TrendingHandler{
+		db:              db,
+		trendingService: trendingService,
+		authService:     authService,
+	}
+}
+
+// RegisterRoutes registers the trending routes. Featured recipes are public
+// for the landing page; curation is limited to admins.
+func (h *TrendingHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/recipes/featured", h.GetFeaturedRecipes)
+
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(h.authService))
+	{
+		protected.GET("/recipes/trending", h.GetTrendingRecipes)
+	}
+
+	admin := router.Group("/admin/featured")
+	admin.Use(middleware.AuthMiddleware(h.authService), requireAdmin)
+	{
+		admin.GET("", h.ListCurations)
+		admin.PUT("/:recipe_id", h.SetCuration)
+		admin.DELETE("/:recipe_id", h.DeleteCuration)
+	}
+}
+
+// requireAdmin rejects requests from users who are not admins
+func requireAdmin(c *gin.Context) {
+	if c.GetString("role") != models.RoleAdmin {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+	c.Next()
+}
+
+// GetFeaturedRecipes returns the featured recipes for the landing page
+func (h *TrendingHandler) GetFeaturedRecipes(c *gin.Context) {
+	recipes, err := h.trendingService.Featured(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch featured recipes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recipes": localizeRecipes(recipes, requestedUnitSystem(c, ""))})
+}
+
+// GetTrendingRecipes returns one page of trending recipes, highest score
+// first
+func (h *TrendingHandler) GetTrendingRecipes(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	recipes, info, err := h.trendingService.Trending(c.Request.Context(), userID, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	recipes = localizeRecipes(recipes, resolveUnitSystem(c, h.db, userID))
+	c.JSON(http.StatusOK, pageResponse("recipes", recipes, info))
+}
+
+// ListCurations returns the pinned and excluded recipes
+func (h *TrendingHandler) ListCurations(c *gin.Context) {
+	curations, err := h.trendingService.ListCurations(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"curations": curations})
+}
+
+// SetCuration pins a recipe to the featured recipes or excludes it
+func (h *TrendingHandler) SetCuration(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	recipeID, ok := parseIDParam(c, "recipe_id", "invalid recipe ID format")
+	if !ok {
+		return
+	}
+
+	var req types.SetCurationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	curation, err := h.trendingService.SetCuration(c.Request.Context(), userID, recipeID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"curation": curation})
+}
+
+// DeleteCuration removes a recipe's pin or exclusion
+func (h *TrendingHandler) DeleteCuration(c *gin.Context) {
+	recipeID, ok := parseIDParam(c, "recipe_id", "invalid recipe ID format")
+	if !ok {
+		return
+	}
+
+	if err := h.trendingService.DeleteCuration(c.Request.Context(), recipeID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleError maps trending service errors to HTTP responses
+func (h *TrendingHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRecipeNotFound), errors.Is(err, service.ErrCurationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidCuration):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
+	"github.com/pageza/alchemorsel-v2/backend/internal/units"
+	"gorm.io/gorm"
+)
+
+// requestedUnitSystem returns the unit system asked for with the "units" query
+// parameter, falling back to the given preference and then the default
+func requestedUnitSystem(c *gin.Context, preference string) units.System {
+	if system, ok := units.ParseSystem(c.Query("units")); ok {
+		return system
+	}
+	if system, ok := units.ParseSystem(preference); ok {
+		return system
+	}
+	return units.DefaultSystem
+}
+
+// resolveUnitSystem looks up the user's preferred unit system, honouring a
+// "units" query parameter override
+func resolveUnitSystem(c *gin.Context, db *gorm.DB, userID uuid.UUID) units.System {
+	preference := ""
+	if db != nil && userID != uuid.Nil {
+		var profile models.UserProfile
+		if err := db.Select("unit_system").Where("user_id = ?", userID).First(&profile).Error; err == nil {
+			preference = profile.UnitSystem
+		}
+	}
+	return requestedUnitSystem(c, preference)
+}
+
+// localizeRecipe returns a copy of the recipe with ingredient quantities and
+// instruction temperatures rendered in the given unit system
+func localizeRecipe(recipe *models.Recipe, system units.System) *models.Recipe {
+	if recipe == nil {
+		return nil
+	}
+	localized := *recipe
+	localized.Ingredients = models.JSONBStringArray(units.LocalizeIngredients(recipe.Ingredients, system))
+	localized.Instructions = models.JSONBStringArray(units.LocalizeInstructions(recipe.Instructions, system))
+	return &localized
+}
+
+// localizeRecipes applies localizeRecipe to every recipe in the slice
+func localizeRecipes(recipes []*models.Recipe, system units.System) []*models.Recipe {
+	localized := make([]*models.Recipe, len(recipes))
+	for i, recipe := range recipes {
+		localized[i] = localizeRecipe(recipe, system)
+	}
+	return localized
+}
+
+// localizeDraft returns a copy of the draft rendered in the given unit system
+func localizeDraft(draft *service.RecipeDraft, system units.System) *service.RecipeDraft {
+	if draft == nil {
+		return nil
+	}
+	localized := *draft
+	localized.Ingredients = units.LocalizeIngredients(draft.Ingredients, system)
+	localized.Instructions = units.LocalizeInstructions(draft.Instructions, system)
+	return &localized
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/pageza/alchemorsel-v2/backend/config"
@@ -23,6 +24,9 @@ func New(cfg *config.Config) (*gorm.DB, error) {
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBSSLMode,
 	)
+	if options := VectorSearchOptions(cfg); options != "" {
+		dsn += " options='" + options + "'"
+	}
 
 	// Log connection string (without password)
 	log.Printf("Connecting to database at %s:%s as user %s", cfg.DBHost, cfg.DBPort, cfg.DBUser)
@@ -58,6 +62,19 @@ func New(cfg *config.Config) (*gorm.DB, error) {
 	return db, nil
 }
 
+// VectorSearchOptions returns the session settings for the configured vector
+// search tuning, in the form of the Postgres options connection parameter
+func VectorSearchOptions(cfg *config.Config) string {
+	var options []string
+	if cfg.VectorEfSearch > 0 {
+		options = append(options, fmt.Sprintf("-c hnsw.ef_search=%d", cfg.VectorEfSearch))
+	}
+	if cfg.VectorProbes > 0 {
+		options = append(options, fmt.Sprintf("-c ivfflat.probes=%d", cfg.VectorProbes))
+	}
+	return strings.Join(options, " ")
+}
+
 // HealthCheck checks if the database is accessible
 func (db *DB) HealthCheck(ctx context.Context) error {
 	sqlDB, err := db.GormDB.DB()
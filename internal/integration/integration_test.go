@@ -17,6 +17,7 @@ import (
 	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
 	"github.com/pageza/alchemorsel-v2/backend/internal/mocks"
 	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
 	"github.com/pageza/alchemorsel-v2/backend/internal/service"
 	"github.com/pageza/alchemorsel-v2/backend/internal/types"
 	"github.com/stretchr/testify/assert"
@@ -34,6 +35,14 @@ func (m *MockEmbeddingService) GenerateEmbeddingFromRecipe(name, description str
 	return pgvector.NewVector([]float32{0.1, 0.2, 0.3}), nil
 }
 
+func (m *MockEmbeddingService) Model() string {
+	return "mock"
+}
+
+func (m *MockEmbeddingService) Dimensions() int {
+	return 3
+}
+
 // MockTokenValidator is a mock implementation of the token validator
 type MockTokenValidator struct{}
 
@@ -117,6 +126,10 @@ func (m *MockLLMService) GenerateRecipesBatch(prompts []string) ([]string, error
 	return []string{`{"name":"Test Recipe","description":"Desc","category":"Cat","ingredients":["i1"],"instructions":["s1"],"calories":100,"protein":10,"carbs":20,"fat":5}`}, nil
 }
 
+func (m *MockLLMService) SuggestSubstitutions(recipeName string, ingredients []string, constraint string) ([]service.SubstitutionSuggestion, error) {
+	return []service.SubstitutionSuggestion{}, nil
+}
+
 // setupTestRouter creates a test router with mock services
 func setupTestRouter(authService *mocks.MockAuthService, profileService *mocks.MockProfileService, recipeService *mocks.MockRecipeService) *gin.Engine {
 	router := gin.Default()
@@ -270,7 +283,7 @@ func setupTestRouter(authService *mocks.MockAuthService, profileService *mocks.M
 			return
 		}
 
-		recipe, err := recipeService.GetRecipe(c.Request.Context(), id)
+		recipe, err := recipeService.GetRecipe(c.Request.Context(), c.MustGet("user_id").(uuid.UUID), id)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -310,7 +323,7 @@ func setupTestRouter(authService *mocks.MockAuthService, profileService *mocks.M
 			Tags:               req.Tags,
 		}
 
-		updatedRecipe, err := recipeService.UpdateRecipe(c.Request.Context(), id, recipe)
+		updatedRecipe, err := recipeService.UpdateRecipe(c.Request.Context(), recipe.UserID, id, recipe)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -326,7 +339,7 @@ func setupTestRouter(authService *mocks.MockAuthService, profileService *mocks.M
 			return
 		}
 
-		err = recipeService.DeleteRecipe(c.Request.Context(), id)
+		err = recipeService.DeleteRecipe(c.Request.Context(), c.MustGet("user_id").(uuid.UUID), id)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -338,7 +351,7 @@ func setupTestRouter(authService *mocks.MockAuthService, profileService *mocks.M
 	// Add route for getting all recipes
 	router.GET("/api/v1/recipes", middleware.AuthMiddleware(authService), func(c *gin.Context) {
 		userID := c.MustGet("user_id").(uuid.UUID)
-		recipes, err := profileService.GetUserRecipes(c.Request.Context(), userID)
+		recipes, _, err := profileService.GetUserRecipes(c.Request.Context(), userID, pagination.Page{})
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -399,14 +412,14 @@ func TestIntegrationRegisterLoginCreateModify(t *testing.T) {
 	}, nil)
 
 	// For debugging: relax userID expectation to any value
-	profileService.On("GetUserRecipes", mock.Anything, mock.Anything).Return([]*models.Recipe{
+	profileService.On("GetUserRecipes", mock.Anything, mock.Anything, mock.Anything).Return([]*models.Recipe{
 		{
 			ID:          uuid.New(),
 			UserID:      testUserID,
 			Name:        "Test Recipe",
 			Description: "Test Description",
 		},
-	}, nil)
+	}, &pagination.Info{Limit: pagination.DefaultLimit}, nil)
 
 	// Mock recipe operations
 	testRecipeID := uuid.New()
@@ -417,21 +430,21 @@ func TestIntegrationRegisterLoginCreateModify(t *testing.T) {
 		Description: "Test Description",
 	}, nil)
 
-	recipeService.On("GetRecipe", mock.Anything, testRecipeID).Return(&models.Recipe{
+	recipeService.On("GetRecipe", mock.Anything, mock.Anything, testRecipeID).Return(&models.Recipe{
 		ID:          testRecipeID,
 		UserID:      testUserID,
 		Name:        "Test Recipe",
 		Description: "Test Description",
 	}, nil)
 
-	recipeService.On("UpdateRecipe", mock.Anything, testRecipeID, mock.Anything).Return(&models.Recipe{
+	recipeService.On("UpdateRecipe", mock.Anything, mock.Anything, testRecipeID, mock.Anything).Return(&models.Recipe{
 		ID:          testRecipeID,
 		UserID:      testUserID,
 		Name:        "Updated Recipe",
 		Description: "Updated Description",
 	}, nil)
 
-	recipeService.On("DeleteRecipe", mock.Anything, testRecipeID).Return(nil)
+	recipeService.On("DeleteRecipe", mock.Anything, mock.Anything, testRecipeID).Return(nil)
 
 	// Setup test router
 	router := setupTestRouter(authService, profileService, recipeService)
@@ -630,7 +643,7 @@ func TestRecipeCRUD(t *testing.T) {
 	}, nil)
 
 	// Mock recipe retrieval
-	mockRecipeService.On("GetRecipe", mock.Anything, recipeID).Return(&models.Recipe{
+	mockRecipeService.On("GetRecipe", mock.Anything, mock.Anything, recipeID).Return(&models.Recipe{
 		ID:                 recipeID,
 		UserID:             testUUID,
 		Name:               "Test Recipe",
@@ -649,7 +662,7 @@ func TestRecipeCRUD(t *testing.T) {
 	}, nil)
 
 	// Mock recipe update
-	mockRecipeService.On("UpdateRecipe", mock.Anything, recipeID, mock.Anything).Return(&models.Recipe{
+	mockRecipeService.On("UpdateRecipe", mock.Anything, mock.Anything, recipeID, mock.Anything).Return(&models.Recipe{
 		ID:                 recipeID,
 		UserID:             testUUID,
 		Name:               "Updated Recipe",
@@ -668,7 +681,7 @@ func TestRecipeCRUD(t *testing.T) {
 	}, nil)
 
 	// Mock recipe deletion
-	mockRecipeService.On("DeleteRecipe", mock.Anything, recipeID).Return(nil)
+	mockRecipeService.On("DeleteRecipe", mock.Anything, mock.Anything, recipeID).Return(nil)
 
 	router := setupTestRouter(mockAuthService, mockProfileService, mockRecipeService)
 
@@ -813,7 +826,7 @@ func TestGetRecipe(t *testing.T) {
 
 	// Mock recipe operations
 	testRecipeID := uuid.New()
-	mockRecipeService.On("GetRecipe", mock.Anything, testRecipeID).Return(&models.Recipe{
+	mockRecipeService.On("GetRecipe", mock.Anything, mock.Anything, testRecipeID).Return(&models.Recipe{
 		ID:          testRecipeID,
 		UserID:      testUUID,
 		Name:        "Test Recipe",
@@ -188,6 +188,16 @@ func NewRecipeModificationRateLimiter(redisClient *redis.Client) *RateLimiter {
 	})
 }
 
+// NewCommentRateLimiter creates a rate limiter for posting comments and
+// replies (20 per user per 10 minutes)
+func NewCommentRateLimiter(redisClient *redis.Client) *RateLimiter {
+	return NewRateLimiter(redisClient, RateLimitConfig{
+		Window:    10 * time.Minute,
+		Limit:     20,
+		KeyPrefix: "rate_limit:comments",
+	})
+}
+
 // PerRecipeRateLimitMiddleware creates a middleware for per-recipe rate limiting
 func (rl *RateLimiter) PerRecipeRateLimitMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// testRedisClient connects to the Redis named by TEST_REDIS_URL, skipping
+// the test when none is reachable
+func testRedisClient(t *testing.T) *redis.Client {
+	url := os.Getenv("TEST_REDIS_URL")
+	if url == "" {
+		url = "redis://localhost:6379"
+	}
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		t.Fatalf("invalid TEST_REDIS_URL: %v", err)
+	}
+	client := redis.NewClient(opts)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		t.Skipf("redis not available: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestCommentRateLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := NewCommentRateLimiter(testRedisClient(t))
+
+	userID := uuid.New()
+	router := gin.New()
+	router.POST("/comments", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	}, limiter.RateLimitMiddleware(), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	post := func() int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/comments", nil))
+		return w.Code
+	}
+	for i := 0; i < limiter.config.Limit; i++ {
+		if code := post(); code != http.StatusCreated {
+			t.Fatalf("comment %d: got status %d, want %d", i+1, code, http.StatusCreated)
+		}
+	}
+	if code := post(); code != http.StatusTooManyRequests {
+		t.Errorf("comment over the limit: got status %d, want %d", code, http.StatusTooManyRequests)
+	}
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"github.com/pageza/alchemorsel-v2/backend/internal/service"
 	"github.com/pageza/alchemorsel-v2/backend/internal/types"
 	"github.com/stretchr/testify/mock"
 )
@@ -53,18 +55,26 @@ func (m *MockProfileService) ValidateToken(token string) (*types.TokenClaims, er
 	return args.Get(0).(*types.TokenClaims), args.Error(1)
 }
 
-func (m *MockProfileService) GetUserRecipes(ctx context.Context, userID uuid.UUID) ([]*models.Recipe, error) {
-	args := m.Called(ctx, userID)
+func (m *MockProfileService) GetUserRecipes(ctx context.Context, userID uuid.UUID, page pagination.Page) ([]*models.Recipe, *pagination.Info, error) {
+	args := m.Called(ctx, userID, page)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, nil, args.Error(2)
 	}
-	return args.Get(0).([]*models.Recipe), args.Error(1)
+	return args.Get(0).([]*models.Recipe), args.Get(1).(*pagination.Info), args.Error(2)
 }
 
-func (m *MockProfileService) GetUserProfile(username string) (*models.UserProfile, error) {
-	args := m.Called(username)
+func (m *MockProfileService) GetUserProfile(ctx context.Context, username string) (*service.PublicProfile, error) {
+	args := m.Called(ctx, username)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*models.UserProfile), args.Error(1)
+	return args.Get(0).(*service.PublicProfile), args.Error(1)
+}
+
+func (m *MockProfileService) GetPublicRecipes(ctx context.Context, username string, page pagination.Page) ([]*models.Recipe, *pagination.Info, error) {
+	args := m.Called(ctx, username, page)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*models.Recipe), args.Get(1).(*pagination.Info), args.Error(2)
 }
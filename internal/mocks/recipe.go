@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -23,8 +24,8 @@ func (m *MockRecipeService) CreateRecipe(ctx context.Context, recipe *models.Rec
 }
 
 // GetRecipe mocks the GetRecipe method
-func (m *MockRecipeService) GetRecipe(ctx context.Context, id uuid.UUID) (*models.Recipe, error) {
-	args := m.Called(ctx, id)
+func (m *MockRecipeService) GetRecipe(ctx context.Context, viewerID, id uuid.UUID) (*models.Recipe, error) {
+	args := m.Called(ctx, viewerID, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -32,8 +33,8 @@ func (m *MockRecipeService) GetRecipe(ctx context.Context, id uuid.UUID) (*model
 }
 
 // UpdateRecipe mocks the UpdateRecipe method
-func (m *MockRecipeService) UpdateRecipe(ctx context.Context, id uuid.UUID, recipe *models.Recipe) (*models.Recipe, error) {
-	args := m.Called(ctx, id, recipe)
+func (m *MockRecipeService) UpdateRecipe(ctx context.Context, userID, id uuid.UUID, recipe *models.Recipe) (*models.Recipe, error) {
+	args := m.Called(ctx, userID, id, recipe)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -41,25 +42,25 @@ func (m *MockRecipeService) UpdateRecipe(ctx context.Context, id uuid.UUID, reci
 }
 
 // DeleteRecipe mocks the DeleteRecipe method
-func (m *MockRecipeService) DeleteRecipe(ctx context.Context, id uuid.UUID) error {
-	args := m.Called(ctx, id)
+func (m *MockRecipeService) DeleteRecipe(ctx context.Context, userID, id uuid.UUID) error {
+	args := m.Called(ctx, userID, id)
 	return args.Error(0)
 }
 
 // ListRecipes mocks the ListRecipes method
-func (m *MockRecipeService) ListRecipes(ctx context.Context, userID uuid.UUID) ([]*models.Recipe, error) {
-	args := m.Called(ctx, userID)
+func (m *MockRecipeService) ListRecipes(ctx context.Context, viewerID uuid.UUID, ownerID *uuid.UUID, page pagination.Page) ([]*models.Recipe, *pagination.Info, error) {
+	args := m.Called(ctx, viewerID, ownerID, page)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, nil, args.Error(2)
 	}
-	return args.Get(0).([]*models.Recipe), args.Error(1)
+	return args.Get(0).([]*models.Recipe), args.Get(1).(*pagination.Info), args.Error(2)
 }
 
 // SearchRecipes mocks the SearchRecipes method
-func (m *MockRecipeService) SearchRecipes(ctx context.Context, query string) ([]*models.Recipe, error) {
-	args := m.Called(ctx, query)
+func (m *MockRecipeService) SearchRecipes(ctx context.Context, viewerID uuid.UUID, query string, page pagination.Page) ([]*models.Recipe, *pagination.Info, error) {
+	args := m.Called(ctx, viewerID, query, page)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, nil, args.Error(2)
 	}
-	return args.Get(0).([]*models.Recipe), args.Error(1)
+	return args.Get(0).([]*models.Recipe), args.Get(1).(*pagination.Info), args.Error(2)
 }
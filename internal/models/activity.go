@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Activity types
+const (
+	// ActivityRecipePublished is a user creating a recipe
+	ActivityRecipePublished = "recipe_published"
+	// ActivityRecipeForked is a user saving a fork of another recipe
+	ActivityRecipeForked = "recipe_forked"
+	// ActivityRecipeReviewed is a user reviewing a recipe
+	ActivityRecipeReviewed = "recipe_reviewed"
+	// ActivityCollectionRecipeAdded is a user adding a recipe to one of their
+	// public collections
+	ActivityCollectionRecipeAdded = "collection_recipe_added"
+)
+
+// Activity is something a user did that their followers see in their feed.
+// Every activity is about a recipe; ReviewID and CollectionID point at the
+// review or collection involved, when there is one. Username, RecipeName and
+// CollectionName are read when listing.
+type Activity struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primarykey;default:gen_random_uuid()" json:"id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Username       string     `gorm:"->;-:migration" json:"username,omitempty"`
+	Type           string     `gorm:"size:30;not null" json:"type"`
+	RecipeID       uuid.UUID  `gorm:"type:uuid;not null" json:"recipe_id"`
+	RecipeName     string     `gorm:"->;-:migration" json:"recipe_name,omitempty"`
+	ReviewID       *uuid.UUID `gorm:"type:uuid" json:"review_id,omitempty"`
+	CollectionID   *uuid.UUID `gorm:"type:uuid" json:"collection_id,omitempty"`
+	CollectionName string     `gorm:"->;-:migration" json:"collection_name,omitempty"`
+}
+
+// TableName returns the table name for the Activity model
+func (Activity) TableName() string {
+	return "activities"
+}
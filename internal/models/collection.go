@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Collection privacy levels
+const (
+	// CollectionPrivate collections are only visible to their owner
+	CollectionPrivate = "private"
+	// CollectionLink collections are visible to anyone with the share link
+	CollectionLink = "link"
+	// CollectionPublic collections are visible to every user
+	CollectionPublic = "public"
+)
+
+// DefaultCollectionName is the name of each user's default collection, which
+// holds their favorites
+const DefaultCollectionName = "Favorites"
+
+// Collection is a user's named, ordered set of recipes. ShareToken is set
+// while Privacy is CollectionLink. RecipeCount and Recipes are filled in
+// when reading.
+type Collection struct {
+	ID            uuid.UUID `gorm:"type:uuid;primarykey;default:gen_random_uuid()" json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name          string    `gorm:"size:100;not null" json:"name"`
+	Description   string    `gorm:"type:text;not null;default:''" json:"description"`
+	CoverImageURL string    `gorm:"size:255;not null;default:''" json:"cover_image_url"`
+	Privacy       string    `gorm:"size:20;not null;default:'private'" json:"privacy"`
+	ShareToken    *string   `gorm:"size:64;uniqueIndex" json:"share_token,omitempty"`
+	IsDefault     bool      `gorm:"not null;default:false" json:"is_default"`
+	RecipeCount   int       `gorm:"->;-:migration" json:"recipe_count"`
+	Recipes       []*Recipe `gorm:"-" json:"recipes,omitempty"`
+}
+
+// TableName returns the table name for the Collection model
+func (Collection) TableName() string {
+	return "collections"
+}
+
+// CollectionRecipe places a recipe in a collection
+type CollectionRecipe struct {
+	CollectionID uuid.UUID `gorm:"type:uuid;primaryKey" json:"collection_id"`
+	RecipeID     uuid.UUID `gorm:"type:uuid;primaryKey" json:"recipe_id"`
+	Position     int       `gorm:"not null" json:"position"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName returns the table name for the CollectionRecipe model
+func (CollectionRecipe) TableName() string {
+	return "collection_recipes"
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecipeComment is a comment on a recipe or a reply to another comment.
+// RootID is the top-level comment of a reply's thread. Deleted comments are
+// kept, without their body, so that replies keep their place; DeletedAt is a
+// plain timestamp rather than gorm.DeletedAt so that queries still see them.
+type RecipeComment struct {
+	ID        uuid.UUID        `gorm:"type:uuid;primarykey;default:gen_random_uuid()" json:"id"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	RecipeID  uuid.UUID        `gorm:"type:uuid;not null;index" json:"recipe_id"`
+	UserID    uuid.UUID        `gorm:"type:uuid;not null" json:"user_id"`
+	Username  string           `gorm:"->;-:migration" json:"username,omitempty"`
+	ParentID  *uuid.UUID       `gorm:"type:uuid" json:"parent_id,omitempty"`
+	RootID    *uuid.UUID       `gorm:"type:uuid;index" json:"root_id,omitempty"`
+	Body      string           `gorm:"type:text;not null" json:"body"`
+	EditedAt  *time.Time       `json:"edited_at,omitempty"`
+	DeletedAt *time.Time       `json:"deleted_at,omitempty"`
+	DeletedBy *uuid.UUID       `gorm:"type:uuid" json:"-"`
+	Replies   []*RecipeComment `gorm:"-" json:"replies,omitempty"`
+}
+
+// TableName returns the table name for the RecipeComment model
+func (RecipeComment) TableName() string {
+	return "recipe_comments"
+}
+
+// RecipeCommentEdit is an earlier version of an edited comment. CreatedAt is
+// when that version was replaced.
+type RecipeCommentEdit struct {
+	ID        uuid.UUID `gorm:"type:uuid;primarykey;default:gen_random_uuid()" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	CommentID uuid.UUID `gorm:"type:uuid;not null;index" json:"comment_id"`
+	Body      string    `gorm:"type:text;not null" json:"body"`
+}
+
+// TableName returns the table name for the RecipeCommentEdit model
+func (RecipeCommentEdit) TableName() string {
+	return "recipe_comment_edits"
+}
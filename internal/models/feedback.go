@@ -35,6 +35,4 @@ type FeedbackFilters struct {
 	Status   string `json:"status,omitempty"`
 	Priority string `json:"priority,omitempty"`
 	UserID   string `json:"user_id,omitempty"`
-	Limit    int    `json:"limit,omitempty"`
-	Offset   int    `json:"offset,omitempty"`
 }
\ No newline at end of file
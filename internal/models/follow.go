@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Follow records that one user follows another
+type Follow struct {
+	FollowerID  uuid.UUID `gorm:"type:uuid;primaryKey" json:"follower_id"`
+	FollowingID uuid.UUID `gorm:"type:uuid;primaryKey" json:"following_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName returns the table name for the Follow model
+func (Follow) TableName() string {
+	return "follows"
+}
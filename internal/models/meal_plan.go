@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Meal slots a recipe can fill on a meal plan day
+const (
+	MealSlotBreakfast = "breakfast"
+	MealSlotLunch     = "lunch"
+	MealSlotDinner    = "dinner"
+	MealSlotSnack     = "snack"
+)
+
+// MealSlots lists the meal slots in the order they are eaten
+var MealSlots = []string{MealSlotBreakfast, MealSlotLunch, MealSlotDinner, MealSlotSnack}
+
+// MealPlan is a user's plan of recipes for a run of days. Targets are daily
+// amounts; zero means no target.
+type MealPlan struct {
+	ID            uuid.UUID       `gorm:"type:uuid;primarykey;default:gen_random_uuid()" json:"id"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt  `gorm:"index" json:"-"`
+	UserID        uuid.UUID       `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name          string          `gorm:"size:100;not null" json:"name"`
+	StartDate     time.Time       `gorm:"type:date;not null" json:"start_date"`
+	Days          int             `gorm:"not null;default:7" json:"days"`
+	CalorieTarget float64         `gorm:"type:float" json:"calorie_target"`
+	ProteinTarget float64         `gorm:"type:float" json:"protein_target"`
+	CarbsTarget   float64         `gorm:"type:float" json:"carbs_target"`
+	FatTarget     float64         `gorm:"type:float" json:"fat_target"`
+	Entries       []MealPlanEntry `gorm:"foreignKey:MealPlanID" json:"entries"`
+}
+
+// TableName returns the table name for the MealPlan model
+func (MealPlan) TableName() string {
+	return "meal_plans"
+}
+
+// EndDate returns the last day covered by the plan
+func (p *MealPlan) EndDate() time.Time {
+	return p.StartDate.AddDate(0, 0, p.Days-1)
+}
+
+// MealPlanEntry places a recipe in one slot of one day of a meal plan
+type MealPlanEntry struct {
+	ID         uuid.UUID `gorm:"type:uuid;primarykey;default:gen_random_uuid()" json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	MealPlanID uuid.UUID `gorm:"type:uuid;not null;index" json:"meal_plan_id"`
+	Date       time.Time `gorm:"type:date;not null" json:"date"`
+	Slot       string    `gorm:"size:20;not null" json:"slot"`
+	RecipeID   uuid.UUID `gorm:"type:uuid;not null" json:"recipe_id"`
+	Servings   int       `gorm:"not null;default:1" json:"servings"`
+	Recipe     *Recipe   `gorm:"foreignKey:RecipeID" json:"recipe,omitempty"`
+}
+
+// TableName returns the table name for the MealPlanEntry model
+func (MealPlanEntry) TableName() string {
+	return "meal_plan_entries"
+}
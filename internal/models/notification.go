@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification types
+const (
+	// NotificationMention tells a user they were @mentioned in a comment
+	NotificationMention = "mention"
+	// NotificationReply tells a user someone replied to their comment
+	NotificationReply = "reply"
+	// NotificationComment tells a recipe's author about a new comment on it
+	NotificationComment = "comment"
+	// NotificationFollow tells a user someone started following them
+	NotificationFollow = "follow"
+)
+
+// Notification tells a user about something another user, the actor, did.
+// RecipeID and CommentID point at what it is about, when there is one.
+// ActorUsername is read from the actor's profile when listing.
+type Notification struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primarykey;default:gen_random_uuid()" json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UserID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	ActorID       *uuid.UUID `gorm:"type:uuid" json:"actor_id,omitempty"`
+	ActorUsername string     `gorm:"->;-:migration" json:"actor_username,omitempty"`
+	Type          string     `gorm:"size:30;not null" json:"type"`
+	RecipeID      *uuid.UUID `gorm:"type:uuid" json:"recipe_id,omitempty"`
+	CommentID     *uuid.UUID `gorm:"type:uuid" json:"comment_id,omitempty"`
+	ReadAt        *time.Time `json:"read_at,omitempty"`
+}
+
+// TableName returns the table name for the Notification model
+func (Notification) TableName() string {
+	return "notifications"
+}
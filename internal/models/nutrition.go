@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Food is an entry in the local nutrient database. Nutrient values are per
+// 100 g of the edible portion.
+type Food struct {
+	ID        uuid.UUID        `gorm:"type:uuid;primarykey;default:gen_random_uuid()" json:"id"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	DeletedAt gorm.DeletedAt   `gorm:"index" json:"-"`
+	Name      string           `gorm:"size:255;not null;uniqueIndex" json:"name"`
+	Aliases   JSONBStringArray `gorm:"type:jsonb;not null;default:'[]'" json:"aliases"`
+	Source    string           `gorm:"size:50" json:"source"`
+	SourceID  string           `gorm:"size:50" json:"source_id"`
+	Calories  float64          `gorm:"type:float;not null;default:0" json:"calories"`
+	Protein   float64          `gorm:"type:float;not null;default:0" json:"protein"`
+	Carbs     float64          `gorm:"type:float;not null;default:0" json:"carbs"`
+	Fat       float64          `gorm:"type:float;not null;default:0" json:"fat"`
+	Micronutrients
+	UnitWeightGrams float64 `gorm:"type:float" json:"unit_weight_grams"` // weight of one piece, e.g. one egg
+	GramsPerML      float64 `gorm:"type:float" json:"grams_per_ml"`
+}
+
+// TableName returns the table name for the Food model
+func (Food) TableName() string {
+	return "foods"
+}
+
+// Micronutrients are the nutrients tracked alongside calories and macros.
+// Sodium, cholesterol and minerals are in milligrams, vitamin D in
+// micrograms and everything else in grams.
+type Micronutrients struct {
+	Fiber        float64 `gorm:"type:float" json:"fiber"`
+	Sugar        float64 `gorm:"type:float" json:"sugar"`
+	SaturatedFat float64 `gorm:"type:float" json:"saturated_fat"`
+	Sodium       float64 `gorm:"type:float" json:"sodium"`
+	Cholesterol  float64 `gorm:"type:float" json:"cholesterol"`
+	VitaminD     float64 `gorm:"type:float" json:"vitamin_d"`
+	Calcium      float64 `gorm:"type:float" json:"calcium"`
+	Iron         float64 `gorm:"type:float" json:"iron"`
+	Potassium    float64 `gorm:"type:float" json:"potassium"`
+}
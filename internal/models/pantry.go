@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PantryItem is an ingredient a user has on hand. Quantity and Unit are
+// optional; a zero Quantity means the amount is not tracked.
+type PantryItem struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primarykey;default:gen_random_uuid()" json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name      string     `gorm:"size:200;not null" json:"name"`
+	Quantity  float64    `gorm:"type:float" json:"quantity"`
+	Unit      string     `gorm:"size:20" json:"unit"`
+	ExpiresAt *time.Time `gorm:"type:date" json:"expires_at,omitempty"`
+}
+
+// TableName returns the table name for the PantryItem model
+func (PantryItem) TableName() string {
+	return "pantry_items"
+}
@@ -41,7 +41,19 @@ func (a *JSONBStringArray) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, a)
 }
 
-// Recipe represents a recipe in the system
+// Recipe visibility levels
+const (
+	// RecipePrivate recipes are only visible to their author
+	RecipePrivate = "private"
+	// RecipeUnlisted recipes can be opened by anyone with their ID but are
+	// left out of lists and search
+	RecipeUnlisted = "unlisted"
+	// RecipePublic recipes are visible to every user
+	RecipePublic = "public"
+)
+
+// Recipe represents a recipe in the system. Visibility is one of the recipe
+// visibility levels.
 type Recipe struct {
 	ID                 uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	CreatedAt          time.Time        `json:"created_at"`
@@ -58,17 +70,30 @@ type Recipe struct {
 	Protein            float64          `gorm:"type:float" json:"protein"`
 	Carbs              float64          `gorm:"type:float" json:"carbs"`
 	Fat                float64          `gorm:"type:float" json:"fat"`
-	Embedding          pgvector.Vector  `gorm:"type:vector(1536)" json:"-"`
+	Servings           int              `gorm:"type:integer" json:"servings"`
+	PrepTime           int              `gorm:"type:integer" json:"prep_time_minutes"`
+	CookTime           int              `gorm:"type:integer" json:"cook_time_minutes"`
+	Embedding          pgvector.Vector  `gorm:"type:vector" json:"-"`
+	EmbeddingHash      string           `gorm:"size:64" json:"-"`
+	EmbeddingModel     string           `gorm:"size:100" json:"-"`
+	EmbeddingDims      int              `gorm:"column:embedding_dimensions" json:"-"`
 	UserID             uuid.UUID        `gorm:"type:uuid;not null" json:"user_id"`
+	ForkedFromID       *uuid.UUID       `gorm:"type:uuid" json:"forked_from_id,omitempty"`
+	Visibility         string           `gorm:"size:20;not null;default:'private'" json:"visibility"`
 	DietaryPreferences JSONBStringArray `gorm:"type:jsonb;not null;default:'[]'" json:"dietary_preferences"`
 	Tags               JSONBStringArray `gorm:"type:jsonb;not null;default:'[]'" json:"tags"`
+	RatingAverage      float64          `gorm:"type:float;not null;default:0" json:"rating_average"`
+	RatingCount        int              `gorm:"not null;default:0" json:"rating_count"`
+	Micronutrients
 }
 
-// BeforeCreate is a GORM hook that ensures the embedding vector is properly initialized
+// BeforeCreate is a GORM hook that ensures the embedding vector is properly
+// initialized. A recipe without an embedding gets a zero vector with no
+// EmbeddingModel, which the reindex worker replaces later.
 func (r *Recipe) BeforeCreate(tx *gorm.DB) error {
-	// Initialize with a zero vector of the correct dimension
-	zeroVector := make([]float32, 1536)
-	r.Embedding = pgvector.NewVector(zeroVector)
+	if len(r.Embedding.Slice()) == 0 {
+		r.Embedding = pgvector.NewVector(make([]float32, 1536))
+	}
 	return nil
 }
 
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecipeGeneration records a recipe draft generated or forked by the LLM for
+// a user. DraftID refers to the draft in Redis, which expires.
+type RecipeGeneration struct {
+	ID        uuid.UUID `gorm:"type:uuid;primarykey;default:gen_random_uuid()" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	DraftID   string    `gorm:"size:36;not null" json:"draft_id"`
+	Intent    string    `gorm:"size:20;not null" json:"intent"`
+}
+
+// TableName returns the table name for the RecipeGeneration model
+func (RecipeGeneration) TableName() string {
+	return "recipe_generations"
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecipeShare is a link that lets anyone read a recipe without signing in
+// until it expires or is revoked. Token is only filled in for the user who
+// created the link and the recipe's author.
+type RecipeShare struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primarykey;default:gen_random_uuid()" json:"id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	RecipeID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"recipe_id"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null" json:"user_id"`
+	ExpiresAt      time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	AccessCount    int64      `gorm:"not null;default:0" json:"access_count"`
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+	Token          string     `gorm:"-" json:"token,omitempty"`
+}
+
+// TableName returns the table name for the RecipeShare model
+func (RecipeShare) TableName() string {
+	return "recipe_shares"
+}
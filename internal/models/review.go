@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecipeReview is a user's rating of a recipe from 1 to 5 with an optional
+// review. MadeIt marks reviews from users who cooked the recipe, and
+// Modifications notes what they changed. Username is read from the author's
+// profile when listing.
+type RecipeReview struct {
+	ID            uuid.UUID `gorm:"type:uuid;primarykey;default:gen_random_uuid()" json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	RecipeID      uuid.UUID `gorm:"type:uuid;not null;index" json:"recipe_id"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Username      string    `gorm:"->;-:migration" json:"username,omitempty"`
+	Rating        int       `gorm:"type:smallint;not null" json:"rating"`
+	Body          string    `gorm:"type:text;not null;default:''" json:"body"`
+	MadeIt        bool      `gorm:"not null;default:false" json:"made_it"`
+	Modifications string    `gorm:"type:text;not null;default:''" json:"modifications"`
+}
+
+// TableName returns the table name for the RecipeReview model
+func (RecipeReview) TableName() string {
+	return "recipe_reviews"
+}
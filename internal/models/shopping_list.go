@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ShoppingList is a list of ingredients merged from recipes or a meal plan
+type ShoppingList struct {
+	ID         uuid.UUID          `gorm:"type:uuid;primarykey;default:gen_random_uuid()" json:"id"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt     `gorm:"index" json:"-"`
+	UserID     uuid.UUID          `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name       string             `gorm:"size:100;not null" json:"name"`
+	MealPlanID *uuid.UUID         `gorm:"type:uuid" json:"meal_plan_id,omitempty"`
+	Items      []ShoppingListItem `gorm:"foreignKey:ShoppingListID" json:"items"`
+}
+
+// TableName returns the table name for the ShoppingList model
+func (ShoppingList) TableName() string {
+	return "shopping_lists"
+}
+
+// ShoppingListItem is one merged ingredient on a shopping list. Items
+// without a parsed quantity have a zero Quantity and an empty Unit.
+type ShoppingListItem struct {
+	ID             uuid.UUID        `gorm:"type:uuid;primarykey;default:gen_random_uuid()" json:"id"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	ShoppingListID uuid.UUID        `gorm:"type:uuid;not null;index" json:"shopping_list_id"`
+	Name           string           `gorm:"size:200;not null" json:"name"`
+	Quantity       float64          `gorm:"type:float" json:"quantity"`
+	Unit           string           `gorm:"size:20" json:"unit"`
+	Display        string           `gorm:"size:255;not null" json:"display"`
+	Aisle          string           `gorm:"size:50;not null" json:"aisle"`
+	Checked        bool             `gorm:"not null;default:false" json:"checked"`
+	Recipes        JSONBStringArray `gorm:"type:jsonb" json:"recipes"`
+	Position       int              `gorm:"not null;default:0" json:"position"`
+}
+
+// TableName returns the table name for the ShoppingListItem model
+func (ShoppingListItem) TableName() string {
+	return "shopping_list_items"
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecipeView counts a recipe's views and unique viewers on one UTC day.
+// UniqueViewers is an estimate and stays zero for days counted without
+// Redis.
+type RecipeView struct {
+	RecipeID      uuid.UUID `gorm:"type:uuid;primaryKey" json:"recipe_id"`
+	Day           time.Time `gorm:"type:date;primaryKey" json:"day"`
+	Views         int64     `gorm:"not null;default:0" json:"views"`
+	UniqueViewers int64     `gorm:"not null;default:0" json:"unique_viewers"`
+}
+
+// TableName returns the table name for the RecipeView model
+func (RecipeView) TableName() string {
+	return "recipe_views"
+}
+
+// RecipeTrendingScore is a recipe's trending score as of ComputedAt
+type RecipeTrendingScore struct {
+	RecipeID   uuid.UUID `gorm:"type:uuid;primaryKey" json:"recipe_id"`
+	Score      float64   `gorm:"not null" json:"score"`
+	ComputedAt time.Time `gorm:"not null" json:"computed_at"`
+}
+
+// TableName returns the table name for the RecipeTrendingScore model
+func (RecipeTrendingScore) TableName() string {
+	return "recipe_trending_scores"
+}
+
+// Featured curation actions
+const (
+	// CurationPin places a recipe ahead of the trending ones
+	CurationPin = "pin"
+	// CurationExclude keeps a recipe out of featured and trending recipes
+	CurationExclude = "exclude"
+)
+
+// FeaturedCuration is an admin's decision to pin or exclude a recipe.
+// Pinned recipes are featured in ascending Position. RecipeName is read when
+// listing.
+type FeaturedCuration struct {
+	RecipeID   uuid.UUID  `gorm:"type:uuid;primaryKey" json:"recipe_id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	Action     string     `gorm:"size:10;not null" json:"action"`
+	Position   int        `gorm:"not null;default:0" json:"position"`
+	CreatedBy  *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	RecipeName string     `gorm:"->;-:migration" json:"recipe_name,omitempty"`
+}
+
+// TableName returns the table name for the FeaturedCuration model
+func (FeaturedCuration) TableName() string {
+	return "featured_curations"
+}
@@ -19,11 +19,18 @@ type User struct {
 	EmailVerifiedAt              *time.Time          `gorm:"column:email_verified_at" json:"email_verified_at,omitempty"`
 	VerificationToken            *string             `gorm:"column:verification_token" json:"-"`
 	VerificationTokenExpiresAt   *time.Time          `gorm:"column:verification_token_expires_at" json:"-"`
+	Role                         string              `gorm:"size:20;not null;default:'user'" json:"role"`
 	Profile                      UserProfile         `gorm:"foreignKey:UserID" json:"profile"`
 	DietaryPrefs                 []DietaryPreference `gorm:"foreignKey:UserID" json:"dietary_preferences"`
 	Allergens                    []Allergen          `gorm:"foreignKey:UserID" json:"allergens"`
 }
 
+// User roles. Admins can moderate comments and manage feedback.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type UserProfile struct {
 	ID                uuid.UUID      `gorm:"type:uuid;primarykey;default:gen_random_uuid()" json:"id"`
 	UserID            uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
@@ -31,6 +38,7 @@ type UserProfile struct {
 	Bio               string         `gorm:"type:text" json:"bio"`
 	ProfilePictureURL string         `gorm:"size:255" json:"profile_picture_url"`
 	PrivacyLevel      string         `gorm:"size:50;not null;default:'private'" json:"privacy_level"`
+	UnitSystem        string         `gorm:"size:20;not null;default:'imperial'" json:"unit_system"`
 	CreatedAt         time.Time      `json:"created_at"`
 	UpdatedAt         time.Time      `json:"updated_at"`
 	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
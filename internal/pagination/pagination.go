@@ -0,0 +1,120 @@
+// Package pagination implements opaque cursors for list endpoints. Lists
+// ordered by creation time use keyset pagination on (created_at, id); ranked
+// lists such as search results carry an offset in the cursor instead.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Default and maximum page sizes for list endpoints
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// ErrInvalidCursor is returned when a cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks where the next page starts. Keyset cursors hold the creation
+// time and ID of the last row returned; offset cursors hold the number of
+// rows already returned.
+type Cursor struct {
+	CreatedAt time.Time `json:"t,omitempty"`
+	ID        uuid.UUID `json:"id,omitempty"`
+	Offset    int       `json:"o,omitempty"`
+}
+
+// Encode returns the cursor as an opaque URL-safe string
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode parses a cursor produced by Encode
+func Decode(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Offset < 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// Page requests one page of a list. A zero Limit means DefaultLimit and a
+// nil After starts from the beginning.
+type Page struct {
+	Limit int
+	After *Cursor
+}
+
+// Size returns the number of rows the page holds
+func (p Page) Size() int {
+	if p.Limit <= 0 {
+		return DefaultLimit
+	}
+	return p.Limit
+}
+
+// Info describes a returned page. NextCursor is empty on the last page and
+// Total is only set where counting is cheap.
+type Info struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+// Keyset orders a query newest first and starts it after the page cursor.
+// column is the qualified creation time column, such as "recipes.created_at",
+// and idColumn the matching ID column. A cursor holding only an offset, from
+// the offset query parameter, skips that many rows instead. One row more than
+// the page size is fetched so that Finish can tell whether another page
+// follows.
+func Keyset(db *gorm.DB, page Page, column, idColumn string) *gorm.DB {
+	if page.After != nil && !page.After.CreatedAt.IsZero() {
+		db = db.Where("("+column+", "+idColumn+") < (?, ?)", page.After.CreatedAt, page.After.ID)
+	} else if page.After != nil && page.After.Offset > 0 {
+		db = db.Offset(page.After.Offset)
+	}
+	return db.Order(column + " DESC").Order(idColumn + " DESC").Limit(page.Size() + 1)
+}
+
+// Offset starts a ranked query after the page cursor, fetching one extra row
+// like Keyset
+func Offset(db *gorm.DB, page Page) *gorm.DB {
+	if page.After != nil && page.After.Offset > 0 {
+		db = db.Offset(page.After.Offset)
+	}
+	return db.Limit(page.Size() + 1)
+}
+
+// Finish trims the extra row fetched by Keyset or Offset and builds the page
+// info. cursor returns the keyset position of a row; pass nil for offset
+// pagination.
+func Finish[T any](rows []T, page Page, cursor func(T) Cursor) ([]T, *Info) {
+	info := &Info{Limit: page.Size()}
+	if len(rows) <= page.Size() {
+		return rows, info
+	}
+	rows = rows[:page.Size()]
+
+	if cursor != nil {
+		info.NextCursor = cursor(rows[len(rows)-1]).Encode()
+	} else {
+		offset := len(rows)
+		if page.After != nil {
+			offset += page.After.Offset
+		}
+		info.NextCursor = Cursor{Offset: offset}.Encode()
+	}
+	return rows, info
+}
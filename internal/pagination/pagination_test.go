@@ -0,0 +1,74 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC), ID: uuid.New()}
+
+	decoded, err := Decode(cursor.Encode())
+	require.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+	assert.Equal(t, cursor.ID, decoded.ID)
+
+	decoded, err = Decode(Cursor{Offset: 40}.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, 40, decoded.Offset)
+
+	for _, bad := range []string{"not a cursor!", "bm90IGpzb24", Cursor{Offset: -1}.Encode()} {
+		_, err := Decode(bad)
+		assert.ErrorIs(t, err, ErrInvalidCursor, bad)
+	}
+}
+
+func TestFinish(t *testing.T) {
+	rows := []int{1, 2, 3, 4}
+
+	// A short page is the last one
+	got, info := Finish(rows, Page{Limit: 5}, nil)
+	assert.Equal(t, rows, got)
+	assert.Equal(t, 5, info.Limit)
+	assert.Empty(t, info.NextCursor)
+
+	// The extra row is trimmed and the next offset follows the previous one
+	got, info = Finish(rows, Page{Limit: 3, After: &Cursor{Offset: 6}}, nil)
+	assert.Equal(t, []int{1, 2, 3}, got)
+	next, err := Decode(info.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, 9, next.Offset)
+
+	// Keyset cursors point at the last row returned
+	id := uuid.New()
+	_, info = Finish(rows, Page{Limit: 2}, func(row int) Cursor {
+		return Cursor{CreatedAt: time.Unix(int64(row), 0), ID: id}
+	})
+	next, err = Decode(info.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), next.CreatedAt.Unix())
+	assert.Equal(t, id, next.ID)
+
+	assert.Equal(t, DefaultLimit, Page{}.Size())
+}
+
+func TestKeysetOffset(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+	query := func(page Page) string {
+		var rows []struct{ ID int }
+		return Keyset(db.Table("items"), page, "created_at", "id").Find(&rows).Statement.SQL.String()
+	}
+
+	assert.Equal(t, "SELECT * FROM `items` ORDER BY created_at DESC,id DESC LIMIT 21", query(Page{}))
+	assert.Contains(t, query(Page{After: &Cursor{CreatedAt: time.Now(), ID: uuid.New()}}), "WHERE (created_at, id) < (?, ?)")
+
+	// Offset-only cursors from the offset parameter skip rows instead
+	assert.Equal(t, "SELECT * FROM `items` ORDER BY created_at DESC,id DESC LIMIT 21 OFFSET 40", query(Page{After: &Cursor{Offset: 40}}))
+}
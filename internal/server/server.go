@@ -4,12 +4,16 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
 	"github.com/pageza/alchemorsel-v2/backend/config"
 	"github.com/pageza/alchemorsel-v2/backend/internal/api"
+	"github.com/pageza/alchemorsel-v2/backend/internal/database"
 	"github.com/pageza/alchemorsel-v2/backend/internal/middleware"
 	"github.com/pageza/alchemorsel-v2/backend/internal/service"
 )
@@ -22,6 +26,17 @@ type Server struct {
 	logger  *log.Logger
 	auth    *service.AuthService
 	profile *service.ProfileService
+
+	// embeddingWorker re-embeds stale recipes while the server runs; nil
+	// when disabled
+	embeddingWorker *service.EmbeddingWorker
+	// trendingWorker refreshes trending scores while the server runs; nil
+	// when disabled
+	trendingWorker *service.TrendingWorker
+	// viewFlushWorker copies recipe view counters from Redis to the
+	// database; nil when disabled or Redis is unavailable
+	viewFlushWorker *service.ViewFlushWorker
+	stopWorkers     context.CancelFunc
 }
 
 // NewServer creates a new server instance
@@ -45,11 +60,88 @@ func NewServer(db *gorm.DB, auth *service.AuthService, profile *service.ProfileS
 	api.RegisterRoutes(router, db, auth, llmService, embeddingService, cfg)
 
 	return &Server{
-		router:  router,
-		db:      db,
-		auth:    auth,
-		profile: profile,
+		router:          router,
+		db:              db,
+		auth:            auth,
+		profile:         profile,
+		embeddingWorker: newEmbeddingWorker(db, embeddingService),
+		trendingWorker:  newTrendingWorker(db),
+		viewFlushWorker: newViewFlushWorker(db, cfg),
+	}
+}
+
+// newEmbeddingWorker configures the background embedding reindex from
+// EMBEDDING_REINDEX_INTERVAL (default 30m, 0 disables it) and
+// EMBEDDING_REINDEX_RPM (default 60 embedding requests per minute)
+func newEmbeddingWorker(db *gorm.DB, embeddingService service.EmbeddingServiceInterface) *service.EmbeddingWorker {
+	interval := 30 * time.Minute
+	if v := os.Getenv("EMBEDDING_REINDEX_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Printf("Invalid EMBEDDING_REINDEX_INTERVAL %q, using %s", v, interval)
+		} else {
+			interval = d
+		}
+	}
+	if interval <= 0 {
+		return nil
+	}
+
+	rpm := 60
+	if v := os.Getenv("EMBEDDING_REINDEX_RPM"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			log.Printf("Invalid EMBEDDING_REINDEX_RPM %q, using %d", v, rpm)
+		} else {
+			rpm = n
+		}
+	}
+
+	reindexer := service.NewEmbeddingReindexer(db, embeddingService)
+	return service.NewEmbeddingWorker(reindexer, interval, service.ReindexOptions{RequestsPerMinute: rpm})
+}
+
+// newTrendingWorker configures the background trending refresh from
+// TRENDING_REFRESH_INTERVAL (default 15m, 0 disables it)
+func newTrendingWorker(db *gorm.DB) *service.TrendingWorker {
+	interval := 15 * time.Minute
+	if v := os.Getenv("TRENDING_REFRESH_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Printf("Invalid TRENDING_REFRESH_INTERVAL %q, using %s", v, interval)
+		} else {
+			interval = d
+		}
 	}
+	if interval <= 0 {
+		return nil
+	}
+	return service.NewTrendingWorker(service.NewTrendingService(db, nil), interval)
+}
+
+// newViewFlushWorker configures the background flush of recipe view counters
+// from VIEW_FLUSH_INTERVAL (default 1m, 0 disables it). Views are only
+// counted in Redis when it is configured, so the worker needs it too.
+func newViewFlushWorker(db *gorm.DB, cfg *config.Config) *service.ViewFlushWorker {
+	interval := time.Minute
+	if v := os.Getenv("VIEW_FLUSH_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Printf("Invalid VIEW_FLUSH_INTERVAL %q, using %s", v, interval)
+		} else {
+			interval = d
+		}
+	}
+	if interval <= 0 {
+		return nil
+	}
+
+	redisClient, err := database.NewRedisClient(cfg)
+	if err != nil {
+		log.Printf("Warning: Failed to connect to Redis for view counters: %v", err)
+		return nil
+	}
+	return service.NewViewFlushWorker(service.NewAnalyticsService(db, redisClient), interval)
 }
 
 // Start starts the server
@@ -66,11 +158,27 @@ func (s *Server) Start(port string) error {
 		}
 	}()
 
+	// Start background workers
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopWorkers = cancel
+	if s.embeddingWorker != nil {
+		go s.embeddingWorker.Run(ctx)
+	}
+	if s.trendingWorker != nil {
+		go s.trendingWorker.Run(ctx)
+	}
+	if s.viewFlushWorker != nil {
+		go s.viewFlushWorker.Run(ctx)
+	}
+
 	return nil
 }
 
-// Stop gracefully stops the HTTP server
+// Stop gracefully stops the HTTP server and background workers
 func (s *Server) Stop(ctx context.Context) error {
+	if s.stopWorkers != nil {
+		s.stopWorkers()
+	}
 	if s.http != nil {
 		return s.http.Shutdown(ctx)
 	}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// ActivityService serves activity feeds. Other services record activities
+// with recordActivity as part of their own writes, and feeds are assembled
+// from the followed users' activities when read, so each activity is stored
+// once. Activities stay hidden while their user's profile, recipe or
+// collection is hidden from the viewer.
+type ActivityService struct {
+	db *gorm.DB
+}
+
+// Ensure ActivityService implements IActivityService
+var _ IActivityService = (*ActivityService)(nil)
+
+// NewActivityService creates a new ActivityService instance
+func NewActivityService(db *gorm.DB) *ActivityService {
+	return &ActivityService{db: db}
+}
+
+// Feed returns one page of the activities of the users the user follows,
+// newest first
+func (s *ActivityService) Feed(ctx context.Context, userID uuid.UUID, page pagination.Page) ([]*models.Activity, *pagination.Info, error) {
+	db := s.db.WithContext(ctx)
+	following := db.Model(&models.Follow{}).Select("following_id").Where("follower_id = ?", userID)
+	return listActivities(visibleActivities(db, userID).Where("activities.user_id IN (?)", following), page)
+}
+
+// ListUserActivity returns one page of the activities of the user with the
+// username, newest first
+func (s *ActivityService) ListUserActivity(ctx context.Context, viewerID uuid.UUID, username string, page pagination.Page) ([]*models.Activity, *pagination.Info, error) {
+	db := s.db.WithContext(ctx)
+	profile, err := visibleProfile(db, viewerID, username)
+	if err != nil {
+		return nil, nil, err
+	}
+	return listActivities(visibleActivities(db, viewerID).Where("activities.user_id = ?", profile.UserID), page)
+}
+
+// visibleActivities selects the activities the viewer can see, with their
+// user's username and their recipe and collection names. Recipes must be
+// listed for the viewer and collections public and still hold the recipe.
+func visibleActivities(db *gorm.DB, viewerID uuid.UUID) *gorm.DB {
+	return db.Model(&models.Activity{}).
+		Select("activities.*, user_profiles.username, recipes.name AS recipe_name, collections.name AS collection_name").
+		Joins("JOIN user_profiles ON user_profiles.user_id = activities.user_id AND user_profiles.deleted_at IS NULL").
+		Joins("JOIN recipes ON recipes.id = activities.recipe_id AND recipes.deleted_at IS NULL").
+		Joins("LEFT JOIN collections ON collections.id = activities.collection_id").
+		Scopes(VisibleProfiles(viewerID), ListedRecipes(viewerID)).
+		Where("(activities.collection_id IS NULL OR (collections.privacy = ? AND EXISTS (SELECT 1 FROM collection_recipes "+
+			"WHERE collection_recipes.collection_id = activities.collection_id AND collection_recipes.recipe_id = activities.recipe_id)))",
+			models.CollectionPublic)
+}
+
+// listActivities returns one page of an activities query, newest first
+func listActivities(query *gorm.DB, page pagination.Page) ([]*models.Activity, *pagination.Info, error) {
+	var activities []*models.Activity
+	if err := pagination.Keyset(query, page, "activities.created_at", "activities.id").Find(&activities).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to list activities: %w", err)
+	}
+	activities, info := pagination.Finish(activities, page, activityCursor)
+	return activities, info, nil
+}
+
+// recordActivity stores activities for their users' followers to see
+func recordActivity(tx *gorm.DB, activities []models.Activity) error {
+	if len(activities) == 0 {
+		return nil
+	}
+	if err := tx.Create(&activities).Error; err != nil {
+		return fmt.Errorf("failed to record activity: %w", err)
+	}
+	return nil
+}
+
+// activityCursor returns the keyset position of an activity
+func activityCursor(a *models.Activity) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: a.CreatedAt, ID: a.ID}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func activityTypes(activities []*models.Activity) []string {
+	types := make([]string, len(activities))
+	for i, a := range activities {
+		types[i] = a.Type + " " + a.RecipeName
+	}
+	return types
+}
+
+func TestFeedHidesWhatFollowersCannotSee(t *testing.T) {
+	f, hermit := newSocialFixture(t)
+	s := NewActivityService(f.db)
+	ctx := context.Background()
+
+	public, private := uuid.New(), uuid.New()
+	require.NoError(t, f.db.Exec("INSERT INTO collections (id, user_id, name, privacy) VALUES (?, ?, 'Soups', ?), (?, ?, 'Secret', ?)",
+		public, f.author, models.CollectionPublic, private, f.author, models.CollectionPrivate).Error)
+	require.NoError(t, f.db.Exec("INSERT INTO collection_recipes (collection_id, recipe_id, position) VALUES (?, ?, 1), (?, ?, 1)",
+		public, f.public, private, f.public).Error)
+
+	now := time.Now()
+	require.NoError(t, recordActivity(f.db, []models.Activity{
+		{CreatedAt: now.Add(-6 * time.Minute), UserID: f.author, Type: models.ActivityRecipePublished, RecipeID: f.public},
+		{CreatedAt: now.Add(-5 * time.Minute), UserID: f.author, Type: models.ActivityRecipePublished, RecipeID: f.private},
+		{CreatedAt: now.Add(-4 * time.Minute), UserID: f.author, Type: models.ActivityRecipePublished, RecipeID: f.unlisted},
+		{CreatedAt: now.Add(-3 * time.Minute), UserID: f.author, Type: models.ActivityCollectionRecipeAdded, RecipeID: f.public, CollectionID: &public},
+		{CreatedAt: now.Add(-2 * time.Minute), UserID: f.author, Type: models.ActivityCollectionRecipeAdded, RecipeID: f.public, CollectionID: &private},
+		{CreatedAt: now.Add(-time.Minute), UserID: hermit, Type: models.ActivityRecipeReviewed, RecipeID: f.public},
+		{CreatedAt: now, UserID: f.viewer, Type: models.ActivityRecipePublished, RecipeID: f.own},
+	}))
+
+	activities, _, err := s.Feed(ctx, f.viewer, pagination.Page{})
+	require.NoError(t, err)
+	assert.Empty(t, activities)
+
+	require.NoError(t, f.db.Create([]models.Follow{
+		{FollowerID: f.viewer, FollowingID: f.author},
+		{FollowerID: f.viewer, FollowingID: hermit},
+	}).Error)
+	activities, _, err = s.Feed(ctx, f.viewer, pagination.Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		models.ActivityCollectionRecipeAdded + " Public soup",
+		models.ActivityRecipePublished + " Public soup",
+	}, activityTypes(activities))
+	assert.Equal(t, "Author", activities[0].Username)
+	assert.Equal(t, "Soups", activities[0].CollectionName)
+
+	// Recipes taken out of a collection leave its activity
+	require.NoError(t, f.db.Exec("DELETE FROM collection_recipes WHERE collection_id = ?", public).Error)
+	activities, _, err = s.Feed(ctx, f.viewer, pagination.Page{})
+	require.NoError(t, err)
+	assert.Len(t, activities, 1)
+}
+
+func TestListUserActivity(t *testing.T) {
+	f, _ := newSocialFixture(t)
+	s := NewActivityService(f.db)
+	ctx := context.Background()
+
+	now := time.Now()
+	require.NoError(t, recordActivity(f.db, []models.Activity{
+		{CreatedAt: now.Add(-2 * time.Minute), UserID: f.author, Type: models.ActivityRecipePublished, RecipeID: f.public},
+		{CreatedAt: now.Add(-time.Minute), UserID: f.author, Type: models.ActivityRecipeForked, RecipeID: f.private},
+		{CreatedAt: now, UserID: f.viewer, Type: models.ActivityRecipePublished, RecipeID: f.own},
+	}))
+
+	activities, info, err := s.ListUserActivity(ctx, f.viewer, "author", pagination.Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.ActivityRecipePublished + " Public soup"}, activityTypes(activities))
+	assert.Empty(t, info.NextCursor)
+
+	// Authors see activity about their own private recipes
+	activities, _, err = s.ListUserActivity(ctx, f.author, "author", pagination.Page{Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.ActivityRecipeForked + " Private soup"}, activityTypes(activities))
+
+	_, _, err = s.ListUserActivity(ctx, f.viewer, "hermit", pagination.Page{})
+	assert.True(t, errors.Is(err, ErrUserNotFound))
+}
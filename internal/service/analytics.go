@@ -0,0 +1,388 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// DefaultAnalyticsDays is the number of days analytics cover by default
+	DefaultAnalyticsDays = 30
+	// MaxAnalyticsDays is the most days analytics can cover
+	MaxAnalyticsDays = 90
+
+	// viewCounterTTL keeps a day's view counters in Redis long enough to be
+	// flushed after the day ends
+	viewCounterTTL     = 48 * time.Hour
+	pendingViewsKey    = "recipe_views:pending"
+	analyticsDayLayout = "2006-01-02"
+)
+
+// AnalyticsCounts are the views, unique viewers, favorites, forks and share
+// links a recipe or day collected. UniqueViewers adds up each day's unique
+// viewers, so someone viewing on two days counts twice.
+type AnalyticsCounts struct {
+	Views         int64 `json:"views"`
+	UniqueViewers int64 `json:"unique_viewers"`
+	Favorites     int64 `json:"favorites"`
+	Forks         int64 `json:"forks"`
+	Shares        int64 `json:"shares"`
+}
+
+// add adds other's counts to c
+func (c *AnalyticsCounts) add(other AnalyticsCounts) {
+	c.Views += other.Views
+	c.UniqueViewers += other.UniqueViewers
+	c.Favorites += other.Favorites
+	c.Forks += other.Forks
+	c.Shares += other.Shares
+}
+
+// AnalyticsDay holds one UTC day of a creator's analytics
+type AnalyticsDay struct {
+	Date string `json:"date"`
+	AnalyticsCounts
+}
+
+// RecipeAnalytics holds one recipe's totals over the analytics range
+type RecipeAnalytics struct {
+	RecipeID uuid.UUID `json:"recipe_id"`
+	Name     string    `json:"name"`
+	AnalyticsCounts
+}
+
+// CreatorAnalytics describes how a creator's recipes did from From to To,
+// both inclusive UTC dates. Series has one entry per day, oldest first, and
+// Recipes lists the recipes with any activity, most viewed first.
+type CreatorAnalytics struct {
+	From    string            `json:"from"`
+	To      string            `json:"to"`
+	Totals  AnalyticsCounts   `json:"totals"`
+	Series  []AnalyticsDay    `json:"series"`
+	Recipes []RecipeAnalytics `json:"recipes"`
+}
+
+// AnalyticsService counts recipe views and reports how creators' recipes do
+// over time. With Redis, views are counted per recipe and UTC day with a
+// counter and a HyperLogLog of viewers, and FlushViews copies the day's
+// totals to recipe_views. Without Redis, views are counted in the database
+// directly and unique viewers are not tracked.
+type AnalyticsService struct {
+	db    *gorm.DB
+	cache *redis.Client
+}
+
+// Ensure AnalyticsService implements IAnalyticsService
+var _ IAnalyticsService = (*AnalyticsService)(nil)
+
+// NewAnalyticsService creates a new AnalyticsService. cache may be nil.
+func NewAnalyticsService(db *gorm.DB, cache *redis.Client) *AnalyticsService {
+	return &AnalyticsService{db: db, cache: cache}
+}
+
+// viewKeys returns the Redis keys of a recipe's view counter and viewer
+// HyperLogLog for a day, and the member marking them for flushing
+func viewKeys(recipeID uuid.UUID, day string) (views, viewers, pending string) {
+	pending = day + ":" + recipeID.String()
+	return "recipe_views:" + pending, "recipe_viewers:" + pending, pending
+}
+
+// RecordView counts a view of a recipe by a user on the current UTC day.
+// Redis errors are logged and fall back to counting in the database.
+func (s *AnalyticsService) RecordView(ctx context.Context, recipeID, viewerID uuid.UUID) error {
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	if s.cache != nil {
+		views, viewers, pending := viewKeys(recipeID, day.Format(analyticsDayLayout))
+		_, err := s.cache.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Incr(ctx, views)
+			pipe.PFAdd(ctx, viewers, viewerID.String())
+			pipe.Expire(ctx, views, viewCounterTTL)
+			pipe.Expire(ctx, viewers, viewCounterTTL)
+			pipe.SAdd(ctx, pendingViewsKey, pending)
+			return nil
+		})
+		if err == nil {
+			return nil
+		}
+		log.Printf("Failed to count recipe view in Redis: %v", err)
+	}
+
+	view := models.RecipeView{RecipeID: recipeID, Day: day, Views: 1}
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "recipe_id"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"views": gorm.Expr("recipe_views.views + 1")}),
+	}).Create(&view).Error; err != nil {
+		return fmt.Errorf("failed to record view: %w", err)
+	}
+	return nil
+}
+
+// FlushViews copies the view counters waiting in Redis to recipe_views and
+// returns how many it wrote. Counters hold a day's running totals, so
+// flushing is safe to repeat, and stored totals are only replaced by larger
+// ones in case Redis lost a counter. Days before now's are final once
+// flushed and stop being tracked.
+func (s *AnalyticsService) FlushViews(ctx context.Context, now time.Time) (int, error) {
+	if s.cache == nil {
+		return 0, nil
+	}
+	pending, err := s.cache.SMembers(ctx, pendingViewsKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read pending views: %w", err)
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	counters, done := pendingViewCounters(pending, now)
+	views := make([]*redis.StringCmd, len(counters))
+	viewers := make([]*redis.IntCmd, len(counters))
+	if _, err := s.cache.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, c := range counters {
+			viewsKey, viewersKey, _ := viewKeys(c.recipeID, c.day.Format(analyticsDayLayout))
+			views[i], viewers[i] = pipe.Get(ctx, viewsKey), pipe.PFCount(ctx, viewersKey)
+		}
+		return nil
+	}); err != nil && !errors.Is(err, redis.Nil) {
+		return 0, fmt.Errorf("failed to read view counters: %w", err)
+	}
+
+	rows := make([]models.RecipeView, 0, len(counters))
+	for i, c := range counters {
+		n, err := views[i].Int64()
+		if err != nil {
+			// The counter expired before it was flushed
+			continue
+		}
+		rows = append(rows, models.RecipeView{RecipeID: c.recipeID, Day: c.day, Views: n, UniqueViewers: viewers[i].Val()})
+	}
+
+	if err := storeViewCounts(s.db.WithContext(ctx), rows); err != nil {
+		return 0, err
+	}
+	if len(done) > 0 {
+		if err := s.cache.SRem(ctx, pendingViewsKey, done...).Err(); err != nil {
+			return 0, fmt.Errorf("failed to clear flushed views: %w", err)
+		}
+	}
+	return len(rows), nil
+}
+
+// viewCounter is a recipe's view counter for a day
+type viewCounter struct {
+	recipeID uuid.UUID
+	day      time.Time
+}
+
+// pendingViewCounters parses the members of the pending views set into the
+// counters to flush, and picks the members to stop tracking once flushed:
+// those of days before now's, whose counts are final, and malformed ones
+func pendingViewCounters(pending []string, now time.Time) (counters []viewCounter, done []interface{}) {
+	today := now.UTC().Truncate(24 * time.Hour)
+	for _, member := range pending {
+		date, id, _ := strings.Cut(member, ":")
+		day, dayErr := time.Parse(analyticsDayLayout, date)
+		recipeID, idErr := uuid.Parse(id)
+		if dayErr != nil || idErr != nil {
+			done = append(done, member)
+			continue
+		}
+		if day.Before(today) {
+			done = append(done, member)
+		}
+		counters = append(counters, viewCounter{recipeID: recipeID, day: day})
+	}
+	return counters, done
+}
+
+// storeViewCounts writes daily view totals, keeping each stored total where
+// it is higher
+func storeViewCounts(db *gorm.DB, rows []models.RecipeView) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "recipe_id"}, {Name: "day"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "views"}, Value: gorm.Expr("CASE WHEN excluded.views > recipe_views.views THEN excluded.views ELSE recipe_views.views END")},
+			{Column: clause.Column{Name: "unique_viewers"}, Value: gorm.Expr("CASE WHEN excluded.unique_viewers > recipe_views.unique_viewers THEN excluded.unique_viewers ELSE recipe_views.unique_viewers END")},
+		},
+	}).CreateInBatches(rows, 500).Error; err != nil {
+		return fmt.Errorf("failed to store view counts: %w", err)
+	}
+	return nil
+}
+
+// analyticsEvent is a favorite, fork or share link of one of the creator's
+// recipes
+type analyticsEvent struct {
+	RecipeID  uuid.UUID
+	CreatedAt time.Time
+}
+
+// CreatorAnalytics returns the analytics of the user's recipes over the last
+// days UTC days, today included. recipeID limits them to one of the user's
+// recipes unless it is uuid.Nil.
+func (s *AnalyticsService) CreatorAnalytics(ctx context.Context, userID, recipeID uuid.UUID, days int, now time.Time) (*CreatorAnalytics, error) {
+	if days <= 0 {
+		days = DefaultAnalyticsDays
+	}
+	if days > MaxAnalyticsDays {
+		days = MaxAnalyticsDays
+	}
+	db := s.db.WithContext(ctx)
+	to := now.UTC().Truncate(24 * time.Hour)
+	from := to.AddDate(0, 0, 1-days)
+
+	var recipes []models.Recipe
+	query := db.Select("id, name").Where("user_id = ?", userID)
+	if recipeID != uuid.Nil {
+		query = query.Where("id = ?", recipeID)
+	}
+	if err := query.Find(&recipes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get recipes: %w", err)
+	}
+	if recipeID != uuid.Nil && len(recipes) == 0 {
+		return nil, ErrRecipeNotFound
+	}
+	owned := db.Model(&models.Recipe{}).Select("id").Where("user_id = ?", userID)
+	if recipeID != uuid.Nil {
+		owned = owned.Where("id = ?", recipeID)
+	}
+
+	counts := make(map[uuid.UUID]map[string]*AnalyticsCounts)
+	at := func(recipeID uuid.UUID, day time.Time) *AnalyticsCounts {
+		byDay := counts[recipeID]
+		if byDay == nil {
+			byDay = make(map[string]*AnalyticsCounts)
+			counts[recipeID] = byDay
+		}
+		date := day.UTC().Format(analyticsDayLayout)
+		if byDay[date] == nil {
+			byDay[date] = &AnalyticsCounts{}
+		}
+		return byDay[date]
+	}
+
+	var views []models.RecipeView
+	if err := db.Where("recipe_id IN (?) AND day >= ?", owned, from).Find(&views).Error; err != nil {
+		return nil, fmt.Errorf("failed to read views: %w", err)
+	}
+	for _, v := range views {
+		c := at(v.RecipeID, v.Day)
+		c.Views += v.Views
+		c.UniqueViewers += v.UniqueViewers
+	}
+
+	events := []struct {
+		name  string
+		query *gorm.DB
+		count func(*AnalyticsCounts)
+	}{
+		{"favorites", db.Model(&models.RecipeFavorite{}).Select("recipe_id, created_at").Where("recipe_id IN (?)", owned),
+			func(c *AnalyticsCounts) { c.Favorites++ }},
+		{"forks", db.Model(&models.Recipe{}).Select("forked_from_id AS recipe_id, created_at").Where("forked_from_id IN (?)", owned),
+			func(c *AnalyticsCounts) { c.Forks++ }},
+		{"shares", db.Model(&models.RecipeShare{}).Select("recipe_id, created_at").Where("recipe_id IN (?)", owned),
+			func(c *AnalyticsCounts) { c.Shares++ }},
+	}
+	for _, e := range events {
+		var rows []analyticsEvent
+		if err := e.query.Where("created_at >= ?", from).Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", e.name, err)
+		}
+		for _, row := range rows {
+			e.count(at(row.RecipeID, row.CreatedAt))
+		}
+	}
+
+	return buildCreatorAnalytics(recipes, counts, from, to), nil
+}
+
+// buildCreatorAnalytics totals per-recipe daily counts into a daily series
+// from from to to and per-recipe totals
+func buildCreatorAnalytics(recipes []models.Recipe, counts map[uuid.UUID]map[string]*AnalyticsCounts, from, to time.Time) *CreatorAnalytics {
+	analytics := &CreatorAnalytics{
+		From:    from.Format(analyticsDayLayout),
+		To:      to.Format(analyticsDayLayout),
+		Series:  []AnalyticsDay{},
+		Recipes: []RecipeAnalytics{},
+	}
+
+	index := make(map[string]int)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(analyticsDayLayout)
+		index[date] = len(analytics.Series)
+		analytics.Series = append(analytics.Series, AnalyticsDay{Date: date})
+	}
+
+	for _, recipe := range recipes {
+		byDay := counts[recipe.ID]
+		if len(byDay) == 0 {
+			continue
+		}
+		total := RecipeAnalytics{RecipeID: recipe.ID, Name: recipe.Name}
+		for date, c := range byDay {
+			i, ok := index[date]
+			if !ok {
+				continue
+			}
+			analytics.Series[i].add(*c)
+			total.add(*c)
+		}
+		analytics.Totals.add(total.AnalyticsCounts)
+		analytics.Recipes = append(analytics.Recipes, total)
+	}
+
+	sort.SliceStable(analytics.Recipes, func(i, j int) bool {
+		a, b := analytics.Recipes[i], analytics.Recipes[j]
+		if a.Views != b.Views {
+			return a.Views > b.Views
+		}
+		return a.Favorites > b.Favorites
+	})
+	return analytics
+}
+
+// ViewFlushWorker flushes view counters from Redis in the background
+type ViewFlushWorker struct {
+	service  *AnalyticsService
+	interval time.Duration
+}
+
+// NewViewFlushWorker creates a worker that flushes view counters every
+// interval
+func NewViewFlushWorker(service *AnalyticsService, interval time.Duration) *ViewFlushWorker {
+	return &ViewFlushWorker{service: service, interval: interval}
+}
+
+// Run flushes view counters until ctx is cancelled, starting with one flush
+// right away. Counters stay in Redis between runs, so nothing is lost when
+// the worker stops.
+func (w *ViewFlushWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.service.FlushViews(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("View flush failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
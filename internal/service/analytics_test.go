@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordViewWithoutRedis(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewAnalyticsService(f.db, nil)
+	ctx := context.Background()
+
+	require.NoError(t, s.RecordView(ctx, f.public, f.viewer))
+	require.NoError(t, s.RecordView(ctx, f.public, f.viewer))
+	var views []models.RecipeView
+	require.NoError(t, f.db.Find(&views).Error)
+	require.Len(t, views, 1)
+	assert.Equal(t, int64(2), views[0].Views)
+	assert.Zero(t, views[0].UniqueViewers)
+
+	n, err := s.FlushViews(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestStoreViewCountsKeepsHigherTotals(t *testing.T) {
+	f := newVisibilityFixture(t)
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+
+	require.NoError(t, storeViewCounts(f.db, []models.RecipeView{{RecipeID: f.public, Day: day, Views: 5, UniqueViewers: 3}}))
+	require.NoError(t, storeViewCounts(f.db, []models.RecipeView{{RecipeID: f.public, Day: day, Views: 8, UniqueViewers: 4}}))
+	// A counter Redis lost and restarted does not lower the stored totals
+	require.NoError(t, storeViewCounts(f.db, []models.RecipeView{{RecipeID: f.public, Day: day, Views: 1, UniqueViewers: 1}}))
+
+	var views []models.RecipeView
+	require.NoError(t, f.db.Find(&views).Error)
+	require.Len(t, views, 1)
+	assert.Equal(t, int64(8), views[0].Views)
+	assert.Equal(t, int64(4), views[0].UniqueViewers)
+
+	// Each total keeps the higher of the two
+	require.NoError(t, storeViewCounts(f.db, []models.RecipeView{
+		{RecipeID: f.public, Day: day, Views: 9, UniqueViewers: 2},
+		{RecipeID: f.own, Day: day, Views: 1, UniqueViewers: 1},
+	}))
+	views = nil
+	require.NoError(t, f.db.Order("views DESC").Find(&views).Error)
+	require.Len(t, views, 2)
+	assert.Equal(t, int64(9), views[0].Views)
+	assert.Equal(t, int64(4), views[0].UniqueViewers)
+}
+
+func TestPendingViewCounters(t *testing.T) {
+	now := time.Date(2024, 3, 5, 18, 0, 0, 0, time.UTC)
+	today, yesterday := uuid.New(), uuid.New()
+	_, _, todayMember := viewKeys(today, "2024-03-05")
+	_, _, yesterdayMember := viewKeys(yesterday, "2024-03-04")
+
+	counters, done := pendingViewCounters([]string{todayMember, yesterdayMember, "2024-03-05:not-a-uuid", "garbage"}, now)
+	assert.Equal(t, []viewCounter{
+		{recipeID: today, day: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{recipeID: yesterday, day: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)},
+	}, counters)
+	// Only past days and malformed members stop being tracked
+	assert.Equal(t, []interface{}{yesterdayMember, "2024-03-05:not-a-uuid", "garbage"}, done)
+
+	// Today's counters become final once the day is over
+	_, done = pendingViewCounters([]string{todayMember}, now.Add(6*time.Hour))
+	assert.Equal(t, []interface{}{todayMember}, done)
+}
+
+func TestCreatorAnalytics(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewAnalyticsService(f.db, nil)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(24 * time.Hour).Add(12 * time.Hour)
+	today, yesterday := now.Truncate(24*time.Hour), now.Truncate(24*time.Hour).AddDate(0, 0, -1)
+	require.NoError(t, f.db.Create([]models.RecipeView{
+		{RecipeID: f.public, Day: today, Views: 5, UniqueViewers: 3},
+		{RecipeID: f.public, Day: yesterday, Views: 2, UniqueViewers: 2},
+		{RecipeID: f.public, Day: today.AddDate(0, 0, -40), Views: 50, UniqueViewers: 50},
+		{RecipeID: f.own, Day: today, Views: 9, UniqueViewers: 9},
+	}).Error)
+	require.NoError(t, f.db.Exec("INSERT INTO recipe_favorites (id, user_id, recipe_id, created_at) VALUES (?, ?, ?, ?)",
+		uuid.New(), f.viewer, f.private, now.Add(-time.Hour)).Error)
+	require.NoError(t, f.db.Exec("UPDATE recipes SET forked_from_id = ?, created_at = ? WHERE id = ?", f.public, now.Add(-time.Hour), f.own).Error)
+	require.NoError(t, f.db.Exec("INSERT INTO recipe_shares (id, created_at, recipe_id, user_id, expires_at) VALUES (?, ?, ?, ?, ?)",
+		uuid.New(), now.Add(-time.Hour), f.unlisted, f.author, now.Add(time.Hour)).Error)
+
+	analytics, err := s.CreatorAnalytics(ctx, f.author, uuid.Nil, 7, now)
+	require.NoError(t, err)
+	assert.Equal(t, today.AddDate(0, 0, -6).Format("2006-01-02"), analytics.From)
+	assert.Equal(t, today.Format("2006-01-02"), analytics.To)
+	assert.Equal(t, AnalyticsCounts{Views: 7, UniqueViewers: 5, Favorites: 1, Forks: 1, Shares: 1}, analytics.Totals)
+
+	require.Len(t, analytics.Series, 7)
+	assert.Equal(t, AnalyticsDay{Date: analytics.To, AnalyticsCounts: AnalyticsCounts{Views: 5, UniqueViewers: 3, Favorites: 1, Forks: 1, Shares: 1}},
+		analytics.Series[6])
+	assert.Equal(t, int64(2), analytics.Series[5].Views)
+	assert.Zero(t, analytics.Series[0].Views)
+
+	require.Len(t, analytics.Recipes, 3)
+	assert.Equal(t, RecipeAnalytics{RecipeID: f.public, Name: "Public soup", AnalyticsCounts: AnalyticsCounts{Views: 7, UniqueViewers: 5, Forks: 1}},
+		analytics.Recipes[0])
+
+	analytics, err = s.CreatorAnalytics(ctx, f.author, f.public, 7, now)
+	require.NoError(t, err)
+	require.Len(t, analytics.Recipes, 1)
+	assert.Equal(t, int64(7), analytics.Totals.Views)
+	assert.Zero(t, analytics.Totals.Favorites)
+
+	// Other users' recipes have no analytics for the author
+	_, err = s.CreatorAnalytics(ctx, f.author, f.own, 7, now)
+	assert.True(t, errors.Is(err, ErrRecipeNotFound))
+}
@@ -0,0 +1,490 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrCollectionNotFound is returned when a collection does not exist or
+	// is not visible to the user
+	ErrCollectionNotFound = errors.New("collection not found")
+	// ErrInvalidCollection is returned for empty names, changes the default
+	// collection does not allow and reorders that do not list every recipe
+	ErrInvalidCollection = errors.New("invalid collection")
+	// ErrCollectionExists is returned when the user already has a collection
+	// with the name
+	ErrCollectionExists = errors.New("you already have a collection with this name")
+)
+
+// CollectionService manages recipe collections. Each user's default
+// collection mirrors their favorites: adding to or removing from one changes
+// the other.
+type CollectionService struct {
+	db *gorm.DB
+}
+
+// Ensure CollectionService implements ICollectionService
+var _ ICollectionService = (*CollectionService)(nil)
+
+// NewCollectionService creates a new CollectionService instance
+func NewCollectionService(db *gorm.DB) *CollectionService {
+	return &CollectionService{db: db}
+}
+
+// ListCollections returns the user's collections with their recipe counts,
+// the default collection first and the rest oldest first
+func (s *CollectionService) ListCollections(ctx context.Context, userID uuid.UUID) ([]*models.Collection, error) {
+	var collections []*models.Collection
+	if err := withRecipeCount(s.db.WithContext(ctx).Model(&models.Collection{}), userID).
+		Where("collections.user_id = ?", userID).
+		Order("collections.is_default DESC").Order("collections.created_at").Order("collections.id").
+		Find(&collections).Error; err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	return collections, nil
+}
+
+// CreateCollection creates a collection for the user
+func (s *CollectionService) CreateCollection(ctx context.Context, userID uuid.UUID, req *types.CreateCollectionRequest) (*models.Collection, error) {
+	collection := &models.Collection{
+		UserID:        userID,
+		Name:          strings.TrimSpace(req.Name),
+		Description:   strings.TrimSpace(req.Description),
+		CoverImageURL: strings.TrimSpace(req.CoverImageURL),
+		Privacy:       req.Privacy,
+	}
+	if collection.Privacy == "" {
+		collection.Privacy = models.CollectionPrivate
+	}
+	if err := s.checkName(ctx, collection); err != nil {
+		return nil, err
+	}
+	if err := setShareToken(collection); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Create(collection).Error; err != nil {
+		return nil, fmt.Errorf("failed to create collection: %w", err)
+	}
+	return collection, nil
+}
+
+// GetCollection returns a collection with the recipes in it the viewer can
+// see, in order. Users see their own collections and public ones; the share
+// token is only shown to the owner.
+func (s *CollectionService) GetCollection(ctx context.Context, viewerID, id uuid.UUID) (*models.Collection, error) {
+	var collection models.Collection
+	if err := withRecipeCount(s.db.WithContext(ctx).Model(&models.Collection{}), viewerID).
+		Where("collections.id = ?", id).
+		First(&collection).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+	if collection.UserID != viewerID {
+		if collection.Privacy != models.CollectionPublic {
+			return nil, ErrCollectionNotFound
+		}
+		collection.ShareToken = nil
+	}
+	return s.withRecipes(ctx, &collection, viewerID)
+}
+
+// GetSharedCollection returns the collection a share link points at, while
+// it is shared by link, with the recipes in it anyone can see
+func (s *CollectionService) GetSharedCollection(ctx context.Context, token string) (*models.Collection, error) {
+	var collection models.Collection
+	if err := withRecipeCount(s.db.WithContext(ctx).Model(&models.Collection{}), uuid.Nil).
+		Where("collections.share_token = ? AND collections.privacy = ?", token, models.CollectionLink).
+		First(&collection).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+	collection.ShareToken = nil
+	return s.withRecipes(ctx, &collection, uuid.Nil)
+}
+
+// UpdateCollection updates one of the user's collections. Sharing by link
+// creates a new share token, and leaving link sharing revokes it. The default
+// collection keeps its name.
+func (s *CollectionService) UpdateCollection(ctx context.Context, userID, id uuid.UUID, req *types.UpdateCollectionRequest) (*models.Collection, error) {
+	collection, err := s.ownCollection(s.db.WithContext(ctx), userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if collection.IsDefault && name != collection.Name {
+			return nil, fmt.Errorf("%w: the %s collection cannot be renamed", ErrInvalidCollection, models.DefaultCollectionName)
+		}
+		collection.Name = name
+		if err := s.checkName(ctx, collection); err != nil {
+			return nil, err
+		}
+	}
+	if req.Description != nil {
+		collection.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.CoverImageURL != nil {
+		collection.CoverImageURL = strings.TrimSpace(*req.CoverImageURL)
+	}
+	if req.Privacy != nil {
+		collection.Privacy = *req.Privacy
+	}
+	if err := setShareToken(collection); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Model(collection).
+		Select("name", "description", "cover_image_url", "privacy", "share_token").
+		Updates(collection).Error; err != nil {
+		return nil, fmt.Errorf("failed to update collection: %w", err)
+	}
+	return collection, nil
+}
+
+// DeleteCollection deletes one of the user's collections, leaving its
+// recipes in place. The default collection cannot be deleted.
+func (s *CollectionService) DeleteCollection(ctx context.Context, userID, id uuid.UUID) error {
+	collection, err := s.ownCollection(s.db.WithContext(ctx), userID, id)
+	if err != nil {
+		return err
+	}
+	if collection.IsDefault {
+		return fmt.Errorf("%w: the %s collection cannot be deleted", ErrInvalidCollection, models.DefaultCollectionName)
+	}
+	if err := s.db.WithContext(ctx).Delete(collection).Error; err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+	return nil
+}
+
+// AddRecipes appends recipes to the end of one of the user's collections in
+// the order given, skipping those already in it, and returns how many were
+// added
+func (s *CollectionService) AddRecipes(ctx context.Context, userID, id uuid.UUID, recipeIDs []uuid.UUID) (int, error) {
+	added := 0
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		collection, err := s.ownCollection(tx.Clauses(clause.Locking{Strength: "UPDATE"}), userID, id)
+		if err != nil {
+			return err
+		}
+
+		ids := uniqueIDs(recipeIDs)
+		var found []uuid.UUID
+		if err := tx.Model(&models.Recipe{}).Scopes(collectionRecipes(collection, userID)).
+			Where("recipes.id IN ?", ids).Pluck("recipes.id", &found).Error; err != nil {
+			return fmt.Errorf("failed to get recipes: %w", err)
+		}
+		if len(found) != len(ids) {
+			return fmt.Errorf("%w: %s", ErrRecipeNotFound, missingIDs(ids, found))
+		}
+
+		addedIDs, err := appendToCollection(tx, collection.ID, ids)
+		if err != nil {
+			return err
+		}
+		added = len(addedIDs)
+		if collection.Privacy == models.CollectionPublic {
+			activities := make([]models.Activity, len(addedIDs))
+			for i, recipeID := range addedIDs {
+				activities[i] = models.Activity{UserID: userID, Type: models.ActivityCollectionRecipeAdded, RecipeID: recipeID, CollectionID: &collection.ID}
+			}
+			if err := recordActivity(tx, activities); err != nil {
+				return err
+			}
+		}
+		if collection.IsDefault {
+			for _, recipeID := range ids {
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+					Create(&models.RecipeFavorite{UserID: userID, RecipeID: recipeID}).Error; err != nil {
+					return fmt.Errorf("failed to add favorite: %w", err)
+				}
+			}
+		}
+		return nil
+	})
+	return added, err
+}
+
+// RemoveRecipes removes recipes from one of the user's collections and
+// returns how many were removed
+func (s *CollectionService) RemoveRecipes(ctx context.Context, userID, id uuid.UUID, recipeIDs []uuid.UUID) (int, error) {
+	removed := 0
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		collection, err := s.ownCollection(tx.Clauses(clause.Locking{Strength: "UPDATE"}), userID, id)
+		if err != nil {
+			return err
+		}
+
+		ids := uniqueIDs(recipeIDs)
+		result := tx.Where("collection_id = ? AND recipe_id IN ?", collection.ID, ids).Delete(&models.CollectionRecipe{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to remove recipes: %w", result.Error)
+		}
+		removed = int(result.RowsAffected)
+
+		if collection.IsDefault {
+			if err := tx.Unscoped().Where("user_id = ? AND recipe_id IN ?", userID, ids).
+				Delete(&models.RecipeFavorite{}).Error; err != nil {
+				return fmt.Errorf("failed to remove favorites: %w", err)
+			}
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// ReorderRecipes sets the order of the recipes in one of the user's
+// collections. recipeIDs must list every recipe in it the user can see
+// exactly once.
+func (s *CollectionService) ReorderRecipes(ctx context.Context, userID, id uuid.UUID, recipeIDs []uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		collection, err := s.ownCollection(tx.Clauses(clause.Locking{Strength: "UPDATE"}), userID, id)
+		if err != nil {
+			return err
+		}
+
+		var current []uuid.UUID
+		if err := tx.Model(&models.CollectionRecipe{}).
+			Joins("JOIN recipes ON recipes.id = collection_recipes.recipe_id AND recipes.deleted_at IS NULL").
+			Where("collection_recipes.collection_id = ?", collection.ID).
+			Scopes(collectionRecipes(collection, userID)).
+			Pluck("collection_recipes.recipe_id", &current).Error; err != nil {
+			return fmt.Errorf("failed to get collection recipes: %w", err)
+		}
+		if !sameIDs(current, recipeIDs) {
+			return fmt.Errorf("%w: recipe_ids must list every recipe in the collection exactly once", ErrInvalidCollection)
+		}
+
+		for i, recipeID := range recipeIDs {
+			if err := tx.Model(&models.CollectionRecipe{}).
+				Where("collection_id = ? AND recipe_id = ?", collection.ID, recipeID).
+				Update("position", i+1).Error; err != nil {
+				return fmt.Errorf("failed to reorder recipes: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ownCollection loads one of the user's collections
+func (s *CollectionService) ownCollection(db *gorm.DB, userID, id uuid.UUID) (*models.Collection, error) {
+	var collection models.Collection
+	if err := db.Where("id = ? AND user_id = ?", id, userID).First(&collection).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+	return &collection, nil
+}
+
+// checkName checks a collection's name is set and not used by another of the
+// user's collections. The default collection's name is reserved for it.
+func (s *CollectionService) checkName(ctx context.Context, collection *models.Collection) error {
+	if collection.Name == "" {
+		return fmt.Errorf("%w: name must not be empty", ErrInvalidCollection)
+	}
+	if !collection.IsDefault && strings.EqualFold(collection.Name, models.DefaultCollectionName) {
+		return ErrCollectionExists
+	}
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.Collection{}).
+		Where("user_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", collection.UserID, collection.Name, collection.ID).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check collection name: %w", err)
+	}
+	if count > 0 {
+		return ErrCollectionExists
+	}
+	return nil
+}
+
+// withRecipes loads the recipes in a collection that the viewer can see, in
+// order, and counts them
+func (s *CollectionService) withRecipes(ctx context.Context, collection *models.Collection, viewerID uuid.UUID) (*models.Collection, error) {
+	collection.Recipes = []*models.Recipe{}
+	if err := s.db.WithContext(ctx).Model(&models.Recipe{}).
+		Joins("JOIN collection_recipes ON collection_recipes.recipe_id = recipes.id AND collection_recipes.collection_id = ?", collection.ID).
+		Scopes(collectionRecipes(collection, viewerID)).
+		Order("collection_recipes.position").Order("recipes.id").
+		Find(&collection.Recipes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get collection recipes: %w", err)
+	}
+	collection.RecipeCount = len(collection.Recipes)
+	return collection, nil
+}
+
+// collectionRecipes limits a recipes query to those in a collection the
+// viewer can see. Owners see every recipe visible to them; anyone else only
+// listed recipes, so that a shared collection does not give away links to
+// unlisted ones.
+func collectionRecipes(collection *models.Collection, viewerID uuid.UUID) func(*gorm.DB) *gorm.DB {
+	if collection.UserID == viewerID {
+		return VisibleRecipes(viewerID)
+	}
+	return ListedRecipes(viewerID)
+}
+
+// withRecipeCount selects collections with the number of recipes in them
+// the viewer can see, as collectionRecipes limits them
+func withRecipeCount(db *gorm.DB, viewerID uuid.UUID) *gorm.DB {
+	return db.Select("collections.*, (SELECT COUNT(*) FROM collection_recipes JOIN recipes ON recipes.id = collection_recipes.recipe_id AND recipes.deleted_at IS NULL "+
+		"WHERE collection_recipes.collection_id = collections.id AND (recipes.visibility = ? OR recipes.user_id = ? OR "+
+		"(recipes.visibility = ? AND collections.user_id = ?))) AS recipe_count",
+		models.RecipePublic, viewerID, models.RecipeUnlisted, viewerID)
+}
+
+// setShareToken gives a collection shared by link a share token if it has
+// none, and takes it away from collections that are not
+func setShareToken(collection *models.Collection) error {
+	if collection.Privacy != models.CollectionLink {
+		collection.ShareToken = nil
+		return nil
+	}
+	if collection.ShareToken != nil {
+		return nil
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("failed to generate share token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+	collection.ShareToken = &token
+	return nil
+}
+
+// appendToCollection adds recipes after the last one in a collection,
+// skipping those already in it, and returns the ones it added
+func appendToCollection(tx *gorm.DB, collectionID uuid.UUID, recipeIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var existing []uuid.UUID
+	if err := tx.Model(&models.CollectionRecipe{}).
+		Where("collection_id = ? AND recipe_id IN ?", collectionID, recipeIDs).
+		Pluck("recipe_id", &existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to get collection recipes: %w", err)
+	}
+	present := make(map[uuid.UUID]bool, len(existing))
+	for _, id := range existing {
+		present[id] = true
+	}
+
+	var last int
+	if err := tx.Model(&models.CollectionRecipe{}).Where("collection_id = ?", collectionID).
+		Select("COALESCE(MAX(position), 0)").Scan(&last).Error; err != nil {
+		return nil, fmt.Errorf("failed to get collection recipes: %w", err)
+	}
+
+	var rows []models.CollectionRecipe
+	var added []uuid.UUID
+	for _, id := range recipeIDs {
+		if !present[id] {
+			last++
+			rows = append(rows, models.CollectionRecipe{CollectionID: collectionID, RecipeID: id, Position: last})
+			added = append(added, id)
+		}
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to add recipes: %w", err)
+	}
+	return added, nil
+}
+
+// defaultCollection returns the user's default collection, creating it if
+// needed
+func defaultCollection(tx *gorm.DB, userID uuid.UUID) (*models.Collection, error) {
+	collection := &models.Collection{UserID: userID, Name: models.DefaultCollectionName, Privacy: models.CollectionPrivate, IsDefault: true}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(collection).Error; err != nil {
+		return nil, fmt.Errorf("failed to create %s collection: %w", models.DefaultCollectionName, err)
+	}
+	if err := tx.Where("user_id = ? AND is_default", userID).First(collection).Error; err != nil {
+		return nil, fmt.Errorf("failed to get %s collection: %w", models.DefaultCollectionName, err)
+	}
+	return collection, nil
+}
+
+// addToDefaultCollection adds a newly favorited recipe to the user's default
+// collection
+func addToDefaultCollection(tx *gorm.DB, userID, recipeID uuid.UUID) error {
+	collection, err := defaultCollection(tx, userID)
+	if err != nil {
+		return err
+	}
+	_, err = appendToCollection(tx, collection.ID, []uuid.UUID{recipeID})
+	return err
+}
+
+// removeFromDefaultCollection removes an unfavorited recipe from the user's
+// default collection
+func removeFromDefaultCollection(tx *gorm.DB, userID, recipeID uuid.UUID) error {
+	if err := tx.Where("recipe_id = ? AND collection_id IN (?)", recipeID,
+		tx.Model(&models.Collection{}).Select("id").Where("user_id = ? AND is_default", userID)).
+		Delete(&models.CollectionRecipe{}).Error; err != nil {
+		return fmt.Errorf("failed to remove recipe from %s collection: %w", models.DefaultCollectionName, err)
+	}
+	return nil
+}
+
+// uniqueIDs drops repeated IDs, keeping the first of each
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// missingIDs lists the wanted IDs that were not found
+func missingIDs(wanted, found []uuid.UUID) string {
+	present := make(map[uuid.UUID]bool, len(found))
+	for _, id := range found {
+		present[id] = true
+	}
+	var missing []string
+	for _, id := range wanted {
+		if !present[id] {
+			missing = append(missing, id.String())
+		}
+	}
+	return strings.Join(missing, ", ")
+}
+
+// sameIDs reports whether ids lists each of want exactly once
+func sameIDs(want, ids []uuid.UUID) bool {
+	if len(want) != len(ids) {
+		return false
+	}
+	remaining := make(map[uuid.UUID]bool, len(want))
+	for _, id := range want {
+		remaining[id] = true
+	}
+	for _, id := range ids {
+		if !remaining[id] {
+			return false
+		}
+		delete(remaining, id)
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+)
+
+// FormatCollectionMarkdown renders a collection as Markdown, with a section
+// per recipe in the collection's order
+func FormatCollectionMarkdown(collection *models.Collection) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", collection.Name)
+	if collection.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", collection.Description)
+	}
+
+	for _, recipe := range collection.Recipes {
+		fmt.Fprintf(&b, "\n## %s\n", recipe.Name)
+		if recipe.Description != "" {
+			fmt.Fprintf(&b, "\n%s\n", recipe.Description)
+		}
+		if details := recipeDetails(recipe); details != "" {
+			fmt.Fprintf(&b, "\n%s\n", details)
+		}
+
+		if len(recipe.Ingredients) > 0 {
+			b.WriteString("\n### Ingredients\n\n")
+			for _, ingredient := range recipe.Ingredients {
+				fmt.Fprintf(&b, "- %s\n", ingredient)
+			}
+		}
+		if len(recipe.Instructions) > 0 {
+			b.WriteString("\n### Instructions\n\n")
+			for i, step := range recipe.Instructions {
+				fmt.Fprintf(&b, "%d. %s\n", i+1, step)
+			}
+		}
+	}
+	return b.String()
+}
+
+// recipeDetails summarises a recipe's servings and times on one line
+func recipeDetails(recipe *models.Recipe) string {
+	var details []string
+	if recipe.Servings > 0 {
+		details = append(details, fmt.Sprintf("Serves %d", recipe.Servings))
+	}
+	if recipe.PrepTime > 0 {
+		details = append(details, fmt.Sprintf("Prep %d min", recipe.PrepTime))
+	}
+	if recipe.CookTime > 0 {
+		details = append(details, fmt.Sprintf("Cook %d min", recipe.CookTime))
+	}
+	return strings.Join(details, " · ")
+}
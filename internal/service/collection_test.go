@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetShareToken(t *testing.T) {
+	collection := &models.Collection{Privacy: models.CollectionLink}
+	require.NoError(t, setShareToken(collection))
+	require.NotNil(t, collection.ShareToken)
+	assert.Len(t, *collection.ShareToken, 64)
+
+	// An existing token is kept while the collection stays shared by link
+	token := *collection.ShareToken
+	require.NoError(t, setShareToken(collection))
+	assert.Equal(t, token, *collection.ShareToken)
+
+	collection.Privacy = models.CollectionPublic
+	require.NoError(t, setShareToken(collection))
+	assert.Nil(t, collection.ShareToken)
+
+	// Sharing by link again gives a new token, so old links stay revoked
+	collection.Privacy = models.CollectionLink
+	require.NoError(t, setShareToken(collection))
+	require.NotNil(t, collection.ShareToken)
+	assert.NotEqual(t, token, *collection.ShareToken)
+}
+
+func TestCollectionIDHelpers(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+
+	assert.Equal(t, []uuid.UUID{a, b, c}, uniqueIDs([]uuid.UUID{a, b, a, c, b}))
+	assert.Equal(t, c.String(), missingIDs([]uuid.UUID{a, b, c}, []uuid.UUID{b, a}))
+
+	assert.True(t, sameIDs([]uuid.UUID{a, b, c}, []uuid.UUID{c, a, b}))
+	assert.False(t, sameIDs([]uuid.UUID{a, b, c}, []uuid.UUID{a, b}))
+	assert.False(t, sameIDs([]uuid.UUID{a, b, c}, []uuid.UUID{a, b, b}))
+	assert.False(t, sameIDs([]uuid.UUID{a, b}, []uuid.UUID{a, c}))
+}
+
+func TestFormatCollectionMarkdown(t *testing.T) {
+	collection := &models.Collection{
+		Name:        "Weeknight dinners",
+		Description: "Quick and easy.",
+		Recipes: []*models.Recipe{
+			{
+				Name:         "Tomato Soup",
+				Servings:     2,
+				CookTime:     20,
+				Ingredients:  models.JSONBStringArray{"400 g tomatoes", "1 onion"},
+				Instructions: models.JSONBStringArray{"Fry the onion.", "Add the tomatoes and simmer."},
+			},
+			{Name: "Toast"},
+		},
+	}
+
+	expected := "# Weeknight dinners\n\nQuick and easy.\n" +
+		"\n## Tomato Soup\n\nServes 2 · Cook 20 min\n" +
+		"\n### Ingredients\n\n- 400 g tomatoes\n- 1 onion\n" +
+		"\n### Instructions\n\n1. Fry the onion.\n2. Add the tomatoes and simmer.\n" +
+		"\n## Toast\n"
+	assert.Equal(t, expected, FormatCollectionMarkdown(collection))
+}
+
+// favoriteIDs returns the recipes a user has favorited
+func favoriteIDs(t *testing.T, f *visibilityFixture, userID uuid.UUID) []uuid.UUID {
+	var ids []uuid.UUID
+	require.NoError(t, f.db.Model(&models.RecipeFavorite{}).Where("user_id = ?", userID).
+		Order("recipe_id").Pluck("recipe_id", &ids).Error)
+	return ids
+}
+
+func TestFavoritesFollowDefaultCollection(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewCollectionService(f.db)
+	recipes := NewRecipeService(f.db, nil)
+	ctx := context.Background()
+
+	// Favoriting creates the default collection and adds the recipe to it
+	require.NoError(t, recipes.FavoriteRecipe(ctx, f.viewer, f.public))
+	collections, err := s.ListCollections(ctx, f.viewer)
+	require.NoError(t, err)
+	require.Len(t, collections, 1)
+	favorites := collections[0]
+	assert.True(t, favorites.IsDefault)
+	assert.Equal(t, models.DefaultCollectionName, favorites.Name)
+	assert.Equal(t, 1, favorites.RecipeCount)
+
+	// Adding to the default collection favorites the recipe
+	added, err := s.AddRecipes(ctx, f.viewer, favorites.ID, []uuid.UUID{f.public, f.unlisted})
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+	assert.ElementsMatch(t, []uuid.UUID{f.public, f.unlisted}, favoriteIDs(t, f, f.viewer))
+
+	// Removing from it unfavorites the recipe, and unfavoriting removes it
+	removed, err := s.RemoveRecipes(ctx, f.viewer, favorites.ID, []uuid.UUID{f.public})
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, []uuid.UUID{f.unlisted}, favoriteIDs(t, f, f.viewer))
+	require.NoError(t, recipes.UnfavoriteRecipe(ctx, f.viewer, f.unlisted))
+	got, err := s.GetCollection(ctx, f.viewer, favorites.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Recipes)
+	assert.Zero(t, got.RecipeCount)
+
+	// The default collection keeps its name and cannot be deleted
+	name := "Renamed"
+	_, err = s.UpdateCollection(ctx, f.viewer, favorites.ID, &types.UpdateCollectionRequest{Name: &name})
+	assert.True(t, errors.Is(err, ErrInvalidCollection))
+	assert.True(t, errors.Is(s.DeleteCollection(ctx, f.viewer, favorites.ID), ErrInvalidCollection))
+	_, err = s.CreateCollection(ctx, f.viewer, &types.CreateCollectionRequest{Name: "favorites"})
+	assert.True(t, errors.Is(err, ErrCollectionExists))
+}
+
+func TestCollectionsHiddenFromOtherUsers(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewCollectionService(f.db)
+	ctx := context.Background()
+
+	ids := map[string]uuid.UUID{}
+	for _, privacy := range []string{models.CollectionPrivate, models.CollectionLink, models.CollectionPublic} {
+		collection, err := s.CreateCollection(ctx, f.author, &types.CreateCollectionRequest{Name: privacy, Privacy: privacy})
+		require.NoError(t, err)
+		_, err = s.AddRecipes(ctx, f.author, collection.ID, []uuid.UUID{f.private, f.unlisted, f.public})
+		require.NoError(t, err)
+		ids[privacy] = collection.ID
+	}
+
+	// Other users find neither private nor link collections by ID
+	for _, privacy := range []string{models.CollectionPrivate, models.CollectionLink} {
+		_, err := s.GetCollection(ctx, f.viewer, ids[privacy])
+		assert.True(t, errors.Is(err, ErrCollectionNotFound), privacy)
+	}
+
+	// The owner sees every recipe; others see only listed ones and no token
+	own, err := s.GetCollection(ctx, f.author, ids[models.CollectionPublic])
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.private, f.unlisted, f.public}, recipeIDs(own.Recipes))
+	public, err := s.GetCollection(ctx, f.viewer, ids[models.CollectionPublic])
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.public}, recipeIDs(public.Recipes))
+	assert.Equal(t, 1, public.RecipeCount)
+
+	collections, err := s.ListCollections(ctx, f.author)
+	require.NoError(t, err)
+	require.Len(t, collections, 3)
+	for _, c := range collections {
+		assert.Equal(t, 3, c.RecipeCount, c.Name)
+	}
+}
+
+func TestShareTokenRotationAndRevoke(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewCollectionService(f.db)
+	ctx := context.Background()
+
+	collection, err := s.CreateCollection(ctx, f.author, &types.CreateCollectionRequest{Name: "Soups", Privacy: models.CollectionLink})
+	require.NoError(t, err)
+	require.NotNil(t, collection.ShareToken)
+	_, err = s.AddRecipes(ctx, f.author, collection.ID, []uuid.UUID{f.unlisted, f.public})
+	require.NoError(t, err)
+	token := *collection.ShareToken
+
+	shared, err := s.GetSharedCollection(ctx, token)
+	require.NoError(t, err)
+	assert.Nil(t, shared.ShareToken)
+	assert.Equal(t, []uuid.UUID{f.public}, recipeIDs(shared.Recipes))
+	assert.Equal(t, 1, shared.RecipeCount)
+
+	// Leaving link sharing revokes the token
+	privacy := models.CollectionPrivate
+	collection, err = s.UpdateCollection(ctx, f.author, collection.ID, &types.UpdateCollectionRequest{Privacy: &privacy})
+	require.NoError(t, err)
+	assert.Nil(t, collection.ShareToken)
+	_, err = s.GetSharedCollection(ctx, token)
+	assert.True(t, errors.Is(err, ErrCollectionNotFound))
+
+	// Sharing again gives a new token; the old link stays dead
+	privacy = models.CollectionLink
+	collection, err = s.UpdateCollection(ctx, f.author, collection.ID, &types.UpdateCollectionRequest{Privacy: &privacy})
+	require.NoError(t, err)
+	require.NotNil(t, collection.ShareToken)
+	assert.NotEqual(t, token, *collection.ShareToken)
+	_, err = s.GetSharedCollection(ctx, token)
+	assert.True(t, errors.Is(err, ErrCollectionNotFound))
+	_, err = s.GetSharedCollection(ctx, *collection.ShareToken)
+	assert.NoError(t, err)
+}
+
+func TestOnlyOwnersChangeCollectionRecipes(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewCollectionService(f.db)
+	ctx := context.Background()
+
+	collection, err := s.CreateCollection(ctx, f.author, &types.CreateCollectionRequest{Name: "Soups", Privacy: models.CollectionPublic})
+	require.NoError(t, err)
+
+	// Repeated and already added recipes are skipped
+	added, err := s.AddRecipes(ctx, f.author, collection.ID, []uuid.UUID{f.public, f.unlisted, f.public})
+	require.NoError(t, err)
+	assert.Equal(t, 2, added)
+	added, err = s.AddRecipes(ctx, f.author, collection.ID, []uuid.UUID{f.unlisted, f.private})
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+
+	// Recipes the owner cannot see are not found, and nothing is added
+	_, err = s.AddRecipes(ctx, f.author, collection.ID, []uuid.UUID{f.own})
+	assert.True(t, errors.Is(err, ErrRecipeNotFound))
+
+	// Other users cannot change the collection
+	_, err = s.AddRecipes(ctx, f.viewer, collection.ID, []uuid.UUID{f.own})
+	assert.True(t, errors.Is(err, ErrCollectionNotFound))
+	_, err = s.RemoveRecipes(ctx, f.viewer, collection.ID, []uuid.UUID{f.public})
+	assert.True(t, errors.Is(err, ErrCollectionNotFound))
+	err = s.ReorderRecipes(ctx, f.viewer, collection.ID, []uuid.UUID{f.private, f.unlisted, f.public})
+	assert.True(t, errors.Is(err, ErrCollectionNotFound))
+	assert.True(t, errors.Is(s.DeleteCollection(ctx, f.viewer, collection.ID), ErrCollectionNotFound))
+
+	require.NoError(t, s.ReorderRecipes(ctx, f.author, collection.ID, []uuid.UUID{f.private, f.unlisted, f.public}))
+	removed, err := s.RemoveRecipes(ctx, f.author, collection.ID, []uuid.UUID{f.unlisted, f.own})
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	got, err := s.GetCollection(ctx, f.author, collection.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.private, f.public}, recipeIDs(got.Recipes))
+
+	// Public additions show up in the owner's activity
+	var activities int64
+	require.NoError(t, f.db.Model(&models.Activity{}).Where("user_id = ?", f.author).Count(&activities).Error)
+	assert.Equal(t, int64(3), activities)
+}
@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxMentionsPerComment caps the users one comment can notify by mention
+const maxMentionsPerComment = 10
+
+var (
+	// ErrCommentNotFound is returned when a comment does not exist, or was
+	// deleted where a live comment is needed
+	ErrCommentNotFound = errors.New("comment not found")
+	// ErrInvalidComment is returned for empty comments and replies to
+	// comments on another recipe
+	ErrInvalidComment = errors.New("invalid comment")
+	// ErrCommentForbidden is returned when a user edits another user's
+	// comment, or deletes it without being an admin
+	ErrCommentForbidden = errors.New("you cannot change this comment")
+)
+
+// mentionPattern matches @username mentions not preceded by a word
+// character, so that email addresses are not taken for mentions
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@(\w{1,50})`)
+
+// CommentService manages threaded recipe comments, their edit history and
+// the notifications they send
+type CommentService struct {
+	db *gorm.DB
+}
+
+// Ensure CommentService implements ICommentService
+var _ ICommentService = (*CommentService)(nil)
+
+// NewCommentService creates a new CommentService instance
+func NewCommentService(db *gorm.DB) *CommentService {
+	return &CommentService{db: db}
+}
+
+// CreateComment comments on a recipe, or replies to a comment when
+// req.ParentID is set. The recipe's author is told about top-level comments,
+// the parent's author about replies, and mentioned users about mentions.
+func (s *CommentService) CreateComment(ctx context.Context, userID, recipeID uuid.UUID, req *types.CreateCommentRequest) (*models.RecipeComment, error) {
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, fmt.Errorf("%w: comment must not be empty", ErrInvalidComment)
+	}
+	comment := &models.RecipeComment{RecipeID: recipeID, UserID: userID, Body: body}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		recipe, err := viewableRecipe(tx, recipeID, userID)
+		if err != nil {
+			return err
+		}
+
+		// Reply notifications go to the parent's author, comment
+		// notifications to the recipe's
+		notification := models.Notification{UserID: recipe.UserID, Type: models.NotificationComment}
+		if req.ParentID != nil {
+			var parent models.RecipeComment
+			if err := tx.Where("id = ? AND deleted_at IS NULL", *req.ParentID).First(&parent).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrCommentNotFound
+				}
+				return fmt.Errorf("failed to get parent comment: %w", err)
+			}
+			if parent.RecipeID != recipeID {
+				return fmt.Errorf("%w: parent comment is on another recipe", ErrInvalidComment)
+			}
+			comment.ParentID = &parent.ID
+			comment.RootID = parent.RootID
+			if comment.RootID == nil {
+				comment.RootID = &parent.ID
+			}
+			notification = models.Notification{UserID: parent.UserID, Type: models.NotificationReply}
+		}
+
+		if err := tx.Create(comment).Error; err != nil {
+			return fmt.Errorf("failed to create comment: %w", err)
+		}
+
+		mentioned, err := mentionedUsers(tx, recipe, body)
+		if err != nil {
+			return err
+		}
+		notified := map[uuid.UUID]bool{notification.UserID: true}
+		notifications := []models.Notification{notification}
+		for _, id := range mentioned {
+			if !notified[id] {
+				notified[id] = true
+				notifications = append(notifications, models.Notification{UserID: id, Type: models.NotificationMention})
+			}
+		}
+		return notify(tx, commentNotifications(comment, notifications))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// UpdateComment edits one of the user's comments, keeping the previous body
+// in its history. Users mentioned for the first time are notified.
+func (s *CommentService) UpdateComment(ctx context.Context, userID, commentID uuid.UUID, req *types.UpdateCommentRequest) (*models.RecipeComment, error) {
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, fmt.Errorf("%w: comment must not be empty", ErrInvalidComment)
+	}
+
+	var comment models.RecipeComment
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockComment(tx, commentID, &comment); err != nil {
+			return err
+		}
+		if comment.UserID != userID {
+			return ErrCommentForbidden
+		}
+		recipe, err := viewableRecipe(tx, comment.RecipeID, userID)
+		if err != nil {
+			return err
+		}
+		if body == comment.Body {
+			return nil
+		}
+
+		if err := tx.Create(&models.RecipeCommentEdit{CommentID: comment.ID, Body: comment.Body}).Error; err != nil {
+			return fmt.Errorf("failed to save comment history: %w", err)
+		}
+		before, err := mentionedUsers(tx, recipe, comment.Body)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		comment.Body = body
+		comment.EditedAt = &now
+		if err := tx.Model(&comment).Select("body", "edited_at").Updates(&comment).Error; err != nil {
+			return fmt.Errorf("failed to update comment: %w", err)
+		}
+
+		after, err := mentionedUsers(tx, recipe, body)
+		if err != nil {
+			return err
+		}
+		already := make(map[uuid.UUID]bool, len(before))
+		for _, id := range before {
+			already[id] = true
+		}
+		var notifications []models.Notification
+		for _, id := range after {
+			if !already[id] {
+				notifications = append(notifications, models.Notification{UserID: id, Type: models.NotificationMention})
+			}
+		}
+		return notify(tx, commentNotifications(&comment, notifications))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// DeleteComment soft-deletes a comment, dropping its body and history.
+// Authors delete their own comments; moderators can delete any.
+func (s *CommentService) DeleteComment(ctx context.Context, userID, commentID uuid.UUID, moderator bool) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var comment models.RecipeComment
+		if err := lockComment(tx, commentID, &comment); err != nil {
+			return err
+		}
+		if comment.UserID != userID && !moderator {
+			return ErrCommentForbidden
+		}
+
+		now := time.Now()
+		if err := tx.Model(&comment).Updates(map[string]interface{}{
+			"body":       "",
+			"deleted_at": now,
+			"deleted_by": userID,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to delete comment: %w", err)
+		}
+		if err := tx.Where("comment_id = ?", comment.ID).Delete(&models.RecipeCommentEdit{}).Error; err != nil {
+			return fmt.Errorf("failed to delete comment history: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListComments returns one page of a recipe's threads, newest first, each
+// top-level comment holding its replies as a tree in the order they were
+// written. Deleted comments only appear where they still have replies.
+func (s *CommentService) ListComments(ctx context.Context, userID, recipeID uuid.UUID, page pagination.Page) ([]*models.RecipeComment, *pagination.Info, error) {
+	if _, err := viewableRecipe(s.db.WithContext(ctx), recipeID, userID); err != nil {
+		return nil, nil, err
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.RecipeComment{}).
+		Where("recipe_comments.recipe_id = ? AND recipe_comments.root_id IS NULL", recipeID).
+		Where("recipe_comments.deleted_at IS NULL OR EXISTS (SELECT 1 FROM recipe_comments replies WHERE replies.root_id = recipe_comments.id AND replies.deleted_at IS NULL)")
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to count comments: %w", err)
+	}
+
+	var threads []*models.RecipeComment
+	if err := withCommentAuthor(pagination.Keyset(query, page, "recipe_comments.created_at", "recipe_comments.id")).
+		Find(&threads).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+	threads, info := pagination.Finish(threads, page, commentCursor)
+	info.Total = &total
+
+	if len(threads) > 0 {
+		rootIDs := make([]uuid.UUID, len(threads))
+		for i, t := range threads {
+			rootIDs[i] = t.ID
+		}
+		var replies []*models.RecipeComment
+		if err := withCommentAuthor(s.db.WithContext(ctx).Model(&models.RecipeComment{})).
+			Where("recipe_comments.root_id IN ?", rootIDs).
+			Order("recipe_comments.created_at").Order("recipe_comments.id").
+			Find(&replies).Error; err != nil {
+			return nil, nil, fmt.Errorf("failed to list replies: %w", err)
+		}
+		buildCommentThreads(threads, replies)
+	}
+	return threads, info, nil
+}
+
+// CommentHistory returns the earlier versions of a comment, oldest first
+func (s *CommentService) CommentHistory(ctx context.Context, userID, commentID uuid.UUID) ([]models.RecipeCommentEdit, error) {
+	var comment models.RecipeComment
+	if err := s.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", commentID).First(&comment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+	if _, err := viewableRecipe(s.db.WithContext(ctx), comment.RecipeID, userID); err != nil {
+		return nil, err
+	}
+
+	edits := []models.RecipeCommentEdit{}
+	if err := s.db.WithContext(ctx).Where("comment_id = ?", commentID).
+		Order("created_at").Order("id").
+		Find(&edits).Error; err != nil {
+		return nil, fmt.Errorf("failed to get comment history: %w", err)
+	}
+	return edits, nil
+}
+
+// viewableRecipe loads a recipe the user is allowed to see. Recipes they
+// cannot see are not found.
+func viewableRecipe(db *gorm.DB, recipeID, userID uuid.UUID) (*models.Recipe, error) {
+	var recipe models.Recipe
+	if err := db.Scopes(VisibleRecipes(userID)).Select("recipes.id", "recipes.user_id", "recipes.visibility").
+		First(&recipe, "recipes.id = ?", recipeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecipeNotFound
+		}
+		return nil, fmt.Errorf("failed to get recipe: %w", err)
+	}
+	return &recipe, nil
+}
+
+// lockComment loads a live comment and locks it until the transaction ends
+func lockComment(tx *gorm.DB, commentID uuid.UUID, comment *models.RecipeComment) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND deleted_at IS NULL", commentID).
+		First(comment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCommentNotFound
+		}
+		return fmt.Errorf("failed to get comment: %w", err)
+	}
+	return nil
+}
+
+// withCommentAuthor selects comments with their author's username
+func withCommentAuthor(db *gorm.DB) *gorm.DB {
+	return db.Select("recipe_comments.*, user_profiles.username").
+		Joins("LEFT JOIN user_profiles ON user_profiles.user_id = recipe_comments.user_id AND user_profiles.deleted_at IS NULL")
+}
+
+// buildCommentThreads nests replies, given oldest first, under their parents
+// in threads. Deleted comments are dropped unless a live reply hangs below
+// them.
+func buildCommentThreads(threads, replies []*models.RecipeComment) {
+	byID := make(map[uuid.UUID]*models.RecipeComment, len(threads)+len(replies))
+	for _, t := range threads {
+		byID[t.ID] = t
+	}
+	for _, r := range replies {
+		byID[r.ID] = r
+	}
+	for _, r := range replies {
+		if r.ParentID == nil {
+			continue
+		}
+		if parent, ok := byID[*r.ParentID]; ok {
+			parent.Replies = append(parent.Replies, r)
+		}
+	}
+	for _, t := range threads {
+		pruneDeletedReplies(t)
+	}
+}
+
+// pruneDeletedReplies drops deleted replies with no live replies below them,
+// reporting whether anything live remains at or below the comment
+func pruneDeletedReplies(comment *models.RecipeComment) bool {
+	kept := comment.Replies[:0]
+	for _, r := range comment.Replies {
+		if pruneDeletedReplies(r) {
+			kept = append(kept, r)
+		}
+	}
+	comment.Replies = kept
+	if len(comment.Replies) == 0 {
+		comment.Replies = nil
+	}
+	return comment.DeletedAt == nil || len(comment.Replies) > 0
+}
+
+// parseMentions returns the distinct usernames @mentioned in a comment,
+// lowercased, in the order they first appear, up to maxMentionsPerComment
+func parseMentions(body string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		name := strings.ToLower(m[1])
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+		if len(names) == maxMentionsPerComment {
+			break
+		}
+	}
+	return names
+}
+
+// mentionedUsers resolves the @mentions in a comment on recipe to user IDs,
+// ignoring names that match no user and users who cannot see the recipe
+func mentionedUsers(tx *gorm.DB, recipe *models.Recipe, body string) ([]uuid.UUID, error) {
+	names := parseMentions(body)
+	if len(names) == 0 {
+		return nil, nil
+	}
+	var ids []uuid.UUID
+	if err := tx.Model(&models.UserProfile{}).
+		Where("LOWER(username) IN ?", names).
+		Pluck("user_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to resolve mentions: %w", err)
+	}
+	visible := ids[:0]
+	for _, id := range ids {
+		if CanViewRecipe(recipe, id) {
+			visible = append(visible, id)
+		}
+	}
+	return visible, nil
+}
+
+// commentNotifications fills in the actor, recipe and comment of
+// notifications about a comment
+func commentNotifications(comment *models.RecipeComment, notifications []models.Notification) []models.Notification {
+	for i := range notifications {
+		notifications[i].ActorID = &comment.UserID
+		notifications[i].RecipeID = &comment.RecipeID
+		notifications[i].CommentID = &comment.ID
+	}
+	return notifications
+}
+
+// commentCursor returns the keyset position of a comment
+func commentCursor(c *models.RecipeComment) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: c.CreatedAt, ID: c.ID}
+}
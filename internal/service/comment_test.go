@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMentions(t *testing.T) {
+	assert.Nil(t, parseMentions("no mentions here"))
+	assert.Equal(t, []string{"alice", "bob_2"}, parseMentions("@Alice try this, and @bob_2 too. Thanks @alice!"))
+	// Email addresses and doubled @ are not mentions
+	assert.Nil(t, parseMentions("mail chef@example.com or @@nobody"))
+	assert.Equal(t, []string{"carol"}, parseMentions("(@carol)"))
+
+	many := ""
+	for i := 0; i < maxMentionsPerComment+5; i++ {
+		many += " @user" + string(rune('a'+i))
+	}
+	assert.Len(t, parseMentions(many), maxMentionsPerComment)
+}
+
+func TestBuildCommentThreads(t *testing.T) {
+	deleted := time.Now()
+	comment := func(parent *models.RecipeComment, deletedAt *time.Time) *models.RecipeComment {
+		c := &models.RecipeComment{ID: uuid.New(), DeletedAt: deletedAt}
+		if parent != nil {
+			c.ParentID = &parent.ID
+		}
+		return c
+	}
+
+	root := comment(nil, nil)
+	reply := comment(root, nil)
+	nested := comment(reply, nil)
+	deletedLeaf := comment(root, &deleted)
+	deletedWithReply := comment(root, &deleted)
+	liveBelowDeleted := comment(deletedWithReply, nil)
+
+	buildCommentThreads([]*models.RecipeComment{root},
+		[]*models.RecipeComment{reply, nested, deletedLeaf, deletedWithReply, liveBelowDeleted})
+
+	require.Len(t, root.Replies, 2)
+	assert.Equal(t, reply.ID, root.Replies[0].ID)
+	assert.Equal(t, []*models.RecipeComment{nested}, reply.Replies)
+	// A deleted comment stays only to hold its live replies
+	assert.Equal(t, deletedWithReply.ID, root.Replies[1].ID)
+	assert.Equal(t, []*models.RecipeComment{liveBelowDeleted}, deletedWithReply.Replies)
+}
+
+// commentNotificationsFor returns the types of the notifications sent to a user
+func commentNotificationsFor(t *testing.T, f *visibilityFixture, userID uuid.UUID) []string {
+	var kinds []string
+	require.NoError(t, f.db.Model(&models.Notification{}).Where("user_id = ?", userID).
+		Order("created_at").Pluck("type", &kinds).Error)
+	return kinds
+}
+
+func TestCommentMentionsNotifyUsersWhoCanSeeTheRecipe(t *testing.T) {
+	f, hermit := newSocialFixture(t)
+	s := NewCommentService(f.db)
+	ctx := context.Background()
+
+	// Mentions on a public recipe reach everyone named, once, but not the
+	// commenter or names that match no user
+	comment, err := s.CreateComment(ctx, f.author, f.public, &types.CreateCommentRequest{Body: "@viewer @HERMIT @viewer @nobody @Author"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.NotificationMention}, commentNotificationsFor(t, f, f.viewer))
+	assert.Equal(t, []string{models.NotificationMention}, commentNotificationsFor(t, f, hermit))
+	assert.Empty(t, commentNotificationsFor(t, f, f.author))
+
+	// The parent's author hears about replies once, even when mentioned
+	_, err = s.CreateComment(ctx, f.viewer, f.public, &types.CreateCommentRequest{Body: "thanks @author", ParentID: &comment.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.NotificationReply}, commentNotificationsFor(t, f, f.author))
+
+	// Users who cannot see a private recipe are not told about it
+	_, err = s.CreateComment(ctx, f.author, f.private, &types.CreateCommentRequest{Body: "note to @viewer and @hermit"})
+	require.NoError(t, err)
+	assert.Len(t, commentNotificationsFor(t, f, f.viewer), 1)
+	assert.Len(t, commentNotificationsFor(t, f, hermit), 1)
+
+	// Edits only notify users mentioned for the first time
+	_, err = s.UpdateComment(ctx, f.author, comment.ID, &types.UpdateCommentRequest{Body: "@viewer @hermit and @viewer again"})
+	require.NoError(t, err)
+	assert.Len(t, commentNotificationsFor(t, f, f.viewer), 1)
+	assert.Len(t, commentNotificationsFor(t, f, hermit), 1)
+}
+
+func TestOnlyAuthorsEditComments(t *testing.T) {
+	f, _ := newSocialFixture(t)
+	s := NewCommentService(f.db)
+	ctx := context.Background()
+
+	comment, err := s.CreateComment(ctx, f.viewer, f.public, &types.CreateCommentRequest{Body: "  first  "})
+	require.NoError(t, err)
+	assert.Equal(t, "first", comment.Body)
+	assert.Nil(t, comment.EditedAt)
+
+	_, err = s.UpdateComment(ctx, f.author, comment.ID, &types.UpdateCommentRequest{Body: "hijacked"})
+	assert.True(t, errors.Is(err, ErrCommentForbidden))
+	_, err = s.UpdateComment(ctx, f.viewer, comment.ID, &types.UpdateCommentRequest{Body: " "})
+	assert.True(t, errors.Is(err, ErrInvalidComment))
+
+	for _, body := range []string{"second", "second", "third"} {
+		comment, err = s.UpdateComment(ctx, f.viewer, comment.ID, &types.UpdateCommentRequest{Body: body})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, "third", comment.Body)
+	assert.NotNil(t, comment.EditedAt)
+
+	// Saving an unchanged body adds nothing to the history
+	edits, err := s.CommentHistory(ctx, f.author, comment.ID)
+	require.NoError(t, err)
+	require.Len(t, edits, 2)
+	assert.Equal(t, "first", edits[0].Body)
+	assert.Equal(t, "second", edits[1].Body)
+}
+
+func TestDeleteCommentKeepsThreadShape(t *testing.T) {
+	f, hermit := newSocialFixture(t)
+	s := NewCommentService(f.db)
+	ctx := context.Background()
+
+	root, err := s.CreateComment(ctx, f.author, f.public, &types.CreateCommentRequest{Body: "root"})
+	require.NoError(t, err)
+	reply, err := s.CreateComment(ctx, f.viewer, f.public, &types.CreateCommentRequest{Body: "reply", ParentID: &root.ID})
+	require.NoError(t, err)
+	nested, err := s.CreateComment(ctx, hermit, f.public, &types.CreateCommentRequest{Body: "nested", ParentID: &reply.ID})
+	require.NoError(t, err)
+	assert.Equal(t, root.ID, *nested.RootID)
+	_, err = s.UpdateComment(ctx, f.author, root.ID, &types.UpdateCommentRequest{Body: "root, edited"})
+	require.NoError(t, err)
+
+	// Only authors and moderators delete comments
+	assert.True(t, errors.Is(s.DeleteComment(ctx, f.viewer, root.ID, false), ErrCommentForbidden))
+	require.NoError(t, s.DeleteComment(ctx, f.author, root.ID, false))
+	require.NoError(t, s.DeleteComment(ctx, f.author, nested.ID, true))
+	assert.True(t, errors.Is(s.DeleteComment(ctx, f.author, root.ID, false), ErrCommentNotFound))
+
+	var deleted models.RecipeComment
+	require.NoError(t, f.db.First(&deleted, "id = ?", nested.ID).Error)
+	assert.Equal(t, f.author, *deleted.DeletedBy)
+	var edits int64
+	require.NoError(t, f.db.Model(&models.RecipeCommentEdit{}).Count(&edits).Error)
+	assert.Zero(t, edits)
+
+	// The deleted root stays, without its body, to hold the live reply; the
+	// deleted leaf is gone
+	threads, info, err := s.ListComments(ctx, f.viewer, f.public, pagination.Page{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), *info.Total)
+	require.Len(t, threads, 1)
+	assert.Equal(t, root.ID, threads[0].ID)
+	assert.NotNil(t, threads[0].DeletedAt)
+	assert.Empty(t, threads[0].Body)
+	require.Len(t, threads[0].Replies, 1)
+	assert.Equal(t, "reply", threads[0].Replies[0].Body)
+	assert.Equal(t, "viewer", threads[0].Replies[0].Username)
+	assert.Empty(t, threads[0].Replies[0].Replies)
+
+	// Once the reply goes too, so does the thread
+	require.NoError(t, s.DeleteComment(ctx, f.viewer, reply.ID, false))
+	threads, _, err = s.ListComments(ctx, f.viewer, f.public, pagination.Page{})
+	require.NoError(t, err)
+	assert.Empty(t, threads)
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Dashboard tuning
+const (
+	// dashboardStatsTTL bounds how stale cached stats get from changes the
+	// user did not make, such as a favorited recipe being deleted
+	dashboardStatsTTL = 10 * time.Minute
+	// streakWindow is how far back activity is read for the streak, and so the
+	// longest streak reported
+	streakWindow = 366
+	// declaredDietWeight is how many tagged recipes a dietary preference the
+	// user has declared counts as when choosing their primary diet
+	declaredDietWeight = 5
+)
+
+// WeekActivity counts what a user did since the start of the week
+type WeekActivity struct {
+	Generated int `json:"generated"`
+	Published int `json:"published"`
+	Favorited int `json:"favorited"`
+}
+
+// DashboardStats summarizes a user's activity. ThisWeek is the number of
+// meals planned for the current Monday to Sunday and Streak the number of
+// consecutive UTC days, up to today or yesterday, on which the user
+// generated, published or favorited a recipe.
+type DashboardStats struct {
+	RecipesGenerated int          `json:"recipesGenerated"`
+	RecipesPublished int          `json:"recipesPublished"`
+	Favorites        int          `json:"favorites"`
+	ThisWeek         int          `json:"thisWeek"`
+	WeekActivity     WeekActivity `json:"weekActivity"`
+	PrimaryDiet      string       `json:"primaryDiet"`
+	Streak           int          `json:"streak"`
+}
+
+// DashboardService computes dashboard statistics, caching them per user in
+// Redis when a client is configured
+type DashboardService struct {
+	db              *gorm.DB
+	mealPlanService IMealPlanService
+	cache           *redis.Client
+}
+
+// Ensure DashboardService implements IDashboardService
+var _ IDashboardService = (*DashboardService)(nil)
+
+// NewDashboardService creates a new DashboardService. cache may be nil, in
+// which case stats are computed on every request.
+func NewDashboardService(db *gorm.DB, mealPlanService IMealPlanService, cache *redis.Client) *DashboardService {
+	return &DashboardService{
+		db:              db,
+		mealPlanService: mealPlanService,
+		cache:           cache,
+	}
+}
+
+// statsKey is the Redis key of a user's cached stats
+func statsKey(userID uuid.UUID) string {
+	return fmt.Sprintf("dashboard:stats:%s", userID)
+}
+
+// GetStats returns the user's dashboard statistics, from the cache if
+// present. Cache errors are logged and fall back to computing the stats.
+func (s *DashboardService) GetStats(ctx context.Context, userID uuid.UUID) (*DashboardStats, error) {
+	if s.cache != nil {
+		data, err := s.cache.Get(ctx, statsKey(userID)).Bytes()
+		if err == nil {
+			var stats DashboardStats
+			if err := json.Unmarshal(data, &stats); err == nil {
+				return &stats, nil
+			}
+		} else if err != redis.Nil {
+			log.Printf("Failed to read cached dashboard stats: %v", err)
+		}
+	}
+
+	stats, err := s.computeStats(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cache != nil {
+		data, err := json.Marshal(stats)
+		if err == nil {
+			err = s.cache.Set(ctx, statsKey(userID), data, dashboardStatsTTL).Err()
+		}
+		if err != nil {
+			log.Printf("Failed to cache dashboard stats: %v", err)
+		}
+	}
+	return stats, nil
+}
+
+// InvalidateStats drops the user's cached stats so the next request
+// recomputes them
+func (s *DashboardService) InvalidateStats(ctx context.Context, userID uuid.UUID) error {
+	if s.cache == nil {
+		return nil
+	}
+	if err := s.cache.Del(ctx, statsKey(userID)).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate dashboard stats: %w", err)
+	}
+	return nil
+}
+
+// computeStats computes the user's stats as of now
+func (s *DashboardService) computeStats(ctx context.Context, userID uuid.UUID, now time.Time) (*DashboardStats, error) {
+	db := s.db.WithContext(ctx)
+	monday := startOfWeek(now)
+	stats := &DashboardStats{}
+
+	counts := []struct {
+		name    string
+		query   *gorm.DB
+		created string
+		total   *int
+		week    *int
+	}{
+		{"generated recipes", db.Model(&models.RecipeGeneration{}).Where("user_id = ?", userID),
+			"recipe_generations.created_at", &stats.RecipesGenerated, &stats.WeekActivity.Generated},
+		{"published recipes", db.Model(&models.Recipe{}).Where("user_id = ?", userID),
+			"recipes.created_at", &stats.RecipesPublished, &stats.WeekActivity.Published},
+		{"favorites", db.Model(&models.RecipeFavorite{}).
+			Joins("JOIN recipes ON recipes.id = recipe_favorites.recipe_id AND recipes.deleted_at IS NULL").
+			Where("recipe_favorites.user_id = ?", userID),
+			"recipe_favorites.created_at", &stats.Favorites, &stats.WeekActivity.Favorited},
+	}
+	for _, c := range counts {
+		var total, week int64
+		if err := c.query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", c.name, err)
+		}
+		if err := c.query.Session(&gorm.Session{}).Where(c.created+" >= ?", monday).Count(&week).Error; err != nil {
+			return nil, fmt.Errorf("failed to count %s this week: %w", c.name, err)
+		}
+		*c.total, *c.week = int(total), int(week)
+	}
+
+	thisWeek, err := s.mealPlanService.CountPlannedMeals(ctx, userID, monday, monday.AddDate(0, 0, 6))
+	if err != nil {
+		return nil, err
+	}
+	stats.ThisWeek = thisWeek
+
+	if stats.PrimaryDiet, err = s.primaryDiet(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	var days []time.Time
+	if err := db.Raw(`SELECT DISTINCT (created_at AT TIME ZONE 'UTC')::date FROM (
+			SELECT created_at FROM recipe_generations WHERE user_id = @user AND created_at >= @since
+			UNION ALL
+			SELECT created_at FROM recipes WHERE user_id = @user AND created_at >= @since AND deleted_at IS NULL
+			UNION ALL
+			SELECT created_at FROM recipe_favorites WHERE user_id = @user AND created_at >= @since AND deleted_at IS NULL
+		) activity`,
+		map[string]interface{}{"user": userID, "since": now.AddDate(0, 0, -streakWindow)}).
+		Scan(&days).Error; err != nil {
+		return nil, fmt.Errorf("failed to load activity: %w", err)
+	}
+	stats.Streak = activityStreak(days, now.UTC())
+
+	return stats, nil
+}
+
+// primaryDiet tallies the dietary tags of the recipes the user published or
+// favorited together with the preferences they declared
+func (s *DashboardService) primaryDiet(ctx context.Context, userID uuid.UUID) (string, error) {
+	var tags []struct {
+		Tag   string
+		Count int
+	}
+	if err := s.db.WithContext(ctx).Model(&models.Recipe{}).
+		Select("jsonb_array_elements_text(recipes.dietary_preferences) AS tag, COUNT(*) AS count").
+		Where("recipes.user_id = ? OR recipes.id IN (?)", userID,
+			s.db.Model(&models.RecipeFavorite{}).Select("recipe_id").Where("user_id = ?", userID)).
+		Group("tag").
+		Scan(&tags).Error; err != nil {
+		return "", fmt.Errorf("failed to tally recipe diets: %w", err)
+	}
+	counts := make(map[string]int, len(tags))
+	for _, t := range tags {
+		counts[t.Tag] += t.Count
+	}
+
+	declared, err := LoadDietaryPreferences(ctx, s.db, userID)
+	if err != nil {
+		return "", err
+	}
+	return pickPrimaryDiet(counts, declared), nil
+}
+
+// pickPrimaryDiet returns the diet with the highest tally, counting each
+// declared preference as declaredDietWeight recipes. Tags are compared case
+// insensitively and ties go to the alphabetically first diet.
+func pickPrimaryDiet(counts map[string]int, declared []string) string {
+	tally := make(map[string]int, len(counts)+len(declared))
+	for tag, n := range counts {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			tally[tag] += n
+		}
+	}
+	for _, pref := range declared {
+		if pref = strings.ToLower(strings.TrimSpace(pref)); pref != "" {
+			tally[pref] += declaredDietWeight
+		}
+	}
+
+	diets := make([]string, 0, len(tally))
+	for diet := range tally {
+		diets = append(diets, diet)
+	}
+	sort.Slice(diets, func(i, j int) bool {
+		if tally[diets[i]] != tally[diets[j]] {
+			return tally[diets[i]] > tally[diets[j]]
+		}
+		return diets[i] < diets[j]
+	})
+	if len(diets) == 0 {
+		return ""
+	}
+	return diets[0]
+}
+
+// activityStreak counts the consecutive days with activity ending today, or
+// ending yesterday if there is none yet today
+func activityStreak(days []time.Time, today time.Time) int {
+	active := make(map[string]bool, len(days))
+	for _, d := range days {
+		active[d.Format(dateLayout)] = true
+	}
+	day := today
+	if !active[day.Format(dateLayout)] {
+		day = day.AddDate(0, 0, -1)
+	}
+	streak := 0
+	for active[day.Format(dateLayout)] {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak
+}
+
+// RecentFavorites returns the user's most recently favorited recipes that
+// they can still see
+func (s *DashboardService) RecentFavorites(ctx context.Context, userID uuid.UUID, limit int) ([]*models.Recipe, error) {
+	var recipes []*models.Recipe
+	if err := s.db.WithContext(ctx).Model(&models.Recipe{}).
+		Joins("JOIN recipe_favorites ON recipes.id = recipe_favorites.recipe_id AND recipe_favorites.deleted_at IS NULL").
+		Where("recipe_favorites.user_id = ?", userID).
+		Scopes(VisibleRecipes(userID)).
+		Order("recipe_favorites.created_at DESC").Order("recipes.id").
+		Limit(limit).
+		Find(&recipes).Error; err != nil {
+		return nil, fmt.Errorf("failed to load recent favorites: %w", err)
+	}
+	return recipes, nil
+}
+
+// startOfWeek returns midnight on the Monday of t's week
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
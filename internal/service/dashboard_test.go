@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActivityStreak(t *testing.T) {
+	today := time.Date(2024, 3, 6, 15, 0, 0, 0, time.UTC)
+	day := func(offset int) time.Time {
+		return time.Date(2024, 3, 6+offset, 0, 0, 0, 0, time.UTC)
+	}
+
+	assert.Equal(t, 0, activityStreak(nil, today))
+	assert.Equal(t, 3, activityStreak([]time.Time{day(0), day(-1), day(-2), day(-4)}, today))
+	// Nothing yet today keeps yesterday's streak alive
+	assert.Equal(t, 2, activityStreak([]time.Time{day(-1), day(-2)}, today))
+	assert.Equal(t, 0, activityStreak([]time.Time{day(-2), day(-3)}, today))
+	// Streaks span month boundaries
+	assert.Equal(t, 7, activityStreak([]time.Time{day(0), day(-1), day(-2), day(-3), day(-4), day(-5), day(-6)}, today))
+}
+
+func TestPickPrimaryDiet(t *testing.T) {
+	assert.Equal(t, "", pickPrimaryDiet(nil, nil))
+	assert.Equal(t, "vegan", pickPrimaryDiet(map[string]int{"Vegan": 3, "gluten-free": 2}, nil))
+	// A declared preference outweighs a few tagged recipes
+	assert.Equal(t, "vegetarian", pickPrimaryDiet(map[string]int{"vegan": 3}, []string{"vegetarian"}))
+	assert.Equal(t, "vegan", pickPrimaryDiet(map[string]int{"vegan": 6}, []string{"Vegetarian"}))
+	// Ties go to the alphabetically first diet
+	assert.Equal(t, "dairy-free", pickPrimaryDiet(map[string]int{"vegan": 2, "dairy-free": 2, " ": 9}, nil))
+}
+
+func TestStartOfWeek(t *testing.T) {
+	wednesday := time.Date(2024, 3, 6, 15, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), startOfWeek(wednesday))
+	sunday := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), startOfWeek(sunday))
+}
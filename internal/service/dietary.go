@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// allergenCategories maps common allergen names to the ingredient categories
+// used by the substitution table
+var allergenCategories = map[string]string{
+	"dairy": "dairy", "milk": "dairy", "lactose": "dairy",
+	"egg": "egg", "eggs": "egg",
+	"gluten": "gluten", "wheat": "gluten",
+	"nut": "nut", "nuts": "nut", "tree nut": "nut", "tree nuts": "nut", "peanut": "nut", "peanuts": "nut",
+	"soy": "soy", "soya": "soy",
+	"fish": "fish",
+}
+
+// shellfishKeywords are ingredient names excluded by a shellfish allergy or
+// the shellfish-free preference, which the substitution table does not cover
+var shellfishKeywords = []string{"shrimp", "prawn", "crab", "lobster", "scallop", "mussel", "clam", "oyster", "shellfish"}
+
+// DietaryFilter decides whether a recipe suits a set of dietary preferences
+// and allergens. Restrictions are checked against the recipe's ingredient
+// lines; unknown preferences are ignored rather than excluding everything.
+type DietaryFilter struct {
+	categories map[string]bool
+	keywords   []string
+}
+
+// NewDietaryFilter builds a filter from preference names such as "vegan" and
+// allergen names such as "peanuts"
+func NewDietaryFilter(preferences, allergens []string) *DietaryFilter {
+	f := &DietaryFilter{categories: make(map[string]bool)}
+	f.Add(preferences, allergens)
+	return f
+}
+
+// Add extends the filter with more preferences and allergens
+func (f *DietaryFilter) Add(preferences, allergens []string) {
+	for _, pref := range preferences {
+		p := strings.ToLower(strings.TrimSpace(pref))
+		if p == "shellfish-free" {
+			f.keywords = append(f.keywords, shellfishKeywords...)
+			continue
+		}
+		for _, category := range constraintCategories[p] {
+			f.categories[category] = true
+		}
+	}
+	for _, allergen := range allergens {
+		a := strings.ToLower(strings.TrimSpace(allergen))
+		switch {
+		case a == "":
+		case a == "shellfish":
+			f.keywords = append(f.keywords, shellfishKeywords...)
+		case allergenCategories[a] != "":
+			f.categories[allergenCategories[a]] = true
+		default:
+			f.keywords = append(f.keywords, a)
+		}
+	}
+}
+
+// LoadDietaryFilter builds a filter from the user's saved dietary preferences
+// and allergens
+func LoadDietaryFilter(ctx context.Context, db *gorm.DB, userID uuid.UUID) (*DietaryFilter, error) {
+	prefNames, err := LoadDietaryPreferences(ctx, db, userID)
+	if err != nil {
+		return nil, err
+	}
+	allergenNames, err := LoadAllergens(ctx, db, userID)
+	if err != nil {
+		return nil, err
+	}
+	return NewDietaryFilter(prefNames, allergenNames), nil
+}
+
+// LoadDietaryPreferences returns the names of the user's saved dietary
+// preferences, using the custom name for custom preferences
+func LoadDietaryPreferences(ctx context.Context, db *gorm.DB, userID uuid.UUID) ([]string, error) {
+	var prefs []models.DietaryPreference
+	if err := db.WithContext(ctx).Where("user_id = ?", userID).Find(&prefs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load dietary preferences: %w", err)
+	}
+	names := make([]string, 0, len(prefs))
+	for _, pref := range prefs {
+		name := pref.PreferenceType
+		if name == "custom" {
+			name = pref.CustomName
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// LoadAllergens returns the names of the user's saved allergens
+func LoadAllergens(ctx context.Context, db *gorm.DB, userID uuid.UUID) ([]string, error) {
+	var allergens []models.Allergen
+	if err := db.WithContext(ctx).Where("user_id = ?", userID).Find(&allergens).Error; err != nil {
+		return nil, fmt.Errorf("failed to load allergens: %w", err)
+	}
+	names := make([]string, 0, len(allergens))
+	for _, allergen := range allergens {
+		names = append(names, allergen.AllergenName)
+	}
+	return names, nil
+}
+
+// Empty reports whether the filter allows every recipe
+func (f *DietaryFilter) Empty() bool {
+	return f == nil || (len(f.categories) == 0 && len(f.keywords) == 0)
+}
+
+// Allows reports whether none of the recipe's ingredients are restricted
+func (f *DietaryFilter) Allows(recipe *models.Recipe) bool {
+	return f.AllowsIngredients(recipe.Ingredients)
+}
+
+// AllowsIngredients reports whether none of the ingredient lines are restricted
+func (f *DietaryFilter) AllowsIngredients(lines []string) bool {
+	if f.Empty() {
+		return true
+	}
+	for _, line := range lines {
+		if entry := lookupSubstitution(line); entry != nil && entry.violates(f.categories) {
+			return false
+		}
+	}
+	for _, keyword := range f.keywords {
+		if len(findIngredientLines(lines, keyword)) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ingredientLookalikes are ingredient names that contain a restricted
+// ingredient's name without being that ingredient
+var ingredientLookalikes = []string{"eggplant", "butternut squash", "nutmeg", "coconut"}
+
+// ingredientExclusion is an ingredient name a filter rules out, with the
+// longer names containing it that stay allowed, such as "coconut milk" for
+// "milk"
+type ingredientExclusion struct {
+	keyword string
+	except  []string
+}
+
+// exclusions lists the ingredient names the filter rules out so that it can
+// be applied in SQL with substring matches. It mirrors Allows: names in table
+// entries that are not restricted are kept as exceptions, as lookupSubstitution
+// prefers them over the shorter restricted name.
+func (f *DietaryFilter) exclusions() []ingredientExclusion {
+	if f.Empty() {
+		return nil
+	}
+
+	allowed := append([]string{}, ingredientLookalikes...)
+	var keywords []string
+	for _, entry := range substitutionTable {
+		if entry.violates(f.categories) {
+			keywords = append(keywords, entry.keywords...)
+		} else {
+			allowed = append(allowed, entry.keywords...)
+		}
+	}
+	keywords = append(keywords, f.keywords...)
+
+	seen := make(map[string]bool)
+	var exclusions []ingredientExclusion
+	for _, keyword := range keywords {
+		keyword = strings.ToLower(keyword)
+		if seen[keyword] {
+			continue
+		}
+		seen[keyword] = true
+
+		ex := ingredientExclusion{keyword: keyword}
+		for _, name := range allowed {
+			if name != keyword && strings.Contains(name, keyword) {
+				ex.except = append(ex.except, name)
+			}
+		}
+		// Longest first so that shorter exceptions do not break up longer ones
+		ex.except = byLength(ex.except)
+		exclusions = append(exclusions, ex)
+	}
+	sort.Slice(exclusions, func(i, j int) bool { return exclusions[i].keyword < exclusions[j].keyword })
+	return exclusions
+}
@@ -1,11 +1,9 @@
 package service
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/pgvector/pgvector-go"
@@ -15,108 +13,118 @@ import (
 type EmbeddingServiceInterface interface {
 	GenerateEmbedding(text string) (pgvector.Vector, error)
 	GenerateEmbeddingFromRecipe(name, description string, ingredients []string, category string, dietary []string) (pgvector.Vector, error)
+	// Model names the embedding model; vectors are only comparable with
+	// vectors from the same model
+	Model() string
+	// Dimensions is the length of the vectors the model produces
+	Dimensions() int
 }
 
-// EmbeddingService handles interactions with the OpenAI API for embeddings
+// EmbeddingService generates embeddings with a configurable provider
 type EmbeddingService struct {
-	apiKey string
-	apiURL string
+	provider EmbeddingProvider
 }
 
-// NewEmbeddingService creates a new EmbeddingService instance
+// NewEmbeddingService creates a new EmbeddingService for the provider named
+// by EMBEDDING_PROVIDER: "openai" (the default), "ollama" or "hash".
+// EMBEDDING_MODEL and EMBEDDING_DIMENSIONS override the provider's defaults.
 func NewEmbeddingService() (*EmbeddingService, error) {
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		apiKeyFile := os.Getenv("OPENAI_API_KEY_FILE")
-		if apiKeyFile == "" {
-			return nil, fmt.Errorf("OPENAI_API_KEY or OPENAI_API_KEY_FILE must be set")
+	dimensions := 0
+	if v := os.Getenv("EMBEDDING_DIMENSIONS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("EMBEDDING_DIMENSIONS must be a positive number, got %q", v)
 		}
+		dimensions = n
+	}
+	model := os.Getenv("EMBEDDING_MODEL")
 
-		apiKeyBytes, err := os.ReadFile(apiKeyFile)
+	var provider EmbeddingProvider
+	switch name := strings.ToLower(os.Getenv("EMBEDDING_PROVIDER")); name {
+	case "", "openai":
+		apiKey, err := openAIAPIKey()
 		if err != nil {
-			return nil, fmt.Errorf("failed to read API key file: %w", err)
-		}
-
-		apiKey = strings.TrimSpace(string(apiKeyBytes))
-		if apiKey == "" {
-			return nil, fmt.Errorf("API key file is empty")
+			return nil, err
 		}
+		provider = NewOpenAIEmbeddingProvider(os.Getenv("OPENAI_API_URL"), apiKey, model, dimensions)
+	case "ollama":
+		provider = NewOllamaEmbeddingProvider(os.Getenv("OLLAMA_URL"), model, dimensions)
+	case "hash":
+		provider = NewHashEmbeddingProvider(dimensions)
+	default:
+		return nil, fmt.Errorf("unknown EMBEDDING_PROVIDER %q", name)
 	}
-
-	apiURL := os.Getenv("OPENAI_API_URL")
-	if apiURL == "" {
-		apiURL = "https://api.openai.com/v1/embeddings"
+	if provider.Dimensions() == 0 {
+		return nil, fmt.Errorf("EMBEDDING_DIMENSIONS must be set for model %s", provider.Model())
 	}
-
-	return &EmbeddingService{
-		apiKey: apiKey,
-		apiURL: apiURL,
-	}, nil
+	return NewEmbeddingServiceWithProvider(provider), nil
 }
 
-type embeddingRequest struct {
-	Model string `json:"model"`
-	Input string `json:"input"`
+// NewEmbeddingServiceWithProvider creates an EmbeddingService for a provider
+func NewEmbeddingServiceWithProvider(provider EmbeddingProvider) *EmbeddingService {
+	return &EmbeddingService{provider: provider}
 }
 
-type embeddingResponse struct {
-	Data []struct {
-		Embedding []float32 `json:"embedding"`
-	} `json:"data"`
-}
-
-// GenerateEmbedding generates an embedding using OpenAI's Ada model
-func (s *EmbeddingService) GenerateEmbedding(text string) (pgvector.Vector, error) {
-	reqBody := embeddingRequest{
-		Model: "text-embedding-ada-002",
-		Input: text,
+// openAIAPIKey reads the API key from OPENAI_API_KEY or the file named by
+// OPENAI_API_KEY_FILE
+func openAIAPIKey() (string, error) {
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey != "" {
+		return apiKey, nil
 	}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return pgvector.Vector{}, fmt.Errorf("failed to marshal request: %w", err)
+	apiKeyFile := os.Getenv("OPENAI_API_KEY_FILE")
+	if apiKeyFile == "" {
+		return "", fmt.Errorf("OPENAI_API_KEY or OPENAI_API_KEY_FILE must be set")
 	}
 
-	req, err := http.NewRequest("POST", s.apiURL, bytes.NewBuffer(jsonData))
+	apiKeyBytes, err := os.ReadFile(apiKeyFile)
 	if err != nil {
-		return pgvector.Vector{}, fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to read API key file: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	apiKey = strings.TrimSpace(string(apiKeyBytes))
+	if apiKey == "" {
+		return "", fmt.Errorf("API key file is empty")
+	}
+	return apiKey, nil
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// GenerateEmbedding embeds text with the configured provider
+func (s *EmbeddingService) GenerateEmbedding(text string) (pgvector.Vector, error) {
+	embedding, err := s.provider.Embed(text)
 	if err != nil {
-		return pgvector.Vector{}, fmt.Errorf("failed to send request: %w", err)
+		return pgvector.Vector{}, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return pgvector.Vector{}, fmt.Errorf("API request failed with status %d", resp.StatusCode)
+	if len(embedding) != s.provider.Dimensions() {
+		return pgvector.Vector{}, fmt.Errorf("%s returned %d dimensions, expected %d", s.provider.Model(), len(embedding), s.provider.Dimensions())
 	}
+	return pgvector.NewVector(embedding), nil
+}
 
-	var result embeddingResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return pgvector.Vector{}, fmt.Errorf("failed to decode response: %w", err)
-	}
+// GenerateEmbeddingFromRecipe generates an embedding from a recipe's name and description
+func (s *EmbeddingService) GenerateEmbeddingFromRecipe(name, description string, ingredients []string, category string, dietary []string) (pgvector.Vector, error) {
+	return s.GenerateEmbedding(RecipeEmbeddingText(name, description, ingredients, category, dietary))
+}
 
-	if len(result.Data) == 0 || len(result.Data[0].Embedding) == 0 {
-		return pgvector.Vector{}, fmt.Errorf("no embedding data in response")
-	}
+// Model returns the provider's model name
+func (s *EmbeddingService) Model() string {
+	return s.provider.Model()
+}
 
-	return pgvector.NewVector(result.Data[0].Embedding), nil
+// Dimensions returns the provider's vector length
+func (s *EmbeddingService) Dimensions() int {
+	return s.provider.Dimensions()
 }
 
-// GenerateEmbeddingFromRecipe generates an embedding from a recipe's name and description
-func (s *EmbeddingService) GenerateEmbeddingFromRecipe(name, description string, ingredients []string, category string, dietary []string) (pgvector.Vector, error) {
-	// Combine all relevant recipe information for better semantic matching
-	text := fmt.Sprintf("%s %s Ingredients: %s Category: %s Dietary: %s",
+// RecipeEmbeddingText combines all relevant recipe information into the text
+// that is embedded, for better semantic matching
+func RecipeEmbeddingText(name, description string, ingredients []string, category string, dietary []string) string {
+	return fmt.Sprintf("%s %s Ingredients: %s Category: %s Dietary: %s",
 		name,
 		description,
 		strings.Join(ingredients, ", "),
 		category,
 		strings.Join(dietary, ", "),
 	)
-	return s.GenerateEmbedding(text)
 }
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// EmbeddingProvider is a backend that turns text into vectors
+type EmbeddingProvider interface {
+	Embed(text string) ([]float32, error)
+	Model() string
+	Dimensions() int
+}
+
+// Provider defaults
+const (
+	defaultOpenAIEmbeddingURL   = "https://api.openai.com/v1/embeddings"
+	defaultOpenAIEmbeddingModel = "text-embedding-ada-002"
+	defaultOllamaURL            = "http://localhost:11434"
+	defaultOllamaEmbeddingModel = "nomic-embed-text"
+	defaultHashDimensions       = 256
+)
+
+// knownEmbeddingDimensions are the native vector lengths of common models, used
+// when EMBEDDING_DIMENSIONS is not set
+var knownEmbeddingDimensions = map[string]int{
+	"text-embedding-ada-002": 1536,
+	"text-embedding-3-small": 1536,
+	"text-embedding-3-large": 3072,
+	"nomic-embed-text":       768,
+	"mxbai-embed-large":      1024,
+	"all-minilm":             384,
+}
+
+var embeddingHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// postJSON sends a JSON request and decodes a JSON response
+func postJSON(url string, headers map[string]string, body, result interface{}) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := embeddingHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API request failed with status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// OpenAIEmbeddingProvider calls an OpenAI-compatible /v1/embeddings API
+type OpenAIEmbeddingProvider struct {
+	apiURL     string
+	apiKey     string
+	model      string
+	dimensions int
+	// requestDimensions asks the API to shorten vectors, which only newer
+	// models support
+	requestDimensions bool
+}
+
+// NewOpenAIEmbeddingProvider creates an OpenAI-compatible provider. Empty
+// values fall back to the OpenAI API and text-embedding-ada-002; a non-zero
+// dimensions is sent to the API to shorten the vectors.
+func NewOpenAIEmbeddingProvider(apiURL, apiKey, model string, dimensions int) *OpenAIEmbeddingProvider {
+	if apiURL == "" {
+		apiURL = defaultOpenAIEmbeddingURL
+	}
+	if model == "" {
+		model = defaultOpenAIEmbeddingModel
+	}
+	p := &OpenAIEmbeddingProvider{
+		apiURL:            apiURL,
+		apiKey:            apiKey,
+		model:             model,
+		dimensions:        dimensions,
+		requestDimensions: dimensions > 0,
+	}
+	if dimensions == 0 {
+		p.dimensions = knownEmbeddingDimensions[model]
+	}
+	return p
+}
+
+type openAIEmbeddingRequest struct {
+	Model      string `json:"model"`
+	Input      string `json:"input"`
+	Dimensions int    `json:"dimensions,omitempty"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed generates an embedding for text
+func (p *OpenAIEmbeddingProvider) Embed(text string) ([]float32, error) {
+	reqBody := openAIEmbeddingRequest{Model: p.model, Input: text}
+	if p.requestDimensions {
+		reqBody.Dimensions = p.dimensions
+	}
+
+	var result openAIEmbeddingResponse
+	if err := postJSON(p.apiURL, map[string]string{"Authorization": "Bearer " + p.apiKey}, reqBody, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Data) == 0 || len(result.Data[0].Embedding) == 0 {
+		return nil, fmt.Errorf("no embedding data in response")
+	}
+	return result.Data[0].Embedding, nil
+}
+
+// Model returns the model name
+func (p *OpenAIEmbeddingProvider) Model() string { return p.model }
+
+// Dimensions returns the vector length
+func (p *OpenAIEmbeddingProvider) Dimensions() int { return p.dimensions }
+
+// OllamaEmbeddingProvider calls a local Ollama server's /api/embed endpoint
+type OllamaEmbeddingProvider struct {
+	baseURL    string
+	model      string
+	dimensions int
+}
+
+// NewOllamaEmbeddingProvider creates an Ollama provider. Empty values fall
+// back to a local server and nomic-embed-text.
+func NewOllamaEmbeddingProvider(baseURL, model string, dimensions int) *OllamaEmbeddingProvider {
+	if baseURL == "" {
+		baseURL = defaultOllamaURL
+	}
+	if model == "" {
+		model = defaultOllamaEmbeddingModel
+	}
+	if dimensions == 0 {
+		dimensions = knownEmbeddingDimensions[model]
+	}
+	return &OllamaEmbeddingProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		model:      model,
+		dimensions: dimensions,
+	}
+}
+
+type ollamaEmbedRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+type ollamaEmbedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// Embed generates an embedding for text
+func (p *OllamaEmbeddingProvider) Embed(text string) ([]float32, error) {
+	var result ollamaEmbedResponse
+	if err := postJSON(p.baseURL+"/api/embed", nil, ollamaEmbedRequest{Model: p.model, Input: text}, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Embeddings) == 0 || len(result.Embeddings[0]) == 0 {
+		return nil, fmt.Errorf("no embedding data in response")
+	}
+	return result.Embeddings[0], nil
+}
+
+// Model returns the model name
+func (p *OllamaEmbeddingProvider) Model() string { return p.model }
+
+// Dimensions returns the vector length
+func (p *OllamaEmbeddingProvider) Dimensions() int { return p.dimensions }
+
+// HashEmbeddingProvider is a deterministic, offline embedder for tests and
+// local development. Each word is hashed into a signed bucket and the vector
+// is normalized, so texts sharing words are close. It captures no meaning
+// beyond shared words.
+type HashEmbeddingProvider struct {
+	dimensions int
+}
+
+// NewHashEmbeddingProvider creates a hashing provider, 256-dimensional by
+// default
+func NewHashEmbeddingProvider(dimensions int) *HashEmbeddingProvider {
+	if dimensions <= 0 {
+		dimensions = defaultHashDimensions
+	}
+	return &HashEmbeddingProvider{dimensions: dimensions}
+}
+
+// Embed generates an embedding for text
+func (p *HashEmbeddingProvider) Embed(text string) ([]float32, error) {
+	vec := make([]float32, p.dimensions)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, word := range words {
+		h := fnv.New64a()
+		h.Write([]byte(word))
+		sum := h.Sum64()
+		if sum>>63 == 1 {
+			vec[sum%uint64(p.dimensions)]--
+		} else {
+			vec[sum%uint64(p.dimensions)]++
+		}
+	}
+
+	var norm float64
+	for _, x := range vec {
+		norm += float64(x) * float64(x)
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range vec {
+			vec[i] *= scale
+		}
+	}
+	return vec, nil
+}
+
+// Model returns the model name
+func (p *HashEmbeddingProvider) Model() string { return "hash" }
+
+// Dimensions returns the vector length
+func (p *HashEmbeddingProvider) Dimensions() int { return p.dimensions }
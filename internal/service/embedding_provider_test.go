@@ -0,0 +1,93 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashEmbeddingProvider(t *testing.T) {
+	p := NewHashEmbeddingProvider(0)
+	assert.Equal(t, defaultHashDimensions, p.Dimensions())
+
+	soup, err := p.Embed("Tomato soup with basil")
+	require.NoError(t, err)
+	require.Len(t, soup, defaultHashDimensions)
+	again, _ := p.Embed("tomato SOUP, with basil")
+	assert.Equal(t, soup, again, "embedding is deterministic and ignores case and punctuation")
+	assert.InDelta(t, 1, cosineSimilarity(soup, soup), 1e-6)
+
+	similar, _ := p.Embed("Roasted tomato soup")
+	different, _ := p.Embed("Chocolate brownies")
+	assert.Greater(t, cosineSimilarity(soup, similar), cosineSimilarity(soup, different))
+}
+
+func TestOpenAIEmbeddingProvider(t *testing.T) {
+	var got openAIEmbeddingRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]interface{}{{"embedding": []float32{0.1, 0.2, 0.3}}},
+		})
+	}))
+	defer server.Close()
+
+	p := NewOpenAIEmbeddingProvider(server.URL, "key", "text-embedding-3-small", 3)
+	vec, err := p.Embed("soup")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{0.1, 0.2, 0.3}, vec)
+	assert.Equal(t, openAIEmbeddingRequest{Model: "text-embedding-3-small", Input: "soup", Dimensions: 3}, got)
+
+	// Native dimensions are not sent
+	got = openAIEmbeddingRequest{}
+	p = NewOpenAIEmbeddingProvider(server.URL, "key", "", 0)
+	assert.Equal(t, 1536, p.Dimensions())
+	_, err = p.Embed("soup")
+	require.NoError(t, err)
+	assert.Equal(t, defaultOpenAIEmbeddingModel, got.Model)
+	assert.Zero(t, got.Dimensions)
+}
+
+func TestOllamaEmbeddingProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/embed", r.URL.Path)
+		var req ollamaEmbedRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "nomic-embed-text", req.Model)
+		json.NewEncoder(w).Encode(ollamaEmbedResponse{Embeddings: [][]float32{{0.5, 0.5}}})
+	}))
+	defer server.Close()
+
+	p := NewOllamaEmbeddingProvider(server.URL+"/", "", 2)
+	vec, err := p.Embed("soup")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{0.5, 0.5}, vec)
+
+	// The service rejects vectors of the wrong length
+	_, err = NewEmbeddingServiceWithProvider(NewOllamaEmbeddingProvider(server.URL, "", 0)).GenerateEmbedding("soup")
+	assert.Error(t, err)
+}
+
+func TestNewEmbeddingServiceProviders(t *testing.T) {
+	t.Setenv("EMBEDDING_PROVIDER", "hash")
+	t.Setenv("EMBEDDING_DIMENSIONS", "64")
+	svc, err := NewEmbeddingService()
+	require.NoError(t, err)
+	assert.Equal(t, "hash", svc.Model())
+	assert.Equal(t, 64, svc.Dimensions())
+
+	t.Setenv("EMBEDDING_PROVIDER", "ollama")
+	t.Setenv("EMBEDDING_DIMENSIONS", "")
+	t.Setenv("EMBEDDING_MODEL", "some-new-model")
+	_, err = NewEmbeddingService()
+	assert.Error(t, err, "unknown models need EMBEDDING_DIMENSIONS")
+
+	t.Setenv("EMBEDDING_PROVIDER", "word2vec")
+	_, err = NewEmbeddingService()
+	assert.Error(t, err)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
 	"github.com/pageza/alchemorsel-v2/backend/internal/types"
 	"gorm.io/gorm"
 )
@@ -74,8 +75,8 @@ func (s *FeedbackService) GetFeedback(ctx context.Context, id uuid.UUID) (*model
 	return &feedback, nil
 }
 
-func (s *FeedbackService) ListFeedback(ctx context.Context, filters *models.FeedbackFilters) ([]*models.Feedback, error) {
-	query := s.db.WithContext(ctx).Preload("User")
+func (s *FeedbackService) ListFeedback(ctx context.Context, filters *models.FeedbackFilters, page pagination.Page) ([]*models.Feedback, *pagination.Info, error) {
+	query := s.db.WithContext(ctx).Model(&models.Feedback{})
 
 	// Apply filters
 	if filters != nil {
@@ -93,27 +94,24 @@ func (s *FeedbackService) ListFeedback(ctx context.Context, filters *models.Feed
 				query = query.Where("user_id = ?", userUUID)
 			}
 		}
-
-		// Apply pagination
-		if filters.Limit > 0 {
-			query = query.Limit(filters.Limit)
-		} else {
-			query = query.Limit(50) // Default limit
-		}
-		if filters.Offset > 0 {
-			query = query.Offset(filters.Offset)
-		}
 	}
 
-	// Order by creation date (newest first)
-	query = query.Order("created_at DESC")
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to count feedback: %w", err)
+	}
 
+	// Newest first, paginated by creation date
 	var feedback []*models.Feedback
-	if err := query.Find(&feedback).Error; err != nil {
-		return nil, fmt.Errorf("failed to list feedback: %w", err)
+	if err := pagination.Keyset(query.Preload("User"), page, "created_at", "id").Find(&feedback).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to list feedback: %w", err)
 	}
 
-	return feedback, nil
+	feedback, info := pagination.Finish(feedback, page, func(f *models.Feedback) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: f.CreatedAt, ID: f.ID}
+	})
+	info.Total = &total
+	return feedback, info, nil
 }
 
 func (s *FeedbackService) UpdateFeedbackStatus(ctx context.Context, id uuid.UUID, status string, adminNotes string) error {
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrUserNotFound is returned when no user has the username or their
+	// profile is private
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidFollow is returned when users try to follow themselves
+	ErrInvalidFollow = errors.New("you cannot follow yourself")
+)
+
+// FollowUser is a user in a followers or following list
+type FollowUser struct {
+	UserID            uuid.UUID `json:"user_id"`
+	Username          string    `json:"username"`
+	ProfilePictureURL string    `json:"profile_picture_url"`
+	FollowedAt        time.Time `json:"followed_at"`
+}
+
+// FollowService manages the follow graph. Users can follow anyone whose
+// profile is not private and are notified of new followers.
+type FollowService struct {
+	db *gorm.DB
+}
+
+// Ensure FollowService implements IFollowService
+var _ IFollowService = (*FollowService)(nil)
+
+// NewFollowService creates a new FollowService instance
+func NewFollowService(db *gorm.DB) *FollowService {
+	return &FollowService{db: db}
+}
+
+// Follow makes the user follow the user with the username. Following someone
+// twice does nothing.
+func (s *FollowService) Follow(ctx context.Context, followerID uuid.UUID, username string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		profile, err := visibleProfile(tx, followerID, username)
+		if err != nil {
+			return err
+		}
+		if profile.UserID == followerID {
+			return ErrInvalidFollow
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.Follow{FollowerID: followerID, FollowingID: profile.UserID})
+		if result.Error != nil {
+			return fmt.Errorf("failed to follow user: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return notify(tx, []models.Notification{{UserID: profile.UserID, ActorID: &followerID, Type: models.NotificationFollow}})
+	})
+}
+
+// Unfollow stops the user following the user with the username, whatever
+// their profile's privacy level
+func (s *FollowService) Unfollow(ctx context.Context, followerID uuid.UUID, username string) error {
+	var profile models.UserProfile
+	if err := s.db.WithContext(ctx).Where("LOWER(username) = LOWER(?)", username).First(&profile).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := s.db.WithContext(ctx).
+		Where("follower_id = ? AND following_id = ?", followerID, profile.UserID).
+		Delete(&models.Follow{}).Error; err != nil {
+		return fmt.Errorf("failed to unfollow user: %w", err)
+	}
+	return nil
+}
+
+// ListFollowers returns one page of the followers of the user with the
+// username, most recent first. Followers with private or unlisted profiles
+// are left out for everyone but themselves.
+func (s *FollowService) ListFollowers(ctx context.Context, viewerID uuid.UUID, username string, page pagination.Page) ([]*FollowUser, *pagination.Info, error) {
+	return s.listFollows(ctx, viewerID, username, "follows.follower_id", "follows.following_id", page)
+}
+
+// ListFollowing returns one page of the users the user with the username
+// follows, most recent first, leaving out private and unlisted profiles like
+// ListFollowers
+func (s *FollowService) ListFollowing(ctx context.Context, viewerID uuid.UUID, username string, page pagination.Page) ([]*FollowUser, *pagination.Info, error) {
+	return s.listFollows(ctx, viewerID, username, "follows.following_id", "follows.follower_id", page)
+}
+
+// listFollows lists the users in userColumn of the follows whose
+// ownerColumn is the user with the username
+func (s *FollowService) listFollows(ctx context.Context, viewerID uuid.UUID, username, userColumn, ownerColumn string, page pagination.Page) ([]*FollowUser, *pagination.Info, error) {
+	profile, err := visibleProfile(s.db.WithContext(ctx), viewerID, username)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := s.db.WithContext(ctx).Table("follows").
+		Joins("JOIN user_profiles ON user_profiles.user_id = "+userColumn+" AND user_profiles.deleted_at IS NULL").
+		Where(ownerColumn+" = ?", profile.UserID).
+		Scopes(ListedProfiles(viewerID))
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to count follows: %w", err)
+	}
+
+	var users []*FollowUser
+	if err := pagination.Keyset(query, page, "follows.created_at", userColumn).
+		Select(userColumn + " AS user_id, user_profiles.username, user_profiles.profile_picture_url, follows.created_at AS followed_at").
+		Scan(&users).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to list follows: %w", err)
+	}
+	users, info := pagination.Finish(users, page, followCursor)
+	info.Total = &total
+	return users, info, nil
+}
+
+// visibleProfile returns the profile with the username if the viewer can
+// open it. Usernames match case-insensitively.
+func visibleProfile(db *gorm.DB, viewerID uuid.UUID, username string) (*models.UserProfile, error) {
+	var profile models.UserProfile
+	if err := db.Scopes(VisibleProfiles(viewerID)).
+		Where("LOWER(user_profiles.username) = LOWER(?)", username).
+		First(&profile).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &profile, nil
+}
+
+// followCursor returns the keyset position of a user in a follow list
+func followCursor(u *FollowUser) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: u.FollowedAt, ID: u.UserID}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSocialFixture extends the visibility fixture with public profiles for
+// the author and viewer and a private profile for a third user, the hermit
+func newSocialFixture(t *testing.T) (*visibilityFixture, uuid.UUID) {
+	f := newVisibilityFixture(t)
+	hermit := uuid.New()
+	require.NoError(t, f.db.Exec("INSERT INTO user_profiles (id, user_id, username, privacy_level) VALUES (?, ?, ?, ?), (?, ?, ?, ?), (?, ?, ?, ?)",
+		uuid.New(), f.author, "Author", models.RecipePublic,
+		uuid.New(), f.viewer, "viewer", models.RecipePublic,
+		uuid.New(), hermit, "hermit", models.RecipePrivate).Error)
+	return f, hermit
+}
+
+func followUserIDs(users []*FollowUser) []uuid.UUID {
+	ids := make([]uuid.UUID, len(users))
+	for i, u := range users {
+		ids[i] = u.UserID
+	}
+	return ids
+}
+
+func TestFollow(t *testing.T) {
+	f, hermit := newSocialFixture(t)
+	s := NewFollowService(f.db)
+	ctx := context.Background()
+
+	// Usernames match case-insensitively and following twice does nothing
+	require.NoError(t, s.Follow(ctx, f.viewer, "author"))
+	require.NoError(t, s.Follow(ctx, f.viewer, "AUTHOR"))
+
+	var notifications []models.Notification
+	require.NoError(t, f.db.Find(&notifications).Error)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, f.author, notifications[0].UserID)
+	assert.Equal(t, models.NotificationFollow, notifications[0].Type)
+
+	assert.True(t, errors.Is(s.Follow(ctx, f.viewer, "viewer"), ErrInvalidFollow))
+	assert.True(t, errors.Is(s.Follow(ctx, f.viewer, "hermit"), ErrUserNotFound))
+	assert.True(t, errors.Is(s.Follow(ctx, f.viewer, "nobody"), ErrUserNotFound))
+
+	// Private profiles can still be unfollowed
+	require.NoError(t, f.db.Create(&models.Follow{FollowerID: f.viewer, FollowingID: hermit}).Error)
+	require.NoError(t, s.Unfollow(ctx, f.viewer, "hermit"))
+	require.NoError(t, s.Unfollow(ctx, f.viewer, "author"))
+	var follows int64
+	require.NoError(t, f.db.Model(&models.Follow{}).Count(&follows).Error)
+	assert.Zero(t, follows)
+}
+
+func TestFollowListsRespectPrivacy(t *testing.T) {
+	f, hermit := newSocialFixture(t)
+	s := NewFollowService(f.db)
+	ctx := context.Background()
+	now := time.Now()
+	require.NoError(t, f.db.Create([]models.Follow{
+		{FollowerID: hermit, FollowingID: f.author, CreatedAt: now.Add(-time.Hour)},
+		{FollowerID: f.viewer, FollowingID: f.author, CreatedAt: now},
+		{FollowerID: f.author, FollowingID: hermit, CreatedAt: now},
+	}).Error)
+
+	users, info, err := s.ListFollowers(ctx, f.viewer, "author", pagination.Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.viewer}, followUserIDs(users))
+	assert.Equal(t, int64(1), *info.Total)
+
+	// Users with private profiles see themselves in lists
+	users, info, err = s.ListFollowers(ctx, hermit, "author", pagination.Page{Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.viewer}, followUserIDs(users))
+	after, err := pagination.Decode(info.NextCursor)
+	require.NoError(t, err)
+	users, _, err = s.ListFollowers(ctx, hermit, "author", pagination.Page{Limit: 1, After: after})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{hermit}, followUserIDs(users))
+
+	users, _, err = s.ListFollowing(ctx, f.viewer, "author", pagination.Page{})
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	_, _, err = s.ListFollowing(ctx, f.viewer, "hermit", pagination.Page{})
+	assert.True(t, errors.Is(err, ErrUserNotFound))
+	users, _, err = s.ListFollowers(ctx, hermit, "hermit", pagination.Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.author}, followUserIDs(users))
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// hybridCandidateLimit is how many results each of the full-text and vector
+// searches contributes before fusion
+const hybridCandidateLimit = 100
+
+// maxRankedDepth caps how many candidates a search ranks to reach a deep
+// page, so that a crafted cursor cannot make it rank the whole table. Pages
+// past it come back empty.
+const maxRankedDepth = 10 * hybridCandidateLimit
+
+// defaultMaxVectorDistance is the cosine distance past which the vector
+// search stops counting a recipe as a match. Set SEARCH_MAX_VECTOR_DISTANCE to
+// tune it for the embedding model; 2 keeps every recipe.
+const defaultMaxVectorDistance = 0.6
+
+// rrfK damps the weight of top ranks in reciprocal-rank fusion. 60 is the
+// value from the original RRF paper and works well without tuning.
+const rrfK = 60
+
+// ScoreExplanation shows how a hybrid search result was scored. Ranks are
+// 1-based; a zero rank means the result did not come from that search.
+type ScoreExplanation struct {
+	Score          float64  `json:"score"`
+	TextRank       int      `json:"text_rank,omitempty"`
+	TextScore      float64  `json:"text_score,omitempty"`
+	VectorRank     int      `json:"vector_rank,omitempty"`
+	VectorDistance *float64 `json:"vector_distance,omitempty"`
+}
+
+// RankedRecipe is a recipe ID in hybrid search order with its explanation
+type RankedRecipe struct {
+	ID          uuid.UUID
+	Explanation ScoreExplanation
+}
+
+// hybridRank ranks recipes for a query by fusing Postgres full-text ranking
+// over the weighted search_vector column (name, then ingredients, then
+// description) with embedding similarity, keeping vector matches within
+// maxVectorDistance. scope, if set, restricts both
+// searches. Without an embedding service, or if the query cannot be embedded,
+// the text ranking is used alone.
+func (s *RecipeService) hybridRank(ctx context.Context, query string, scope func(*gorm.DB) *gorm.DB, depth int) ([]RankedRecipe, error) {
+	base := func() *gorm.DB {
+		db := s.db.WithContext(ctx).Model(&models.Recipe{})
+		if scope != nil {
+			db = scope(db)
+		}
+		return db
+	}
+
+	var textHits []struct {
+		ID   uuid.UUID
+		Rank float64
+	}
+	if err := base().
+		Select("recipes.id AS id, ts_rank_cd(recipes.search_vector, websearch_to_tsquery('english', ?)) AS rank", query).
+		Where("recipes.search_vector @@ websearch_to_tsquery('english', ?)", query).
+		Order("rank DESC").Order("recipes.id").Limit(depth).
+		Scan(&textHits).Error; err != nil {
+		return nil, fmt.Errorf("failed to run text search: %w", err)
+	}
+
+	var vectorHits []struct {
+		ID       uuid.UUID
+		Distance float64
+	}
+	if s.embeddingService != nil {
+		vec, err := s.embeddingService.GenerateEmbedding(query)
+		if err != nil {
+			log.Printf("Failed to embed search query, ranking by text only: %v", err)
+		} else if err := base().
+			Select("recipes.id AS id, "+embeddingDistance(s.embeddingService)+" AS distance", vec).
+			Scopes(sameEmbeddingModel(s.embeddingService)).
+			Where("("+embeddingDistance(s.embeddingService)+") <= ?", vec, s.maxVectorDistance).
+			Order("distance").Order("recipes.id").Limit(depth).
+			Scan(&vectorHits).Error; err != nil {
+			return nil, fmt.Errorf("failed to run vector search: %w", err)
+		}
+	}
+
+	text := make([]RankedRecipe, len(textHits))
+	for i, hit := range textHits {
+		text[i] = RankedRecipe{ID: hit.ID, Explanation: ScoreExplanation{TextRank: i + 1, TextScore: hit.Rank}}
+	}
+	vector := make([]RankedRecipe, len(vectorHits))
+	for i, hit := range vectorHits {
+		distance := hit.Distance
+		vector[i] = RankedRecipe{ID: hit.ID, Explanation: ScoreExplanation{VectorRank: i + 1, VectorDistance: &distance}}
+	}
+	return fuseRankings(text, vector), nil
+}
+
+// maxVectorDistance returns SEARCH_MAX_VECTOR_DISTANCE, or
+// defaultMaxVectorDistance if it is unset or not a distance
+func maxVectorDistance() float64 {
+	v := os.Getenv("SEARCH_MAX_VECTOR_DISTANCE")
+	if v == "" {
+		return defaultMaxVectorDistance
+	}
+	distance, err := strconv.ParseFloat(v, 64)
+	if err != nil || distance <= 0 || distance > 2 {
+		log.Printf("SEARCH_MAX_VECTOR_DISTANCE must be a cosine distance between 0 and 2, got %q; using %g", v, defaultMaxVectorDistance)
+		return defaultMaxVectorDistance
+	}
+	return distance
+}
+
+// embeddingDistance is the cosine distance from recipes.embedding to a query
+// vector. The cast matches the per-model partial indexes.
+func embeddingDistance(embeddingService EmbeddingServiceInterface) string {
+	return fmt.Sprintf("recipes.embedding::vector(%d) <=> ?", embeddingService.Dimensions())
+}
+
+// sameEmbeddingModel limits a query to recipes embedded by the current model,
+// the only vectors comparable with its query vectors. Recipes not embedded
+// yet, or still on a previous model, are left out until they are re-embedded.
+// The model is inlined rather than bound so that the planner can match the
+// per-model partial indexes, even with a cached generic plan.
+func sameEmbeddingModel(embeddingService EmbeddingServiceInterface) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(fmt.Sprintf("recipes.embedding_model = %s AND recipes.embedding_dimensions = %d",
+			quoteLiteral(embeddingService.Model()), embeddingService.Dimensions()))
+	}
+}
+
+// quoteLiteral quotes a string as an SQL literal
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// fuseRankings merges text and vector rankings with reciprocal-rank fusion:
+// each result scores 1/(rrfK+rank) for every ranking it appears in. Ties keep
+// the better text rank first.
+func fuseRankings(text, vector []RankedRecipe) []RankedRecipe {
+	byID := make(map[uuid.UUID]*RankedRecipe, len(text)+len(vector))
+	var fused []*RankedRecipe
+	add := func(r RankedRecipe) *RankedRecipe {
+		if existing, ok := byID[r.ID]; ok {
+			return existing
+		}
+		entry := &RankedRecipe{ID: r.ID}
+		byID[r.ID] = entry
+		fused = append(fused, entry)
+		return entry
+	}
+	for _, r := range text {
+		entry := add(r)
+		entry.Explanation.TextRank = r.Explanation.TextRank
+		entry.Explanation.TextScore = r.Explanation.TextScore
+		entry.Explanation.Score += 1 / float64(rrfK+r.Explanation.TextRank)
+	}
+	for _, r := range vector {
+		entry := add(r)
+		entry.Explanation.VectorRank = r.Explanation.VectorRank
+		entry.Explanation.VectorDistance = r.Explanation.VectorDistance
+		entry.Explanation.Score += 1 / float64(rrfK+r.Explanation.VectorRank)
+	}
+
+	// Stable, so equal scores keep text results ahead in text order
+	sort.SliceStable(fused, func(i, j int) bool { return fused[i].Explanation.Score > fused[j].Explanation.Score })
+	result := make([]RankedRecipe, len(fused))
+	for i, r := range fused {
+		result[i] = *r
+	}
+	return result
+}
+
+// rankDepth returns how many candidates to rank to reach the page: at least
+// hybridCandidateLimit, and no more than maxRankedDepth
+func rankDepth(page pagination.Page) int {
+	depth := hybridCandidateLimit
+	if page.After != nil && page.After.Offset+page.Size()+1 > depth {
+		depth = page.After.Offset + page.Size() + 1
+	}
+	if depth > maxRankedDepth {
+		depth = maxRankedDepth
+	}
+	return depth
+}
+
+// rankedPage returns one page of ranked IDs, addressed by offset like
+// pagination.Offset
+func rankedPage(ranked []RankedRecipe, page pagination.Page) ([]RankedRecipe, *pagination.Info) {
+	offset := 0
+	if page.After != nil {
+		offset = page.After.Offset
+	}
+	if offset > len(ranked) {
+		offset = len(ranked)
+	}
+	end := offset + page.Size() + 1
+	if end > len(ranked) {
+		end = len(ranked)
+	}
+	return pagination.Finish(ranked[offset:end], page, nil)
+}
+
+// loadRanked loads the recipes for ranked IDs, keeping their order
+func (s *RecipeService) loadRanked(ctx context.Context, ranked []RankedRecipe) ([]*models.Recipe, error) {
+	ids := make([]uuid.UUID, len(ranked))
+	for i, r := range ranked {
+		ids[i] = r.ID
+	}
+	return loadRecipesInOrder(ctx, s.db, ids)
+}
+
+// loadRecipesInOrder loads recipes by ID in the order given, skipping IDs
+// that no longer exist
+func loadRecipesInOrder(ctx context.Context, db *gorm.DB, ids []uuid.UUID) ([]*models.Recipe, error) {
+	if len(ids) == 0 {
+		return []*models.Recipe{}, nil
+	}
+	var rows []*models.Recipe
+	if err := db.WithContext(ctx).Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*models.Recipe, len(rows))
+	for _, r := range rows {
+		byID[r.ID] = r
+	}
+	recipes := make([]*models.Recipe, 0, len(rows))
+	for _, id := range ids {
+		if r, ok := byID[id]; ok {
+			recipes = append(recipes, r)
+		}
+	}
+	return recipes, nil
+}
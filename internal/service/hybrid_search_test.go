@@ -0,0 +1,94 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuseRankings(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	distance := 0.2
+	text := []RankedRecipe{
+		{ID: a, Explanation: ScoreExplanation{TextRank: 1, TextScore: 0.5}},
+		{ID: b, Explanation: ScoreExplanation{TextRank: 2, TextScore: 0.3}},
+	}
+	vector := []RankedRecipe{
+		{ID: c, Explanation: ScoreExplanation{VectorRank: 1, VectorDistance: &distance}},
+		{ID: b, Explanation: ScoreExplanation{VectorRank: 2, VectorDistance: &distance}},
+	}
+
+	fused := fuseRankings(text, vector)
+	require.Len(t, fused, 3)
+
+	// b is found by both searches, so it outranks the single top hits
+	assert.Equal(t, b, fused[0].ID)
+	assert.InDelta(t, 1.0/62+1.0/62, fused[0].Explanation.Score, 1e-9)
+	assert.Equal(t, 2, fused[0].Explanation.TextRank)
+	assert.Equal(t, 2, fused[0].Explanation.VectorRank)
+
+	// Equal scores keep the text result first
+	assert.Equal(t, a, fused[1].ID)
+	assert.Equal(t, c, fused[2].ID)
+	assert.Zero(t, fused[2].Explanation.TextRank)
+}
+
+func TestRankedPage(t *testing.T) {
+	ranked := make([]RankedRecipe, 5)
+	for i := range ranked {
+		ranked[i] = RankedRecipe{ID: uuid.New()}
+	}
+
+	first, info := rankedPage(ranked, pagination.Page{Limit: 2})
+	assert.Equal(t, ranked[:2], first)
+	next, err := pagination.Decode(info.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.Offset)
+
+	last, info := rankedPage(ranked, pagination.Page{Limit: 2, After: &pagination.Cursor{Offset: 4}})
+	assert.Equal(t, ranked[4:], last)
+	assert.Empty(t, info.NextCursor)
+
+	beyond, _ := rankedPage(ranked, pagination.Page{After: &pagination.Cursor{Offset: 9}})
+	assert.Empty(t, beyond)
+}
+
+func TestRankDepth(t *testing.T) {
+	assert.Equal(t, hybridCandidateLimit, rankDepth(pagination.Page{}))
+	assert.Equal(t, hybridCandidateLimit, rankDepth(pagination.Page{Limit: 20, After: &pagination.Cursor{Offset: 40}}))
+	assert.Equal(t, 221, rankDepth(pagination.Page{Limit: 20, After: &pagination.Cursor{Offset: 200}}))
+
+	// Deep cursors rank no further than the cap, and their pages are empty
+	page := pagination.Page{Limit: 20, After: &pagination.Cursor{Offset: 1 << 30}}
+	assert.Equal(t, maxRankedDepth, rankDepth(page))
+	ranked := make([]RankedRecipe, rankDepth(page))
+	for i := range ranked {
+		ranked[i] = RankedRecipe{ID: uuid.New()}
+	}
+	beyond, info := rankedPage(ranked, page)
+	assert.Empty(t, beyond)
+	assert.Empty(t, info.NextCursor)
+
+	// The page that reaches the cap is the last one
+	page.After.Offset = maxRankedDepth - 10
+	last, info := rankedPage(ranked, page)
+	assert.Len(t, last, 10)
+	assert.Empty(t, info.NextCursor)
+}
+
+func TestMaxVectorDistance(t *testing.T) {
+	for value, want := range map[string]float64{
+		"":     defaultMaxVectorDistance,
+		"0.35": 0.35,
+		"2":    2,
+		"0":    defaultMaxVectorDistance,
+		"3":    defaultMaxVectorDistance,
+		"far":  defaultMaxVectorDistance,
+	} {
+		t.Setenv("SEARCH_MAX_VECTOR_DISTANCE", value)
+		assert.Equal(t, want, maxVectorDistance(), value)
+	}
+}
@@ -2,10 +2,13 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
 	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"github.com/pageza/alchemorsel-v2/backend/internal/units"
 )
 
 type LLMServiceInterface interface {
@@ -16,6 +19,7 @@ type LLMServiceInterface interface {
 	DeleteDraft(ctx context.Context, id string) error
 	CalculateMacros(ingredients []string) (*Macros, error)
 	GenerateRecipesBatch(prompts []string) ([]string, error)
+	SuggestSubstitutions(recipeName string, ingredients []string, constraint string) ([]SubstitutionSuggestion, error)
 }
 
 // IAuthService defines the interface for authentication operations
@@ -36,29 +40,33 @@ type IProfileService interface {
 	GetProfile(ctx context.Context, userID uuid.UUID) (*models.UserProfile, error)
 	UpdateProfile(ctx context.Context, userID uuid.UUID, req *types.UpdateProfileRequest) (*models.UserProfile, error)
 	Logout(ctx context.Context, userID uuid.UUID) error
-	GetUserRecipes(ctx context.Context, userID uuid.UUID) ([]*models.Recipe, error)
+	GetUserRecipes(ctx context.Context, userID uuid.UUID, page pagination.Page) ([]*models.Recipe, *pagination.Info, error)
 	GetProfileHistory(ctx context.Context, userID uuid.UUID) ([]*types.ProfileHistory, error)
-	GetUserProfile(username string) (*models.UserProfile, error)
+	GetUserProfile(ctx context.Context, username string) (*PublicProfile, error)
+	GetPublicRecipes(ctx context.Context, username string, page pagination.Page) ([]*models.Recipe, *pagination.Info, error)
 }
 
 // IRecipeService defines the interface for recipe operations
 type IRecipeService interface {
 	CreateRecipe(ctx context.Context, recipe *models.Recipe) (*models.Recipe, error)
-	GetRecipe(ctx context.Context, id uuid.UUID) (*models.Recipe, error)
-	UpdateRecipe(ctx context.Context, id uuid.UUID, recipe *models.Recipe) (*models.Recipe, error)
-	DeleteRecipe(ctx context.Context, id uuid.UUID) error
-	ListRecipes(ctx context.Context, userID *uuid.UUID) ([]*models.Recipe, error)
-	SearchRecipes(ctx context.Context, query string) ([]*models.Recipe, error)
+	GetRecipe(ctx context.Context, viewerID, id uuid.UUID) (*models.Recipe, error)
+	UpdateRecipe(ctx context.Context, userID, id uuid.UUID, recipe *models.Recipe) (*models.Recipe, error)
+	DeleteRecipe(ctx context.Context, userID, id uuid.UUID) error
+	ListRecipes(ctx context.Context, viewerID uuid.UUID, ownerID *uuid.UUID, page pagination.Page) ([]*models.Recipe, *pagination.Info, error)
+	SearchRecipes(ctx context.Context, viewerID uuid.UUID, query string, page pagination.Page) ([]*models.Recipe, *pagination.Info, error)
+	FacetedSearch(ctx context.Context, search *RecipeSearch, page pagination.Page) (*RecipeSearchResult, error)
+	SimilarRecipes(ctx context.Context, id uuid.UUID, search *RecipeSearch, excludeForks bool, page pagination.Page) ([]*models.Recipe, *pagination.Info, error)
 	FavoriteRecipe(ctx context.Context, userID, recipeID uuid.UUID) error
 	UnfavoriteRecipe(ctx context.Context, userID, recipeID uuid.UUID) error
-	GetFavoriteRecipes(ctx context.Context, userID uuid.UUID) ([]*models.Recipe, error)
+	GetFavoriteRecipes(ctx context.Context, userID uuid.UUID, page pagination.Page) ([]*models.Recipe, *pagination.Info, error)
+	FavoriteRecipeIDs(ctx context.Context, userID uuid.UUID, recipeIDs []uuid.UUID) (map[uuid.UUID]bool, error)
 }
 
 // IFeedbackService defines the interface for feedback operations
 type IFeedbackService interface {
 	CreateFeedback(ctx context.Context, req *types.CreateFeedbackRequest, userID *uuid.UUID) (*models.Feedback, error)
 	GetFeedback(ctx context.Context, id uuid.UUID) (*models.Feedback, error)
-	ListFeedback(ctx context.Context, filters *models.FeedbackFilters) ([]*models.Feedback, error)
+	ListFeedback(ctx context.Context, filters *models.FeedbackFilters, page pagination.Page) ([]*models.Feedback, *pagination.Info, error)
 	UpdateFeedbackStatus(ctx context.Context, id uuid.UUID, status string, adminNotes string) error
 }
 
@@ -69,3 +77,140 @@ type IEmailService interface {
 	SendVerificationEmail(user *models.User, token string) error
 	SendWelcomeEmail(user *models.User) error
 }
+
+// INutritionService defines the interface for nutrition calculation
+type INutritionService interface {
+	CalculateNutrition(ctx context.Context, ingredients []string, servings int) (*RecipeNutrition, error)
+	CalculateRecipeNutrition(ctx context.Context, recipe *models.Recipe) (*RecipeNutrition, error)
+	ImportFoods(ctx context.Context, foods []models.Food) (int, error)
+}
+
+// ISubstitutionService defines the interface for ingredient substitutions
+type ISubstitutionService interface {
+	SuggestSubstitutions(ctx context.Context, recipe *models.Recipe, ingredient, constraint string) ([]SubstitutionSuggestion, error)
+	ApplySubstitutions(ctx context.Context, recipe *models.Recipe, choices []SubstitutionChoice) (*RecipeDraft, error)
+}
+
+// IMealPlanService defines the interface for meal planning
+type IMealPlanService interface {
+	CreateMealPlan(ctx context.Context, userID uuid.UUID, req *types.CreateMealPlanRequest) (*models.MealPlan, error)
+	GetMealPlan(ctx context.Context, userID, id uuid.UUID) (*models.MealPlan, error)
+	ListMealPlans(ctx context.Context, userID uuid.UUID) ([]*models.MealPlan, error)
+	UpdateMealPlan(ctx context.Context, userID, id uuid.UUID, req *types.UpdateMealPlanRequest) (*models.MealPlan, error)
+	DeleteMealPlan(ctx context.Context, userID, id uuid.UUID) error
+	SetEntry(ctx context.Context, userID, planID uuid.UUID, req *types.MealPlanEntryRequest) (*models.MealPlanEntry, error)
+	DeleteEntry(ctx context.Context, userID, planID, entryID uuid.UUID) error
+	AutoPlan(ctx context.Context, userID, planID uuid.UUID, req *types.AutoPlanRequest) (*models.MealPlan, error)
+	CountPlannedMeals(ctx context.Context, userID uuid.UUID, from, to time.Time) (int, error)
+}
+
+// IShoppingListService defines the interface for shopping lists
+type IShoppingListService interface {
+	CreateShoppingList(ctx context.Context, userID uuid.UUID, req *types.CreateShoppingListRequest, system units.System) (*models.ShoppingList, error)
+	GetShoppingList(ctx context.Context, userID, id uuid.UUID) (*models.ShoppingList, error)
+	ListShoppingLists(ctx context.Context, userID uuid.UUID) ([]*models.ShoppingList, error)
+	DeleteShoppingList(ctx context.Context, userID, id uuid.UUID) error
+	SetItemChecked(ctx context.Context, userID, listID, itemID uuid.UUID, checked bool) (*models.ShoppingListItem, error)
+	SubtractPantry(ctx context.Context, userID, listID uuid.UUID, pantry []string, system units.System) (*models.ShoppingList, error)
+}
+
+// IRecommendationService defines the interface for recipe recommendations
+type IRecommendationService interface {
+	Recommend(ctx context.Context, userID uuid.UUID, limit int) ([]Recommendation, error)
+}
+
+// IReviewService defines the interface for recipe ratings and reviews
+type IReviewService interface {
+	CreateReview(ctx context.Context, userID, recipeID uuid.UUID, req *types.CreateReviewRequest) (*models.RecipeReview, error)
+	UpdateReview(ctx context.Context, userID, reviewID uuid.UUID, req *types.UpdateReviewRequest) (*models.RecipeReview, error)
+	DeleteReview(ctx context.Context, userID, reviewID uuid.UUID) error
+	ListReviews(ctx context.Context, userID, recipeID uuid.UUID, page pagination.Page) ([]*models.RecipeReview, *pagination.Info, error)
+	RatingSummary(ctx context.Context, recipeID uuid.UUID) (*RatingSummary, error)
+}
+
+// ICommentService defines the interface for recipe comments
+type ICommentService interface {
+	CreateComment(ctx context.Context, userID, recipeID uuid.UUID, req *types.CreateCommentRequest) (*models.RecipeComment, error)
+	UpdateComment(ctx context.Context, userID, commentID uuid.UUID, req *types.UpdateCommentRequest) (*models.RecipeComment, error)
+	DeleteComment(ctx context.Context, userID, commentID uuid.UUID, moderator bool) error
+	ListComments(ctx context.Context, userID, recipeID uuid.UUID, page pagination.Page) ([]*models.RecipeComment, *pagination.Info, error)
+	CommentHistory(ctx context.Context, userID, commentID uuid.UUID) ([]models.RecipeCommentEdit, error)
+}
+
+// INotificationService defines the interface for user notifications
+type INotificationService interface {
+	ListNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, page pagination.Page) ([]*models.Notification, *pagination.Info, error)
+	UnreadCount(ctx context.Context, userID uuid.UUID) (int64, error)
+	MarkRead(ctx context.Context, userID, id uuid.UUID) error
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+// IDashboardService defines the interface for dashboard statistics
+type IDashboardService interface {
+	GetStats(ctx context.Context, userID uuid.UUID) (*DashboardStats, error)
+	InvalidateStats(ctx context.Context, userID uuid.UUID) error
+	RecentFavorites(ctx context.Context, userID uuid.UUID, limit int) ([]*models.Recipe, error)
+}
+
+// ICollectionService defines the interface for recipe collections
+type ICollectionService interface {
+	ListCollections(ctx context.Context, userID uuid.UUID) ([]*models.Collection, error)
+	CreateCollection(ctx context.Context, userID uuid.UUID, req *types.CreateCollectionRequest) (*models.Collection, error)
+	GetCollection(ctx context.Context, viewerID, id uuid.UUID) (*models.Collection, error)
+	GetSharedCollection(ctx context.Context, token string) (*models.Collection, error)
+	UpdateCollection(ctx context.Context, userID, id uuid.UUID, req *types.UpdateCollectionRequest) (*models.Collection, error)
+	DeleteCollection(ctx context.Context, userID, id uuid.UUID) error
+	AddRecipes(ctx context.Context, userID, id uuid.UUID, recipeIDs []uuid.UUID) (int, error)
+	RemoveRecipes(ctx context.Context, userID, id uuid.UUID, recipeIDs []uuid.UUID) (int, error)
+	ReorderRecipes(ctx context.Context, userID, id uuid.UUID, recipeIDs []uuid.UUID) error
+}
+
+// IFollowService defines the interface for the follow graph
+type IFollowService interface {
+	Follow(ctx context.Context, followerID uuid.UUID, username string) error
+	Unfollow(ctx context.Context, followerID uuid.UUID, username string) error
+	ListFollowers(ctx context.Context, viewerID uuid.UUID, username string, page pagination.Page) ([]*FollowUser, *pagination.Info, error)
+	ListFollowing(ctx context.Context, viewerID uuid.UUID, username string, page pagination.Page) ([]*FollowUser, *pagination.Info, error)
+}
+
+// IActivityService defines the interface for activity feeds
+type IActivityService interface {
+	Feed(ctx context.Context, userID uuid.UUID, page pagination.Page) ([]*models.Activity, *pagination.Info, error)
+	ListUserActivity(ctx context.Context, viewerID uuid.UUID, username string, page pagination.Page) ([]*models.Activity, *pagination.Info, error)
+}
+
+// IShareService defines the interface for recipe share links
+type IShareService interface {
+	CreateRecipeShare(ctx context.Context, userID, recipeID uuid.UUID, expiresIn time.Duration) (*models.RecipeShare, error)
+	ListRecipeShares(ctx context.Context, userID, recipeID uuid.UUID) ([]*models.RecipeShare, error)
+	RevokeRecipeShare(ctx context.Context, userID, recipeID, shareID uuid.UUID) error
+	GetSharedRecipe(ctx context.Context, token string) (*models.Recipe, error)
+}
+
+// ITrendingService defines the interface for trending and featured recipes
+type ITrendingService interface {
+	RefreshScores(ctx context.Context, now time.Time) (int, error)
+	Trending(ctx context.Context, viewerID uuid.UUID, page pagination.Page) ([]*models.Recipe, *pagination.Info, error)
+	Featured(ctx context.Context) ([]*models.Recipe, error)
+	InvalidateFeatured(ctx context.Context) error
+	ListCurations(ctx context.Context) ([]*models.FeaturedCuration, error)
+	SetCuration(ctx context.Context, adminID, recipeID uuid.UUID, req *types.SetCurationRequest) (*models.FeaturedCuration, error)
+	DeleteCuration(ctx context.Context, recipeID uuid.UUID) error
+}
+
+// IAnalyticsService defines the interface for recipe view counting and
+// creator analytics
+type IAnalyticsService interface {
+	RecordView(ctx context.Context, recipeID, viewerID uuid.UUID) error
+	FlushViews(ctx context.Context, now time.Time) (int, error)
+	CreatorAnalytics(ctx context.Context, userID, recipeID uuid.UUID, days int, now time.Time) (*CreatorAnalytics, error)
+}
+
+// IPantryService defines the interface for pantry operations
+type IPantryService interface {
+	ListPantryItems(ctx context.Context, userID uuid.UUID) ([]models.PantryItem, error)
+	AddPantryItem(ctx context.Context, userID uuid.UUID, req *types.CreatePantryItemRequest) (*models.PantryItem, error)
+	UpdatePantryItem(ctx context.Context, userID, id uuid.UUID, req *types.UpdatePantryItemRequest) (*models.PantryItem, error)
+	DeletePantryItem(ctx context.Context, userID, id uuid.UUID) error
+	CookableRecipes(ctx context.Context, userID uuid.UUID, query string, maxMissing, limit int) ([]CookableRecipe, error)
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
 	"github.com/pgvector/pgvector-go"
 	"github.com/redis/go-redis/v9"
 )
@@ -171,7 +172,9 @@ type RecipeDraft struct {
 	Carbs        float64         `json:"carbs"`
 	Fat          float64         `json:"fat"`
 	UserID       string          `json:"user_id"`
+	ForkedFromID string          `json:"forked_from_id,omitempty"`
 	Embedding    pgvector.Vector `json:"embedding"`
+	models.Micronutrients
 }
 
 // SaveDraft saves a recipe draft to Redis
@@ -305,14 +308,14 @@ func (s *LLMService) generateRecipeAttempt(query string, dietaryPrefs, allergens
     "category": "One of: Main Course, Dessert, Snack, Appetizer, Breakfast, Lunch, Dinner, Side Dish, Beverage, Soup, Salad, Bread, Pasta, Seafood, Meat, Vegetarian, Vegan, Gluten-Free",
     "cuisine": "One of: Italian, French, Chinese, Japanese, Thai, Indian, Mexican, Mediterranean, American, British, German, Korean, Spanish, Brazilian, Moroccan, Fusion, or Other",
     "ingredients": [
-        "2 cups flour",
-        "1 cup sugar",
+        "250 g flour",
+        "200 g sugar",
         "3 eggs"
     ],
     "instructions": [
         "Step 1: Mix the dry ingredients",
         "Step 2: Add the wet ingredients",
-        "Step 3: Bake at 350°F for 30 minutes"
+        "Step 3: Bake at 180°C for 30 minutes"
     ],
     "prep_time": "Preparation time",
     "cook_time": "Cooking time",
@@ -325,6 +328,7 @@ func (s *LLMService) generateRecipeAttempt(query string, dietaryPrefs, allergens
 }
 
 Note: The calories, protein, carbs, and fat fields must be numbers, not strings.
+Use metric measurements (g, kg, ml, l) for ingredient quantities and Celsius for temperatures; teaspoons and tablespoons are fine for small amounts. Quantities are converted to the reader's preferred unit system when displayed.
 The category field MUST be one of the listed categories above.
 The cuisine field MUST be one of the listed cuisines above.`,
 		},
@@ -497,6 +501,57 @@ func (s *LLMService) CalculateMacros(ingredients []string) (*Macros, error) {
 		},
 	}
 
+	content, err := s.completeJSON(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	var macros Macros
+	if err := json.Unmarshal([]byte(content), &macros); err != nil {
+		return nil, fmt.Errorf("failed to parse macros: %w", err)
+	}
+
+	return &macros, nil
+}
+
+// SuggestSubstitutions asks the LLM for substitutes for ingredients the local
+// substitution table does not cover. When a constraint is given the LLM only
+// returns ingredients that break it.
+func (s *LLMService) SuggestSubstitutions(recipeName string, ingredients []string, constraint string) ([]SubstitutionSuggestion, error) {
+	prompt := fmt.Sprintf("Recipe: %s\nIngredients:\n%s", recipeName, strings.Join(ingredients, "\n"))
+	if constraint != "" {
+		prompt += fmt.Sprintf("\nConstraint: %s\nOnly include ingredients that break the constraint, and only substitutes that satisfy it.", constraint)
+	}
+	messages := []Message{
+		{
+			Role: "system",
+			Content: `You are a professional chef. Suggest up to three ingredient substitutes for each ingredient, best first. Respond only with JSON like
+{"suggestions":[{"ingredient":"2 eggs","substitutes":[{"name":"aquafaba","ratio":"1 egg = 3 tbsp aquafaba","notes":"How the swap changes flavor, texture or method","replacement":"6 tbsp aquafaba"}]}]}
+The ingredient field must repeat the ingredient line exactly as given. Use metric measurements in replacements.`,
+		},
+		{
+			Role:    "user",
+			Content: prompt,
+		},
+	}
+
+	content, err := s.completeJSON(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Suggestions []SubstitutionSuggestion `json:"suggestions"`
+	}
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse substitutions: %w", err)
+	}
+	return result.Suggestions, nil
+}
+
+// completeJSON sends a chat completion request in JSON mode and returns the
+// content of the first choice
+func (s *LLMService) completeJSON(messages []Message) (string, error) {
 	reqBody := Request{
 		Model:    "deepseek-chat",
 		Messages: messages,
@@ -507,12 +562,12 @@ func (s *LLMService) CalculateMacros(ingredients []string) (*Macros, error) {
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	req, err := http.NewRequest("POST", s.apiURL, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -521,17 +576,17 @@ func (s *LLMService) CalculateMacros(ingredients []string) (*Macros, error) {
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return "", fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, readErr := io.ReadAll(resp.Body)
 		if readErr != nil {
-			return nil, fmt.Errorf("failed to read error response: %w", readErr)
+			return "", fmt.Errorf("failed to read error response: %w", readErr)
 		}
 		log.Printf("API request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
 	}
 
 	var result struct {
@@ -543,19 +598,14 @@ func (s *LLMService) CalculateMacros(ingredients []string) (*Macros, error) {
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	if len(result.Choices) == 0 {
-		return nil, fmt.Errorf("no response from API")
-	}
-
-	var macros Macros
-	if err := json.Unmarshal([]byte(result.Choices[0].Message.Content), &macros); err != nil {
-		return nil, fmt.Errorf("failed to parse macros: %w", err)
+		return "", fmt.Errorf("no response from API")
 	}
 
-	return &macros, nil
+	return result.Choices[0].Message.Content, nil
 }
 
 // GenerateRecipesBatch generates multiple recipes in a single batch
@@ -570,14 +620,14 @@ func (s *LLMService) GenerateRecipesBatch(prompts []string) ([]string, error) {
     "description": "Brief description of the recipe",
     "category": "One of: Main Course, Dessert, Snack, Appetizer, Breakfast, Lunch, Dinner, Side Dish, Beverage, Soup, Salad, Bread, Pasta, Seafood, Meat, Vegetarian, Vegan, Gluten-Free",
     "ingredients": [
-        "2 cups flour",
-        "1 cup sugar",
+        "250 g flour",
+        "200 g sugar",
         "3 eggs"
     ],
     "instructions": [
         "Step 1: Mix the dry ingredients",
         "Step 2: Add the wet ingredients",
-        "Step 3: Bake at 350°F for 30 minutes"
+        "Step 3: Bake at 180°C for 30 minutes"
     ],
     "prep_time": "Preparation time",
     "cook_time": "Cooking time",
@@ -585,6 +635,8 @@ func (s *LLMService) GenerateRecipesBatch(prompts []string) ([]string, error) {
     "difficulty": "Easy/Medium/Hard"
 }
 
+Use metric measurements (g, kg, ml, l) for ingredient quantities and Celsius for temperatures; teaspoons and tablespoons are fine for small amounts.
+
 Please provide each recipe as a separate JSON object in an array.`,
 		},
 	}
@@ -0,0 +1,597 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"gorm.io/gorm"
+)
+
+// dateLayout is the format used for meal plan dates in requests
+const dateLayout = "2006-01-02"
+
+// autoPlanCandidateLimit caps how many recent recipes are considered when
+// filling a plan, in addition to favorites and search results
+const autoPlanCandidateLimit = 200
+
+var (
+	// ErrMealPlanNotFound is returned when a plan does not exist or belongs to
+	// another user
+	ErrMealPlanNotFound = errors.New("meal plan not found")
+	// ErrInvalidMealPlan is returned for invalid dates, slots or recipes
+	ErrInvalidMealPlan = errors.New("invalid meal plan")
+	// ErrNoMealCandidates is returned when no recipe suits the user's
+	// preferences and allergens
+	ErrNoMealCandidates = errors.New("no recipes match your preferences")
+)
+
+// slotCalorieShare is the share of daily calories each meal slot receives
+// when a plan is filled automatically
+var slotCalorieShare = map[string]float64{
+	models.MealSlotBreakfast: 0.25,
+	models.MealSlotLunch:     0.35,
+	models.MealSlotDinner:    0.40,
+	models.MealSlotSnack:     0.10,
+}
+
+// MealPlanDay summarizes one day of a meal plan
+type MealPlanDay struct {
+	Date    string                 `json:"date"`
+	Entries []models.MealPlanEntry `json:"entries"`
+	Totals  Macros                 `json:"totals"`
+}
+
+// MealPlanService manages meal plans
+type MealPlanService struct {
+	db            *gorm.DB
+	recipeService IRecipeService
+}
+
+// Ensure MealPlanService implements IMealPlanService
+var _ IMealPlanService = (*MealPlanService)(nil)
+
+// NewMealPlanService creates a new MealPlanService instance
+func NewMealPlanService(db *gorm.DB, recipeService IRecipeService) *MealPlanService {
+	return &MealPlanService{
+		db:            db,
+		recipeService: recipeService,
+	}
+}
+
+// CreateMealPlan creates a meal plan with optional initial entries
+func (s *MealPlanService) CreateMealPlan(ctx context.Context, userID uuid.UUID, req *types.CreateMealPlanRequest) (*models.MealPlan, error) {
+	start, err := parsePlanDate(req.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	plan := &models.MealPlan{
+		UserID:        userID,
+		Name:          req.Name,
+		StartDate:     start,
+		Days:          req.Days,
+		CalorieTarget: req.CalorieTarget,
+		ProteinTarget: req.ProteinTarget,
+		CarbsTarget:   req.CarbsTarget,
+		FatTarget:     req.FatTarget,
+	}
+	if plan.Days == 0 {
+		plan.Days = 7
+	}
+
+	entries, err := s.buildEntries(ctx, plan, req.Entries)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Entries").Create(plan).Error; err != nil {
+			return err
+		}
+		for i := range entries {
+			entries[i].MealPlanID = plan.ID
+		}
+		if len(entries) > 0 {
+			return tx.Create(&entries).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create meal plan: %w", err)
+	}
+	return s.GetMealPlan(ctx, userID, plan.ID)
+}
+
+// GetMealPlan returns a plan with its entries and their recipes. Entries
+// whose recipes their authors have since made private keep no recipe.
+func (s *MealPlanService) GetMealPlan(ctx context.Context, userID, id uuid.UUID) (*models.MealPlan, error) {
+	var plan models.MealPlan
+	err := s.db.WithContext(ctx).
+		Preload("Entries", func(db *gorm.DB) *gorm.DB {
+			return db.Order("date ASC")
+		}).
+		Preload("Entries.Recipe", VisibleRecipes(userID)).
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&plan).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMealPlanNotFound
+		}
+		return nil, fmt.Errorf("failed to get meal plan: %w", err)
+	}
+	sortEntries(plan.Entries)
+	return &plan, nil
+}
+
+// ListMealPlans returns the user's plans, most recent first
+func (s *MealPlanService) ListMealPlans(ctx context.Context, userID uuid.UUID) ([]*models.MealPlan, error) {
+	var plans []*models.MealPlan
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("start_date DESC").Find(&plans).Error; err != nil {
+		return nil, fmt.Errorf("failed to list meal plans: %w", err)
+	}
+	return plans, nil
+}
+
+// UpdateMealPlan updates a plan's details, replacing its entries when given
+func (s *MealPlanService) UpdateMealPlan(ctx context.Context, userID, id uuid.UUID, req *types.UpdateMealPlanRequest) (*models.MealPlan, error) {
+	plan, err := s.GetMealPlan(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		plan.Name = *req.Name
+	}
+	if req.StartDate != nil {
+		start, err := parsePlanDate(*req.StartDate)
+		if err != nil {
+			return nil, err
+		}
+		plan.StartDate = start
+	}
+	if req.Days != nil {
+		plan.Days = *req.Days
+	}
+	if req.CalorieTarget != nil {
+		plan.CalorieTarget = *req.CalorieTarget
+	}
+	if req.ProteinTarget != nil {
+		plan.ProteinTarget = *req.ProteinTarget
+	}
+	if req.CarbsTarget != nil {
+		plan.CarbsTarget = *req.CarbsTarget
+	}
+	if req.FatTarget != nil {
+		plan.FatTarget = *req.FatTarget
+	}
+
+	var entries []models.MealPlanEntry
+	if req.Entries != nil {
+		if entries, err = s.buildEntries(ctx, plan, *req.Entries); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(plan).Select("name", "start_date", "days", "calorie_target", "protein_target", "carbs_target", "fat_target").Updates(plan).Error; err != nil {
+			return err
+		}
+		if req.Entries != nil {
+			if err := tx.Where("meal_plan_id = ?", plan.ID).Delete(&models.MealPlanEntry{}).Error; err != nil {
+				return err
+			}
+			for i := range entries {
+				entries[i].MealPlanID = plan.ID
+			}
+			if len(entries) > 0 {
+				return tx.Create(&entries).Error
+			}
+			return nil
+		}
+		// Drop entries that fall outside a moved or shortened plan
+		return tx.Where("meal_plan_id = ? AND (date < ? OR date > ?)", plan.ID, plan.StartDate, plan.EndDate()).
+			Delete(&models.MealPlanEntry{}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update meal plan: %w", err)
+	}
+	return s.GetMealPlan(ctx, userID, id)
+}
+
+// DeleteMealPlan deletes a plan
+func (s *MealPlanService) DeleteMealPlan(ctx context.Context, userID, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&models.MealPlan{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete meal plan: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrMealPlanNotFound
+	}
+	return nil
+}
+
+// SetEntry places a recipe in a slot, replacing whatever was there
+func (s *MealPlanService) SetEntry(ctx context.Context, userID, planID uuid.UUID, req *types.MealPlanEntryRequest) (*models.MealPlanEntry, error) {
+	plan, err := s.GetMealPlan(ctx, userID, planID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.buildEntries(ctx, plan, []types.MealPlanEntryRequest{*req})
+	if err != nil {
+		return nil, err
+	}
+	entry := entries[0]
+	entry.MealPlanID = plan.ID
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("meal_plan_id = ? AND date = ? AND slot = ?", plan.ID, entry.Date, entry.Slot).
+			Delete(&models.MealPlanEntry{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&entry).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set meal plan entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// DeleteEntry removes an entry from a plan
+func (s *MealPlanService) DeleteEntry(ctx context.Context, userID, planID, entryID uuid.UUID) error {
+	if _, err := s.GetMealPlan(ctx, userID, planID); err != nil {
+		return err
+	}
+	result := s.db.WithContext(ctx).Where("id = ? AND meal_plan_id = ?", entryID, planID).Delete(&models.MealPlanEntry{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete meal plan entry: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrMealPlanNotFound
+	}
+	return nil
+}
+
+// AutoPlan fills a plan's empty slots, or all slots when overwriting, with
+// favorites and other recipes that suit the user's preferences, allergens and
+// calorie and macro targets
+func (s *MealPlanService) AutoPlan(ctx context.Context, userID, planID uuid.UUID, req *types.AutoPlanRequest) (*models.MealPlan, error) {
+	plan, err := s.GetMealPlan(ctx, userID, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := LoadDietaryFilter(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	filter.Add(req.DietaryPreferences, req.Allergens)
+
+	candidates, err := s.autoPlanCandidates(ctx, userID, req.Query, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, ErrNoMealCandidates
+	}
+
+	targets := Macros{
+		Calories: overrideTarget(req.CalorieTarget, plan.CalorieTarget),
+		Protein:  overrideTarget(req.ProteinTarget, plan.ProteinTarget),
+		Carbs:    overrideTarget(req.CarbsTarget, plan.CarbsTarget),
+		Fat:      overrideTarget(req.FatTarget, plan.FatTarget),
+	}
+	slots := req.Slots
+	if len(slots) == 0 {
+		slots = []string{models.MealSlotBreakfast, models.MealSlotLunch, models.MealSlotDinner}
+	}
+
+	entries := planMeals(plan, candidates, slots, targets, req.Overwrite)
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, entry := range entries {
+			if err := tx.Where("meal_plan_id = ? AND date = ? AND slot = ?", plan.ID, entry.Date, entry.Slot).
+				Delete(&models.MealPlanEntry{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(entries) > 0 {
+			return tx.Create(&entries).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save meal plan: %w", err)
+	}
+	return s.GetMealPlan(ctx, userID, planID)
+}
+
+// CountPlannedMeals counts the meals the user has planned between two dates,
+// inclusive
+func (s *MealPlanService) CountPlannedMeals(ctx context.Context, userID uuid.UUID, from, to time.Time) (int, error) {
+	var count int64
+	err := s.db.WithContext(ctx).Model(&models.MealPlanEntry{}).
+		Joins("JOIN meal_plans ON meal_plans.id = meal_plan_entries.meal_plan_id AND meal_plans.deleted_at IS NULL").
+		Where("meal_plans.user_id = ? AND meal_plan_entries.date BETWEEN ? AND ?", userID, from.Format(dateLayout), to.Format(dateLayout)).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count planned meals: %w", err)
+	}
+	return int(count), nil
+}
+
+// SummarizeMealPlan groups a plan's entries by day and totals the macros
+// for each day
+func SummarizeMealPlan(plan *models.MealPlan) []MealPlanDay {
+	days := make([]MealPlanDay, plan.Days)
+	for i := range days {
+		days[i] = MealPlanDay{
+			Date:    plan.StartDate.AddDate(0, 0, i).Format(dateLayout),
+			Entries: []models.MealPlanEntry{},
+		}
+	}
+	for _, entry := range plan.Entries {
+		i := int(entry.Date.Sub(plan.StartDate).Hours() / 24)
+		if i < 0 || i >= len(days) {
+			continue
+		}
+		days[i].Entries = append(days[i].Entries, entry)
+		if entry.Recipe != nil {
+			servings := float64(entry.Servings)
+			days[i].Totals = addMacros(days[i].Totals, Macros{
+				Calories: entry.Recipe.Calories * servings,
+				Protein:  entry.Recipe.Protein * servings,
+				Carbs:    entry.Recipe.Carbs * servings,
+				Fat:      entry.Recipe.Fat * servings,
+			})
+		}
+	}
+	for i := range days {
+		days[i].Totals = roundMacros(days[i].Totals)
+	}
+	return days
+}
+
+// buildEntries validates entry requests against the plan and checks that the
+// recipes exist
+func (s *MealPlanService) buildEntries(ctx context.Context, plan *models.MealPlan, reqs []types.MealPlanEntryRequest) ([]models.MealPlanEntry, error) {
+	entries := make([]models.MealPlanEntry, 0, len(reqs))
+	seen := make(map[string]bool)
+	for _, req := range reqs {
+		date, err := parsePlanDate(req.Date)
+		if err != nil {
+			return nil, err
+		}
+		if date.Before(plan.StartDate) || date.After(plan.EndDate()) {
+			return nil, fmt.Errorf("%w: %s is outside the plan", ErrInvalidMealPlan, req.Date)
+		}
+		if !isMealSlot(req.Slot) {
+			return nil, fmt.Errorf("%w: unknown slot %q", ErrInvalidMealPlan, req.Slot)
+		}
+		key := req.Date + "/" + req.Slot
+		if seen[key] {
+			return nil, fmt.Errorf("%w: %s %s is planned twice", ErrInvalidMealPlan, req.Date, req.Slot)
+		}
+		seen[key] = true
+
+		if _, err := s.recipeService.GetRecipe(ctx, plan.UserID, req.RecipeID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: recipe %s not found", ErrInvalidMealPlan, req.RecipeID)
+			}
+			return nil, err
+		}
+
+		servings := req.Servings
+		if servings == 0 {
+			servings = 1
+		}
+		entries = append(entries, models.MealPlanEntry{
+			Date:     date,
+			Slot:     req.Slot,
+			RecipeID: req.RecipeID,
+			Servings: servings,
+		})
+	}
+	return entries, nil
+}
+
+// mealCandidate is a recipe considered when filling a plan
+type mealCandidate struct {
+	recipe   *models.Recipe
+	favorite bool
+}
+
+func (s *MealPlanService) autoPlanCandidates(ctx context.Context, userID uuid.UUID, query string, filter *DietaryFilter) ([]mealCandidate, error) {
+	favorites, _, err := s.recipeService.GetFavoriteRecipes(ctx, userID, pagination.Page{Limit: autoPlanCandidateLimit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load favorites: %w", err)
+	}
+
+	var others []*models.Recipe
+	if query != "" {
+		if others, _, err = s.recipeService.SearchRecipes(ctx, userID, query, pagination.Page{Limit: autoPlanCandidateLimit}); err != nil {
+			return nil, fmt.Errorf("failed to search recipes: %w", err)
+		}
+	} else {
+		if err := s.db.WithContext(ctx).Scopes(ListedRecipes(userID)).Order("created_at DESC").Limit(autoPlanCandidateLimit).Find(&others).Error; err != nil {
+			return nil, fmt.Errorf("failed to load recipes: %w", err)
+		}
+	}
+
+	var candidates []mealCandidate
+	seen := make(map[uuid.UUID]bool)
+	add := func(recipes []*models.Recipe, favorite bool) {
+		for _, recipe := range recipes {
+			if seen[recipe.ID] || !filter.Allows(recipe) {
+				continue
+			}
+			seen[recipe.ID] = true
+			candidates = append(candidates, mealCandidate{recipe: recipe, favorite: favorite})
+		}
+	}
+	add(favorites, true)
+	add(others, false)
+	return candidates, nil
+}
+
+// planMeals picks a recipe for every slot that needs one. Each pick scores
+// favorites, recipes that suit the slot and recipes close to the slot's share
+// of the daily targets, and avoids repeating recipes.
+func planMeals(plan *models.MealPlan, candidates []mealCandidate, slots []string, targets Macros, overwrite bool) []models.MealPlanEntry {
+	filled := make(map[string]bool)
+	uses := make(map[uuid.UUID]int)
+	for _, entry := range plan.Entries {
+		uses[entry.RecipeID]++
+		if !overwrite {
+			filled[entry.Date.Format(dateLayout)+"/"+entry.Slot] = true
+		}
+	}
+
+	var totalShare float64
+	for _, slot := range slots {
+		totalShare += slotCalorieShare[slot]
+	}
+
+	var entries []models.MealPlanEntry
+	for day := 0; day < plan.Days; day++ {
+		date := plan.StartDate.AddDate(0, 0, day)
+		usedToday := make(map[uuid.UUID]bool)
+		for _, slot := range slots {
+			if filled[date.Format(dateLayout)+"/"+slot] {
+				continue
+			}
+			share := slotCalorieShare[slot] / totalShare
+			slotTargets := scaleMacros(targets, share)
+
+			best, bestScore := -1, math.Inf(-1)
+			for i, candidate := range candidates {
+				if usedToday[candidate.recipe.ID] {
+					continue
+				}
+				score := scoreMealCandidate(candidate, slot, slotTargets) - 0.4*float64(uses[candidate.recipe.ID])
+				if score > bestScore {
+					best, bestScore = i, score
+				}
+			}
+			if best < 0 {
+				continue
+			}
+
+			recipe := candidates[best].recipe
+			uses[recipe.ID]++
+			usedToday[recipe.ID] = true
+			entries = append(entries, models.MealPlanEntry{
+				MealPlanID: plan.ID,
+				Date:       date,
+				Slot:       slot,
+				RecipeID:   recipe.ID,
+				Servings:   1,
+			})
+		}
+	}
+	return entries
+}
+
+// scoreMealCandidate rates how well a recipe fits a slot
+func scoreMealCandidate(candidate mealCandidate, slot string, targets Macros) float64 {
+	score := 0.0
+	if candidate.favorite {
+		score += 0.3
+	}
+	if recipeSuitsSlot(candidate.recipe, slot) {
+		score += 0.3
+	}
+
+	// Closeness to each target, from 1 when exact down to 0 when off by the
+	// whole target or more
+	recipe := candidate.recipe
+	var fit float64
+	var counted int
+	for _, pair := range [][2]float64{
+		{recipe.Calories, targets.Calories},
+		{recipe.Protein, targets.Protein},
+		{recipe.Carbs, targets.Carbs},
+		{recipe.Fat, targets.Fat},
+	} {
+		if pair[1] <= 0 {
+			continue
+		}
+		counted++
+		fit += math.Max(0, 1-math.Abs(pair[0]-pair[1])/pair[1])
+	}
+	if counted > 0 {
+		score += fit / float64(counted)
+	} else {
+		score += 0.5
+	}
+	return score
+}
+
+// recipeSuitsSlot reports whether a recipe's category, tags or name mention
+// the meal slot
+func recipeSuitsSlot(recipe *models.Recipe, slot string) bool {
+	keywords := []string{slot}
+	switch slot {
+	case models.MealSlotDinner:
+		keywords = append(keywords, "main course", "main")
+	case models.MealSlotSnack:
+		keywords = append(keywords, "appetizer", "dessert")
+	case models.MealSlotLunch:
+		keywords = append(keywords, "salad", "soup", "sandwich")
+	}
+	fields := append([]string{recipe.Category, recipe.Name}, recipe.Tags...)
+	for _, field := range fields {
+		f := strings.ToLower(field)
+		for _, keyword := range keywords {
+			if strings.Contains(f, keyword) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func overrideTarget(override *float64, target float64) float64 {
+	if override != nil {
+		return *override
+	}
+	return target
+}
+
+func parsePlanDate(value string) (time.Time, error) {
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: dates must use YYYY-MM-DD", ErrInvalidMealPlan)
+	}
+	return date, nil
+}
+
+func isMealSlot(slot string) bool {
+	for _, s := range models.MealSlots {
+		if s == slot {
+			return true
+		}
+	}
+	return false
+}
+
+// sortEntries orders entries by date and then by meal slot
+func sortEntries(entries []models.MealPlanEntry) {
+	order := make(map[string]int, len(models.MealSlots))
+	for i, slot := range models.MealSlots {
+		order[slot] = i
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Date.Equal(entries[j].Date) {
+			return entries[i].Date.Before(entries[j].Date)
+		}
+		return order[entries[i].Slot] < order[entries[j].Slot]
+	})
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMealRecipe(name, category string, calories float64, ingredients ...string) *models.Recipe {
+	return &models.Recipe{
+		ID:          uuid.New(),
+		Name:        name,
+		Category:    category,
+		Calories:    calories,
+		Ingredients: models.JSONBStringArray(ingredients),
+	}
+}
+
+func TestDietaryFilter(t *testing.T) {
+	omelette := testMealRecipe("Omelette", "Breakfast", 300, "3 eggs", "30 g cheddar cheese")
+	salad := testMealRecipe("Salad", "Lunch", 250, "100 g lettuce", "1 tbsp olive oil")
+	shrimp := testMealRecipe("Shrimp Tacos", "Dinner", 500, "200 g shrimp", "4 corn tortillas")
+
+	vegan := NewDietaryFilter([]string{"vegan"}, nil)
+	assert.False(t, vegan.Allows(omelette))
+	assert.True(t, vegan.Allows(salad))
+
+	allergies := NewDietaryFilter(nil, []string{"shellfish"})
+	assert.True(t, allergies.Allows(omelette))
+	assert.False(t, allergies.Allows(shrimp))
+
+	custom := NewDietaryFilter(nil, []string{"Lettuce"})
+	assert.False(t, custom.Allows(salad))
+
+	assert.True(t, NewDietaryFilter(nil, nil).Empty())
+	assert.True(t, NewDietaryFilter([]string{"unknown diet"}, nil).Allows(omelette))
+}
+
+func TestPlanMealsFillsEmptySlots(t *testing.T) {
+	start := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	porridge := testMealRecipe("Porridge", "Breakfast", 400, "80 g oats")
+	soup := testMealRecipe("Lentil Soup", "Soup", 600, "200 g lentils")
+	stew := testMealRecipe("Bean Stew", "Main Course", 800, "400 g beans")
+	pasta := testMealRecipe("Pasta", "Main Course", 750, "200 g pasta")
+
+	plan := &models.MealPlan{
+		ID:            uuid.New(),
+		StartDate:     start,
+		Days:          2,
+		CalorieTarget: 2000,
+		Entries: []models.MealPlanEntry{
+			{Date: start, Slot: models.MealSlotDinner, RecipeID: pasta.ID},
+		},
+	}
+	candidates := []mealCandidate{{recipe: porridge}, {recipe: soup}, {recipe: stew}, {recipe: pasta, favorite: true}}
+	slots := []string{models.MealSlotBreakfast, models.MealSlotLunch, models.MealSlotDinner}
+
+	entries := planMeals(plan, candidates, slots, Macros{Calories: plan.CalorieTarget}, false)
+	require.Len(t, entries, 5, "the planned dinner on day one is kept")
+
+	bySlot := make(map[string]uuid.UUID)
+	for _, entry := range entries {
+		bySlot[entry.Date.Format(dateLayout)+"/"+entry.Slot] = entry.RecipeID
+	}
+	assert.Equal(t, porridge.ID, bySlot["2024-03-04/breakfast"])
+	assert.Equal(t, soup.ID, bySlot["2024-03-04/lunch"])
+	_, replaced := bySlot["2024-03-04/dinner"]
+	assert.False(t, replaced)
+
+	// No recipe appears twice on the same day
+	for day := 0; day < plan.Days; day++ {
+		date := start.AddDate(0, 0, day).Format(dateLayout)
+		seen := make(map[uuid.UUID]bool)
+		for _, slot := range slots {
+			id, ok := bySlot[date+"/"+slot]
+			if !ok {
+				continue
+			}
+			assert.False(t, seen[id], "%s repeats a recipe", date)
+			seen[id] = true
+		}
+	}
+
+	entries = planMeals(plan, candidates, slots, Macros{Calories: plan.CalorieTarget}, true)
+	assert.Len(t, entries, 6)
+}
+
+func TestScoreMealCandidatePrefersTargetFit(t *testing.T) {
+	targets := Macros{Calories: 800}
+	close := mealCandidate{recipe: testMealRecipe("Stew", "Main Course", 780)}
+	far := mealCandidate{recipe: testMealRecipe("Cake", "Main Course", 1600)}
+	assert.Greater(t, scoreMealCandidate(close, models.MealSlotDinner, targets), scoreMealCandidate(far, models.MealSlotDinner, targets))
+
+	favorite := mealCandidate{recipe: far.recipe, favorite: true}
+	assert.Greater(t, scoreMealCandidate(favorite, models.MealSlotDinner, targets), scoreMealCandidate(far, models.MealSlotDinner, targets))
+}
+
+func TestSummarizeMealPlan(t *testing.T) {
+	start := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	recipe := &models.Recipe{Calories: 500, Protein: 20.25}
+	plan := &models.MealPlan{
+		StartDate: start,
+		Days:      3,
+		Entries: []models.MealPlanEntry{
+			{Date: start.AddDate(0, 0, 1), Slot: models.MealSlotLunch, Servings: 2, Recipe: recipe},
+			{Date: start.AddDate(0, 0, 1), Slot: models.MealSlotDinner, Servings: 1, Recipe: recipe},
+		},
+	}
+
+	days := SummarizeMealPlan(plan)
+	require.Len(t, days, 3)
+	assert.Equal(t, "2024-03-05", days[1].Date)
+	assert.Len(t, days[1].Entries, 2)
+	assert.Equal(t, 1500.0, days[1].Totals.Calories)
+	assert.Equal(t, 60.8, days[1].Totals.Protein)
+	assert.Empty(t, days[0].Entries)
+}
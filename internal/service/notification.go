@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// ErrNotificationNotFound is returned when a notification does not exist or
+// belongs to another user
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// NotificationService lists and marks a user's notifications. Other services
+// create notifications with notify as part of their own writes.
+type NotificationService struct {
+	db *gorm.DB
+}
+
+// Ensure NotificationService implements INotificationService
+var _ INotificationService = (*NotificationService)(nil)
+
+// NewNotificationService creates a new NotificationService instance
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{db: db}
+}
+
+// ListNotifications returns one page of the user's notifications, newest
+// first, optionally only the unread ones
+func (s *NotificationService) ListNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, page pagination.Page) ([]*models.Notification, *pagination.Info, error) {
+	query := s.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("notifications.user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("notifications.read_at IS NULL")
+	}
+
+	var notifications []*models.Notification
+	if err := pagination.Keyset(query, page, "notifications.created_at", "notifications.id").
+		Select("notifications.*, user_profiles.username AS actor_username").
+		Joins("LEFT JOIN user_profiles ON user_profiles.user_id = notifications.actor_id AND user_profiles.deleted_at IS NULL").
+		Find(&notifications).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	notifications, info := pagination.Finish(notifications, page, notificationCursor)
+	return notifications, info, nil
+}
+
+// UnreadCount counts the user's unread notifications
+func (s *NotificationService) UnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead marks one of the user's notifications as read
+func (s *NotificationService) MarkRead(ctx context.Context, userID, id uuid.UUID) error {
+	var notification models.Notification
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotificationNotFound
+		}
+		return fmt.Errorf("failed to get notification: %w", err)
+	}
+	if notification.ReadAt != nil {
+		return nil
+	}
+	if err := s.db.WithContext(ctx).Model(&notification).Update("read_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+	return nil
+}
+
+// MarkAllRead marks all of the user's notifications as read, returning how
+// many were unread
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result := s.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// notify creates notifications, leaving out any addressed to their own actor
+// so that users are not told about what they did themselves
+func notify(tx *gorm.DB, notifications []models.Notification) error {
+	kept := make([]models.Notification, 0, len(notifications))
+	for _, n := range notifications {
+		if n.ActorID != nil && *n.ActorID == n.UserID {
+			continue
+		}
+		kept = append(kept, n)
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	if err := tx.Create(&kept).Error; err != nil {
+		return fmt.Errorf("failed to create notifications: %w", err)
+	}
+	return nil
+}
+
+// notificationCursor returns the keyset position of a notification
+func notificationCursor(n *models.Notification) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: n.CreatedAt, ID: n.ID}
+}
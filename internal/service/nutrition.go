@@ -0,0 +1,625 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/units"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Sources reported for each ingredient in a nutrition calculation
+const (
+	NutritionSourceDatabase  = "database"
+	NutritionSourceLLM       = "llm"
+	NutritionSourceSkipped   = "skipped"
+	NutritionSourceUnmatched = "unmatched"
+)
+
+// foodIndexTTL controls how long the in-memory food index is reused before it
+// is reloaded from the database
+const foodIndexTTL = 10 * time.Minute
+
+// llmConfidence is the confidence assigned to ingredients estimated by the LLM
+const llmConfidence = 0.4
+
+// IngredientNutrition describes how one ingredient line contributed to a
+// recipe's nutrition
+type IngredientNutrition struct {
+	Ingredient string  `json:"ingredient"`
+	Food       string  `json:"food,omitempty"`
+	Grams      float64 `json:"grams,omitempty"`
+	Source     string  `json:"source"`
+	Confidence float64 `json:"confidence"`
+	Nutrients
+}
+
+// RecipeNutrition is the result of a nutrition calculation
+type RecipeNutrition struct {
+	Servings    int                   `json:"servings"`
+	Total       Nutrients             `json:"total"`
+	PerServing  Nutrients             `json:"per_serving"`
+	ServingSize float64               `json:"serving_size_grams"`
+	Confidence  float64               `json:"confidence"`
+	Unmatched   []string              `json:"unmatched"`
+	LLMEstimate *Macros               `json:"llm_estimate,omitempty"`
+	Ingredients []IngredientNutrition `json:"ingredients"`
+}
+
+// Nutrients combines macros with the micronutrients tracked for a recipe.
+// Micronutrients only cover ingredients matched in the nutrient database.
+type Nutrients struct {
+	Macros
+	models.Micronutrients
+}
+
+// NutritionService calculates nutrition from the local nutrient database,
+// falling back to the LLM only for ingredients it cannot match
+type NutritionService struct {
+	db         *gorm.DB
+	llmService LLMServiceInterface
+
+	mu       sync.RWMutex
+	index    []foodEntry
+	loadedAt time.Time
+}
+
+// Ensure NutritionService implements INutritionService
+var _ INutritionService = (*NutritionService)(nil)
+
+// NewNutritionService creates a new NutritionService instance. llmService may
+// be nil, in which case unmatched ingredients are reported but not estimated.
+func NewNutritionService(db *gorm.DB, llmService LLMServiceInterface) *NutritionService {
+	return &NutritionService{
+		db:         db,
+		llmService: llmService,
+	}
+}
+
+// CalculateRecipeNutrition calculates nutrition for a stored recipe
+func (s *NutritionService) CalculateRecipeNutrition(ctx context.Context, recipe *models.Recipe) (*RecipeNutrition, error) {
+	return s.CalculateNutrition(ctx, recipe.Ingredients, recipe.Servings)
+}
+
+// CalculateNutrition calculates total and per-serving macros for a list of
+// ingredient lines
+func (s *NutritionService) CalculateNutrition(ctx context.Context, ingredients []string, servings int) (*RecipeNutrition, error) {
+	index, err := s.foodIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if servings <= 0 {
+		servings = 1
+	}
+	result := &RecipeNutrition{
+		Servings:    servings,
+		Unmatched:   []string{},
+		Ingredients: make([]IngredientNutrition, 0, len(ingredients)),
+	}
+
+	var unmatched []int
+	for _, line := range ingredients {
+		entry := IngredientNutrition{Ingredient: line, Source: NutritionSourceSkipped}
+		ing := units.ParseIngredient(line)
+		if !ing.HasQuantity {
+			// "Salt to taste" and similar lines contribute negligibly
+			result.Ingredients = append(result.Ingredients, entry)
+			continue
+		}
+
+		food, score := matchFood(index, normalizeFoodName(ing.Name))
+		if food != nil {
+			if grams, exact, ok := ingredientGrams(ing, food); ok {
+				entry.Food = food.Name
+				entry.Grams = round1(grams)
+				entry.Source = NutritionSourceDatabase
+				entry.Confidence = score
+				if !exact {
+					entry.Confidence *= 0.7
+				}
+				entry.Nutrients = foodNutrients(food, grams)
+				result.Total = addNutrients(result.Total, entry.Nutrients)
+				result.ServingSize += grams
+				entry.Nutrients = roundNutrients(entry.Nutrients)
+				result.Ingredients = append(result.Ingredients, entry)
+				continue
+			}
+		}
+
+		entry.Source = NutritionSourceUnmatched
+		unmatched = append(unmatched, len(result.Ingredients))
+		result.Ingredients = append(result.Ingredients, entry)
+		result.Unmatched = append(result.Unmatched, line)
+	}
+
+	if len(unmatched) > 0 && s.llmService != nil {
+		estimate, err := s.llmService.CalculateMacros(result.Unmatched)
+		if err != nil {
+			fmt.Printf("[NutritionService] LLM fallback failed: %v\n", err)
+		} else {
+			rounded := roundMacros(*estimate)
+			result.LLMEstimate = &rounded
+			result.Total.Macros = addMacros(result.Total.Macros, *estimate)
+			for _, i := range unmatched {
+				result.Ingredients[i].Source = NutritionSourceLLM
+				result.Ingredients[i].Confidence = llmConfidence
+			}
+		}
+	}
+
+	counted := 0
+	var confidence float64
+	for _, entry := range result.Ingredients {
+		if entry.Source == NutritionSourceSkipped {
+			continue
+		}
+		counted++
+		confidence += entry.Confidence
+	}
+	if counted > 0 {
+		result.Confidence = math.Round(confidence/float64(counted)*100) / 100
+	}
+
+	result.PerServing = roundNutrients(scaleNutrients(result.Total, 1/float64(servings)))
+	result.Total = roundNutrients(result.Total)
+	result.ServingSize = math.Round(result.ServingSize / float64(servings))
+	return result, nil
+}
+
+// ImportFoods inserts foods into the nutrient database, updating existing
+// entries with the same name. A name listed twice takes its last entry.
+func (s *NutritionService) ImportFoods(ctx context.Context, foods []models.Food) (int, error) {
+	foods = uniqueFoods(foods)
+	if len(foods) == 0 {
+		return 0, nil
+	}
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"aliases", "source", "source_id", "calories", "protein", "carbs", "fat", "unit_weight_grams", "grams_per_ml",
+			"fiber", "sugar", "saturated_fat", "sodium", "cholesterol", "vitamin_d", "calcium", "iron", "potassium", "updated_at"}),
+	}).CreateInBatches(foods, 500).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to import foods: %w", err)
+	}
+	s.InvalidateCache()
+	return len(foods), nil
+}
+
+// uniqueFoods keeps the last entry for each name, in the position of the
+// first, since one upsert cannot update the same row twice
+func uniqueFoods(foods []models.Food) []models.Food {
+	positions := make(map[string]int, len(foods))
+	unique := make([]models.Food, 0, len(foods))
+	for _, food := range foods {
+		if i, ok := positions[food.Name]; ok {
+			unique[i] = food
+			continue
+		}
+		positions[food.Name] = len(unique)
+		unique = append(unique, food)
+	}
+	return unique
+}
+
+// InvalidateCache forces the food index to be reloaded on next use
+func (s *NutritionService) InvalidateCache() {
+	s.mu.Lock()
+	s.index = nil
+	s.mu.Unlock()
+}
+
+func (s *NutritionService) foodIndex(ctx context.Context) ([]foodEntry, error) {
+	s.mu.RLock()
+	if s.index != nil && time.Since(s.loadedAt) < foodIndexTTL {
+		index := s.index
+		s.mu.RUnlock()
+		return index, nil
+	}
+	s.mu.RUnlock()
+
+	var foods []models.Food
+	if err := s.db.WithContext(ctx).Find(&foods).Error; err != nil {
+		return nil, fmt.Errorf("failed to load foods: %w", err)
+	}
+	index := buildFoodIndex(foods)
+
+	s.mu.Lock()
+	s.index = index
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return index, nil
+}
+
+// foodEntry is a food with its name and aliases pre-tokenized for matching
+type foodEntry struct {
+	food models.Food
+	keys [][]string
+}
+
+func buildFoodIndex(foods []models.Food) []foodEntry {
+	index := make([]foodEntry, 0, len(foods))
+	for _, food := range foods {
+		entry := foodEntry{food: food}
+		for _, name := range append([]string{food.Name}, food.Aliases...) {
+			if tokens := normalizeFoodName(name); len(tokens) > 0 {
+				entry.keys = append(entry.keys, tokens)
+			}
+		}
+		index = append(index, entry)
+	}
+	return index
+}
+
+// matchFood finds the food whose name or alias best matches the ingredient
+// tokens. A name whose words all appear in the ingredient is preferred, the
+// more specific the better; otherwise the closest word overlap is accepted
+// when it is strong enough. The returned score is between 0 and 1.
+func matchFood(index []foodEntry, tokens []string) (*models.Food, float64) {
+	if len(tokens) == 0 {
+		return nil, 0
+	}
+	have := make(map[string]bool, len(tokens))
+	for _, t := range tokens {
+		have[t] = true
+	}
+
+	var best *models.Food
+	bestScore := 0.0
+	for i := range index {
+		for _, key := range index[i].keys {
+			shared := 0
+			for _, t := range key {
+				if have[t] {
+					shared++
+				}
+			}
+			if shared == 0 {
+				continue
+			}
+
+			var score float64
+			if shared == len(key) {
+				score = 0.6 + 0.4*float64(len(key))/float64(len(have))
+			} else {
+				jaccard := float64(shared) / float64(len(key)+len(have)-shared)
+				if jaccard < 0.5 {
+					continue
+				}
+				score = jaccard * 0.8
+			}
+			if score > bestScore {
+				best, bestScore = &index[i].food, score
+			}
+		}
+	}
+	return best, math.Round(bestScore*100) / 100
+}
+
+var (
+	parentheticalPattern = regexp.MustCompile(`\([^)]*\)`)
+	nonWordPattern       = regexp.MustCompile(`[^a-z\s]+`)
+)
+
+// foodDescriptors are preparation words that do not change what food an
+// ingredient is
+var foodDescriptors = map[string]bool{
+	"a": true, "an": true, "and": true, "of": true, "or": true, "the": true,
+	"chopped": true, "diced": true, "minced": true, "sliced": true, "grated": true,
+	"fresh": true, "freshly": true, "large": true, "medium": true, "small": true,
+	"finely": true, "roughly": true, "thinly": true, "coarsely": true, "peeled": true,
+	"optional": true, "divided": true, "packed": true, "softened": true, "melted": true,
+	"beaten": true, "cubed": true, "crushed": true, "trimmed": true, "rinsed": true,
+	"drained": true, "cooked": true, "uncooked": true, "boneless": true, "skinless": true,
+	"whole": true, "about": true, "plus": true, "more": true, "extra": true,
+	"taste": true, "to": true, "for": true, "serving": true, "garnish": true,
+}
+
+// normalizeFoodName lower-cases an ingredient or food name, drops
+// parentheticals, text after the first comma, preparation descriptors and
+// plural endings, and returns the remaining words
+func normalizeFoodName(name string) []string {
+	s := strings.ToLower(name)
+	s = parentheticalPattern.ReplaceAllString(s, " ")
+	if before, _, ok := strings.Cut(s, ","); ok && strings.TrimSpace(before) != "" {
+		s = before
+	}
+	s = strings.ReplaceAll(s, "-", " ")
+	s = nonWordPattern.ReplaceAllString(s, " ")
+
+	var tokens []string
+	for _, word := range strings.Fields(s) {
+		if foodDescriptors[word] {
+			continue
+		}
+		tokens = append(tokens, singularize(word))
+	}
+	return tokens
+}
+
+func singularize(word string) string {
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case len(word) > 4 && strings.HasSuffix(word, "oes"):
+		return strings.TrimSuffix(word, "es")
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return strings.TrimSuffix(word, "s")
+	default:
+		return word
+	}
+}
+
+// ingredientGrams works out the weight of an ingredient. exact is false when
+// a volume had to be converted without a known density.
+func ingredientGrams(ing units.Ingredient, food *models.Food) (grams float64, exact bool, ok bool) {
+	qty := ing.Quantity
+	if ing.MaxQuantity > 0 {
+		qty = (ing.Quantity + ing.MaxQuantity) / 2
+	}
+
+	if !ing.HasUnit {
+		if food.UnitWeightGrams > 0 {
+			return qty * food.UnitWeightGrams, true, true
+		}
+		return 0, false, false
+	}
+
+	switch ing.Unit.Dimension {
+	case units.Mass:
+		return qty * ing.Unit.ToBase, true, true
+	case units.Volume:
+		ml := qty * ing.Unit.ToBase
+		if food.GramsPerML > 0 {
+			return ml * food.GramsPerML, true, true
+		}
+		if g, ok := units.GramsFor(qty, ing.Unit, ing.Name); ok {
+			return g, true, true
+		}
+		if g, ok := units.GramsFor(qty, ing.Unit, food.Name); ok {
+			return g, true, true
+		}
+		// Assume the density of water
+		return ml, false, true
+	}
+	return 0, false, false
+}
+
+// foodNutrients scales a food's per-100 g values to the given weight
+func foodNutrients(food *models.Food, grams float64) Nutrients {
+	return scaleNutrients(Nutrients{
+		Macros: Macros{
+			Calories: food.Calories,
+			Protein:  food.Protein,
+			Carbs:    food.Carbs,
+			Fat:      food.Fat,
+		},
+		Micronutrients: food.Micronutrients,
+	}, grams/100)
+}
+
+func addNutrients(a, b Nutrients) Nutrients {
+	return Nutrients{
+		Macros: addMacros(a.Macros, b.Macros),
+		Micronutrients: models.Micronutrients{
+			Fiber:        a.Fiber + b.Fiber,
+			Sugar:        a.Sugar + b.Sugar,
+			SaturatedFat: a.SaturatedFat + b.SaturatedFat,
+			Sodium:       a.Sodium + b.Sodium,
+			Cholesterol:  a.Cholesterol + b.Cholesterol,
+			VitaminD:     a.VitaminD + b.VitaminD,
+			Calcium:      a.Calcium + b.Calcium,
+			Iron:         a.Iron + b.Iron,
+			Potassium:    a.Potassium + b.Potassium,
+		},
+	}
+}
+
+func scaleNutrients(n Nutrients, factor float64) Nutrients {
+	return Nutrients{
+		Macros: scaleMacros(n.Macros, factor),
+		Micronutrients: models.Micronutrients{
+			Fiber:        n.Fiber * factor,
+			Sugar:        n.Sugar * factor,
+			SaturatedFat: n.SaturatedFat * factor,
+			Sodium:       n.Sodium * factor,
+			Cholesterol:  n.Cholesterol * factor,
+			VitaminD:     n.VitaminD * factor,
+			Calcium:      n.Calcium * factor,
+			Iron:         n.Iron * factor,
+			Potassium:    n.Potassium * factor,
+		},
+	}
+}
+
+// roundNutrients rounds grams to one decimal place and milligrams and
+// micrograms to whole numbers, except iron and vitamin D which are small
+func roundNutrients(n Nutrients) Nutrients {
+	return Nutrients{
+		Macros: roundMacros(n.Macros),
+		Micronutrients: models.Micronutrients{
+			Fiber:        round1(n.Fiber),
+			Sugar:        round1(n.Sugar),
+			SaturatedFat: round1(n.SaturatedFat),
+			Sodium:       math.Round(n.Sodium),
+			Cholesterol:  math.Round(n.Cholesterol),
+			VitaminD:     round1(n.VitaminD),
+			Calcium:      math.Round(n.Calcium),
+			Iron:         round1(n.Iron),
+			Potassium:    math.Round(n.Potassium),
+		},
+	}
+}
+
+func addMacros(a, b Macros) Macros {
+	return Macros{
+		Calories: a.Calories + b.Calories,
+		Protein:  a.Protein + b.Protein,
+		Carbs:    a.Carbs + b.Carbs,
+		Fat:      a.Fat + b.Fat,
+	}
+}
+
+func scaleMacros(m Macros, factor float64) Macros {
+	return Macros{
+		Calories: m.Calories * factor,
+		Protein:  m.Protein * factor,
+		Carbs:    m.Carbs * factor,
+		Fat:      m.Fat * factor,
+	}
+}
+
+func roundMacros(m Macros) Macros {
+	return Macros{
+		Calories: math.Round(m.Calories),
+		Protein:  round1(m.Protein),
+		Carbs:    round1(m.Carbs),
+		Fat:      round1(m.Fat),
+	}
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// ParseServings extracts the leading number from a servings description such
+// as "4" or "4-6 people", returning 0 when there is none
+func ParseServings(value string) int {
+	digits := strings.TrimSpace(value)
+	end := 0
+	for end < len(digits) && digits[end] >= '0' && digits[end] <= '9' {
+		end++
+	}
+	n, err := strconv.Atoi(digits[:end])
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// foodCSVColumns maps accepted CSV header spellings to food fields so that
+// both the bundled table and USDA-style exports can be imported
+var foodCSVColumns = map[string]string{
+	"name": "name", "description": "name", "food": "name", "food_name": "name",
+	"fdc_id": "source_id", "ndb_no": "source_id", "source_id": "source_id",
+	"calories": "calories", "energy_kcal": "calories", "energy (kcal)": "calories", "kcal": "calories",
+	"protein": "protein", "protein_g": "protein", "protein (g)": "protein",
+	"carbs": "carbs", "carbohydrate": "carbs", "carbohydrates": "carbs", "carbohydrate_g": "carbs",
+	"carbohydrate, by difference (g)": "carbs",
+	"fat":                             "fat", "total_fat": "fat", "fat_g": "fat", "total lipid (fat) (g)": "fat",
+	"aliases":       "aliases",
+	"unit_weight_g": "unit_weight_grams", "unit_weight_grams": "unit_weight_grams",
+	"grams_per_ml": "grams_per_ml", "density": "grams_per_ml",
+	"fiber": "fiber", "fiber_g": "fiber", "fiber, total dietary (g)": "fiber",
+	"sugar": "sugar", "sugars": "sugar", "sugar_g": "sugar", "sugars, total (g)": "sugar",
+	"saturated_fat": "saturated_fat", "saturated_fat_g": "saturated_fat", "fatty acids, total saturated (g)": "saturated_fat",
+	"sodium": "sodium", "sodium_mg": "sodium", "sodium, na (mg)": "sodium",
+	"cholesterol": "cholesterol", "cholesterol_mg": "cholesterol", "cholesterol (mg)": "cholesterol",
+	"vitamin_d": "vitamin_d", "vitamin_d_mcg": "vitamin_d", "vitamin d (d2 + d3) (ug)": "vitamin_d",
+	"calcium": "calcium", "calcium_mg": "calcium", "calcium, ca (mg)": "calcium",
+	"iron": "iron", "iron_mg": "iron", "iron, fe (mg)": "iron",
+	"potassium": "potassium", "potassium_mg": "potassium", "potassium, k (mg)": "potassium",
+}
+
+// ParseFoodCSV reads a nutrient table in CSV format. The header row selects
+// columns by name; nutrient values are per 100 g and aliases are separated by
+// semicolons. Micronutrient columns are optional.
+func ParseFoodCSV(r io.Reader, source string) ([]models.Food, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		if field, ok := foodCSVColumns[strings.ToLower(strings.TrimSpace(name))]; ok {
+			columns[field] = i
+		}
+	}
+	for _, required := range []string{"name", "calories", "protein", "carbs", "fat"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV is missing a %s column", required)
+		}
+	}
+
+	get := func(record []string, field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	number := func(record []string, field string, line int) (float64, error) {
+		v := get(record, field)
+		if v == "" {
+			return 0, nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("line %d: invalid %s %q", line, field, v)
+		}
+		return f, nil
+	}
+
+	var foods []models.Food
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		food := models.Food{
+			Name:     get(record, "name"),
+			Source:   source,
+			SourceID: get(record, "source_id"),
+			Aliases:  models.JSONBStringArray{},
+		}
+		if food.Name == "" {
+			continue
+		}
+		for _, alias := range strings.Split(get(record, "aliases"), ";") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				food.Aliases = append(food.Aliases, alias)
+			}
+		}
+		for field, dst := range map[string]*float64{
+			"calories":          &food.Calories,
+			"protein":           &food.Protein,
+			"carbs":             &food.Carbs,
+			"fat":               &food.Fat,
+			"unit_weight_grams": &food.UnitWeightGrams,
+			"grams_per_ml":      &food.GramsPerML,
+			"fiber":             &food.Fiber,
+			"sugar":             &food.Sugar,
+			"saturated_fat":     &food.SaturatedFat,
+			"sodium":            &food.Sodium,
+			"cholesterol":       &food.Cholesterol,
+			"vitamin_d":         &food.VitaminD,
+			"calcium":           &food.Calcium,
+			"iron":              &food.Iron,
+			"potassium":         &food.Potassium,
+		} {
+			if *dst, err = number(record, field, line); err != nil {
+				return nil, err
+			}
+		}
+		foods = append(foods, food)
+	}
+	return foods, nil
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"math"
+)
+
+// Reference daily values used for percent daily value, from the FDA's 2020
+// labeling rules for adults and children 4 years and older
+const (
+	dailyValueFat          = 78.0   // g
+	dailyValueSaturatedFat = 20.0   // g
+	dailyValueCholesterol  = 300.0  // mg
+	dailyValueSodium       = 2300.0 // mg
+	dailyValueCarbs        = 275.0  // g
+	dailyValueFiber        = 28.0   // g
+	dailyValueVitaminD     = 20.0   // mcg
+	dailyValueCalcium      = 1300.0 // mg
+	dailyValueIron         = 18.0   // mg
+	dailyValuePotassium    = 4700.0 // mg
+)
+
+// LabelNutrient is one line of a nutrition facts label
+type LabelNutrient struct {
+	Name              string  `json:"name"`
+	Amount            float64 `json:"amount"`
+	Unit              string  `json:"unit"`
+	PercentDailyValue *int    `json:"percent_daily_value,omitempty"`
+	Indent            bool    `json:"indent,omitempty"`
+}
+
+// NutritionLabel is a per-serving nutrition facts label. Amounts are rounded
+// the way they would be printed on a package.
+type NutritionLabel struct {
+	Servings    int             `json:"servings"`
+	ServingSize float64         `json:"serving_size_grams"`
+	Calories    float64         `json:"calories"`
+	Nutrients   []LabelNutrient `json:"nutrients"`
+	Vitamins    []LabelNutrient `json:"vitamins_and_minerals"`
+	Confidence  float64         `json:"confidence"`
+	Unmatched   []string        `json:"unmatched"`
+}
+
+// BuildNutritionLabel creates a nutrition facts label from a calculation
+func BuildNutritionLabel(n *RecipeNutrition) *NutritionLabel {
+	p := n.PerServing
+	return &NutritionLabel{
+		Servings:    n.Servings,
+		ServingSize: n.ServingSize,
+		Calories:    roundLabelCalories(p.Calories),
+		Nutrients: []LabelNutrient{
+			labelNutrient("Total Fat", roundLabelGrams(p.Fat, 0.5), "g", p.Fat, dailyValueFat, false),
+			labelNutrient("Saturated Fat", roundLabelGrams(p.SaturatedFat, 0.5), "g", p.SaturatedFat, dailyValueSaturatedFat, true),
+			labelNutrient("Cholesterol", roundLabelStep(p.Cholesterol, 2, 5), "mg", p.Cholesterol, dailyValueCholesterol, false),
+			labelNutrient("Sodium", roundLabelSodium(p.Sodium), "mg", p.Sodium, dailyValueSodium, false),
+			labelNutrient("Total Carbohydrate", roundLabelGrams(p.Carbs, 1), "g", p.Carbs, dailyValueCarbs, false),
+			labelNutrient("Dietary Fiber", roundLabelGrams(p.Fiber, 1), "g", p.Fiber, dailyValueFiber, true),
+			labelNutrient("Total Sugars", roundLabelGrams(p.Sugar, 1), "g", p.Sugar, 0, true),
+			labelNutrient("Protein", roundLabelGrams(p.Protein, 1), "g", p.Protein, 0, false),
+		},
+		Vitamins: []LabelNutrient{
+			labelNutrient("Vitamin D", math.Round(p.VitaminD*10)/10, "mcg", p.VitaminD, dailyValueVitaminD, false),
+			labelNutrient("Calcium", roundLabelStep(p.Calcium, 0, 10), "mg", p.Calcium, dailyValueCalcium, false),
+			labelNutrient("Iron", math.Round(p.Iron*10)/10, "mg", p.Iron, dailyValueIron, false),
+			labelNutrient("Potassium", roundLabelStep(p.Potassium, 0, 10), "mg", p.Potassium, dailyValuePotassium, false),
+		},
+		Confidence: n.Confidence,
+		Unmatched:  n.Unmatched,
+	}
+}
+
+func labelNutrient(name string, amount float64, unit string, exact, dailyValue float64, indent bool) LabelNutrient {
+	nutrient := LabelNutrient{Name: name, Amount: amount, Unit: unit, Indent: indent}
+	if dailyValue > 0 {
+		percent := int(math.Round(exact / dailyValue * 100))
+		nutrient.PercentDailyValue = &percent
+	}
+	return nutrient
+}
+
+// roundLabelCalories rounds to the nearest 5 up to 50 and the nearest 10 above
+func roundLabelCalories(v float64) float64 {
+	switch {
+	case v < 5:
+		return 0
+	case v <= 50:
+		return math.Round(v/5) * 5
+	default:
+		return math.Round(v/10) * 10
+	}
+}
+
+// roundLabelGrams declares amounts under half a gram as zero, rounds small
+// amounts to the given step and everything from 5 g up to whole grams
+func roundLabelGrams(v, step float64) float64 {
+	switch {
+	case v < 0.5:
+		return 0
+	case v < 5:
+		return math.Round(v/step) * step
+	default:
+		return math.Round(v)
+	}
+}
+
+func roundLabelSodium(v float64) float64 {
+	switch {
+	case v < 5:
+		return 0
+	case v <= 140:
+		return math.Round(v/5) * 5
+	default:
+		return math.Round(v/10) * 10
+	}
+}
+
+func roundLabelStep(v, zeroBelow, step float64) float64 {
+	if v < zeroBelow {
+		return 0
+	}
+	return math.Round(v/step) * step
+}
+
+func formatLabelAmount(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.1f", v)
+}
+
+// labelRow is a nutrient line positioned for rendering
+type labelRow struct {
+	LabelNutrient
+	Text    string
+	Percent string
+	Y       int
+}
+
+type labelView struct {
+	*NutritionLabel
+	Size         string
+	Rows         []labelRow
+	Vitamins     []labelRow
+	RuleY        int
+	FootnoteY    int
+	FootnoteY2   int
+	Height       int
+	BorderHeight int
+}
+
+func newLabelView(label *NutritionLabel) labelView {
+	view := labelView{NutritionLabel: label}
+	view.Size = fmt.Sprintf("1/%d recipe", label.Servings)
+	if label.ServingSize > 0 {
+		view.Size = fmt.Sprintf("%s (%.0fg)", view.Size, label.ServingSize)
+	}
+
+	row := func(n LabelNutrient, y int) labelRow {
+		r := labelRow{LabelNutrient: n, Text: formatLabelAmount(n.Amount) + n.Unit, Y: y}
+		if n.PercentDailyValue != nil {
+			r.Percent = fmt.Sprintf("%d%%", *n.PercentDailyValue)
+		}
+		return r
+	}
+	y := 150
+	for _, n := range label.Nutrients {
+		view.Rows = append(view.Rows, row(n, y))
+		y += 20
+	}
+	view.RuleY = y - 12
+	y += 8
+	for _, n := range label.Vitamins {
+		view.Vitamins = append(view.Vitamins, row(n, y))
+		y += 20
+	}
+	view.FootnoteY = y + 6
+	view.FootnoteY2 = view.FootnoteY + 12
+	view.Height = y + 40
+	view.BorderHeight = view.Height - 2
+	return view
+}
+
+var labelSVGTemplate = template.Must(template.New("label.svg").Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="300" height="{{.Height}}" viewBox="0 0 300 {{.Height}}" font-family="Helvetica, Arial, sans-serif">
+<rect x="1" y="1" width="298" height="{{.BorderHeight}}" fill="#fff" stroke="#000" stroke-width="2"/>
+<text x="10" y="36" font-size="28" font-weight="900">Nutrition Facts</text>
+<line x1="10" y1="46" x2="290" y2="46" stroke="#000"/>
+<text x="10" y="64" font-size="13">{{.Servings}} servings per recipe</text>
+<text x="10" y="82" font-size="14" font-weight="bold">Serving size</text>
+<text x="290" y="82" font-size="14" font-weight="bold" text-anchor="end">{{.Size}}</text>
+<line x1="10" y1="92" x2="290" y2="92" stroke="#000" stroke-width="8"/>
+<text x="10" y="108" font-size="11" font-weight="bold">Amount per serving</text>
+<text x="10" y="128" font-size="22" font-weight="900">Calories</text>
+<text x="290" y="128" font-size="26" font-weight="900" text-anchor="end">{{.Calories}}</text>
+<line x1="10" y1="136" x2="290" y2="136" stroke="#000" stroke-width="4"/>
+{{range .Rows}}<text x="{{if .Indent}}24{{else}}10{{end}}" y="{{.Y}}" font-size="13">{{if not .Indent}}<tspan font-weight="bold">{{.Name}}</tspan>{{else}}{{.Name}}{{end}} {{.Text}}</text>
+{{if .Percent}}<text x="290" y="{{.Y}}" font-size="13" font-weight="bold" text-anchor="end">{{.Percent}}</text>
+{{end}}{{end}}<line x1="10" y1="{{.RuleY}}" x2="290" y2="{{.RuleY}}" stroke="#000" stroke-width="8"/>
+{{range .Vitamins}}<text x="10" y="{{.Y}}" font-size="13">{{.Name}} {{.Text}}</text>
+<text x="290" y="{{.Y}}" font-size="13" text-anchor="end">{{.Percent}}</text>
+{{end}}<text x="10" y="{{.FootnoteY}}" font-size="9">* The % Daily Value tells you how much a nutrient in a serving</text>
+<text x="10" y="{{.FootnoteY2}}" font-size="9">of food contributes to a daily diet. 2,000 calories a day is used.</text>
+</svg>
+`))
+
+var labelHTMLTemplate = template.Must(template.New("label.html").Parse(`<section class="nutrition-label" style="font-family:Helvetica,Arial,sans-serif;border:2px solid #000;padding:6px 10px;width:280px">
+<h1 style="font-size:28px;font-weight:900;margin:0;border-bottom:1px solid #000">Nutrition Facts</h1>
+<p style="margin:4px 0">{{.Servings}} servings per recipe</p>
+<p style="margin:0;font-weight:bold;display:flex;justify-content:space-between;border-bottom:8px solid #000"><span>Serving size</span><span>{{.Size}}</span></p>
+<p style="margin:4px 0 0;font-size:11px;font-weight:bold">Amount per serving</p>
+<p style="margin:0;font-weight:900;font-size:22px;display:flex;justify-content:space-between;border-bottom:4px solid #000"><span>Calories</span><span>{{.Calories}}</span></p>
+<p style="margin:2px 0;text-align:right;font-size:11px;font-weight:bold">% Daily Value*</p>
+<table style="width:100%;border-collapse:collapse;font-size:13px">
+{{range .Rows}}<tr style="border-top:1px solid #000"><td{{if .Indent}} style="padding-left:14px"{{end}}>{{if .Indent}}{{.Name}}{{else}}<b>{{.Name}}</b>{{end}} {{.Text}}</td><td style="text-align:right"><b>{{.Percent}}</b></td></tr>
+{{end}}</table>
+<table style="width:100%;border-collapse:collapse;font-size:13px;border-top:8px solid #000">
+{{range .Vitamins}}<tr style="border-top:1px solid #000"><td>{{.Name}} {{.Text}}</td><td style="text-align:right">{{.Percent}}</td></tr>
+{{end}}</table>
+<p style="font-size:9px;border-top:4px solid #000;margin:4px 0 0;padding-top:2px">* The % Daily Value (DV) tells you how much a nutrient in a serving of food contributes to a daily diet. 2,000 calories a day is used for general nutrition advice.</p>
+</section>
+`))
+
+// RenderNutritionLabelSVG renders a label as a standalone SVG image
+func RenderNutritionLabelSVG(label *NutritionLabel) (string, error) {
+	var buf bytes.Buffer
+	if err := labelSVGTemplate.Execute(&buf, newLabelView(label)); err != nil {
+		return "", fmt.Errorf("failed to render nutrition label: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// RenderNutritionLabelHTML renders a label as an HTML fragment
+func RenderNutritionLabelHTML(label *NutritionLabel) (string, error) {
+	var buf bytes.Buffer
+	if err := labelHTMLTemplate.Execute(&buf, newLabelView(label)); err != nil {
+		return "", fmt.Errorf("failed to render nutrition label: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/units"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFoodIndex() []foodEntry {
+	return buildFoodIndex([]models.Food{
+		{Name: "all-purpose flour", Aliases: models.JSONBStringArray{"flour"}, Calories: 364, Protein: 10.3, Carbs: 76.3, Fat: 1, GramsPerML: 0.53},
+		{Name: "egg", Aliases: models.JSONBStringArray{"eggs"}, Calories: 143, Protein: 12.6, Carbs: 0.7, Fat: 9.5, UnitWeightGrams: 50},
+		{Name: "bell pepper", Aliases: models.JSONBStringArray{"pepper"}, Calories: 26, UnitWeightGrams: 119},
+		{Name: "black pepper", Calories: 251},
+		{Name: "chicken breast", Aliases: models.JSONBStringArray{"chicken"}, Calories: 120},
+		{Name: "chicken stock", Calories: 15, GramsPerML: 1},
+	})
+}
+
+func TestNormalizeFoodName(t *testing.T) {
+	assert.Equal(t, []string{"onion"}, normalizeFoodName("Large Onions, finely chopped"))
+	assert.Equal(t, []string{"tomato"}, normalizeFoodName("fresh tomatoes (about 3)"))
+	assert.Equal(t, []string{"all", "purpose", "flour"}, normalizeFoodName("all-purpose flour"))
+	assert.Equal(t, []string{"berry"}, normalizeFoodName("berries"))
+}
+
+func TestMatchFood(t *testing.T) {
+	index := testFoodIndex()
+
+	tests := []struct {
+		ingredient string
+		want       string
+	}{
+		{"flour", "all-purpose flour"},
+		{"large eggs, beaten", "egg"},
+		{"ground black pepper", "black pepper"},
+		{"red bell pepper, sliced", "bell pepper"},
+		{"low-sodium chicken stock", "chicken stock"},
+		{"boneless chicken breasts", "chicken breast"},
+	}
+	for _, tt := range tests {
+		food, score := matchFood(index, normalizeFoodName(tt.ingredient))
+		require.NotNil(t, food, tt.ingredient)
+		assert.Equal(t, tt.want, food.Name, tt.ingredient)
+		assert.Greater(t, score, 0.5, tt.ingredient)
+	}
+
+	food, _ := matchFood(index, normalizeFoodName("saffron threads"))
+	assert.Nil(t, food)
+}
+
+func TestIngredientGrams(t *testing.T) {
+	index := testFoodIndex()
+
+	flour := units.ParseIngredient("2 cups flour")
+	food, _ := matchFood(index, normalizeFoodName(flour.Name))
+	require.NotNil(t, food)
+	grams, exact, ok := ingredientGrams(flour, food)
+	assert.True(t, ok)
+	assert.True(t, exact)
+	assert.InDelta(t, 250, grams, 10)
+
+	eggs := units.ParseIngredient("3 eggs")
+	food, _ = matchFood(index, normalizeFoodName(eggs.Name))
+	require.NotNil(t, food)
+	grams, _, ok = ingredientGrams(eggs, food)
+	assert.True(t, ok)
+	assert.Equal(t, 150.0, grams)
+}
+
+func TestParseFoodCSV(t *testing.T) {
+	csv := "Description,FDC_ID,Energy_kcal,Protein_g,Carbohydrate_g,Fat_g,Aliases\n" +
+		"butter,173410,717,0.9,0.1,81.1,unsalted butter; salted butter\n"
+
+	foods, err := ParseFoodCSV(strings.NewReader(csv), "usda")
+	require.NoError(t, err)
+	require.Len(t, foods, 1)
+	assert.Equal(t, "butter", foods[0].Name)
+	assert.Equal(t, "173410", foods[0].SourceID)
+	assert.Equal(t, 717.0, foods[0].Calories)
+	assert.Equal(t, models.JSONBStringArray{"unsalted butter", "salted butter"}, foods[0].Aliases)
+
+	_, err = ParseFoodCSV(strings.NewReader("name,calories\nbutter,717\n"), "usda")
+	assert.Error(t, err)
+}
+
+func TestImportFoodsKeepsLastDuplicate(t *testing.T) {
+	csv := "name,calories,protein,carbs,fat\n" +
+		"butter,700,1,0,80\n" +
+		"olive oil,884,0,0,100\n" +
+		"butter,717,0.9,0.1,81.1\n"
+	foods, err := ParseFoodCSV(strings.NewReader(csv), "usda")
+	require.NoError(t, err)
+
+	unique := uniqueFoods(foods)
+	require.Len(t, unique, 2)
+	assert.Equal(t, "butter", unique[0].Name)
+	assert.Equal(t, 717.0, unique[0].Calories)
+	assert.Equal(t, "olive oil", unique[1].Name)
+
+	db := newTestDB(t)
+	s := NewNutritionService(db, nil)
+	n, err := s.ImportFoods(context.Background(), foods)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	var stored []models.Food
+	require.NoError(t, db.Order("name").Find(&stored).Error)
+	require.Len(t, stored, 2)
+	assert.Equal(t, 717.0, stored[0].Calories)
+}
+
+func TestParseServings(t *testing.T) {
+	assert.Equal(t, 4, ParseServings("4"))
+	assert.Equal(t, 4, ParseServings("4-6 people"))
+	assert.Equal(t, 0, ParseServings("a crowd"))
+}
+
+func TestBuildNutritionLabel(t *testing.T) {
+	n := &RecipeNutrition{
+		Servings:    4,
+		ServingSize: 212,
+		PerServing: Nutrients{
+			Macros:         Macros{Calories: 347, Protein: 12.3, Carbs: 41.6, Fat: 15.2},
+			Micronutrients: models.Micronutrients{Sodium: 612, Fiber: 3.4, SaturatedFat: 0.3, Calcium: 130},
+		},
+	}
+
+	label := BuildNutritionLabel(n)
+	assert.Equal(t, 350.0, label.Calories)
+
+	byName := map[string]LabelNutrient{}
+	for _, nutrient := range append(label.Nutrients, label.Vitamins...) {
+		byName[nutrient.Name] = nutrient
+	}
+	assert.Equal(t, 15.0, byName["Total Fat"].Amount)
+	assert.Equal(t, 19, *byName["Total Fat"].PercentDailyValue)
+	assert.Equal(t, 0.0, byName["Saturated Fat"].Amount)
+	assert.Equal(t, 610.0, byName["Sodium"].Amount)
+	assert.Equal(t, 27, *byName["Sodium"].PercentDailyValue)
+	assert.Equal(t, 12, *byName["Dietary Fiber"].PercentDailyValue)
+	assert.Nil(t, byName["Total Sugars"].PercentDailyValue)
+	assert.Equal(t, 10, *byName["Calcium"].PercentDailyValue)
+
+	svg, err := RenderNutritionLabelSVG(label)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(svg, "<svg"))
+	assert.Contains(t, svg, "1/4 recipe (212g)")
+	assert.Contains(t, svg, "610mg")
+
+	html, err := RenderNutritionLabelHTML(label)
+	require.NoError(t, err)
+	assert.Contains(t, html, "Nutrition Facts")
+	assert.Contains(t, html, "27%")
+}
+
+func TestBundledFoodCSV(t *testing.T) {
+	f, err := os.Open("../../data/nutrients/common_foods.csv")
+	require.NoError(t, err)
+	defer f.Close()
+
+	foods, err := ParseFoodCSV(f, "usda")
+	require.NoError(t, err)
+	assert.NotEmpty(t, foods)
+	for _, food := range foods {
+		if food.Name == "butter" {
+			assert.Equal(t, 643.0, food.Sodium)
+			assert.Equal(t, 51.4, food.SaturatedFat)
+		}
+	}
+}
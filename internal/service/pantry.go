@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"github.com/pageza/alchemorsel-v2/backend/internal/units"
+	"gorm.io/gorm"
+)
+
+// PantryExpiringWithin is how close to its expiry date a pantry item counts
+// as about to expire
+const PantryExpiringWithin = 3 * 24 * time.Hour
+
+// cookableCandidateLimit caps the recipes ranked against the pantry
+const cookableCandidateLimit = 500
+
+var (
+	// ErrPantryItemNotFound is returned when an item does not exist or belongs
+	// to another user
+	ErrPantryItemNotFound = errors.New("pantry item not found")
+	// ErrInvalidPantryItem is returned for unknown units or malformed dates
+	ErrInvalidPantryItem = errors.New("invalid pantry item")
+)
+
+// pantryStapleWords are assumed to be in every kitchen. Ingredients made up
+// only of these words, such as "salt and pepper" or "cold water", are left
+// out of cookability scores.
+var pantryStapleWords = map[string]bool{
+	"salt": true, "pepper": true, "black": true, "kosher": true, "sea": true,
+	"water": true, "ice": true, "cold": true, "warm": true, "hot": true, "boiling": true,
+}
+
+// CookableRecipe is a recipe ranked by how much of it the pantry covers
+type CookableRecipe struct {
+	Recipe   *models.Recipe `json:"recipe"`
+	Coverage float64        `json:"coverage"`
+	Matched  []string       `json:"matched"`
+	Missing  []string       `json:"missing"`
+	Expiring []string       `json:"expiring"`
+}
+
+// PantryService manages pantry items and finds recipes they can make
+type PantryService struct {
+	db            *gorm.DB
+	recipeService IRecipeService
+}
+
+// Ensure PantryService implements IPantryService
+var _ IPantryService = (*PantryService)(nil)
+
+// NewPantryService creates a new PantryService instance
+func NewPantryService(db *gorm.DB, recipeService IRecipeService) *PantryService {
+	return &PantryService{
+		db:            db,
+		recipeService: recipeService,
+	}
+}
+
+// ListPantryItems returns the user's pantry, soonest to expire first
+func (s *PantryService) ListPantryItems(ctx context.Context, userID uuid.UUID) ([]models.PantryItem, error) {
+	var items []models.PantryItem
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).
+		Order("expires_at ASC NULLS LAST").Order("name ASC").
+		Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to list pantry items: %w", err)
+	}
+	return items, nil
+}
+
+// AddPantryItem adds an ingredient to the user's pantry
+func (s *PantryService) AddPantryItem(ctx context.Context, userID uuid.UUID, req *types.CreatePantryItemRequest) (*models.PantryItem, error) {
+	item := &models.PantryItem{
+		UserID:   userID,
+		Name:     strings.TrimSpace(req.Name),
+		Quantity: req.Quantity,
+		Unit:     strings.TrimSpace(req.Unit),
+	}
+	if req.ExpiresAt != nil {
+		expires, err := parsePantryDate(*req.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		item.ExpiresAt = expires
+	}
+	if err := validatePantryItem(item); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Create(item).Error; err != nil {
+		return nil, fmt.Errorf("failed to add pantry item: %w", err)
+	}
+	return item, nil
+}
+
+// UpdatePantryItem updates an item's name, amount or expiry date
+func (s *PantryService) UpdatePantryItem(ctx context.Context, userID, id uuid.UUID, req *types.UpdatePantryItemRequest) (*models.PantryItem, error) {
+	var item models.PantryItem
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPantryItemNotFound
+		}
+		return nil, fmt.Errorf("failed to get pantry item: %w", err)
+	}
+
+	if req.Name != nil {
+		item.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Quantity != nil {
+		item.Quantity = *req.Quantity
+	}
+	if req.Unit != nil {
+		item.Unit = strings.TrimSpace(*req.Unit)
+	}
+	if req.ExpiresAt != nil {
+		expires, err := parsePantryDate(*req.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		item.ExpiresAt = expires
+	}
+	if err := validatePantryItem(&item); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Model(&item).Select("name", "quantity", "unit", "expires_at").Updates(&item).Error; err != nil {
+		return nil, fmt.Errorf("failed to update pantry item: %w", err)
+	}
+	return &item, nil
+}
+
+// DeletePantryItem removes an item from the pantry
+func (s *PantryService) DeletePantryItem(ctx context.Context, userID, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&models.PantryItem{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete pantry item: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrPantryItemNotFound
+	}
+	return nil
+}
+
+// CookableRecipes ranks the recipes matching query, or all recipes when query
+// is empty, by how many of their ingredients the user's pantry covers.
+// Recipes missing more than maxMissing ingredients are left out unless
+// maxMissing is negative, and limit caps the results when positive.
+func (s *PantryService) CookableRecipes(ctx context.Context, userID uuid.UUID, query string, maxMissing, limit int) ([]CookableRecipe, error) {
+	pantry, err := s.ListPantryItems(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(pantry) == 0 {
+		return []CookableRecipe{}, nil
+	}
+
+	recipes, _, err := s.recipeService.SearchRecipes(ctx, userID, query, pagination.Page{Limit: cookableCandidateLimit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search recipes: %w", err)
+	}
+
+	ranked := RankCookableRecipes(recipes, pantry, time.Now())
+	results := make([]CookableRecipe, 0, len(ranked))
+	for _, r := range ranked {
+		if maxMissing >= 0 && len(r.Missing) > maxMissing {
+			continue
+		}
+		results = append(results, r)
+		if limit > 0 && len(results) == limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// RankCookableRecipes scores recipes against a pantry. An ingredient is
+// covered when a pantry item names it and, where both give amounts that can
+// be compared, the pantry holds enough. Expired items are ignored. Recipes
+// are ordered by coverage, then by fewest missing ingredients, then by how
+// many soon-to-expire items they use.
+func RankCookableRecipes(recipes []*models.Recipe, pantry []models.PantryItem, now time.Time) []CookableRecipe {
+	type stockItem struct {
+		amount   shoppingAmount
+		name     string
+		expiring bool
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var stock []stockItem
+	for _, item := range pantry {
+		if item.ExpiresAt != nil && item.ExpiresAt.Before(today) {
+			continue
+		}
+		a, ok := parseShoppingAmount(PantryLine(item), 1)
+		if !ok {
+			continue
+		}
+		stock = append(stock, stockItem{amount: a, name: item.Name, expiring: PantryItemExpiring(item, now)})
+	}
+
+	var ranked []CookableRecipe
+	for _, recipe := range recipes {
+		result := CookableRecipe{Recipe: recipe, Matched: []string{}, Missing: []string{}, Expiring: []string{}}
+		for _, line := range recipe.Ingredients {
+			need, ok := parseShoppingAmount(line, 1)
+			if !ok || isPantryStaple(need.key) {
+				continue
+			}
+			covered := false
+			for _, s := range stock {
+				if !pantryKeysMatch(s.amount.key, need.key) || !pantryHasEnough(s.amount, need) {
+					continue
+				}
+				covered = true
+				if s.expiring {
+					result.Expiring = appendUnique(result.Expiring, s.name)
+				}
+				break
+			}
+			if covered {
+				result.Matched = append(result.Matched, line)
+			} else {
+				result.Missing = append(result.Missing, line)
+			}
+		}
+
+		total := len(result.Matched) + len(result.Missing)
+		if len(result.Matched) == 0 || total == 0 {
+			continue
+		}
+		result.Coverage = math.Round(float64(len(result.Matched))/float64(total)*100) / 100
+		ranked = append(ranked, result)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Coverage != b.Coverage {
+			return a.Coverage > b.Coverage
+		}
+		if len(a.Missing) != len(b.Missing) {
+			return len(a.Missing) < len(b.Missing)
+		}
+		return len(a.Expiring) > len(b.Expiring)
+	})
+	return ranked
+}
+
+// PantryLine renders a pantry item as an ingredient line such as "500 ml milk"
+func PantryLine(item models.PantryItem) string {
+	if item.Quantity <= 0 {
+		return item.Name
+	}
+	return strings.Join(strings.Fields(fmt.Sprintf("%s %s %s", strconv.FormatFloat(item.Quantity, 'f', -1, 64), item.Unit, item.Name)), " ")
+}
+
+// PantryItemExpiring reports whether an unexpired item expires within
+// PantryExpiringWithin of now
+func PantryItemExpiring(item models.PantryItem, now time.Time) bool {
+	if item.ExpiresAt == nil {
+		return false
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return !item.ExpiresAt.Before(today) && item.ExpiresAt.Before(now.Add(PantryExpiringWithin))
+}
+
+// pantryKeysMatch reports whether two normalized names are the same or one
+// starts with the other word for word, so that "cheddar" covers "cheddar
+// cheese" and "chicken" covers "chicken breast" while "milk" does not cover
+// "coconut milk"
+func pantryKeysMatch(have, need string) bool {
+	return hasWordPrefix(need, have) || hasWordPrefix(have, need)
+}
+
+func hasWordPrefix(s, prefix string) bool {
+	return s == prefix || strings.HasPrefix(s, prefix+" ")
+}
+
+// pantryHasEnough compares amounts when both are known and comparable
+func pantryHasEnough(have, need shoppingAmount) bool {
+	if have.dimension == shoppingNoQuant || need.dimension == shoppingNoQuant {
+		return true
+	}
+	amount, ok := convertAmount(have, need.dimension)
+	if !ok {
+		return true
+	}
+	return amount >= need.amount*0.99
+}
+
+func isPantryStaple(key string) bool {
+	for _, w := range strings.Fields(key) {
+		if !pantryStapleWords[w] {
+			return false
+		}
+	}
+	return true
+}
+
+func validatePantryItem(item *models.PantryItem) error {
+	if item.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidPantryItem)
+	}
+	if item.Unit != "" {
+		if _, ok := units.LookupUnit(item.Unit); !ok {
+			return fmt.Errorf("%w: unknown unit %q", ErrInvalidPantryItem, item.Unit)
+		}
+	}
+	return nil
+}
+
+// parsePantryDate parses a YYYY-MM-DD expiry date; an empty string clears it
+func parsePantryDate(value string) (*time.Time, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: dates must use YYYY-MM-DD", ErrInvalidPantryItem)
+	}
+	return &date, nil
+}
+
+// BuildPantryPrompt describes the pantry for recipe generation, asking the
+// model to use the items that are about to expire first. It returns an empty
+// string when nothing usable is on hand.
+func BuildPantryPrompt(pantry []models.PantryItem, now time.Time) string {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var expiring, others []string
+	for _, item := range pantry {
+		if item.ExpiresAt != nil && item.ExpiresAt.Before(today) {
+			continue
+		}
+		if PantryItemExpiring(item, now) {
+			expiring = append(expiring, fmt.Sprintf("%s (expires %s)", PantryLine(item), item.ExpiresAt.Format(dateLayout)))
+		} else {
+			others = append(others, PantryLine(item))
+		}
+	}
+	if len(expiring) == 0 && len(others) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("Cook mainly from the ingredients I have on hand and keep extra purchases to a minimum.")
+	if len(expiring) > 0 {
+		b.WriteString(" Use these first because they expire soon: ")
+		b.WriteString(strings.Join(expiring, ", "))
+		b.WriteString(".")
+	}
+	if len(others) > 0 {
+		b.WriteString(" Also available: ")
+		b.WriteString(strings.Join(others, ", "))
+		b.WriteString(".")
+	}
+	return b.String()
+}
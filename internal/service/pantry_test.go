@@ -0,0 +1,76 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRankCookableRecipes(t *testing.T) {
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	soon := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	expired := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	pantry := []models.PantryItem{
+		{Name: "eggs", Quantity: 6},
+		{Name: "milk", Quantity: 500, Unit: "ml", ExpiresAt: &soon},
+		{Name: "cheddar"},
+		{Name: "spinach", ExpiresAt: &expired},
+	}
+	omelette := &models.Recipe{Name: "Omelette", Ingredients: models.JSONBStringArray{
+		"3 large eggs", "50 ml milk", "30 g cheddar cheese", "salt and pepper to taste",
+	}}
+	pancakes := &models.Recipe{Name: "Pancakes", Ingredients: models.JSONBStringArray{
+		"2 eggs", "3 cups milk", "200 g flour",
+	}}
+	curry := &models.Recipe{Name: "Curry", Ingredients: models.JSONBStringArray{
+		"400 ml coconut milk", "200 g spinach",
+	}}
+
+	ranked := RankCookableRecipes([]*models.Recipe{pancakes, curry, omelette}, pantry, now)
+	require.Len(t, ranked, 2, "nothing in the curry is on hand")
+
+	assert.Equal(t, "Omelette", ranked[0].Recipe.Name)
+	assert.Equal(t, 1.0, ranked[0].Coverage)
+	assert.Len(t, ranked[0].Matched, 3)
+	assert.Empty(t, ranked[0].Missing)
+	assert.Equal(t, []string{"milk"}, ranked[0].Expiring)
+
+	assert.Equal(t, "Pancakes", ranked[1].Recipe.Name)
+	assert.Equal(t, 0.33, ranked[1].Coverage)
+	assert.Equal(t, []string{"3 cups milk", "200 g flour"}, ranked[1].Missing, "500 ml is not enough milk")
+}
+
+func TestPantryKeysMatch(t *testing.T) {
+	assert.True(t, pantryKeysMatch("cheddar", "cheddar cheese"))
+	assert.True(t, pantryKeysMatch("chicken breast", "chicken"))
+	assert.False(t, pantryKeysMatch("milk", "coconut milk"))
+	assert.True(t, isPantryStaple("salt pepper"))
+	assert.False(t, isPantryStaple("bell pepper"))
+}
+
+func TestBuildPantryPrompt(t *testing.T) {
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	soon := time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC)
+	later := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	prompt := BuildPantryPrompt([]models.PantryItem{
+		{Name: "rice", Quantity: 1, Unit: "kg", ExpiresAt: &later},
+		{Name: "spinach", Quantity: 200, Unit: "g", ExpiresAt: &soon},
+	}, now)
+	assert.True(t, strings.Contains(prompt, "expire soon: 200 g spinach (expires 2024-03-06)"))
+	assert.True(t, strings.Contains(prompt, "Also available: 1 kg rice."))
+
+	assert.Empty(t, BuildPantryPrompt(nil, now))
+}
+
+func TestValidatePantryItem(t *testing.T) {
+	assert.NoError(t, validatePantryItem(&models.PantryItem{Name: "milk", Quantity: 1, Unit: "l"}))
+	assert.ErrorIs(t, validatePantryItem(&models.PantryItem{Name: "tomatoes", Quantity: 2, Unit: "cans"}), ErrInvalidPantryItem)
+	_, err := parsePantryDate("03/05/2024")
+	assert.ErrorIs(t, err, ErrInvalidPantryItem)
+}
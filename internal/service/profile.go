@@ -3,17 +3,23 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
 	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"github.com/pageza/alchemorsel-v2/backend/internal/units"
 	"gorm.io/gorm"
 )
 
 var (
 	ErrInvalidToken = errors.New("invalid token")
 	ErrTokenExpired = errors.New("token has expired")
+
+	ErrInvalidUnitSystem   = errors.New("unit_system must be metric or imperial")
+	ErrInvalidPrivacyLevel = errors.New("privacy_level must be private, unlisted or public")
 )
 
 // ProfileService handles user profile operations
@@ -58,8 +64,18 @@ func (s *ProfileService) UpdateProfile(ctx context.Context, userID uuid.UUID, re
 		profile.ProfilePictureURL = *req.ProfilePictureURL
 	}
 	if req.PrivacyLevel != nil {
+		if !ValidRecipeVisibility(*req.PrivacyLevel) {
+			return nil, ErrInvalidPrivacyLevel
+		}
 		profile.PrivacyLevel = *req.PrivacyLevel
 	}
+	if req.UnitSystem != nil {
+		system, ok := units.ParseSystem(*req.UnitSystem)
+		if !ok {
+			return nil, ErrInvalidUnitSystem
+		}
+		profile.UnitSystem = string(system)
+	}
 
 	if err := s.db.Save(&profile).Error; err != nil {
 		return nil, err
@@ -75,19 +91,23 @@ func (s *ProfileService) Logout(ctx context.Context, userID uuid.UUID) error {
 	return nil
 }
 
-// GetUserRecipes retrieves a user's recipes
-func (s *ProfileService) GetUserRecipes(ctx context.Context, userID uuid.UUID) ([]*models.Recipe, error) {
-	var recipes []models.Recipe
-	if err := s.db.Where("user_id = ?", userID).Find(&recipes).Error; err != nil {
-		return nil, err
+// GetUserRecipes retrieves one page of a user's recipes, newest first, with
+// the user's total recipe count
+func (s *ProfileService) GetUserRecipes(ctx context.Context, userID uuid.UUID, page pagination.Page) ([]*models.Recipe, *pagination.Info, error) {
+	query := s.db.WithContext(ctx).Model(&models.Recipe{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, nil, err
 	}
 
-	// Convert to []*models.Recipe
-	result := make([]*models.Recipe, len(recipes))
-	for i := range recipes {
-		result[i] = &recipes[i]
+	var recipes []*models.Recipe
+	if err := pagination.Keyset(query, page, "created_at", "id").Find(&recipes).Error; err != nil {
+		return nil, nil, err
 	}
-	return result, nil
+	recipes, info := pagination.Finish(recipes, page, recipeCursor)
+	info.Total = &total
+	return recipes, info, nil
 }
 
 // GetProfileHistory retrieves the change history for a user's profile
@@ -145,13 +165,97 @@ func (s *ProfileService) RecordProfileChange(userID, field, oldValue, newValue,
 	return s.db.Create(history).Error
 }
 
-// GetUserProfile retrieves a user's profile by username
-func (s *ProfileService) GetUserProfile(username string) (*models.UserProfile, error) {
-	var profile models.UserProfile
-	if err := s.db.Joins("JOIN users ON users.id = user_profiles.user_id").
-		Where("users.username = ?", username).
-		First(&profile).Error; err != nil {
+// PublicProfile is what anyone can see of a user: no email or settings, and
+// only their public recipes and collections. Recipes holds the first page of
+// recipes, newest first, and RecentActivity their latest public activity.
+type PublicProfile struct {
+	UserID            uuid.UUID            `json:"user_id"`
+	Username          string               `json:"username"`
+	Bio               string               `json:"bio"`
+	ProfilePictureURL string               `json:"profile_picture_url"`
+	JoinedAt          time.Time            `json:"joined_at"`
+	RecipeCount       int64                `json:"recipe_count"`
+	FollowerCount     int64                `json:"follower_count"`
+	FollowingCount    int64                `json:"following_count"`
+	Recipes           []*models.Recipe     `json:"recipes"`
+	RecipesNextCursor string               `json:"recipes_next_cursor,omitempty"`
+	Collections       []*models.Collection `json:"collections"`
+	RecentActivity    []*models.Activity   `json:"recent_activity"`
+}
+
+// recentActivityLimit is how many activities a public profile shows
+const recentActivityLimit = 10
+
+// GetUserProfile returns the public profile of the user with the username.
+// Private profiles are not found.
+func (s *ProfileService) GetUserProfile(ctx context.Context, username string) (*PublicProfile, error) {
+	db := s.db.WithContext(ctx)
+	profile, err := visibleProfile(db, uuid.Nil, username)
+	if err != nil {
 		return nil, err
 	}
-	return &profile, nil
+
+	public := &PublicProfile{
+		UserID:            profile.UserID,
+		Username:          profile.Username,
+		Bio:               profile.Bio,
+		ProfilePictureURL: profile.ProfilePictureURL,
+		JoinedAt:          profile.CreatedAt,
+	}
+	recipes, info, err := s.publicRecipes(db, profile.UserID, pagination.Page{})
+	if err != nil {
+		return nil, err
+	}
+	public.Recipes, public.RecipesNextCursor, public.RecipeCount = recipes, info.NextCursor, *info.Total
+
+	if err := db.Model(&models.Follow{}).Where("following_id = ?", profile.UserID).Count(&public.FollowerCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to count followers: %w", err)
+	}
+	if err := db.Model(&models.Follow{}).Where("follower_id = ?", profile.UserID).Count(&public.FollowingCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to count following: %w", err)
+	}
+
+	if err := withRecipeCount(db.Model(&models.Collection{}), uuid.Nil).
+		Where("collections.user_id = ? AND collections.privacy = ?", profile.UserID, models.CollectionPublic).
+		Order("collections.created_at").Order("collections.id").
+		Find(&public.Collections).Error; err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+
+	public.RecentActivity, _, err = listActivities(visibleActivities(db, uuid.Nil).
+		Where("activities.user_id = ?", profile.UserID), pagination.Page{Limit: recentActivityLimit})
+	if err != nil {
+		return nil, err
+	}
+	return public, nil
+}
+
+// GetPublicRecipes returns one page of the public recipes of the user with
+// the username, newest first, with their count. Private profiles are not
+// found.
+func (s *ProfileService) GetPublicRecipes(ctx context.Context, username string, page pagination.Page) ([]*models.Recipe, *pagination.Info, error) {
+	db := s.db.WithContext(ctx)
+	profile, err := visibleProfile(db, uuid.Nil, username)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.publicRecipes(db, profile.UserID, page)
+}
+
+// publicRecipes returns one page of a user's public recipes with their count
+func (s *ProfileService) publicRecipes(db *gorm.DB, userID uuid.UUID, page pagination.Page) ([]*models.Recipe, *pagination.Info, error) {
+	query := db.Model(&models.Recipe{}).Scopes(ListedRecipes(uuid.Nil)).Where("recipes.user_id = ?", userID)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to count recipes: %w", err)
+	}
+
+	var recipes []*models.Recipe
+	if err := pagination.Keyset(query, page, "recipes.created_at", "recipes.id").Find(&recipes).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to list recipes: %w", err)
+	}
+	recipes, info := pagination.Finish(recipes, page, recipeCursor)
+	info.Total = &total
+	return recipes, info, nil
 }
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUserProfileShowsOnlyPublicContent(t *testing.T) {
+	f, hermit := newSocialFixture(t)
+	s := NewProfileService(f.db)
+	ctx := context.Background()
+
+	public, private := uuid.New(), uuid.New()
+	require.NoError(t, f.db.Exec("INSERT INTO collections (id, user_id, name, privacy) VALUES (?, ?, 'Soups', ?), (?, ?, 'Secret', ?)",
+		public, f.author, models.CollectionPublic, private, f.author, models.CollectionPrivate).Error)
+	require.NoError(t, f.db.Exec("INSERT INTO collection_recipes (collection_id, recipe_id, position) VALUES (?, ?, 1), (?, ?, 2), (?, ?, 3)",
+		public, f.public, public, f.private, public, f.unlisted).Error)
+	require.NoError(t, f.db.Create([]models.Follow{
+		{FollowerID: f.viewer, FollowingID: f.author},
+		{FollowerID: hermit, FollowingID: f.author},
+	}).Error)
+	require.NoError(t, recordActivity(f.db, []models.Activity{
+		{UserID: f.author, Type: models.ActivityRecipePublished, RecipeID: f.private},
+		{UserID: f.author, Type: models.ActivityRecipePublished, RecipeID: f.public},
+	}))
+
+	profile, err := s.GetUserProfile(ctx, "author")
+	require.NoError(t, err)
+	assert.Equal(t, "Author", profile.Username)
+	assert.Equal(t, []uuid.UUID{f.public}, recipeIDs(profile.Recipes))
+	assert.Equal(t, int64(1), profile.RecipeCount)
+	assert.Equal(t, int64(2), profile.FollowerCount)
+	assert.Zero(t, profile.FollowingCount)
+	require.Len(t, profile.Collections, 1)
+	assert.Equal(t, public, profile.Collections[0].ID)
+	assert.Equal(t, 1, profile.Collections[0].RecipeCount)
+	require.Len(t, profile.RecentActivity, 1)
+	assert.Equal(t, f.public, profile.RecentActivity[0].RecipeID)
+
+	_, err = s.GetUserProfile(ctx, "hermit")
+	assert.True(t, errors.Is(err, ErrUserNotFound))
+	_, _, err = s.GetPublicRecipes(ctx, "hermit", pagination.Page{})
+	assert.True(t, errors.Is(err, ErrUserNotFound))
+
+	// Unlisted profiles can be opened by username
+	require.NoError(t, f.db.Exec("UPDATE user_profiles SET privacy_level = ? WHERE user_id = ?", models.RecipeUnlisted, hermit).Error)
+	profile, err = s.GetUserProfile(ctx, "hermit")
+	require.NoError(t, err)
+	assert.Empty(t, profile.Recipes)
+}
+
+func TestGetPublicRecipesPages(t *testing.T) {
+	f, _ := newSocialFixture(t)
+	s := NewProfileService(f.db)
+	ctx := context.Background()
+	require.NoError(t, f.db.Exec("UPDATE recipes SET visibility = ? WHERE user_id = ?", models.RecipePublic, f.author).Error)
+
+	recipes, info, err := s.GetPublicRecipes(ctx, "author", pagination.Page{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.public, f.unlisted}, recipeIDs(recipes))
+	assert.Equal(t, int64(3), *info.Total)
+
+	after, err := pagination.Decode(info.NextCursor)
+	require.NoError(t, err)
+	recipes, info, err = s.GetPublicRecipes(ctx, "author", pagination.Page{Limit: 2, After: after})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.private}, recipeIDs(recipes))
+	assert.Empty(t, info.NextCursor)
+}
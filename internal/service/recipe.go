@@ -3,131 +3,221 @@ package service
 import (
 	"context"
 	"errors"
+	"log"
 	"strings"
 
 	"github.com/google/uuid"
 	"github.com/pageza/alchemorsel-v2/backend/internal/model"
 	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
 	"gorm.io/gorm"
 )
 
 // RecipeService handles recipe operations
 type RecipeService struct {
-	db               *gorm.DB
-	embeddingService EmbeddingServiceInterface
+	db                *gorm.DB
+	embeddingService  EmbeddingServiceInterface
+	maxVectorDistance float64
 }
 
 // NewRecipeService creates a new RecipeService instance
 func NewRecipeService(db *gorm.DB, embeddingService EmbeddingServiceInterface) *RecipeService {
 	return &RecipeService{
-		db:               db,
-		embeddingService: embeddingService,
+		db:                db,
+		embeddingService:  embeddingService,
+		maxVectorDistance: maxVectorDistance(),
 	}
 }
 
-// CreateRecipe creates a new recipe
+// CreateRecipe creates a new recipe, embedding it if no embedding is given.
+// A recipe without a visibility gets its author's default.
 func (s *RecipeService) CreateRecipe(ctx context.Context, recipe *models.Recipe) (*models.Recipe, error) {
-	if err := s.db.Create(recipe).Error; err != nil {
+	if recipe.Visibility == "" {
+		recipe.Visibility = DefaultRecipeVisibility(ctx, s.db, recipe.UserID)
+	}
+	if EmbeddingMissing(recipe.Embedding) {
+		s.embed(recipe)
+	} else if recipe.EmbeddingHash == "" {
+		recipe.EmbeddingHash = RecipeEmbeddingHash(recipe)
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(recipe).Error; err != nil {
+			return err
+		}
+		activity := models.Activity{UserID: recipe.UserID, Type: models.ActivityRecipePublished, RecipeID: recipe.ID}
+		if recipe.ForkedFromID != nil {
+			activity.Type = models.ActivityRecipeForked
+		}
+		return recordActivity(tx, []models.Activity{activity})
+	})
+	if err != nil {
 		return nil, err
 	}
 	return recipe, nil
 }
 
-// GetRecipe retrieves a recipe by ID
-func (s *RecipeService) GetRecipe(ctx context.Context, id uuid.UUID) (*models.Recipe, error) {
+// GetRecipe retrieves a recipe by ID. Recipes the viewer cannot see are not
+// found.
+func (s *RecipeService) GetRecipe(ctx context.Context, viewerID, id uuid.UUID) (*models.Recipe, error) {
 	var recipe models.Recipe
-	if err := s.db.First(&recipe, "id = ?", id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(VisibleRecipes(viewerID)).First(&recipe, "recipes.id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &recipe, nil
 }
 
-// UpdateRecipe updates a recipe
-func (s *RecipeService) UpdateRecipe(ctx context.Context, id uuid.UUID, recipe *models.Recipe) (*models.Recipe, error) {
-	if err := s.db.Model(&models.Recipe{}).Where("id = ?", id).Updates(recipe).Error; err != nil {
+// UpdateRecipe updates one of the user's recipes and re-embeds it if the
+// embedded content changed. Other users' recipes are not found.
+func (s *RecipeService) UpdateRecipe(ctx context.Context, userID, id uuid.UUID, recipe *models.Recipe) (*models.Recipe, error) {
+	if err := s.ownRecipe(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Model(&models.Recipe{}).Where("id = ? AND user_id = ?", id, userID).Updates(recipe).Error; err != nil {
+		return nil, err
+	}
+	updated := &models.Recipe{}
+	if err := s.db.WithContext(ctx).Scopes(VisibleRecipes(userID)).First(updated, "recipes.id = ?", id).Error; err != nil {
 		return nil, err
 	}
-	return s.GetRecipe(ctx, id)
+
+	if s.embeddingStale(updated) && s.embed(updated) {
+		if err := s.db.Model(&models.Recipe{}).Where("id = ?", id).
+			UpdateColumns(embeddingColumns(updated)).Error; err != nil {
+			return nil, err
+		}
+	}
+	return updated, nil
 }
 
-// DeleteRecipe deletes a recipe
-func (s *RecipeService) DeleteRecipe(ctx context.Context, id uuid.UUID) error {
-	// First check if the recipe exists
+// ownRecipe returns gorm.ErrRecordNotFound unless the recipe exists and
+// belongs to the user, so other users cannot tell their recipes exist
+func (s *RecipeService) ownRecipe(ctx context.Context, userID, id uuid.UUID) error {
 	var recipe models.Recipe
-	if err := s.db.First(&recipe, "id = ?", id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return gorm.ErrRecordNotFound
-		}
+	if err := s.db.WithContext(ctx).Select("id, user_id").First(&recipe, "id = ?", id).Error; err != nil {
 		return err
 	}
+	if recipe.UserID != userID {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
 
-	// If recipe exists, delete it
-	return s.db.Delete(&models.Recipe{}, "id = ?", id).Error
+// embeddingStale reports whether the recipe's embedding is out of date: made
+// from other content, or by another model than the current one
+func (s *RecipeService) embeddingStale(recipe *models.Recipe) bool {
+	if s.embeddingService == nil {
+		return false
+	}
+	return recipe.EmbeddingHash != RecipeEmbeddingHash(recipe) ||
+		recipe.EmbeddingModel != s.embeddingService.Model() ||
+		recipe.EmbeddingDims != s.embeddingService.Dimensions()
 }
 
-// ListRecipes lists recipes for a user or all users if userID is nil
-func (s *RecipeService) ListRecipes(ctx context.Context, userID *uuid.UUID) ([]*models.Recipe, error) {
-	var recipes []models.Recipe
-	query := s.db
-	if userID != nil {
-		query = query.Where("user_id = ?", *userID)
+// embed sets the recipe's embedding, content hash and model, reporting
+// whether it succeeded. Failures are logged and left for the reindex worker.
+func (s *RecipeService) embed(recipe *models.Recipe) bool {
+	if s.embeddingService == nil {
+		return false
 	}
-	if err := query.Find(&recipes).Error; err != nil {
-		return nil, err
+	vec, err := s.embeddingService.GenerateEmbeddingFromRecipe(recipe.Name, recipe.Description, recipe.Ingredients, recipe.Category, recipe.DietaryPreferences)
+	if err != nil {
+		log.Printf("Failed to embed recipe %q: %v", recipe.Name, err)
+		return false
+	}
+	recipe.Embedding = vec
+	recipe.EmbeddingHash = RecipeEmbeddingHash(recipe)
+	recipe.EmbeddingModel = s.embeddingService.Model()
+	recipe.EmbeddingDims = s.embeddingService.Dimensions()
+	return true
+}
+
+// embeddingColumns are the columns written when a recipe is re-embedded
+func embeddingColumns(recipe *models.Recipe) map[string]interface{} {
+	return map[string]interface{}{
+		"embedding":            recipe.Embedding,
+		"embedding_hash":       recipe.EmbeddingHash,
+		"embedding_model":      recipe.EmbeddingModel,
+		"embedding_dimensions": recipe.EmbeddingDims,
+	}
+}
+
+// DeleteRecipe deletes one of the user's recipes. Other users' recipes are
+// not found.
+func (s *RecipeService) DeleteRecipe(ctx context.Context, userID, id uuid.UUID) error {
+	if err := s.ownRecipe(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Delete(&models.Recipe{}, "id = ? AND user_id = ?", id, userID).Error
+}
+
+// ListRecipes lists one page of the recipes listed to the viewer, newest
+// first: only ownerID's if it is set, otherwise all users'. The total is
+// counted only for a single user's recipes.
+func (s *RecipeService) ListRecipes(ctx context.Context, viewerID uuid.UUID, ownerID *uuid.UUID, page pagination.Page) ([]*models.Recipe, *pagination.Info, error) {
+	query := s.db.WithContext(ctx).Model(&models.Recipe{}).Scopes(ListedRecipes(viewerID))
+	if ownerID != nil {
+		query = query.Where("recipes.user_id = ?", *ownerID)
 	}
-	// Convert to []*models.Recipe
-	result := make([]*models.Recipe, len(recipes))
-	for i := range recipes {
-		result[i] = &recipes[i]
-	}
-	return result, nil
-}
-
-// SearchRecipes searches for recipes
-func (s *RecipeService) SearchRecipes(ctx context.Context, query string) ([]*models.Recipe, error) {
-	var recipes []models.Recipe
-
-	dbQuery := s.db
-
-	if query != "" {
-		if s.db.Dialector.Name() == "postgres" {
-			// Generate embedding for semantic search
-			vec, err := s.embeddingService.GenerateEmbedding(query)
-			if err != nil {
-				return nil, err
-			}
-
-			// Combine semantic and keyword search
-			// Use a subquery to get both semantic and keyword matches
-			subQuery := s.db.Model(&models.Recipe{}).
-				Select("id, embedding <-> ? as similarity", vec).
-				Where("LOWER(name) LIKE ? OR LOWER(description) LIKE ? OR LOWER(ingredients::text) LIKE ?",
-					"%"+strings.ToLower(query)+"%",
-					"%"+strings.ToLower(query)+"%",
-					"%"+strings.ToLower(query)+"%",
-				)
-
-			// Join with the main query and order by similarity
-			dbQuery = dbQuery.Joins("JOIN (?) as search ON recipes.id = search.id", subQuery).
-				Order("search.similarity ASC")
-		} else {
-			// Fallback to keyword search for non-PostgreSQL databases
-			like := "%" + strings.ToLower(query) + "%"
-			dbQuery = dbQuery.Where("LOWER(name) LIKE ? OR LOWER(description) LIKE ? OR LOWER(ingredients) LIKE ?",
-				like, like, like)
+
+	var total int64
+	if ownerID != nil {
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, nil, err
 		}
 	}
 
-	if err := dbQuery.Find(&recipes).Error; err != nil {
-		return nil, err
+	var recipes []*models.Recipe
+	if err := pagination.Keyset(query, page, "recipes.created_at", "recipes.id").Find(&recipes).Error; err != nil {
+		return nil, nil, err
+	}
+	recipes, info := pagination.Finish(recipes, page, recipeCursor)
+	if ownerID != nil {
+		info.Total = &total
+	}
+	return recipes, info, nil
+}
+
+// SearchRecipes searches the recipes listed to the viewer and returns one
+// page of results ranked by hybrid text and vector search. Without a query it
+// lists them newest first.
+func (s *RecipeService) SearchRecipes(ctx context.Context, viewerID uuid.UUID, query string, page pagination.Page) ([]*models.Recipe, *pagination.Info, error) {
+	if query == "" {
+		return s.ListRecipes(ctx, viewerID, nil, page)
+	}
+
+	if s.db.Dialector.Name() == "postgres" {
+		// Rank enough candidates to reach this page
+		depth := rankDepth(page)
+		ranked, err := s.hybridRank(ctx, query, ListedRecipes(viewerID), depth)
+		if err != nil {
+			return nil, nil, err
+		}
+		pageIDs, info := rankedPage(ranked, page)
+		recipes, err := s.loadRanked(ctx, pageIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+		return recipes, info, nil
 	}
 
-	// Convert to []*models.Recipe
-	result := make([]*models.Recipe, len(recipes))
-	for i := range recipes {
-		result[i] = &recipes[i]
+	// Fallback to keyword search for non-PostgreSQL databases
+	var recipes []*models.Recipe
+	like := "%" + strings.ToLower(query) + "%"
+	dbQuery := s.db.WithContext(ctx).Scopes(ListedRecipes(viewerID)).
+		Where("LOWER(name) LIKE ? OR LOWER(description) LIKE ? OR LOWER(ingredients) LIKE ?", like, like, like).
+		Order("created_at DESC").Order("id")
+
+	// Results are ranked, so pages are addressed by offset
+	if err := pagination.Offset(dbQuery, page).Find(&recipes).Error; err != nil {
+		return nil, nil, err
 	}
-	return result, nil
+	recipes, info := pagination.Finish(recipes, page, nil)
+	return recipes, info, nil
+}
+
+// recipeCursor returns the keyset position of a recipe
+func recipeCursor(r *models.Recipe) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: r.CreatedAt, ID: r.ID}
 }
 
 // FavoriteRecipe adds a recipe to user's favorites
@@ -142,44 +232,73 @@ func (s *RecipeService) FavoriteRecipe(ctx context.Context, userID, recipeID uui
 		return err
 	}
 
-	// Create new favorite
+	// Create new favorite, keeping the default collection in step
 	favorite := model.RecipeFavorite{
 		UserID:   userID,
 		RecipeID: recipeID,
 	}
 
-	return s.db.Create(&favorite).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&favorite).Error; err != nil {
+			return err
+		}
+		return addToDefaultCollection(tx, userID, recipeID)
+	})
 }
 
-// UnfavoriteRecipe removes a recipe from user's favorites
+// UnfavoriteRecipe removes a recipe from user's favorites and their default
+// collection
 func (s *RecipeService) UnfavoriteRecipe(ctx context.Context, userID, recipeID uuid.UUID) error {
-	result := s.db.Where("user_id = ? AND recipe_id = ?", userID, recipeID).Delete(&model.RecipeFavorite{})
-	if result.Error != nil {
-		return result.Error
-	}
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
-	}
-	return nil
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND recipe_id = ?", userID, recipeID).Delete(&model.RecipeFavorite{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return removeFromDefaultCollection(tx, userID, recipeID)
+	})
 }
 
-// GetFavoriteRecipes retrieves all favorite recipes for a user
-func (s *RecipeService) GetFavoriteRecipes(ctx context.Context, userID uuid.UUID) ([]*models.Recipe, error) {
-	var recipes []models.Recipe
-	
-	err := s.db.Table("recipes").
+// GetFavoriteRecipes retrieves one page of a user's favorite recipes, newest
+// recipes first, with the total number of favorites. Favorites their authors
+// have since made private are left out.
+func (s *RecipeService) GetFavoriteRecipes(ctx context.Context, userID uuid.UUID, page pagination.Page) ([]*models.Recipe, *pagination.Info, error) {
+	query := s.db.WithContext(ctx).Model(&models.Recipe{}).
 		Joins("JOIN recipe_favorites ON recipes.id = recipe_favorites.recipe_id").
 		Where("recipe_favorites.user_id = ?", userID).
-		Find(&recipes).Error
-	
-	if err != nil {
-		return nil, err
+		Scopes(VisibleRecipes(userID))
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, nil, err
 	}
 
-	// Convert to []*models.Recipe
-	result := make([]*models.Recipe, len(recipes))
-	for i := range recipes {
-		result[i] = &recipes[i]
+	var recipes []*models.Recipe
+	if err := pagination.Keyset(query, page, "recipes.created_at", "recipes.id").Find(&recipes).Error; err != nil {
+		return nil, nil, err
+	}
+	recipes, info := pagination.Finish(recipes, page, recipeCursor)
+	info.Total = &total
+	return recipes, info, nil
+}
+
+// FavoriteRecipeIDs reports which of the given recipes the user has favorited
+func (s *RecipeService) FavoriteRecipeIDs(ctx context.Context, userID uuid.UUID, recipeIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	favorites := make(map[uuid.UUID]bool)
+	if len(recipeIDs) == 0 {
+		return favorites, nil
+	}
+
+	var ids []uuid.UUID
+	if err := s.db.WithContext(ctx).Model(&model.RecipeFavorite{}).
+		Where("user_id = ? AND recipe_id IN ?", userID, recipeIDs).
+		Pluck("recipe_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		favorites[id] = true
 	}
-	return result, nil
+	return favorites, nil
 }
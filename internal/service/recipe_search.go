@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// Recipe search sort orders
+const (
+	SortNewest    = "newest"
+	SortRelevance = "relevance"
+	SortFavorites = "favorites"
+	SortQuickest  = "quickest"
+	SortPopular   = "popular"
+	SortTopRated  = "top_rated"
+)
+
+// Top rated ranks recipes by their Bayesian average rating: the mean of their
+// ratings and ratingPriorCount ratings of ratingPriorMean, so that a single
+// five-star review does not outrank dozens of good ones
+const (
+	ratingPriorCount = 5
+	ratingPriorMean  = 3.0
+)
+
+// facetLimit caps the values returned for each facet
+const facetLimit = 25
+
+// Facet names, used to leave a facet's own filter out of its counts.
+// facetAll leaves out every facet filter.
+const (
+	facetCategory = "category"
+	facetCuisine  = "cuisine"
+	facetTags     = "tags"
+	facetDietary  = "dietary"
+	facetAll      = "*"
+)
+
+// ErrInvalidSearch is returned for an unknown sort order or an empty range
+var ErrInvalidSearch = errors.New("invalid search")
+
+// NumberRange bounds a numeric filter. A nil end is open.
+type NumberRange struct {
+	Min *float64
+	Max *float64
+}
+
+// RecipeSearch holds the filters and sort order of a faceted recipe search.
+// Empty fields do not filter. Several categories or cuisines match any of
+// them, while several tags or dietary preferences must all be present.
+// Allergens exclude ingredients the same way as DietaryFilter.
+type RecipeSearch struct {
+	Query        string
+	Categories   []string
+	Cuisines     []string
+	Tags         []string
+	Dietary      []string
+	Calories     NumberRange
+	Protein      NumberRange
+	Carbs        NumberRange
+	Fat          NumberRange
+	MaxTotalTime int
+	Include      []string
+	Exclude      []string
+	Allergens    []string
+	// Restrictions are dietary preference names, such as "vegan", whose
+	// restricted ingredients are left out like allergens
+	Restrictions []string
+	// ViewerID is the user searching. Only public recipes and the viewer's
+	// own are found; a nil ID finds only public recipes.
+	ViewerID uuid.UUID
+	// Sort is one of the Sort constants. It defaults to relevance when there
+	// is a query and newest otherwise.
+	Sort string
+}
+
+// FacetCount is the number of matching recipes with a facet value
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// RecipeFacets counts matching recipes by category, cuisine, tag and dietary
+// preference. Each facet's counts ignore that facet's own filter so that
+// clients can offer the other values.
+type RecipeFacets struct {
+	Categories []FacetCount `json:"categories"`
+	Cuisines   []FacetCount `json:"cuisines"`
+	Tags       []FacetCount `json:"tags"`
+	Dietary    []FacetCount `json:"dietary"`
+}
+
+// RecipeSearchResult is one page of a faceted search with its facet counts.
+// Explanations holds the hybrid search scores of the page's recipes when the
+// search has a query.
+type RecipeSearchResult struct {
+	Recipes      []*models.Recipe
+	Info         *pagination.Info
+	Facets       RecipeFacets
+	Explanations map[uuid.UUID]ScoreExplanation
+}
+
+// sortOrder validates the search and returns its effective sort order
+func (q *RecipeSearch) sortOrder() (string, error) {
+	for name, r := range map[string]NumberRange{"calories": q.Calories, "protein": q.Protein, "carbs": q.Carbs, "fat": q.Fat} {
+		if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+			return "", fmt.Errorf("%w: min_%s is greater than max_%s", ErrInvalidSearch, name, name)
+		}
+	}
+	if q.MaxTotalTime < 0 {
+		return "", fmt.Errorf("%w: max_time must not be negative", ErrInvalidSearch)
+	}
+
+	switch q.Sort {
+	case "":
+		if q.Query != "" {
+			return SortRelevance, nil
+		}
+		return SortNewest, nil
+	case SortRelevance:
+		if q.Query == "" {
+			return SortNewest, nil
+		}
+		return SortRelevance, nil
+	case SortNewest, SortFavorites, SortQuickest, SortPopular, SortTopRated:
+		return q.Sort, nil
+	default:
+		return "", fmt.Errorf("%w: sort must be newest, relevance, favorites, quickest, popular or top_rated", ErrInvalidSearch)
+	}
+}
+
+// FacetedSearch returns one page of recipes matching the search, sorted as
+// requested, with facet counts and, without a query, the total match count. A query matches the
+// candidates of a hybrid text and vector search, which also gives the
+// relevance order. Newest-first results page by keyset; the other orders
+// page by offset.
+func (s *RecipeService) FacetedSearch(ctx context.Context, search *RecipeSearch, page pagination.Page) (*RecipeSearchResult, error) {
+	sortBy, err := search.sortOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	// Rank candidates under the filters that facets never leave out, so that
+	// facet counts see every candidate
+	var ranked []RankedRecipe
+	if search.Query != "" {
+		depth := rankDepth(page)
+		scope := func(db *gorm.DB) *gorm.DB { return applyRecipeSearch(db, search, facetAll) }
+		if ranked, err = s.hybridRank(ctx, search.Query, scope, depth); err != nil {
+			return nil, err
+		}
+	}
+	filtered := func(skip string) *gorm.DB {
+		db := applyRecipeSearch(s.db.WithContext(ctx).Model(&models.Recipe{}), search, skip)
+		if search.Query != "" {
+			db = db.Where("recipes.id IN ?", rankedIDs(ranked))
+		}
+		return db
+	}
+
+	query := filtered("").Select("recipes.*")
+	var recipes []*models.Recipe
+	var info *pagination.Info
+	switch sortBy {
+	case SortNewest:
+		if err := pagination.Keyset(query, page, "recipes.created_at", "recipes.id").Find(&recipes).Error; err != nil {
+			return nil, fmt.Errorf("failed to search recipes: %w", err)
+		}
+		recipes, info = pagination.Finish(recipes, page, recipeCursor)
+	case SortRelevance:
+		// Keep the ranked candidates that pass the facet filters
+		var ids []uuid.UUID
+		if err := filtered("").Pluck("recipes.id", &ids).Error; err != nil {
+			return nil, fmt.Errorf("failed to search recipes: %w", err)
+		}
+		matched := make(map[uuid.UUID]bool, len(ids))
+		for _, id := range ids {
+			matched[id] = true
+		}
+		var kept []RankedRecipe
+		for _, r := range ranked {
+			if matched[r.ID] {
+				kept = append(kept, r)
+			}
+		}
+		var pageIDs []RankedRecipe
+		pageIDs, info = rankedPage(kept, page)
+		if recipes, err = s.loadRanked(ctx, pageIDs); err != nil {
+			return nil, fmt.Errorf("failed to search recipes: %w", err)
+		}
+	default:
+		query = orderRecipes(query, sortBy)
+		if err := pagination.Offset(query, page).Find(&recipes).Error; err != nil {
+			return nil, fmt.Errorf("failed to search recipes: %w", err)
+		}
+		recipes, info = pagination.Finish(recipes, page, nil)
+	}
+	// Searches with a query only know the candidates ranked so far, so their
+	// count would understate the matches
+	if search.Query == "" {
+		var total int64
+		if err := filtered("").Count(&total).Error; err != nil {
+			return nil, fmt.Errorf("failed to count recipes: %w", err)
+		}
+		info.Total = &total
+	}
+
+	facets, err := recipeFacets(filtered)
+	if err != nil {
+		return nil, err
+	}
+	result := &RecipeSearchResult{Recipes: recipes, Info: info, Facets: *facets}
+	if search.Query != "" {
+		explanations := make(map[uuid.UUID]ScoreExplanation, len(ranked))
+		for _, r := range ranked {
+			explanations[r.ID] = r.Explanation
+		}
+		result.Explanations = make(map[uuid.UUID]ScoreExplanation, len(recipes))
+		for _, recipe := range recipes {
+			result.Explanations[recipe.ID] = explanations[recipe.ID]
+		}
+	}
+	return result, nil
+}
+
+// orderRecipes orders a recipe query by one of the offset-paged sort orders,
+// newest first among equals
+func orderRecipes(query *gorm.DB, sortBy string) *gorm.DB {
+	favorites := "LEFT JOIN (SELECT recipe_id, COUNT(*) AS favorite_count FROM recipe_favorites WHERE deleted_at IS NULL GROUP BY recipe_id) favorites ON favorites.recipe_id = recipes.id"
+	switch sortBy {
+	case SortFavorites:
+		query = query.Joins(favorites).Order("COALESCE(favorites.favorite_count, 0) DESC")
+	case SortPopular:
+		// Favorites and reviews both count as engagement
+		query = query.Joins(favorites).Order("COALESCE(favorites.favorite_count, 0) + recipes.rating_count DESC")
+	case SortTopRated:
+		query = query.
+			Order(fmt.Sprintf("(recipes.rating_average * recipes.rating_count + %g) / (recipes.rating_count + %d) DESC",
+				ratingPriorMean*ratingPriorCount, ratingPriorCount)).
+			Order("recipes.rating_count DESC")
+	default:
+		// Quickest first; recipes without times come last
+		query = query.Order("NULLIF(COALESCE(recipes.prep_time, 0) + COALESCE(recipes.cook_time, 0), 0) ASC NULLS LAST")
+	}
+	return query.Order("recipes.created_at DESC").Order("recipes.id DESC")
+}
+
+// recipeFacets counts the matching recipes for each facet, leaving out the
+// facet's own filter
+func recipeFacets(filtered func(skip string) *gorm.DB) (*RecipeFacets, error) {
+	facets := &RecipeFacets{}
+	columns := []struct {
+		facet  string
+		column string
+		counts *[]FacetCount
+	}{
+		{facetCategory, "recipes.category", &facets.Categories},
+		{facetCuisine, "recipes.cuisine", &facets.Cuisines},
+	}
+	for _, c := range columns {
+		*c.counts = []FacetCount{}
+		if err := filtered(c.facet).
+			Select(c.column + " AS value, COUNT(*) AS count").
+			Where("COALESCE(" + c.column + ", '') <> ''").
+			Group(c.column).Order("count DESC").Order("value").Limit(facetLimit).
+			Scan(c.counts).Error; err != nil {
+			return nil, fmt.Errorf("failed to count %s facet: %w", c.facet, err)
+		}
+	}
+
+	arrays := []struct {
+		facet  string
+		column string
+		counts *[]FacetCount
+	}{
+		{facetTags, "recipes.tags", &facets.Tags},
+		{facetDietary, "recipes.dietary_preferences", &facets.Dietary},
+	}
+	for _, a := range arrays {
+		*a.counts = []FacetCount{}
+		elements := fmt.Sprintf("CROSS JOIN LATERAL jsonb_array_elements_text(CASE WHEN jsonb_typeof(%s) = 'array' THEN %s ELSE '[]'::jsonb END) AS facet(value)", a.column, a.column)
+		if err := filtered(a.facet).
+			Select("facet.value AS value, COUNT(*) AS count").
+			Joins(elements).
+			Group("facet.value").Order("count DESC").Order("value").Limit(facetLimit).
+			Scan(a.counts).Error; err != nil {
+			return nil, fmt.Errorf("failed to count %s facet: %w", a.facet, err)
+		}
+	}
+	return facets, nil
+}
+
+// applyRecipeSearch adds the search's filters, other than the query, to a
+// recipes query, limited to the recipes listed to the viewer. The filter for
+// the facet named skip is left out.
+func applyRecipeSearch(db *gorm.DB, q *RecipeSearch, skip string) *gorm.DB {
+	db = db.Scopes(ListedRecipes(q.ViewerID))
+	applies := func(facet string) bool { return skip != facet && skip != facetAll }
+	if applies(facetCategory) && len(q.Categories) > 0 {
+		db = db.Where("LOWER(recipes.category) IN ?", lowerAll(q.Categories))
+	}
+	if applies(facetCuisine) && len(q.Cuisines) > 0 {
+		db = db.Where("LOWER(recipes.cuisine) IN ?", lowerAll(q.Cuisines))
+	}
+	if applies(facetTags) && len(q.Tags) > 0 {
+		db = db.Where("recipes.tags @> ?::jsonb", jsonArray(q.Tags))
+	}
+	if applies(facetDietary) && len(q.Dietary) > 0 {
+		db = db.Where("recipes.dietary_preferences @> ?::jsonb", jsonArray(q.Dietary))
+	}
+
+	ranges := []struct {
+		column string
+		r      NumberRange
+	}{
+		{"recipes.calories", q.Calories},
+		{"recipes.protein", q.Protein},
+		{"recipes.carbs", q.Carbs},
+		{"recipes.fat", q.Fat},
+	}
+	for _, c := range ranges {
+		if c.r.Min != nil {
+			db = db.Where(c.column+" >= ?", *c.r.Min)
+		}
+		if c.r.Max != nil {
+			db = db.Where(c.column+" <= ?", *c.r.Max)
+		}
+	}
+	if q.MaxTotalTime > 0 {
+		// Recipes without times are left out
+		db = db.Where("COALESCE(recipes.prep_time, 0) + COALESCE(recipes.cook_time, 0) BETWEEN 1 AND ?", q.MaxTotalTime)
+	}
+
+	for _, ingredient := range q.Include {
+		if ingredient = strings.TrimSpace(ingredient); ingredient != "" {
+			db = db.Where("LOWER(recipes.ingredients::text) LIKE ?", likePattern(ingredient))
+		}
+	}
+	for _, ingredient := range q.Exclude {
+		if ingredient = strings.TrimSpace(ingredient); ingredient != "" {
+			db = excludeIngredient(db, ingredientExclusion{keyword: strings.ToLower(ingredient)})
+		}
+	}
+	for _, ex := range NewDietaryFilter(q.Restrictions, q.Allergens).exclusions() {
+		db = excludeIngredient(db, ex)
+	}
+	return db
+}
+
+// excludeIngredient leaves out recipes whose ingredients mention the
+// exclusion's keyword outside its allowed longer names
+func excludeIngredient(db *gorm.DB, ex ingredientExclusion) *gorm.DB {
+	expr := "LOWER(recipes.ingredients::text)"
+	args := make([]interface{}, 0, len(ex.except)+1)
+	for _, name := range ex.except {
+		expr = "REPLACE(" + expr + ", ?, '')"
+		args = append(args, name)
+	}
+	args = append(args, likePattern(ex.keyword))
+	return db.Where(expr+" NOT LIKE ?", args...)
+}
+
+// likePattern returns a case-insensitive substring pattern for s, escaping
+// LIKE wildcards
+func likePattern(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(strings.ToLower(strings.TrimSpace(s)))
+	return "%" + s + "%"
+}
+
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, v := range values {
+		lowered[i] = strings.ToLower(strings.TrimSpace(v))
+	}
+	return lowered
+}
+
+// jsonArray encodes values as a JSON array for jsonb containment
+func jsonArray(values []string) string {
+	data, _ := json.Marshal(values)
+	return string(data)
+}
+
+// rankedIDs returns the IDs of ranked recipes. An empty ranking gives a nil
+// ID so that an IN clause matches nothing.
+func rankedIDs(ranked []RankedRecipe) []uuid.UUID {
+	if len(ranked) == 0 {
+		return []uuid.UUID{uuid.Nil}
+	}
+	ids := make([]uuid.UUID, len(ranked))
+	for i, r := range ranked {
+		ids[i] = r.ID
+	}
+	return ids
+}
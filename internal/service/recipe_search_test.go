@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecipeSearchSortOrder(t *testing.T) {
+	tests := []struct {
+		search RecipeSearch
+		want   string
+	}{
+		{RecipeSearch{}, SortNewest},
+		{RecipeSearch{Query: "soup"}, SortRelevance},
+		{RecipeSearch{Sort: SortRelevance}, SortNewest},
+		{RecipeSearch{Query: "soup", Sort: SortQuickest}, SortQuickest},
+		{RecipeSearch{Sort: SortFavorites}, SortFavorites},
+		{RecipeSearch{Sort: SortPopular}, SortPopular},
+		{RecipeSearch{Query: "soup", Sort: SortTopRated}, SortTopRated},
+	}
+	for _, tt := range tests {
+		got, err := tt.search.sortOrder()
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+
+	_, err := (&RecipeSearch{Sort: "oldest"}).sortOrder()
+	assert.ErrorIs(t, err, ErrInvalidSearch)
+
+	low, high := 500.0, 200.0
+	_, err = (&RecipeSearch{Calories: NumberRange{Min: &low, Max: &high}}).sortOrder()
+	assert.ErrorIs(t, err, ErrInvalidSearch)
+}
+
+func TestDietaryFilterExclusions(t *testing.T) {
+	assert.Empty(t, NewDietaryFilter(nil, nil).exclusions())
+
+	exclusions := make(map[string][]string)
+	for _, ex := range NewDietaryFilter(nil, []string{"milk", "sesame"}).exclusions() {
+		exclusions[ex.keyword] = ex.except
+	}
+
+	// Dairy names are excluded, keeping look-alikes that are not dairy
+	require.Contains(t, exclusions, "milk")
+	assert.Contains(t, exclusions["milk"], "coconut milk")
+	assert.Contains(t, exclusions["milk"], "almond milk")
+	assert.NotContains(t, exclusions["milk"], "buttermilk")
+	assert.Contains(t, exclusions["butter"], "peanut butter")
+	assert.Contains(t, exclusions, "cheese")
+	assert.NotContains(t, exclusions, "egg")
+
+	// Unknown allergens are matched by name
+	assert.Contains(t, exclusions, "sesame")
+
+	// Peanut butter stays excluded when nuts are too
+	for _, ex := range NewDietaryFilter(nil, []string{"dairy", "peanuts"}).exclusions() {
+		if ex.keyword == "butter" {
+			assert.NotContains(t, ex.except, "peanut butter")
+		}
+	}
+}
+
+func TestLikePattern(t *testing.T) {
+	assert.Equal(t, "%olive oil%", likePattern(" Olive Oil "))
+	assert.Equal(t, `%100\% rye\_flour%`, likePattern("100% rye_flour"))
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pgvector/pgvector-go"
+	"gorm.io/gorm"
+)
+
+// Recommendation tuning
+const (
+	// recommendationCandidateLimit is how many of the nearest recipes to the
+	// taste vector the diversity re-ranking chooses from
+	recommendationCandidateLimit = 100
+	// tasteSignalLimit caps the recipes of each kind read into a taste vector
+	tasteSignalLimit = 200
+	// tasteHalfLife is the age at which a recipe counts half as much towards
+	// the taste vector
+	tasteHalfLife = 30 * 24 * time.Hour
+	// mmrLambda trades relevance (1) against diversity (0)
+	mmrLambda = 0.7
+)
+
+// Taste signal weights. Cooking a recipe says less about taste than choosing
+// to favorite or publish it.
+const (
+	favoriteSignalWeight  = 1.0
+	publishedSignalWeight = 1.0
+	cookedSignalWeight    = 0.5
+)
+
+// Recommendation reasons
+const (
+	// ReasonTaste recommends a recipe close to the user's taste vector
+	ReasonTaste = "taste"
+	// ReasonPopular recommends a popular recipe to a user with no taste
+	// vector yet
+	ReasonPopular = "popular"
+)
+
+// Recommendation is a recipe recommended to a user. Similarity is the cosine
+// similarity to the user's taste vector, zero for popular recipes.
+type Recommendation struct {
+	Recipe     *models.Recipe `json:"recipe"`
+	Similarity float64        `json:"similarity"`
+	Reason     string         `json:"reason"`
+}
+
+// RecommendationService recommends recipes from a user's favorites,
+// published recipes and cooking history
+type RecommendationService struct {
+	db               *gorm.DB
+	embeddingService EmbeddingServiceInterface
+}
+
+// NewRecommendationService creates a new RecommendationService
+func NewRecommendationService(db *gorm.DB, embeddingService EmbeddingServiceInterface) *RecommendationService {
+	return &RecommendationService{
+		db:               db,
+		embeddingService: embeddingService,
+	}
+}
+
+// Recommend returns up to limit recipes the user has not authored, favorited
+// or planned, leaving out their saved dietary restrictions and allergens.
+// Recipes nearest to the user's taste vector are re-ranked for diversity;
+// users without one get the most favorited recipes.
+func (s *RecommendationService) Recommend(ctx context.Context, userID uuid.UUID, limit int) ([]Recommendation, error) {
+	preferences, err := LoadDietaryPreferences(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	allergens, err := LoadAllergens(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	filters := &RecipeSearch{Restrictions: preferences, Allergens: allergens, ViewerID: userID}
+
+	if s.embeddingService != nil {
+		signals, err := s.tasteSignals(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if taste := tasteVector(signals, time.Now()); taste != nil {
+			recommendations, err := s.personalized(ctx, userID, taste, filters, limit)
+			if err != nil || len(recommendations) > 0 {
+				return recommendations, err
+			}
+		}
+	}
+	return s.popular(ctx, userID, filters, limit)
+}
+
+// tasteSignal is the embedding of a recipe the user interacted with, when
+// they did and how much it counts
+type tasteSignal struct {
+	Embedding pgvector.Vector
+	At        time.Time
+	Weight    float64
+}
+
+// tasteSignals reads the embeddings of the user's most recent favorites,
+// published recipes and planned meals up to today. Recipes embedded by
+// another model are skipped.
+func (s *RecommendationService) tasteSignals(ctx context.Context, userID uuid.UUID) ([]tasteSignal, error) {
+	sources := []struct {
+		name   string
+		weight float64
+		query  *gorm.DB
+	}{
+		{"favorites", favoriteSignalWeight, s.db.WithContext(ctx).Table("recipe_favorites").
+			Select("recipes.embedding AS embedding, recipe_favorites.created_at AS at").
+			Joins("JOIN recipes ON recipes.id = recipe_favorites.recipe_id AND recipes.deleted_at IS NULL").
+			Where("recipe_favorites.user_id = ?", userID)},
+		{"published recipes", publishedSignalWeight, s.db.WithContext(ctx).Model(&models.Recipe{}).
+			Select("recipes.embedding AS embedding, recipes.created_at AS at").
+			Where("recipes.user_id = ?", userID)},
+		{"cooking history", cookedSignalWeight, s.db.WithContext(ctx).Table("meal_plan_entries").
+			Select("recipes.embedding AS embedding, meal_plan_entries.date AS at").
+			Joins("JOIN meal_plans ON meal_plans.id = meal_plan_entries.meal_plan_id AND meal_plans.deleted_at IS NULL").
+			Joins("JOIN recipes ON recipes.id = meal_plan_entries.recipe_id AND recipes.deleted_at IS NULL").
+			Where("meal_plans.user_id = ? AND meal_plan_entries.date <= CURRENT_DATE", userID)},
+	}
+
+	var signals []tasteSignal
+	for _, source := range sources {
+		var rows []tasteSignal
+		if err := source.query.Scopes(sameEmbeddingModel(s.embeddingService)).
+			Order("at DESC").Limit(tasteSignalLimit).
+			Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", source.name, err)
+		}
+		for _, row := range rows {
+			row.Weight = source.weight
+			signals = append(signals, row)
+		}
+	}
+	return signals, nil
+}
+
+// tasteVector averages the normalized signal embeddings, weighting each by its
+// kind and halving it for every tasteHalfLife of age. It returns nil when
+// there are no signals.
+func tasteVector(signals []tasteSignal, now time.Time) []float32 {
+	var sum []float64
+	for _, signal := range signals {
+		v := signal.Embedding.Slice()
+		norm := vectorNorm(v)
+		if norm == 0 {
+			continue
+		}
+		if sum == nil {
+			sum = make([]float64, len(v))
+		}
+		if len(v) != len(sum) {
+			continue
+		}
+		age := now.Sub(signal.At)
+		if age < 0 {
+			age = 0
+		}
+		weight := signal.Weight * math.Pow(0.5, float64(age)/float64(tasteHalfLife))
+		for i, x := range v {
+			sum[i] += weight * float64(x) / norm
+		}
+	}
+
+	taste := make([]float32, len(sum))
+	for i, x := range sum {
+		taste[i] = float32(x)
+	}
+	if vectorNorm(taste) == 0 {
+		return nil
+	}
+	return taste
+}
+
+// unseenBy leaves out recipes the user wrote, favorited or planned
+func unseenBy(userID uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("recipes.user_id <> ?", userID).
+			Where("NOT EXISTS (SELECT 1 FROM recipe_favorites WHERE recipe_favorites.recipe_id = recipes.id AND recipe_favorites.user_id = ?)", userID).
+			Where("NOT EXISTS (SELECT 1 FROM meal_plan_entries JOIN meal_plans ON meal_plans.id = meal_plan_entries.meal_plan_id "+
+				"WHERE meal_plan_entries.recipe_id = recipes.id AND meal_plans.user_id = ?)", userID)
+	}
+}
+
+// personalized picks recipes near the taste vector and re-ranks them for
+// diversity
+func (s *RecommendationService) personalized(ctx context.Context, userID uuid.UUID, taste []float32, filters *RecipeSearch, limit int) ([]Recommendation, error) {
+	var rows []struct {
+		ID        uuid.UUID
+		Embedding pgvector.Vector
+		Distance  float64
+	}
+	if err := applyRecipeSearch(s.db.WithContext(ctx).Model(&models.Recipe{}), filters, "").
+		Scopes(sameEmbeddingModel(s.embeddingService), unseenBy(userID)).
+		Select("recipes.id AS id, recipes.embedding AS embedding, "+embeddingDistance(s.embeddingService)+" AS distance", pgvector.NewVector(taste)).
+		Order("distance").Order("recipes.id").Limit(recommendationCandidateLimit).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to find recommended recipes: %w", err)
+	}
+
+	candidates := make([]mmrCandidate, len(rows))
+	for i, row := range rows {
+		candidates[i] = mmrCandidate{ID: row.ID, Embedding: row.Embedding.Slice(), Relevance: 1 - row.Distance}
+	}
+	selected := mmrSelect(candidates, limit, mmrLambda)
+
+	ids := make([]uuid.UUID, len(selected))
+	for i, c := range selected {
+		ids[i] = c.ID
+	}
+	recipes, err := loadRecipesInOrder(ctx, s.db, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recommended recipes: %w", err)
+	}
+	relevance := make(map[uuid.UUID]float64, len(selected))
+	for _, c := range selected {
+		relevance[c.ID] = c.Relevance
+	}
+	recommendations := make([]Recommendation, len(recipes))
+	for i, recipe := range recipes {
+		recommendations[i] = Recommendation{Recipe: recipe, Similarity: relevance[recipe.ID], Reason: ReasonTaste}
+	}
+	return recommendations, nil
+}
+
+// popular returns the most favorited recipes, newest first among equals
+func (s *RecommendationService) popular(ctx context.Context, userID uuid.UUID, filters *RecipeSearch, limit int) ([]Recommendation, error) {
+	var recipes []*models.Recipe
+	if err := applyRecipeSearch(s.db.WithContext(ctx).Model(&models.Recipe{}), filters, "").
+		Scopes(unseenBy(userID)).
+		Select("recipes.*").
+		Joins("LEFT JOIN (SELECT recipe_id, COUNT(*) AS favorite_count FROM recipe_favorites GROUP BY recipe_id) favorites ON favorites.recipe_id = recipes.id").
+		Order("COALESCE(favorites.favorite_count, 0) DESC").Order("recipes.created_at DESC").Order("recipes.id").
+		Limit(limit).
+		Find(&recipes).Error; err != nil {
+		return nil, fmt.Errorf("failed to find popular recipes: %w", err)
+	}
+	recommendations := make([]Recommendation, len(recipes))
+	for i, recipe := range recipes {
+		recommendations[i] = Recommendation{Recipe: recipe, Reason: ReasonPopular}
+	}
+	return recommendations, nil
+}
+
+// mmrCandidate is a recipe that diversity re-ranking can pick, with its
+// relevance to the user
+type mmrCandidate struct {
+	ID        uuid.UUID
+	Embedding []float32
+	Relevance float64
+}
+
+// mmrSelect picks up to k candidates by maximal marginal relevance. Each pick
+// maximizes lambda*relevance - (1-lambda)*(similarity to the closest earlier
+// pick), so near-duplicates of a pick give way to slightly less relevant
+// recipes that add variety.
+func mmrSelect(candidates []mmrCandidate, k int, lambda float64) []mmrCandidate {
+	remaining := append([]mmrCandidate{}, candidates...)
+	var selected []mmrCandidate
+	for len(selected) < k && len(remaining) > 0 {
+		best, bestScore := 0, math.Inf(-1)
+		for i, c := range remaining {
+			redundancy := 0.0
+			for _, s := range selected {
+				if sim := cosineSimilarity(c.Embedding, s.Embedding); sim > redundancy {
+					redundancy = sim
+				}
+			}
+			if score := lambda*c.Relevance - (1-lambda)*redundancy; score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		selected = append(selected, remaining[best])
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+	return selected
+}
+
+// cosineSimilarity returns the cosine of the angle between two vectors of the
+// same length, or zero if either is all zeros
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	na, nb := vectorNorm(a), vectorNorm(b)
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (na * nb)
+}
+
+// vectorNorm returns the Euclidean length of a vector
+func vectorNorm(v []float32) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	return math.Sqrt(sum)
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMMRSelect(t *testing.T) {
+	soup := mmrCandidate{ID: uuid.New(), Embedding: []float32{1, 0, 0}, Relevance: 0.95}
+	soupAgain := mmrCandidate{ID: uuid.New(), Embedding: []float32{0.99, 0.1, 0}, Relevance: 0.94}
+	salad := mmrCandidate{ID: uuid.New(), Embedding: []float32{0, 1, 0}, Relevance: 0.8}
+	candidates := []mmrCandidate{soup, soupAgain, salad}
+
+	// Pure relevance keeps the near-duplicate
+	picked := mmrSelect(candidates, 2, 1)
+	assert.Equal(t, []uuid.UUID{soup.ID, soupAgain.ID}, []uuid.UUID{picked[0].ID, picked[1].ID})
+
+	// Diversity prefers the different recipe
+	picked = mmrSelect(candidates, 2, 0.7)
+	assert.Equal(t, []uuid.UUID{soup.ID, salad.ID}, []uuid.UUID{picked[0].ID, picked[1].ID})
+
+	assert.Len(t, mmrSelect(candidates, 10, 0.7), 3)
+	assert.Empty(t, mmrSelect(nil, 5, 0.7))
+}
+
+func TestTasteVector(t *testing.T) {
+	now := time.Now()
+	assert.Nil(t, tasteVector(nil, now))
+
+	recent := tasteSignal{Embedding: pgvector.NewVector([]float32{2, 0}), At: now, Weight: 1}
+	old := tasteSignal{Embedding: pgvector.NewVector([]float32{0, 1}), At: now.Add(-2 * tasteHalfLife), Weight: 1}
+	taste := tasteVector([]tasteSignal{recent, old}, now)
+	require.Len(t, taste, 2)
+	// Embeddings are normalized, then the older one counts a quarter as much
+	assert.InDelta(t, 1, taste[0], 1e-6)
+	assert.InDelta(t, 0.25, taste[1], 1e-6)
+
+	// Zero vectors are ignored
+	missing := tasteSignal{Embedding: pgvector.NewVector([]float32{0, 0}), At: now, Weight: 1}
+	assert.Nil(t, tasteVector([]tasteSignal{missing}, now))
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pgvector/pgvector-go"
+	"gorm.io/gorm"
+)
+
+// DefaultReindexBatchSize is how many recipes a reindex pass scans at a time
+const DefaultReindexBatchSize = 50
+
+// ErrNoEmbeddingService is returned when embeddings are needed but no
+// embedding service is configured
+var ErrNoEmbeddingService = errors.New("no embedding service configured")
+
+// RecipeEmbeddingHash returns a hash of the recipe content that its embedding
+// is generated from. A stored hash that no longer matches means the embedding
+// is stale.
+func RecipeEmbeddingHash(recipe *models.Recipe) string {
+	text := RecipeEmbeddingText(recipe.Name, recipe.Description, recipe.Ingredients, recipe.Category, recipe.DietaryPreferences)
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// EmbeddingMissing reports whether a vector is empty or all zeros, as stored
+// for recipes that have not been embedded
+func EmbeddingMissing(v pgvector.Vector) bool {
+	for _, x := range v.Slice() {
+		if x != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ReindexOptions controls a reindex run
+type ReindexOptions struct {
+	// BatchSize is how many recipes are scanned per batch
+	BatchSize int
+	// RequestsPerMinute limits embedding requests; zero means no limit
+	RequestsPerMinute int
+	// After resumes the run after this recipe ID
+	After uuid.UUID
+	// Force re-embeds every recipe, not only missing or stale ones
+	Force bool
+	// Progress, if set, is called after each batch
+	Progress func(ReindexProgress)
+}
+
+// ReindexProgress counts the recipes handled so far. LastID is the last
+// recipe scanned; pass it as ReindexOptions.After to resume.
+type ReindexProgress struct {
+	Scanned  int       `json:"scanned"`
+	Embedded int       `json:"embedded"`
+	Failed   int       `json:"failed"`
+	LastID   uuid.UUID `json:"last_id"`
+	Done     bool      `json:"done"`
+}
+
+// EmbeddingReindexer re-embeds recipes whose embedding is missing or stale
+type EmbeddingReindexer struct {
+	db               *gorm.DB
+	embeddingService EmbeddingServiceInterface
+}
+
+// NewEmbeddingReindexer creates a new EmbeddingReindexer
+func NewEmbeddingReindexer(db *gorm.DB, embeddingService EmbeddingServiceInterface) *EmbeddingReindexer {
+	return &EmbeddingReindexer{
+		db:               db,
+		embeddingService: embeddingService,
+	}
+}
+
+// reindexRow is the part of a recipe a reindex pass reads
+type reindexRow struct {
+	ID                 uuid.UUID
+	Name               string
+	Description        string
+	Category           string
+	Ingredients        models.JSONBStringArray
+	DietaryPreferences models.JSONBStringArray
+	EmbeddingHash      string
+	EmbeddingModel     string
+	EmbeddingDims      int
+	Missing            bool
+}
+
+// Run scans recipes in ID order and re-embeds those that need it. Failed
+// recipes are logged and counted but do not stop the run; cancelling ctx
+// does, returning the progress so far.
+func (r *EmbeddingReindexer) Run(ctx context.Context, opts ReindexOptions) (*ReindexProgress, error) {
+	if r.embeddingService == nil {
+		return nil, ErrNoEmbeddingService
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultReindexBatchSize
+	}
+	var throttle <-chan time.Time
+	if opts.RequestsPerMinute > 0 {
+		ticker := time.NewTicker(time.Minute / time.Duration(opts.RequestsPerMinute))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	progress := &ReindexProgress{LastID: opts.After}
+	for {
+		var rows []reindexRow
+		if err := r.db.WithContext(ctx).Model(&models.Recipe{}).
+			Select("id, name, description, category, ingredients, dietary_preferences, embedding_hash, "+
+				"embedding_model, embedding_dimensions AS embedding_dims, "+
+				"(embedding IS NULL OR vector_norm(embedding) = 0) AS missing").
+			Where("id > ?", progress.LastID).
+			Order("id").Limit(batchSize).
+			Scan(&rows).Error; err != nil {
+			return progress, fmt.Errorf("failed to load recipes: %w", err)
+		}
+		if len(rows) == 0 {
+			progress.Done = true
+			if opts.Progress != nil {
+				opts.Progress(*progress)
+			}
+			return progress, nil
+		}
+
+		for _, row := range rows {
+			recipe := &models.Recipe{
+				Name:               row.Name,
+				Description:        row.Description,
+				Category:           row.Category,
+				Ingredients:        row.Ingredients,
+				DietaryPreferences: row.DietaryPreferences,
+				EmbeddingHash:      row.EmbeddingHash,
+				EmbeddingModel:     row.EmbeddingModel,
+				EmbeddingDims:      row.EmbeddingDims,
+			}
+			if opts.Force || row.Missing || r.stale(recipe) {
+				if throttle != nil {
+					select {
+					case <-ctx.Done():
+						return progress, ctx.Err()
+					case <-throttle:
+					}
+				}
+				if err := r.embed(ctx, row.ID, recipe); err != nil {
+					log.Printf("Failed to embed recipe %s: %v", row.ID, err)
+					progress.Failed++
+				} else {
+					progress.Embedded++
+				}
+			}
+			progress.Scanned++
+			progress.LastID = row.ID
+		}
+		if opts.Progress != nil {
+			opts.Progress(*progress)
+		}
+		if err := ctx.Err(); err != nil {
+			return progress, err
+		}
+	}
+}
+
+// stale reports whether a recipe's embedding was made from other content or
+// by another model than the current one
+func (r *EmbeddingReindexer) stale(recipe *models.Recipe) bool {
+	return recipe.EmbeddingHash != RecipeEmbeddingHash(recipe) ||
+		recipe.EmbeddingModel != r.embeddingService.Model() ||
+		recipe.EmbeddingDims != r.embeddingService.Dimensions()
+}
+
+// embed stores a fresh embedding with its content hash and model. The update
+// skips hooks and updated_at since the recipe content has not changed.
+func (r *EmbeddingReindexer) embed(ctx context.Context, id uuid.UUID, recipe *models.Recipe) error {
+	vec, err := r.embeddingService.GenerateEmbeddingFromRecipe(recipe.Name, recipe.Description, recipe.Ingredients, recipe.Category, recipe.DietaryPreferences)
+	if err != nil {
+		return err
+	}
+	recipe.Embedding = vec
+	recipe.EmbeddingHash = RecipeEmbeddingHash(recipe)
+	recipe.EmbeddingModel = r.embeddingService.Model()
+	recipe.EmbeddingDims = r.embeddingService.Dimensions()
+	return r.db.WithContext(ctx).Model(&models.Recipe{}).Where("id = ?", id).
+		UpdateColumns(embeddingColumns(recipe)).Error
+}
+
+// EmbeddingWorker re-embeds missing and stale recipes in the background
+type EmbeddingWorker struct {
+	reindexer *EmbeddingReindexer
+	interval  time.Duration
+	options   ReindexOptions
+}
+
+// NewEmbeddingWorker creates a worker that runs a reindex pass every interval
+func NewEmbeddingWorker(reindexer *EmbeddingReindexer, interval time.Duration, options ReindexOptions) *EmbeddingWorker {
+	return &EmbeddingWorker{
+		reindexer: reindexer,
+		interval:  interval,
+		options:   options,
+	}
+}
+
+// Run runs reindex passes until ctx is cancelled, starting with one right
+// away. A pass that fails part way is resumed from its last recipe.
+func (w *EmbeddingWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	opts := w.options
+	for {
+		progress, err := w.reindexer.Run(ctx, opts)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			log.Printf("Embedding reindex failed: %v", err)
+			if progress != nil {
+				opts.After = progress.LastID
+			}
+		default:
+			if progress.Embedded > 0 || progress.Failed > 0 {
+				log.Printf("Embedding reindex: scanned %d, embedded %d, failed %d", progress.Scanned, progress.Embedded, progress.Failed)
+			}
+			opts.After = uuid.Nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
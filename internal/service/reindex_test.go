@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecipeEmbeddingHash(t *testing.T) {
+	recipe := &models.Recipe{
+		Name:        "Tomato Soup",
+		Description: "Creamy and warming",
+		Category:    "Lunch",
+		Ingredients: models.JSONBStringArray{"tomatoes", "cream"},
+	}
+	hash := RecipeEmbeddingHash(recipe)
+	assert.Len(t, hash, 64)
+
+	// Fields outside the embedding text do not change the hash
+	recipe.Calories = 300
+	recipe.Tags = models.JSONBStringArray{"quick"}
+	assert.Equal(t, hash, RecipeEmbeddingHash(recipe))
+
+	recipe.Ingredients = append(recipe.Ingredients, "basil")
+	assert.NotEqual(t, hash, RecipeEmbeddingHash(recipe))
+}
+
+func TestEmbeddingMissing(t *testing.T) {
+	assert.True(t, EmbeddingMissing(pgvector.Vector{}))
+	assert.True(t, EmbeddingMissing(pgvector.NewVector(make([]float32, 4))))
+	assert.False(t, EmbeddingMissing(pgvector.NewVector([]float32{0, 0.5, 0, 0})))
+}
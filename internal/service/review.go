@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrRecipeNotFound is returned when a recipe does not exist
+	ErrRecipeNotFound = errors.New("recipe not found")
+	// ErrReviewNotFound is returned when a review does not exist or was
+	// written by another user
+	ErrReviewNotFound = errors.New("review not found")
+	// ErrInvalidReview is returned for ratings outside 1 to 5 and reviews of
+	// the reviewer's own recipe
+	ErrInvalidReview = errors.New("invalid review")
+	// ErrReviewExists is returned when the user has already reviewed the
+	// recipe
+	ErrReviewExists = errors.New("you have already reviewed this recipe")
+)
+
+// RatingSummary is a recipe's aggregate rating. Distribution counts the
+// ratings from 1 star at index 0 to 5 stars at index 4.
+type RatingSummary struct {
+	Average      float64 `json:"average"`
+	Count        int     `json:"count"`
+	MadeIt       int     `json:"made_it"`
+	Distribution [5]int  `json:"distribution"`
+}
+
+// ReviewService manages recipe ratings and reviews and keeps each recipe's
+// rating_average and rating_count in step with them
+type ReviewService struct {
+	db *gorm.DB
+}
+
+// Ensure ReviewService implements IReviewService
+var _ IReviewService = (*ReviewService)(nil)
+
+// NewReviewService creates a new ReviewService instance
+func NewReviewService(db *gorm.DB) *ReviewService {
+	return &ReviewService{db: db}
+}
+
+// CreateReview rates and reviews a recipe. Users review each recipe once and
+// cannot review their own.
+func (s *ReviewService) CreateReview(ctx context.Context, userID, recipeID uuid.UUID, req *types.CreateReviewRequest) (*models.RecipeReview, error) {
+	review := &models.RecipeReview{
+		RecipeID:      recipeID,
+		UserID:        userID,
+		Rating:        req.Rating,
+		Body:          strings.TrimSpace(req.Body),
+		MadeIt:        req.MadeIt,
+		Modifications: strings.TrimSpace(req.Modifications),
+	}
+	if err := validateReview(review); err != nil {
+		return nil, err
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		recipe, err := lockRecipe(tx, recipeID)
+		if err != nil {
+			return err
+		}
+		if !CanViewRecipe(recipe, userID) {
+			return ErrRecipeNotFound
+		}
+		if recipe.UserID == userID {
+			return fmt.Errorf("%w: you cannot review your own recipe", ErrInvalidReview)
+		}
+
+		var existing int64
+		if err := tx.Model(&models.RecipeReview{}).Where("recipe_id = ? AND user_id = ?", recipeID, userID).Count(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check for an existing review: %w", err)
+		}
+		if existing > 0 {
+			return ErrReviewExists
+		}
+
+		if err := tx.Create(review).Error; err != nil {
+			return fmt.Errorf("failed to create review: %w", err)
+		}
+		if err := recordActivity(tx, []models.Activity{{
+			UserID: userID, Type: models.ActivityRecipeReviewed, RecipeID: recipeID, ReviewID: &review.ID,
+		}}); err != nil {
+			return err
+		}
+		return syncRecipeRating(tx, recipeID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// UpdateReview edits one of the user's reviews
+func (s *ReviewService) UpdateReview(ctx context.Context, userID, reviewID uuid.UUID, req *types.UpdateReviewRequest) (*models.RecipeReview, error) {
+	var review models.RecipeReview
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.ownReview(tx, userID, reviewID, &review); err != nil {
+			return err
+		}
+		recipe, err := lockRecipe(tx, review.RecipeID)
+		if err != nil {
+			return err
+		}
+		if !CanViewRecipe(recipe, userID) {
+			return ErrRecipeNotFound
+		}
+
+		if req.Rating != nil {
+			review.Rating = *req.Rating
+		}
+		if req.Body != nil {
+			review.Body = strings.TrimSpace(*req.Body)
+		}
+		if req.MadeIt != nil {
+			review.MadeIt = *req.MadeIt
+		}
+		if req.Modifications != nil {
+			review.Modifications = strings.TrimSpace(*req.Modifications)
+		}
+		if err := validateReview(&review); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&review).Select("rating", "body", "made_it", "modifications").Updates(&review).Error; err != nil {
+			return fmt.Errorf("failed to update review: %w", err)
+		}
+		return syncRecipeRating(tx, review.RecipeID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// DeleteReview deletes one of the user's reviews
+func (s *ReviewService) DeleteReview(ctx context.Context, userID, reviewID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var review models.RecipeReview
+		if err := s.ownReview(tx, userID, reviewID, &review); err != nil {
+			return err
+		}
+		if _, err := lockRecipe(tx, review.RecipeID); err != nil {
+			return err
+		}
+		if err := tx.Delete(&review).Error; err != nil {
+			return fmt.Errorf("failed to delete review: %w", err)
+		}
+		return syncRecipeRating(tx, review.RecipeID)
+	})
+}
+
+// ListReviews returns one page of the reviews of a recipe the user can see,
+// newest first, with the total number of reviews
+func (s *ReviewService) ListReviews(ctx context.Context, userID, recipeID uuid.UUID, page pagination.Page) ([]*models.RecipeReview, *pagination.Info, error) {
+	if _, err := viewableRecipe(s.db.WithContext(ctx), recipeID, userID); err != nil {
+		return nil, nil, err
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.RecipeReview{}).
+		Where("recipe_reviews.recipe_id = ?", recipeID)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to count reviews: %w", err)
+	}
+
+	var reviews []*models.RecipeReview
+	if err := pagination.Keyset(query, page, "recipe_reviews.created_at", "recipe_reviews.id").
+		Select("recipe_reviews.*, user_profiles.username").
+		Joins("LEFT JOIN user_profiles ON user_profiles.user_id = recipe_reviews.user_id AND user_profiles.deleted_at IS NULL").
+		Find(&reviews).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to list reviews: %w", err)
+	}
+	reviews, info := pagination.Finish(reviews, page, reviewCursor)
+	info.Total = &total
+	return reviews, info, nil
+}
+
+// RatingSummary returns a recipe's aggregate rating with its distribution
+func (s *ReviewService) RatingSummary(ctx context.Context, recipeID uuid.UUID) (*RatingSummary, error) {
+	var rows []struct {
+		Rating int
+		Count  int
+		MadeIt int
+	}
+	if err := s.db.WithContext(ctx).Model(&models.RecipeReview{}).
+		Select("rating, COUNT(*) AS count, COUNT(*) FILTER (WHERE made_it) AS made_it").
+		Where("recipe_id = ?", recipeID).
+		Group("rating").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to summarize ratings: %w", err)
+	}
+
+	summary := &RatingSummary{}
+	total := 0
+	for _, row := range rows {
+		if row.Rating < 1 || row.Rating > 5 {
+			continue
+		}
+		summary.Distribution[row.Rating-1] = row.Count
+		summary.Count += row.Count
+		summary.MadeIt += row.MadeIt
+		total += row.Rating * row.Count
+	}
+	if summary.Count > 0 {
+		summary.Average = float64(total) / float64(summary.Count)
+	}
+	return summary, nil
+}
+
+// ownReview loads a review written by the user
+func (s *ReviewService) ownReview(tx *gorm.DB, userID, reviewID uuid.UUID, review *models.RecipeReview) error {
+	if err := tx.Where("id = ? AND user_id = ?", reviewID, userID).First(review).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrReviewNotFound
+		}
+		return fmt.Errorf("failed to get review: %w", err)
+	}
+	return nil
+}
+
+// lockRecipe loads a recipe and locks its row until the transaction ends, so
+// that concurrent reviews update its rating one at a time
+func lockRecipe(tx *gorm.DB, recipeID uuid.UUID) (*models.Recipe, error) {
+	var recipe models.Recipe
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "user_id", "visibility").
+		First(&recipe, "id = ?", recipeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecipeNotFound
+		}
+		return nil, fmt.Errorf("failed to get recipe: %w", err)
+	}
+	return &recipe, nil
+}
+
+// syncRecipeRating recomputes a recipe's rating_average and rating_count from
+// its reviews. The update skips hooks and updated_at since the recipe itself
+// has not changed.
+func syncRecipeRating(tx *gorm.DB, recipeID uuid.UUID) error {
+	if err := tx.Model(&models.Recipe{}).Where("id = ?", recipeID).UpdateColumns(map[string]interface{}{
+		"rating_count":   gorm.Expr("(SELECT COUNT(*) FROM recipe_reviews WHERE recipe_id = ?)", recipeID),
+		"rating_average": gorm.Expr("(SELECT COALESCE(AVG(rating), 0) FROM recipe_reviews WHERE recipe_id = ?)", recipeID),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update recipe rating: %w", err)
+	}
+	return nil
+}
+
+// validateReview checks the rating is between 1 and 5
+func validateReview(review *models.RecipeReview) error {
+	if review.Rating < 1 || review.Rating > 5 {
+		return fmt.Errorf("%w: rating must be between 1 and 5", ErrInvalidReview)
+	}
+	return nil
+}
+
+// reviewCursor returns the keyset position of a review
+func reviewCursor(r *models.RecipeReview) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: r.CreatedAt, ID: r.ID}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertRating checks the rating stored on a recipe
+func assertRating(t *testing.T, f *visibilityFixture, recipeID uuid.UUID, count int, average float64) {
+	t.Helper()
+	var recipe models.Recipe
+	require.NoError(t, f.db.Select("rating_count", "rating_average").First(&recipe, "id = ?", recipeID).Error)
+	assert.Equal(t, count, recipe.RatingCount)
+	assert.InDelta(t, average, recipe.RatingAverage, 0.001)
+}
+
+func TestCreateReview(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewReviewService(f.db)
+	ctx := context.Background()
+
+	// Ratings outside 1 to 5 are rejected
+	for _, rating := range []int{0, 6} {
+		_, err := s.CreateReview(ctx, f.viewer, f.public, &types.CreateReviewRequest{Rating: rating})
+		assert.ErrorIs(t, err, ErrInvalidReview)
+	}
+
+	review, err := s.CreateReview(ctx, f.viewer, f.public, &types.CreateReviewRequest{Rating: 4, Body: "  Lovely  ", MadeIt: true})
+	require.NoError(t, err)
+	assert.Equal(t, "Lovely", review.Body)
+	assertRating(t, f, f.public, 1, 4)
+
+	// Each user reviews a recipe once
+	_, err = s.CreateReview(ctx, f.viewer, f.public, &types.CreateReviewRequest{Rating: 5})
+	assert.ErrorIs(t, err, ErrReviewExists)
+	assertRating(t, f, f.public, 1, 4)
+
+	// Authors cannot review their own recipes
+	_, err = s.CreateReview(ctx, f.author, f.public, &types.CreateReviewRequest{Rating: 5})
+	assert.ErrorIs(t, err, ErrInvalidReview)
+
+	// Nor can anyone review a recipe they cannot see
+	_, err = s.CreateReview(ctx, f.viewer, f.private, &types.CreateReviewRequest{Rating: 5})
+	assert.ErrorIs(t, err, ErrRecipeNotFound)
+	_, err = s.CreateReview(ctx, f.viewer, uuid.New(), &types.CreateReviewRequest{Rating: 5})
+	assert.ErrorIs(t, err, ErrRecipeNotFound)
+}
+
+func TestUpdateAndDeleteReviewKeepRatingInStep(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewReviewService(f.db)
+	ctx := context.Background()
+	other := uuid.New()
+
+	mine, err := s.CreateReview(ctx, f.viewer, f.public, &types.CreateReviewRequest{Rating: 4})
+	require.NoError(t, err)
+	theirs, err := s.CreateReview(ctx, other, f.public, &types.CreateReviewRequest{Rating: 2})
+	require.NoError(t, err)
+	assertRating(t, f, f.public, 2, 3)
+
+	// Other users' reviews look missing
+	five := 5
+	_, err = s.UpdateReview(ctx, other, mine.ID, &types.UpdateReviewRequest{Rating: &five})
+	assert.ErrorIs(t, err, ErrReviewNotFound)
+	assert.ErrorIs(t, s.DeleteReview(ctx, other, mine.ID), ErrReviewNotFound)
+	assertRating(t, f, f.public, 2, 3)
+
+	updated, err := s.UpdateReview(ctx, f.viewer, mine.ID, &types.UpdateReviewRequest{Rating: &five})
+	require.NoError(t, err)
+	assert.Equal(t, 5, updated.Rating)
+	assertRating(t, f, f.public, 2, 3.5)
+
+	nine := 9
+	_, err = s.UpdateReview(ctx, f.viewer, mine.ID, &types.UpdateReviewRequest{Rating: &nine})
+	assert.ErrorIs(t, err, ErrInvalidReview)
+	assertRating(t, f, f.public, 2, 3.5)
+
+	require.NoError(t, s.DeleteReview(ctx, f.viewer, mine.ID))
+	assertRating(t, f, f.public, 1, 2)
+	require.NoError(t, s.DeleteReview(ctx, other, theirs.ID))
+	assertRating(t, f, f.public, 0, 0)
+}
+
+func TestRatingSummary(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewReviewService(f.db)
+	ctx := context.Background()
+
+	for _, req := range []types.CreateReviewRequest{
+		{Rating: 5, MadeIt: true},
+		{Rating: 5},
+		{Rating: 3, MadeIt: true},
+	} {
+		_, err := s.CreateReview(ctx, uuid.New(), f.public, &req)
+		require.NoError(t, err)
+	}
+
+	summary, err := s.RatingSummary(ctx, f.public)
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.Count)
+	assert.Equal(t, 2, summary.MadeIt)
+	assert.Equal(t, [5]int{0, 0, 1, 0, 2}, summary.Distribution)
+	assert.InDelta(t, 13.0/3, summary.Average, 0.001)
+
+	empty, err := s.RatingSummary(ctx, f.unlisted)
+	require.NoError(t, err)
+	assert.Equal(t, &RatingSummary{}, empty)
+}
+
+func TestOrderRecipesByRating(t *testing.T) {
+	f := newVisibilityFixture(t)
+	for id, rating := range map[uuid.UUID]struct {
+		average   float64
+		count     int
+		favorites int
+	}{
+		f.private:  {5, 1, 3},
+		f.unlisted: {4.5, 20, 0},
+		f.public:   {4, 4, 0},
+		f.own:      {0, 0, 6},
+	} {
+		require.NoError(t, f.db.Model(&models.Recipe{}).Where("id = ?", id).
+			UpdateColumns(map[string]interface{}{"rating_average": rating.average, "rating_count": rating.count}).Error)
+		for i := 0; i < rating.favorites; i++ {
+			require.NoError(t, f.db.Create(&models.RecipeFavorite{RecipeID: id, UserID: uuid.New()}).Error)
+		}
+	}
+
+	order := func(sortBy string) []uuid.UUID {
+		var recipes []*models.Recipe
+		require.NoError(t, orderRecipes(f.db.Model(&models.Recipe{}).Select("recipes.*"), sortBy).Find(&recipes).Error)
+		return recipeIDs(recipes)
+	}
+
+	// The prior of five 3-star ratings keeps a single 5-star rating (3.33)
+	// below four 4-star ratings (3.44), with no ratings at the prior mean
+	assert.Equal(t, []uuid.UUID{f.unlisted, f.public, f.private, f.own}, order(SortTopRated))
+
+	// Popularity adds favorites to ratings; ties fall back to newest first
+	assert.Equal(t, []uuid.UUID{f.unlisted, f.own, f.public, f.private}, order(SortPopular))
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// DefaultShareExpiry is how long a share link lasts when no expiry is given
+const DefaultShareExpiry = 7 * 24 * time.Hour
+
+// ErrShareNotFound is returned for share links that do not exist, are
+// forged, expired or revoked, or point at a recipe their creator can no
+// longer see
+var ErrShareNotFound = errors.New("share link not found")
+
+// ShareService manages signed share links to recipes. A link's token is its
+// ID and an HMAC of the ID under the server secret, so forged tokens are
+// turned away before the database is queried and no token is stored.
+type ShareService struct {
+	db     *gorm.DB
+	secret []byte
+}
+
+// Ensure ShareService implements IShareService
+var _ IShareService = (*ShareService)(nil)
+
+// NewShareService creates a new ShareService instance that signs tokens
+// with secret
+func NewShareService(db *gorm.DB, secret []byte) *ShareService {
+	return &ShareService{db: db, secret: secret}
+}
+
+// CreateRecipeShare creates a link to a recipe the user can see that lasts
+// for expiresIn, or DefaultShareExpiry if it is zero
+func (s *ShareService) CreateRecipeShare(ctx context.Context, userID, recipeID uuid.UUID, expiresIn time.Duration) (*models.RecipeShare, error) {
+	if _, err := s.recipe(ctx, userID, recipeID); err != nil {
+		return nil, err
+	}
+	if expiresIn <= 0 {
+		expiresIn = DefaultShareExpiry
+	}
+
+	share := &models.RecipeShare{
+		ID:        uuid.New(),
+		RecipeID:  recipeID,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(expiresIn),
+	}
+	if err := s.db.WithContext(ctx).Create(share).Error; err != nil {
+		return nil, fmt.Errorf("failed to create share link: %w", err)
+	}
+	share.Token = s.sign(share.ID)
+	return share, nil
+}
+
+// ListRecipeShares returns the links to a recipe, newest first: every link
+// for the recipe's author and the user's own links for anyone else
+func (s *ShareService) ListRecipeShares(ctx context.Context, userID, recipeID uuid.UUID) ([]*models.RecipeShare, error) {
+	recipe, err := s.recipe(ctx, userID, recipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.db.WithContext(ctx).Where("recipe_id = ?", recipeID)
+	if recipe.UserID != userID {
+		query = query.Where("user_id = ?", userID)
+	}
+	var shares []*models.RecipeShare
+	if err := query.Order("created_at DESC").Order("id").Find(&shares).Error; err != nil {
+		return nil, fmt.Errorf("failed to list share links: %w", err)
+	}
+	for _, share := range shares {
+		share.Token = s.sign(share.ID)
+	}
+	return shares, nil
+}
+
+// RevokeRecipeShare revokes a link to a recipe. Links can be revoked by the
+// user who created them and by the recipe's author; revoking a revoked link
+// does nothing.
+func (s *ShareService) RevokeRecipeShare(ctx context.Context, userID, recipeID, shareID uuid.UUID) error {
+	recipe, err := s.recipe(ctx, userID, recipeID)
+	if err != nil {
+		return err
+	}
+
+	var share models.RecipeShare
+	if err := s.db.WithContext(ctx).Where("id = ? AND recipe_id = ?", shareID, recipeID).First(&share).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrShareNotFound
+		}
+		return fmt.Errorf("failed to get share link: %w", err)
+	}
+	if share.UserID != userID && recipe.UserID != userID {
+		return ErrShareNotFound
+	}
+
+	if err := s.db.WithContext(ctx).Model(&models.RecipeShare{}).
+		Where("id = ? AND revoked_at IS NULL", shareID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	}
+	return nil
+}
+
+// GetSharedRecipe returns the recipe a share link points at and counts the
+// access. The link stops working once it expires or is revoked, or if its
+// creator can no longer see the recipe.
+func (s *ShareService) GetSharedRecipe(ctx context.Context, token string) (*models.Recipe, error) {
+	id, ok := s.verify(token)
+	if !ok {
+		return nil, ErrShareNotFound
+	}
+
+	now := time.Now()
+	var share models.RecipeShare
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND revoked_at IS NULL AND expires_at > ?", id, now).
+		First(&share).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShareNotFound
+		}
+		return nil, fmt.Errorf("failed to get share link: %w", err)
+	}
+
+	var recipe models.Recipe
+	if err := s.db.WithContext(ctx).Scopes(VisibleRecipes(share.UserID)).
+		First(&recipe, "recipes.id = ?", share.RecipeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShareNotFound
+		}
+		return nil, fmt.Errorf("failed to get recipe: %w", err)
+	}
+
+	if err := s.db.WithContext(ctx).Model(&models.RecipeShare{}).Where("id = ?", share.ID).
+		Updates(map[string]interface{}{
+			"access_count":     gorm.Expr("access_count + 1"),
+			"last_accessed_at": now,
+		}).Error; err != nil {
+		return nil, fmt.Errorf("failed to count share link access: %w", err)
+	}
+	return &recipe, nil
+}
+
+// recipe returns a recipe the user can see, with its author
+func (s *ShareService) recipe(ctx context.Context, userID, recipeID uuid.UUID) (*models.Recipe, error) {
+	var recipe models.Recipe
+	if err := s.db.WithContext(ctx).Scopes(VisibleRecipes(userID)).
+		Select("recipes.id, recipes.user_id, recipes.visibility").
+		First(&recipe, "recipes.id = ?", recipeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecipeNotFound
+		}
+		return nil, fmt.Errorf("failed to get recipe: %w", err)
+	}
+	return &recipe, nil
+}
+
+// sign returns the token for a share link: its ID in hex, a dot and the
+// ID's HMAC-SHA256 in unpadded base64url
+func (s *ShareService) sign(id uuid.UUID) string {
+	return hex.EncodeToString(id[:]) + "." + base64.RawURLEncoding.EncodeToString(s.mac(id))
+}
+
+// verify returns the share ID in a token if its signature is valid
+func (s *ShareService) verify(token string) (uuid.UUID, bool) {
+	rawID, rawMAC, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, false
+	}
+	b, err := hex.DecodeString(rawID)
+	if err != nil || len(b) != len(uuid.UUID{}) {
+		return uuid.Nil, false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(rawMAC)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	id, _ := uuid.FromBytes(b)
+	if !hmac.Equal(mac, s.mac(id)) {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (s *ShareService) mac(id uuid.UUID) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte("recipe-share:"))
+	h.Write(id[:])
+	return h.Sum(nil)
+}
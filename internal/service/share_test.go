@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newShareFixture(t *testing.T) (*visibilityFixture, *ShareService) {
+	f := newVisibilityFixture(t)
+	return f, NewShareService(f.db, []byte("secret"))
+}
+
+func TestShareTokenSignature(t *testing.T) {
+	s := NewShareService(nil, []byte("secret"))
+	id := uuid.New()
+	token := s.sign(id)
+
+	got, ok := s.verify(token)
+	require.True(t, ok)
+	assert.Equal(t, id, got)
+
+	rawID, rawMAC, _ := strings.Cut(token, ".")
+	other := uuid.New()
+	for _, forged := range []string{
+		"",
+		rawID,
+		rawID + ".",
+		strings.Replace(token, rawID[:1], "x", 1),
+		other.String() + "." + rawMAC,
+		NewShareService(nil, []byte("other")).sign(id),
+	} {
+		_, ok := s.verify(forged)
+		assert.False(t, ok, "token %q", forged)
+	}
+}
+
+func TestGetSharedRecipeCountsAccesses(t *testing.T) {
+	f, s := newShareFixture(t)
+	ctx := context.Background()
+
+	share, err := s.CreateRecipeShare(ctx, f.author, f.private, 0)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(DefaultShareExpiry), share.ExpiresAt, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		recipe, err := s.GetSharedRecipe(ctx, share.Token)
+		require.NoError(t, err)
+		assert.Equal(t, f.private, recipe.ID)
+	}
+
+	shares, err := s.ListRecipeShares(ctx, f.author, f.private)
+	require.NoError(t, err)
+	require.Len(t, shares, 1)
+	assert.Equal(t, int64(2), shares[0].AccessCount)
+	assert.NotNil(t, shares[0].LastAccessedAt)
+	assert.Equal(t, share.Token, shares[0].Token)
+}
+
+func TestSharedRecipeLinksStopWorking(t *testing.T) {
+	f, s := newShareFixture(t)
+	ctx := context.Background()
+
+	// Only recipes the user can see can be shared
+	_, err := s.CreateRecipeShare(ctx, f.viewer, f.private, time.Hour)
+	assert.True(t, errors.Is(err, ErrRecipeNotFound))
+
+	expired, err := s.CreateRecipeShare(ctx, f.author, f.public, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, f.db.Model(&models.RecipeShare{}).Where("id = ?", expired.ID).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+	_, err = s.GetSharedRecipe(ctx, expired.Token)
+	assert.True(t, errors.Is(err, ErrShareNotFound))
+
+	revoked, err := s.CreateRecipeShare(ctx, f.author, f.public, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, s.RevokeRecipeShare(ctx, f.author, f.public, revoked.ID))
+	_, err = s.GetSharedRecipe(ctx, revoked.Token)
+	assert.True(t, errors.Is(err, ErrShareNotFound))
+
+	// A link by another user dies when the author makes the recipe private
+	reshared, err := s.CreateRecipeShare(ctx, f.viewer, f.unlisted, time.Hour)
+	require.NoError(t, err)
+	_, err = s.GetSharedRecipe(ctx, reshared.Token)
+	require.NoError(t, err)
+	require.NoError(t, f.db.Exec("UPDATE recipes SET visibility = ? WHERE id = ?", models.RecipePrivate, f.unlisted).Error)
+	_, err = s.GetSharedRecipe(ctx, reshared.Token)
+	assert.True(t, errors.Is(err, ErrShareNotFound))
+}
+
+func TestRecipeShareManagement(t *testing.T) {
+	f, s := newShareFixture(t)
+	ctx := context.Background()
+
+	byAuthor, err := s.CreateRecipeShare(ctx, f.author, f.public, time.Hour)
+	require.NoError(t, err)
+	byViewer, err := s.CreateRecipeShare(ctx, f.viewer, f.public, time.Hour)
+	require.NoError(t, err)
+
+	shares, err := s.ListRecipeShares(ctx, f.viewer, f.public)
+	require.NoError(t, err)
+	require.Len(t, shares, 1)
+	assert.Equal(t, byViewer.ID, shares[0].ID)
+
+	shares, err = s.ListRecipeShares(ctx, f.author, f.public)
+	require.NoError(t, err)
+	assert.Len(t, shares, 2)
+
+	// Other users cannot revoke the author's links, but the author can
+	// revoke theirs
+	err = s.RevokeRecipeShare(ctx, f.viewer, f.public, byAuthor.ID)
+	assert.True(t, errors.Is(err, ErrShareNotFound))
+	require.NoError(t, s.RevokeRecipeShare(ctx, f.author, f.public, byViewer.ID))
+	require.NoError(t, s.RevokeRecipeShare(ctx, f.author, f.public, byViewer.ID))
+
+	err = s.RevokeRecipeShare(ctx, f.author, f.unlisted, byAuthor.ID)
+	assert.True(t, errors.Is(err, ErrShareNotFound))
+}
@@ -0,0 +1,622 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"github.com/pageza/alchemorsel-v2/backend/internal/units"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrShoppingListNotFound is returned when a list or item does not exist or
+	// belongs to another user
+	ErrShoppingListNotFound = errors.New("shopping list not found")
+	// ErrInvalidShoppingList is returned when a list request names no recipes
+	// or names recipes that do not exist
+	ErrInvalidShoppingList = errors.New("invalid shopping list")
+)
+
+// ShoppingSource is a recipe to shop for, scaled by Factor
+type ShoppingSource struct {
+	Recipe *models.Recipe
+	Factor float64
+}
+
+// ShoppingListService generates and manages shopping lists
+type ShoppingListService struct {
+	db              *gorm.DB
+	recipeService   IRecipeService
+	mealPlanService IMealPlanService
+}
+
+// Ensure ShoppingListService implements IShoppingListService
+var _ IShoppingListService = (*ShoppingListService)(nil)
+
+// NewShoppingListService creates a new ShoppingListService instance
+func NewShoppingListService(db *gorm.DB, recipeService IRecipeService, mealPlanService IMealPlanService) *ShoppingListService {
+	return &ShoppingListService{
+		db:              db,
+		recipeService:   recipeService,
+		mealPlanService: mealPlanService,
+	}
+}
+
+// CreateShoppingList merges the ingredients of the requested recipes and meal
+// plan into a new list, minus anything in the pantry
+func (s *ShoppingListService) CreateShoppingList(ctx context.Context, userID uuid.UUID, req *types.CreateShoppingListRequest, system units.System) (*models.ShoppingList, error) {
+	if len(req.Recipes) == 0 && req.MealPlanID == nil {
+		return nil, fmt.Errorf("%w: add recipes or a meal plan", ErrInvalidShoppingList)
+	}
+
+	var sources []ShoppingSource
+	for _, r := range req.Recipes {
+		recipe, err := s.recipeService.GetRecipe(ctx, userID, r.RecipeID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: recipe %s not found", ErrInvalidShoppingList, r.RecipeID)
+			}
+			return nil, err
+		}
+		sources = append(sources, ShoppingSource{Recipe: recipe, Factor: servingsFactor(r.Servings, recipe.Servings)})
+	}
+
+	name := req.Name
+	if req.MealPlanID != nil {
+		plan, err := s.mealPlanService.GetMealPlan(ctx, userID, *req.MealPlanID)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range plan.Entries {
+			if entry.Recipe == nil {
+				continue
+			}
+			sources = append(sources, ShoppingSource{Recipe: entry.Recipe, Factor: servingsFactor(float64(entry.Servings), entry.Recipe.Servings)})
+		}
+		if name == "" {
+			name = plan.Name
+		}
+	}
+	if name == "" {
+		name = "Shopping list"
+	}
+
+	list := &models.ShoppingList{
+		UserID:     userID,
+		Name:       name,
+		MealPlanID: req.MealPlanID,
+	}
+	pantry := req.Pantry
+	if req.UsePantry {
+		var stored []models.PantryItem
+		if err := s.db.WithContext(ctx).Where("user_id = ? AND (expires_at IS NULL OR expires_at >= CURRENT_DATE)", userID).
+			Find(&stored).Error; err != nil {
+			return nil, fmt.Errorf("failed to load pantry: %w", err)
+		}
+		for _, item := range stored {
+			pantry = append(pantry, PantryLine(item))
+		}
+	}
+	items := BuildShoppingList(sources, pantry, system)
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Items").Create(list).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].ShoppingListID = list.ID
+		}
+		if len(items) > 0 {
+			return tx.Create(&items).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shopping list: %w", err)
+	}
+	return s.GetShoppingList(ctx, userID, list.ID)
+}
+
+// GetShoppingList returns a list with its items in aisle order
+func (s *ShoppingListService) GetShoppingList(ctx context.Context, userID, id uuid.UUID) (*models.ShoppingList, error) {
+	var list models.ShoppingList
+	err := s.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&list).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShoppingListNotFound
+		}
+		return nil, fmt.Errorf("failed to get shopping list: %w", err)
+	}
+	return &list, nil
+}
+
+// ListShoppingLists returns the user's lists, newest first, without items
+func (s *ShoppingListService) ListShoppingLists(ctx context.Context, userID uuid.UUID) ([]*models.ShoppingList, error) {
+	var lists []*models.ShoppingList
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&lists).Error; err != nil {
+		return nil, fmt.Errorf("failed to list shopping lists: %w", err)
+	}
+	return lists, nil
+}
+
+// DeleteShoppingList deletes a list
+func (s *ShoppingListService) DeleteShoppingList(ctx context.Context, userID, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&models.ShoppingList{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete shopping list: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrShoppingListNotFound
+	}
+	return nil
+}
+
+// SetItemChecked checks off or unchecks an item
+func (s *ShoppingListService) SetItemChecked(ctx context.Context, userID, listID, itemID uuid.UUID, checked bool) (*models.ShoppingListItem, error) {
+	if _, err := s.GetShoppingList(ctx, userID, listID); err != nil {
+		return nil, err
+	}
+
+	var item models.ShoppingListItem
+	if err := s.db.WithContext(ctx).Where("id = ? AND shopping_list_id = ?", itemID, listID).First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShoppingListNotFound
+		}
+		return nil, fmt.Errorf("failed to get shopping list item: %w", err)
+	}
+	if err := s.db.WithContext(ctx).Model(&item).Update("checked", checked).Error; err != nil {
+		return nil, fmt.Errorf("failed to update shopping list item: %w", err)
+	}
+	item.Checked = checked
+	return &item, nil
+}
+
+// SubtractPantry removes ingredients already on hand from an existing list.
+// Items that are fully covered are deleted and partly covered items keep the
+// remaining amount.
+func (s *ShoppingListService) SubtractPantry(ctx context.Context, userID, listID uuid.UUID, pantry []string, system units.System) (*models.ShoppingList, error) {
+	list, err := s.GetShoppingList(ctx, userID, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, removed := SubtractPantryItems(list.Items, pantry, system)
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(removed) > 0 {
+			if err := tx.Where("id IN ? AND shopping_list_id = ?", removed, list.ID).Delete(&models.ShoppingListItem{}).Error; err != nil {
+				return err
+			}
+		}
+		for _, item := range updated {
+			if err := tx.Model(&models.ShoppingListItem{}).Where("id = ?", item.ID).
+				Updates(map[string]interface{}{"quantity": item.Quantity, "unit": item.Unit, "display": item.Display}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update shopping list: %w", err)
+	}
+	return s.GetShoppingList(ctx, userID, listID)
+}
+
+// servingsFactor scales a recipe to the wanted servings. Recipes with an
+// unknown yield, or requests without servings, are used as written.
+func servingsFactor(want float64, yield int) float64 {
+	if want <= 0 || yield <= 0 {
+		return 1
+	}
+	return want / float64(yield)
+}
+
+// shoppingDimension says how an amount is measured. Counted items such as
+// "2 eggs" have a quantity but no unit; unquantified items such as "salt to
+// taste" have neither.
+type shoppingDimension string
+
+const (
+	shoppingMass    shoppingDimension = "mass"
+	shoppingVolume  shoppingDimension = "volume"
+	shoppingCount   shoppingDimension = "count"
+	shoppingNoQuant shoppingDimension = ""
+)
+
+// shoppingAmount is an ingredient amount in base units: grams, millilitres or
+// a count
+type shoppingAmount struct {
+	key       string
+	name      string
+	dimension shoppingDimension
+	amount    float64
+	recipes   []string
+}
+
+// parseShoppingAmount parses an ingredient line, preferring the upper end of
+// a range since a shopping list should cover the whole recipe
+func parseShoppingAmount(line string, factor float64) (shoppingAmount, bool) {
+	ing := units.ParseIngredient(line)
+	key := strings.Join(normalizeFoodName(ing.Name), " ")
+	if key == "" {
+		return shoppingAmount{}, false
+	}
+
+	a := shoppingAmount{key: key, name: shoppingItemName(ing.Name)}
+	if !ing.HasQuantity {
+		return a, true
+	}
+	qty := ing.Quantity
+	if ing.MaxQuantity > qty {
+		qty = ing.MaxQuantity
+	}
+	qty *= factor
+
+	switch {
+	case !ing.HasUnit:
+		a.dimension, a.amount = shoppingCount, qty
+	case ing.Unit.Dimension == units.Mass:
+		a.dimension, a.amount = shoppingMass, qty*ing.Unit.ToBase
+	case ing.Unit.Dimension == units.Volume:
+		a.dimension, a.amount = shoppingVolume, qty*ing.Unit.ToBase
+	default:
+		return shoppingAmount{}, false
+	}
+	return a, true
+}
+
+// shoppingItemName keeps the ingredient name as written, without notes after
+// a comma or in parentheses
+func shoppingItemName(name string) string {
+	s := parentheticalPattern.ReplaceAllString(name, " ")
+	if before, _, ok := strings.Cut(s, ","); ok && strings.TrimSpace(before) != "" {
+		s = before
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// convertAmount moves an amount between mass and volume using the density
+// table, reporting false when the density is unknown
+func convertAmount(a shoppingAmount, to shoppingDimension) (float64, bool) {
+	switch {
+	case a.dimension == to:
+		return a.amount, true
+	case a.dimension == shoppingVolume && to == shoppingMass:
+		return units.GramsFor(a.amount, units.Milliliter, a.key)
+	case a.dimension == shoppingMass && to == shoppingVolume:
+		return units.MillilitersFor(a.amount, units.Gram, a.key)
+	}
+	return 0, false
+}
+
+// mergeShoppingAmounts combines amounts of the same ingredient. Volumes are
+// folded into weights when the ingredient is also weighed elsewhere and its
+// density is known, and unquantified mentions are dropped when another
+// recipe gives a quantity.
+func mergeShoppingAmounts(amounts []shoppingAmount) []*shoppingAmount {
+	var merged []*shoppingAmount
+	byKey := make(map[string][]*shoppingAmount)
+
+	add := func(a shoppingAmount) {
+		for _, m := range byKey[a.key] {
+			if m.dimension == a.dimension {
+				m.amount += a.amount
+				m.recipes = appendUnique(m.recipes, a.recipes...)
+				return
+			}
+		}
+		m := a
+		m.recipes = appendUnique(nil, a.recipes...)
+		merged = append(merged, &m)
+		byKey[a.key] = append(byKey[a.key], &m)
+	}
+	for _, a := range amounts {
+		add(a)
+	}
+
+	var out []*shoppingAmount
+	for _, m := range merged {
+		group := byKey[m.key]
+		switch m.dimension {
+		case shoppingVolume:
+			if mass := findDimension(group, shoppingMass); mass != nil {
+				if grams, ok := convertAmount(*m, shoppingMass); ok {
+					mass.amount += grams
+					mass.recipes = appendUnique(mass.recipes, m.recipes...)
+					continue
+				}
+			}
+		case shoppingNoQuant:
+			if len(group) > 1 {
+				for _, other := range group {
+					if other != m {
+						other.recipes = appendUnique(other.recipes, m.recipes...)
+						break
+					}
+				}
+				continue
+			}
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func findDimension(group []*shoppingAmount, dimension shoppingDimension) *shoppingAmount {
+	for _, a := range group {
+		if a.dimension == dimension {
+			return a
+		}
+	}
+	return nil
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// subtractShoppingAmount takes a pantry amount off an item. It returns false
+// when the pantry covers the item completely.
+func subtractShoppingAmount(item *shoppingAmount, stock shoppingAmount) bool {
+	if stock.dimension == shoppingNoQuant || item.dimension == shoppingNoQuant {
+		return false
+	}
+	have, ok := convertAmount(stock, item.dimension)
+	if !ok {
+		return true
+	}
+	original := item.amount
+	item.amount -= have
+	return item.amount > original*0.01 && item.amount > 1e-9
+}
+
+// BuildShoppingList merges the ingredients of the sources, subtracts the
+// pantry and returns list items in aisle order, rendered in the given unit
+// system
+func BuildShoppingList(sources []ShoppingSource, pantry []string, system units.System) []models.ShoppingListItem {
+	var amounts []shoppingAmount
+	for _, source := range sources {
+		factor := source.Factor
+		if factor <= 0 {
+			factor = 1
+		}
+		for _, line := range source.Recipe.Ingredients {
+			if a, ok := parseShoppingAmount(line, factor); ok {
+				a.recipes = []string{source.Recipe.Name}
+				amounts = append(amounts, a)
+			}
+		}
+	}
+	merged := mergeShoppingAmounts(amounts)
+	merged = subtractPantry(merged, pantry)
+
+	items := make([]models.ShoppingListItem, 0, len(merged))
+	for _, a := range merged {
+		items = append(items, renderShoppingItem(a, system))
+	}
+	sortShoppingItems(items)
+	return items
+}
+
+// SubtractPantryItems subtracts pantry lines from stored list items. It
+// returns the items whose amounts changed and the IDs of items the pantry
+// covers completely.
+func SubtractPantryItems(items []models.ShoppingListItem, pantry []string, system units.System) ([]models.ShoppingListItem, []uuid.UUID) {
+	var updated []models.ShoppingListItem
+	var removed []uuid.UUID
+	for _, item := range items {
+		a := storedShoppingAmount(item)
+		before := a.amount
+		remaining := subtractPantry([]*shoppingAmount{&a}, pantry)
+		switch {
+		case len(remaining) == 0:
+			removed = append(removed, item.ID)
+		case remaining[0].amount != before:
+			rendered := renderShoppingItem(remaining[0], system)
+			item.Quantity, item.Unit, item.Display = rendered.Quantity, rendered.Unit, rendered.Display
+			updated = append(updated, item)
+		}
+	}
+	return updated, removed
+}
+
+func subtractPantry(items []*shoppingAmount, pantry []string) []*shoppingAmount {
+	var stock []shoppingAmount
+	for _, line := range pantry {
+		if a, ok := parseShoppingAmount(line, 1); ok {
+			stock = append(stock, a)
+		}
+	}
+	if len(stock) == 0 {
+		return items
+	}
+
+	var out []*shoppingAmount
+	for _, item := range items {
+		keep := true
+		for _, s := range stock {
+			if s.key != item.key {
+				continue
+			}
+			if keep = subtractShoppingAmount(item, s); !keep {
+				break
+			}
+		}
+		if keep {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// storedShoppingAmount converts a saved item back into base units
+func storedShoppingAmount(item models.ShoppingListItem) shoppingAmount {
+	a := shoppingAmount{
+		key:     strings.Join(normalizeFoodName(item.Name), " "),
+		name:    item.Name,
+		recipes: item.Recipes,
+	}
+	if item.Quantity == 0 {
+		return a
+	}
+	unit, ok := units.LookupUnit(item.Unit)
+	switch {
+	case item.Unit == "" || !ok:
+		a.dimension, a.amount = shoppingCount, item.Quantity
+	case unit.Dimension == units.Mass:
+		a.dimension, a.amount = shoppingMass, item.Quantity*unit.ToBase
+	default:
+		a.dimension, a.amount = shoppingVolume, item.Quantity*unit.ToBase
+	}
+	return a
+}
+
+// renderShoppingItem picks a unit in the given system and formats the item
+func renderShoppingItem(a *shoppingAmount, system units.System) models.ShoppingListItem {
+	item := models.ShoppingListItem{
+		Name:    a.name,
+		Aisle:   ShoppingAisle(a.key),
+		Recipes: models.JSONBStringArray(a.recipes),
+		Display: a.name,
+	}
+
+	switch a.dimension {
+	case shoppingCount:
+		// Whole items are bought whole
+		item.Quantity = math.Ceil(a.amount - 0.05)
+		if item.Quantity < 1 {
+			item.Quantity = 1
+		}
+		item.Display = fmt.Sprintf("%s %s", units.FormatQuantity(item.Quantity, units.Unit{}), a.name)
+	case shoppingMass, shoppingVolume:
+		base := units.Gram
+		if a.dimension == shoppingVolume {
+			base = units.Milliliter
+		}
+		qty, unit, ok := units.ConvertForSystem(a.amount, base, a.key, system)
+		if !ok {
+			qty, unit = a.amount, base
+		}
+		item.Quantity = math.Round(qty*100) / 100
+		item.Unit = unit.Name
+		item.Display = fmt.Sprintf("%s %s %s", units.FormatQuantity(qty, unit), unit.Label(qty), a.name)
+	}
+	return item
+}
+
+// shoppingAisles lists store aisles in walking order with the ingredient
+// words that belong in each. Keywords are singular, as produced by
+// normalizeFoodName, and the longest matching keyword wins so that "coconut
+// milk" lands in the pantry rather than with dairy.
+var shoppingAisles = []struct {
+	name     string
+	keywords []string
+}{
+	{"Produce", []string{
+		"apple", "avocado", "banana", "basil", "bean sprout", "bell pepper", "berry", "blueberry", "broccoli",
+		"cabbage", "carrot", "cauliflower", "celery", "chili", "cilantro", "corn", "cucumber", "dill",
+		"eggplant", "garlic", "ginger", "green bean", "green onion", "herb", "jalapeno", "kale", "leek",
+		"lemon", "lettuce", "lime", "mango", "mint", "mushroom", "onion", "orange", "parsley", "pea",
+		"pear", "pepper", "potato", "rosemary", "scallion", "shallot", "spinach", "squash", "strawberry",
+		"sweet potato", "thyme", "tomato", "zucchini", "fresh herb",
+	}},
+	{"Meat & Seafood", []string{
+		"bacon", "beef", "chicken", "chorizo", "cod", "crab", "duck", "fish", "ground beef", "ham", "lamb",
+		"lobster", "mussel", "pork", "prawn", "prosciutto", "salmon", "sausage", "scallop", "shrimp",
+		"steak", "tilapia", "tuna", "turkey", "clam",
+	}},
+	{"Dairy & Eggs", []string{
+		"butter", "buttermilk", "cheddar", "cheese", "cream", "cream cheese", "egg", "feta", "ghee",
+		"half and half", "milk", "mozzarella", "parmesan", "ricotta", "sour cream", "yogurt",
+		"heavy cream", "greek yogurt",
+	}},
+	{"Bakery", []string{"bagel", "baguette", "bread", "bun", "pita", "roll", "tortilla", "naan"}},
+	{"Baking", []string{
+		"baking powder", "baking soda", "brown sugar", "chocolate chip", "cocoa", "cornstarch", "flour",
+		"honey", "maple syrup", "powdered sugar", "sugar", "vanilla", "vanilla extract", "yeast",
+	}},
+	{"Spices & Seasonings", []string{
+		"bay leaf", "black pepper", "cayenne", "chili powder", "cinnamon", "clove", "coriander", "cumin",
+		"curry powder", "garam masala", "garlic powder", "ground", "nutmeg", "onion powder", "oregano",
+		"paprika", "pepper flake", "salt", "seasoning", "smoked paprika", "spice", "turmeric",
+		"dried oregano", "dried thyme", "dried basil",
+	}},
+	{"Oils & Condiments", []string{
+		"dressing", "fish sauce", "hot sauce", "ketchup", "mayonnaise", "mustard", "oil", "olive oil",
+		"salsa", "sauce", "soy sauce", "sriracha", "tahini", "vinegar", "worcestershire",
+	}},
+	{"Pantry", []string{
+		"almond", "bean", "broth", "canned", "chickpea", "coconut milk", "couscous", "lentil", "noodle",
+		"nut", "oat", "pasta", "peanut", "peanut butter", "quinoa", "rice", "seed", "spaghetti", "stock",
+		"tomato paste", "tomato sauce", "walnut", "cashew", "crushed tomato", "canned tomato", "black bean",
+		"kidney bean", "almond milk", "oat milk", "soy milk", "breadcrumb", "tofu", "cracker",
+	}},
+	{"Frozen", []string{"frozen", "ice cream"}},
+	{"Beverages", []string{"beer", "coffee", "juice", "tea", "water", "wine", "sparkling water"}},
+}
+
+// ShoppingAisleOther holds items that match no aisle
+const ShoppingAisleOther = "Other"
+
+// ShoppingAisle returns the store aisle for a normalized ingredient name
+func ShoppingAisle(key string) string {
+	padded := " " + key + " "
+	best, bestLen := ShoppingAisleOther, 0
+	for _, aisle := range shoppingAisles {
+		for _, keyword := range aisle.keywords {
+			if len(keyword) > bestLen && strings.Contains(padded, " "+keyword+" ") {
+				best, bestLen = aisle.name, len(keyword)
+			}
+		}
+	}
+	return best
+}
+
+// ShoppingAisleOrder returns the aisles in the order lists are sorted
+func ShoppingAisleOrder() []string {
+	order := make([]string, 0, len(shoppingAisles)+1)
+	for _, aisle := range shoppingAisles {
+		order = append(order, aisle.name)
+	}
+	return append(order, ShoppingAisleOther)
+}
+
+// sortShoppingItems orders items by aisle and then by name and numbers them
+func sortShoppingItems(items []models.ShoppingListItem) {
+	rank := make(map[string]int)
+	for i, name := range ShoppingAisleOrder() {
+		rank[name] = i
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Aisle != items[j].Aisle {
+			return rank[items[i].Aisle] < rank[items[j].Aisle]
+		}
+		return strings.ToLower(items[i].Name) < strings.ToLower(items[j].Name)
+	})
+	for i := range items {
+		items[i].Position = i
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+)
+
+// FormatShoppingListText renders a list as plain text grouped by aisle, with
+// a checkbox per item
+func FormatShoppingListText(list *models.ShoppingList) string {
+	var b strings.Builder
+	b.WriteString(list.Name)
+	b.WriteString("\n")
+
+	aisle := ""
+	for _, item := range list.Items {
+		if item.Aisle != aisle {
+			aisle = item.Aisle
+			fmt.Fprintf(&b, "\n%s\n", aisle)
+		}
+		box := "[ ]"
+		if item.Checked {
+			box = "[x]"
+		}
+		fmt.Fprintf(&b, "%s %s", box, item.Display)
+		if len(item.Recipes) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(item.Recipes, ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// WriteShoppingListCSV writes a list as CSV with one row per item
+func WriteShoppingListCSV(w io.Writer, list *models.ShoppingList) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"aisle", "item", "quantity", "unit", "checked", "recipes"}); err != nil {
+		return err
+	}
+	for _, item := range list.Items {
+		quantity := ""
+		if item.Quantity > 0 {
+			quantity = strconv.FormatFloat(item.Quantity, 'f', -1, 64)
+		}
+		row := []string{
+			item.Aisle,
+			item.Name,
+			quantity,
+			item.Unit,
+			strconv.FormatBool(item.Checked),
+			strings.Join(item.Recipes, "; "),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/units"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func shoppingItemByName(items []models.ShoppingListItem, name string) *models.ShoppingListItem {
+	for i := range items {
+		if items[i].Name == name {
+			return &items[i]
+		}
+	}
+	return nil
+}
+
+func TestBuildShoppingListMergesIngredients(t *testing.T) {
+	pancakes := &models.Recipe{
+		Name:        "Pancakes",
+		Servings:    4,
+		Ingredients: models.JSONBStringArray{"1 cup milk", "2 large eggs", "1 pinch salt", "salt to taste"},
+	}
+	soup := &models.Recipe{
+		Name:        "Soup",
+		Servings:    2,
+		Ingredients: models.JSONBStringArray{"250 ml milk", "1 onion, diced", "200 ml coconut milk"},
+	}
+
+	items := BuildShoppingList([]ShoppingSource{
+		{Recipe: pancakes, Factor: 1},
+		{Recipe: soup, Factor: 2},
+	}, nil, units.Metric)
+
+	milk := shoppingItemByName(items, "milk")
+	require.NotNil(t, milk)
+	assert.Equal(t, "ml", milk.Unit)
+	assert.InDelta(t, 736.6, milk.Quantity, 0.1)
+	assert.Equal(t, "Dairy & Eggs", milk.Aisle)
+	assert.ElementsMatch(t, []string{"Pancakes", "Soup"}, milk.Recipes)
+
+	coconut := shoppingItemByName(items, "coconut milk")
+	require.NotNil(t, coconut)
+	assert.Equal(t, "Pantry", coconut.Aisle)
+	assert.Equal(t, 400.0, coconut.Quantity)
+
+	onion := shoppingItemByName(items, "onion")
+	require.NotNil(t, onion)
+	assert.Equal(t, 2.0, onion.Quantity)
+	assert.Equal(t, "2 onion", onion.Display)
+	assert.Equal(t, "Produce", onion.Aisle)
+
+	eggs := shoppingItemByName(items, "large eggs")
+	require.NotNil(t, eggs)
+	assert.Equal(t, 2.0, eggs.Quantity)
+
+	// Items are grouped by aisle in walking order
+	order := make(map[string]int)
+	for i, aisle := range ShoppingAisleOrder() {
+		order[aisle] = i
+	}
+	for i := 1; i < len(items); i++ {
+		assert.LessOrEqual(t, order[items[i-1].Aisle], order[items[i].Aisle])
+		assert.Equal(t, i, items[i].Position)
+	}
+}
+
+func TestBuildShoppingListFoldsVolumeIntoWeight(t *testing.T) {
+	recipe := &models.Recipe{
+		Name:        "Bread",
+		Ingredients: models.JSONBStringArray{"500 g all-purpose flour", "1 cup all-purpose flour"},
+	}
+
+	items := BuildShoppingList([]ShoppingSource{{Recipe: recipe, Factor: 1}}, nil, units.Metric)
+	require.Len(t, items, 1)
+	assert.Equal(t, "g", items[0].Unit)
+	assert.InDelta(t, 620, items[0].Quantity, 5)
+	assert.Equal(t, "Baking", items[0].Aisle)
+}
+
+func TestBuildShoppingListSubtractsPantry(t *testing.T) {
+	recipe := &models.Recipe{
+		Name:        "Omelette",
+		Ingredients: models.JSONBStringArray{"500 ml milk", "3 eggs", "1 tsp salt", "100 g cheddar cheese"},
+	}
+
+	items := BuildShoppingList([]ShoppingSource{{Recipe: recipe, Factor: 1}}, []string{"200 ml milk", "salt", "6 eggs"}, units.Metric)
+
+	milk := shoppingItemByName(items, "milk")
+	require.NotNil(t, milk)
+	assert.InDelta(t, 300, milk.Quantity, 0.01)
+	assert.Nil(t, shoppingItemByName(items, "salt"))
+	assert.Nil(t, shoppingItemByName(items, "eggs"))
+	assert.NotNil(t, shoppingItemByName(items, "cheddar cheese"))
+}
+
+func TestSubtractPantryItems(t *testing.T) {
+	milkID, eggID := uuid.New(), uuid.New()
+	items := []models.ShoppingListItem{
+		{ID: milkID, Name: "milk", Quantity: 2, Unit: "cup", Display: "2 cups milk"},
+		{ID: eggID, Name: "eggs", Quantity: 4, Display: "4 eggs"},
+	}
+
+	updated, removed := SubtractPantryItems(items, []string{"1 cup milk", "12 eggs"}, units.Imperial)
+	require.Len(t, updated, 1)
+	assert.Equal(t, milkID, updated[0].ID)
+	assert.Equal(t, "1 cup milk", updated[0].Display)
+	assert.Equal(t, []uuid.UUID{eggID}, removed)
+}
+
+func TestShoppingListExports(t *testing.T) {
+	list := &models.ShoppingList{
+		Name: "Week 1",
+		Items: []models.ShoppingListItem{
+			{Name: "onion", Quantity: 2, Display: "2 onion", Aisle: "Produce", Recipes: models.JSONBStringArray{"Soup"}},
+			{Name: "milk", Quantity: 500, Unit: "ml", Display: "500 ml milk", Aisle: "Dairy & Eggs", Checked: true},
+		},
+	}
+
+	text := FormatShoppingListText(list)
+	assert.Equal(t, "Week 1\n\nProduce\n[ ] 2 onion (Soup)\n\nDairy & Eggs\n[x] 500 ml milk\n", text)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteShoppingListCSV(&buf, list))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "aisle,item,quantity,unit,checked,recipes", lines[0])
+	assert.Equal(t, "Produce,onion,2,,false,Soup", lines[1])
+	assert.Equal(t, "Dairy & Eggs,milk,500,ml,true,", lines[2])
+}
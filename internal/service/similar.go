@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+)
+
+// SimilarRecipes returns one page of the recipes nearest to a recipe by
+// embedding, closest first, that pass the search's filters. The recipe must
+// be visible to the search's viewer and is always left out. With
+// excludeForks, so are its forks and, if it is a fork, its original and the
+// original's other forks. A recipe the current model has not embedded yet has
+// no neighbours until the reindex worker reaches it.
+func (s *RecipeService) SimilarRecipes(ctx context.Context, id uuid.UUID, search *RecipeSearch, excludeForks bool, page pagination.Page) ([]*models.Recipe, *pagination.Info, error) {
+	var source models.Recipe
+	if err := s.db.WithContext(ctx).Scopes(VisibleRecipes(search.ViewerID)).
+		Select("recipes.id, recipes.forked_from_id, recipes.embedding, recipes.embedding_model, recipes.embedding_dimensions").
+		First(&source, "recipes.id = ?", id).Error; err != nil {
+		return nil, nil, err
+	}
+	if s.embeddingService == nil || EmbeddingMissing(source.Embedding) ||
+		source.EmbeddingModel != s.embeddingService.Model() || source.EmbeddingDims != s.embeddingService.Dimensions() {
+		return []*models.Recipe{}, &pagination.Info{Limit: page.Size()}, nil
+	}
+
+	query := applyRecipeSearch(s.db.WithContext(ctx).Model(&models.Recipe{}), search, "").
+		Scopes(sameEmbeddingModel(s.embeddingService)).
+		Select("recipes.*, "+embeddingDistance(s.embeddingService)+" AS distance", source.Embedding).
+		Where("recipes.id <> ?", id)
+	if excludeForks {
+		query = query.Where("recipes.forked_from_id IS DISTINCT FROM ?", id)
+		if source.ForkedFromID != nil {
+			query = query.Where("recipes.id <> ? AND recipes.forked_from_id IS DISTINCT FROM ?", *source.ForkedFromID, *source.ForkedFromID)
+		}
+	}
+
+	var recipes []*models.Recipe
+	if err := pagination.Offset(query.Order("distance").Order("recipes.id"), page).Find(&recipes).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to find similar recipes: %w", err)
+	}
+	recipes, info := pagination.Finish(recipes, page, nil)
+	return recipes, info, nil
+}
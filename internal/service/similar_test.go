@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestSimilarRecipes needs Postgres for pgvector distances and the jsonb
+// dietary filters
+func TestSimilarRecipes(t *testing.T) {
+	db := setupTestDB(t)
+	embeddings := NewEmbeddingServiceWithProvider(NewHashEmbeddingProvider(4))
+	s := NewRecipeService(db, embeddings)
+	ctx := context.Background()
+	author, viewer := uuid.New(), uuid.New()
+
+	insert := func(name string, embedding []float32, fields models.Recipe) uuid.UUID {
+		recipe := fields
+		recipe.ID = uuid.New()
+		recipe.Name = name
+		recipe.UserID = author
+		recipe.Embedding = pgvector.NewVector(embedding)
+		if recipe.EmbeddingModel == "" {
+			recipe.EmbeddingModel = embeddings.Model()
+		}
+		recipe.EmbeddingDims = len(embedding)
+		if recipe.Visibility == "" {
+			recipe.Visibility = models.RecipePublic
+		}
+		if recipe.Ingredients == nil {
+			recipe.Ingredients = []string{"rice"}
+		}
+		require.NoError(t, db.Create(&recipe).Error)
+		return recipe.ID
+	}
+
+	source := insert("Source", []float32{1, 0, 0, 0}, models.Recipe{})
+	fork := insert("Fork", []float32{0.99, 0.01, 0, 0}, models.Recipe{ForkedFromID: &source})
+	sibling := insert("Sibling fork", []float32{0.95, 0.05, 0, 0}, models.Recipe{ForkedFromID: &source})
+	private := insert("Private", []float32{0.97, 0.03, 0, 0}, models.Recipe{Visibility: models.RecipePrivate})
+	sesame := insert("Sesame", []float32{0.9, 0.1, 0, 0}, models.Recipe{
+		Ingredients: []string{"sesame oil"}, DietaryPreferences: []string{"vegan"},
+	})
+	vegan := insert("Vegan", []float32{0.5, 0.5, 0, 0}, models.Recipe{DietaryPreferences: []string{"vegan"}})
+	far := insert("Far", []float32{0, 1, 0, 0}, models.Recipe{})
+	otherModel := insert("Other model", []float32{1, 0, 0, 0}, models.Recipe{EmbeddingModel: "other"})
+
+	similar := func(id uuid.UUID, search RecipeSearch, excludeForks bool) []uuid.UUID {
+		search.ViewerID = viewer
+		recipes, _, err := s.SimilarRecipes(ctx, id, &search, excludeForks, pagination.Page{})
+		require.NoError(t, err)
+		return recipeIDs(recipes)
+	}
+
+	// Closest first, leaving out the source, recipes the viewer cannot list
+	// and recipes embedded by another model
+	assert.Equal(t, []uuid.UUID{fork, sibling, sesame, vegan, far}, similar(source, RecipeSearch{}, false))
+
+	// Excluding forks drops the source's forks, and from a fork also its
+	// original and the original's other forks
+	assert.Equal(t, []uuid.UUID{sesame, vegan, far}, similar(source, RecipeSearch{}, true))
+	assert.Equal(t, []uuid.UUID{sesame, vegan, far}, similar(fork, RecipeSearch{}, true))
+	assert.Equal(t, []uuid.UUID{source, sibling, sesame, vegan, far}, similar(fork, RecipeSearch{}, false))
+
+	// The search's dietary and allergen filters apply
+	assert.Equal(t, []uuid.UUID{sesame, vegan}, similar(source, RecipeSearch{Dietary: []string{"vegan"}}, false))
+	assert.Equal(t, []uuid.UUID{vegan}, similar(source, RecipeSearch{Dietary: []string{"vegan"}, Allergens: []string{"sesame"}}, false))
+
+	// A source embedded by another model has no comparable neighbours
+	recipes, info, err := s.SimilarRecipes(ctx, otherModel, &RecipeSearch{ViewerID: viewer}, false, pagination.Page{})
+	require.NoError(t, err)
+	assert.Empty(t, recipes)
+	assert.Empty(t, info.NextCursor)
+
+	// A source the viewer cannot see is not found, which the handler
+	// answers with 404
+	_, _, err = s.SimilarRecipes(ctx, private, &RecipeSearch{ViewerID: viewer}, false, pagination.Page{})
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+}
@@ -0,0 +1,695 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/units"
+)
+
+// Sources reported for substitution suggestions
+const (
+	SubstitutionSourceTable = "table"
+	SubstitutionSourceLLM   = "llm"
+)
+
+// ErrSubstitutionRequest is returned when neither an ingredient nor a
+// constraint is given
+var ErrSubstitutionRequest = errors.New("ingredient or constraint is required")
+
+// ErrSubstitutionNotFound is returned when a chosen substitution does not
+// match an ingredient in the recipe
+var ErrSubstitutionNotFound = errors.New("ingredient not found in recipe")
+
+// Substitute is a single replacement for an ingredient
+type Substitute struct {
+	Name        string  `json:"name"`
+	Ratio       string  `json:"ratio"`
+	Notes       string  `json:"notes"`
+	Replacement string  `json:"replacement"`
+	Score       float64 `json:"score"`
+	Source      string  `json:"source"`
+}
+
+// SubstitutionSuggestion lists ranked substitutes for one recipe ingredient
+type SubstitutionSuggestion struct {
+	Ingredient  string       `json:"ingredient"`
+	Substitutes []Substitute `json:"substitutes"`
+}
+
+// SubstitutionChoice selects a substitute for an ingredient line. Replacement
+// may be given to override the generated ingredient line.
+type SubstitutionChoice struct {
+	Ingredient  string `json:"ingredient" binding:"required"`
+	Substitute  string `json:"substitute" binding:"required"`
+	Replacement string `json:"replacement"`
+}
+
+// SubstitutionService suggests ingredient substitutions from a curated table,
+// asking the LLM only when the table has no answer
+type SubstitutionService struct {
+	llmService LLMServiceInterface
+}
+
+// Ensure SubstitutionService implements ISubstitutionService
+var _ ISubstitutionService = (*SubstitutionService)(nil)
+
+// NewSubstitutionService creates a new SubstitutionService instance
+func NewSubstitutionService(llmService LLMServiceInterface) *SubstitutionService {
+	return &SubstitutionService{llmService: llmService}
+}
+
+// SuggestSubstitutions returns substitutes for an ingredient, or for every
+// ingredient in the recipe that breaks a constraint such as "dairy-free"
+func (s *SubstitutionService) SuggestSubstitutions(ctx context.Context, recipe *models.Recipe, ingredient, constraint string) ([]SubstitutionSuggestion, error) {
+	ingredient = strings.TrimSpace(ingredient)
+	constraint = strings.TrimSpace(constraint)
+	if ingredient == "" && constraint == "" {
+		return nil, ErrSubstitutionRequest
+	}
+
+	excluded, constraintIngredient := parseConstraint(constraint)
+	if ingredient == "" {
+		ingredient = constraintIngredient
+	}
+
+	var lines []string
+	if ingredient != "" {
+		lines = findIngredientLines(recipe.Ingredients, ingredient)
+		if len(lines) == 0 {
+			lines = []string{ingredient}
+		}
+	} else {
+		for _, line := range recipe.Ingredients {
+			if entry := lookupSubstitution(line); entry != nil && entry.violates(excluded) {
+				lines = append(lines, line)
+			}
+		}
+	}
+
+	suggestions := make([]SubstitutionSuggestion, 0, len(lines))
+	var missing []string
+	for _, line := range lines {
+		suggestion := SubstitutionSuggestion{Ingredient: line, Substitutes: tableSubstitutes(line, excluded)}
+		if len(suggestion.Substitutes) == 0 {
+			missing = append(missing, line)
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	// Ask the LLM about ingredients the table cannot handle. For a constraint
+	// with no known offenders the LLM reviews the whole ingredient list.
+	if s.llmService == nil {
+		return suggestions, nil
+	}
+	if ingredient == "" && len(lines) == 0 {
+		missing = recipe.Ingredients
+	}
+	if len(missing) == 0 {
+		return suggestions, nil
+	}
+	llmSuggestions, err := s.llmService.SuggestSubstitutions(recipe.Name, missing, constraint)
+	if err != nil {
+		fmt.Printf("[SubstitutionService] LLM fallback failed: %v\n", err)
+		return suggestions, nil
+	}
+	return mergeSuggestions(suggestions, llmSuggestions), nil
+}
+
+// ApplySubstitutions rewrites the recipe's ingredients with the chosen
+// substitutes and returns the result as an unsaved draft
+func (s *SubstitutionService) ApplySubstitutions(ctx context.Context, recipe *models.Recipe, choices []SubstitutionChoice) (*RecipeDraft, error) {
+	ingredients := append([]string(nil), recipe.Ingredients...)
+	instructions := append([]string(nil), recipe.Instructions...)
+
+	for _, choice := range choices {
+		index := -1
+		for i, line := range ingredients {
+			if strings.EqualFold(strings.TrimSpace(line), strings.TrimSpace(choice.Ingredient)) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			if matches := findIngredientLines(ingredients, choice.Ingredient); len(matches) > 0 {
+				for i, line := range ingredients {
+					if line == matches[0] {
+						index = i
+						break
+					}
+				}
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrSubstitutionNotFound, choice.Ingredient)
+		}
+
+		original := ingredients[index]
+		replacement := strings.TrimSpace(choice.Replacement)
+		if replacement == "" {
+			replacement = replacementLine(original, choice.Substitute)
+		}
+		ingredients[index] = replacement
+
+		if entry := lookupSubstitution(original); entry != nil {
+			instructions = renameInInstructions(instructions, entry.keywords, choice.Substitute)
+		}
+	}
+
+	return &RecipeDraft{
+		Name:         recipe.Name,
+		Description:  recipe.Description,
+		Category:     recipe.Category,
+		Ingredients:  ingredients,
+		Instructions: instructions,
+		Servings:     ServingsType{Value: servingsValue(recipe.Servings)},
+		Difficulty:   "Medium",
+		Calories:     recipe.Calories,
+		Protein:      recipe.Protein,
+		Carbs:        recipe.Carbs,
+		Fat:          recipe.Fat,
+		ForkedFromID: recipe.ID.String(),
+	}, nil
+}
+
+func servingsValue(servings int) string {
+	if servings <= 0 {
+		return "4"
+	}
+	return fmt.Sprintf("%d", servings)
+}
+
+// findIngredientLines returns the recipe lines that mention the ingredient
+func findIngredientLines(lines []string, ingredient string) []string {
+	want := normalizeFoodName(ingredient)
+	if len(want) == 0 {
+		return nil
+	}
+	var matches []string
+	for _, line := range lines {
+		have := make(map[string]bool)
+		for _, t := range normalizeFoodName(units.ParseIngredient(line).Name) {
+			have[t] = true
+		}
+		found := true
+		for _, t := range want {
+			if !have[t] {
+				found = false
+				break
+			}
+		}
+		if found {
+			matches = append(matches, line)
+		}
+	}
+	return matches
+}
+
+// tableSubstitutes ranks the curated substitutes for an ingredient line,
+// dropping those that contain an excluded category
+func tableSubstitutes(line string, excluded map[string]bool) []Substitute {
+	entry := lookupSubstitution(line)
+	if entry == nil {
+		return nil
+	}
+	var substitutes []Substitute
+	for _, rule := range entry.substitutes {
+		if rule.containsAny(excluded) {
+			continue
+		}
+		substitutes = append(substitutes, Substitute{
+			Name:        rule.name,
+			Ratio:       rule.ratio,
+			Notes:       rule.notes,
+			Replacement: rule.replace(units.ParseIngredient(line)),
+			Score:       math.Max(0.5, 1-0.1*float64(len(substitutes))),
+			Source:      SubstitutionSourceTable,
+		})
+	}
+	return substitutes
+}
+
+// mergeSuggestions adds LLM substitutes to the matching table suggestions,
+// ranked after any table entries
+func mergeSuggestions(suggestions, llmSuggestions []SubstitutionSuggestion) []SubstitutionSuggestion {
+	for _, extra := range llmSuggestions {
+		i := -1
+		for j := range suggestions {
+			if strings.EqualFold(suggestions[j].Ingredient, extra.Ingredient) {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			suggestions = append(suggestions, SubstitutionSuggestion{Ingredient: extra.Ingredient})
+			i = len(suggestions) - 1
+		}
+		for k, sub := range extra.Substitutes {
+			sub.Source = SubstitutionSourceLLM
+			sub.Score = math.Max(0.1, 0.45-0.05*float64(k))
+			if sub.Replacement == "" {
+				sub.Replacement = replacementLine(extra.Ingredient, sub.Name)
+			}
+			suggestions[i].Substitutes = append(suggestions[i].Substitutes, sub)
+		}
+	}
+	return suggestions
+}
+
+// replacementLine builds the ingredient line for a substitute, using the
+// table's ratio when there is one and keeping the quantity otherwise
+func replacementLine(original, substitute string) string {
+	ing := units.ParseIngredient(original)
+	if entry := lookupSubstitution(original); entry != nil {
+		for _, rule := range entry.substitutes {
+			if strings.EqualFold(rule.name, substitute) {
+				return rule.replace(ing)
+			}
+		}
+	}
+	return substitutionRule{name: substitute, factor: 1}.replace(ing)
+}
+
+// renameInInstructions replaces mentions of the original ingredient in the
+// method so the steps read naturally after a substitution
+func renameInInstructions(steps []string, keywords []string, substitute string) []string {
+	renamed := make([]string, len(steps))
+	for i, step := range steps {
+		for _, keyword := range byLength(keywords) {
+			pattern := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(keyword) + `(s|es)?\b`)
+			step = pattern.ReplaceAllString(step, substitute)
+		}
+		renamed[i] = step
+	}
+	return renamed
+}
+
+// byLength returns the keywords longest first
+func byLength(keywords []string) []string {
+	sorted := append([]string(nil), keywords...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	return sorted
+}
+
+// constraintCategories maps dietary constraints to the ingredient categories
+// they exclude
+var constraintCategories = map[string][]string{
+	"dairy-free":    {"dairy"},
+	"lactose-free":  {"dairy"},
+	"egg-free":      {"egg"},
+	"gluten-free":   {"gluten"},
+	"nut-free":      {"nut"},
+	"soy-free":      {"soy"},
+	"alcohol-free":  {"alcohol"},
+	"vegetarian":    {"meat", "fish"},
+	"pescatarian":   {"meat"},
+	"vegan":         {"dairy", "egg", "meat", "fish", "honey"},
+	"plant-based":   {"dairy", "egg", "meat", "fish", "honey"},
+	"no dairy":      {"dairy"},
+	"no eggs":       {"egg"},
+	"no gluten":     {"gluten"},
+	"no nuts":       {"nut"},
+	"no meat":       {"meat"},
+	"no alcohol":    {"alcohol"},
+	"without dairy": {"dairy"},
+	"without eggs":  {"egg"},
+}
+
+var constraintIngredientPattern = regexp.MustCompile(`^(?:no|without)\s+(.+)$|^(.+?)[\s-]free$`)
+
+// parseConstraint returns the categories a constraint excludes. Constraints
+// naming a single ingredient, such as "no butter", return that ingredient.
+func parseConstraint(constraint string) (map[string]bool, string) {
+	excluded := make(map[string]bool)
+	c := strings.ToLower(strings.TrimSpace(constraint))
+	if c == "" {
+		return excluded, ""
+	}
+	if categories, ok := constraintCategories[c]; ok {
+		for _, category := range categories {
+			excluded[category] = true
+		}
+		return excluded, ""
+	}
+	if m := constraintIngredientPattern.FindStringSubmatch(c); m != nil {
+		name := m[1]
+		if name == "" {
+			name = m[2]
+		}
+		for _, candidate := range []string{name, singularize(name)} {
+			if substitutionCategories[candidate] {
+				excluded[candidate] = true
+				return excluded, ""
+			}
+		}
+		return excluded, name
+	}
+	return excluded, ""
+}
+
+// portion is a fixed amount of an ingredient
+type portion struct {
+	qty  float64
+	unit units.Unit
+	name string
+}
+
+// substitutionRule describes one curated substitute. Counted ingredients such
+// as eggs use perPiece; everything else scales the original quantity by
+// factor, keeping its unit.
+type substitutionRule struct {
+	name     string
+	factor   float64
+	perPiece []portion
+	ratio    string
+	notes    string
+	contains []string
+}
+
+func (r substitutionRule) containsAny(excluded map[string]bool) bool {
+	for _, c := range r.contains {
+		if excluded[c] {
+			return true
+		}
+	}
+	return false
+}
+
+// replace builds the ingredient line that replaces the parsed original
+func (r substitutionRule) replace(ing units.Ingredient) string {
+	if !ing.HasQuantity {
+		return r.name
+	}
+	qty := ing.Quantity
+	if ing.MaxQuantity > 0 {
+		qty = (ing.Quantity + ing.MaxQuantity) / 2
+	}
+
+	if len(r.perPiece) > 0 && !ing.HasUnit {
+		parts := make([]string, len(r.perPiece))
+		for i, p := range r.perPiece {
+			amount := p.qty * qty
+			parts[i] = units.FormatQuantity(amount, p.unit) + " " + p.unit.Label(amount) + " " + p.name
+		}
+		return strings.Join(parts, " mixed with ")
+	}
+
+	factor := r.factor
+	if factor == 0 {
+		factor = 1
+	}
+	amount := qty * factor
+	if !ing.HasUnit {
+		return units.FormatQuantity(amount, units.Unit{}) + " " + r.name
+	}
+	return units.FormatQuantity(amount, ing.Unit) + " " + ing.Unit.Label(amount) + " " + r.name
+}
+
+// substitutionEntry is a row of the substitution table: the ingredient names
+// it covers, the dietary categories they belong to and ranked substitutes
+type substitutionEntry struct {
+	keywords    []string
+	categories  []string
+	substitutes []substitutionRule
+}
+
+func (e *substitutionEntry) violates(excluded map[string]bool) bool {
+	for _, c := range e.categories {
+		if excluded[c] {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupSubstitution finds the table entry for an ingredient line, preferring
+// the most specific keyword so that "peanut butter" is not treated as butter
+func lookupSubstitution(line string) *substitutionEntry {
+	tokens := normalizeFoodName(units.ParseIngredient(line).Name)
+	have := make(map[string]bool, len(tokens))
+	for _, t := range tokens {
+		have[t] = true
+	}
+
+	var best *substitutionEntry
+	bestLen := 0
+	for i := range substitutionTable {
+		for _, keyword := range substitutionTable[i].keywords {
+			key := normalizeFoodName(keyword)
+			matched := len(key) > 0
+			for _, t := range key {
+				if !have[t] {
+					matched = false
+					break
+				}
+			}
+			if matched && len(key) > bestLen {
+				best, bestLen = &substitutionTable[i], len(key)
+			}
+		}
+	}
+	return best
+}
+
+// substitutionCategories are the categories that can be excluded by name,
+// as in "dairy-free" or "no nuts"
+var substitutionCategories = map[string]bool{
+	"dairy": true, "egg": true, "gluten": true, "nut": true, "soy": true,
+	"meat": true, "fish": true, "honey": true, "alcohol": true,
+}
+
+// substitutionTable is the curated list of substitutions, best first
+var substitutionTable = []substitutionEntry{
+	{
+		// Names that only look like a restricted ingredient. Listed first so
+		// they win ties against the entries they resemble.
+		keywords: []string{
+			"coconut milk", "coconut cream", "oat milk", "rice milk", "cream of tartar", "cocoa butter",
+			"plant-based butter", "vegan butter", "plant-based cheese", "vegan cheese", "coconut yogurt",
+			"sunflower seed butter", "apple butter", "wine vinegar", "coconut flour", "rice flour",
+			"gluten-free flour", "gluten-free pasta", "gluten-free breadcrumbs", "vegetable stock",
+		},
+	},
+	{
+		keywords:   []string{"soy milk", "soy yogurt"},
+		categories: []string{"soy"},
+		substitutes: []substitutionRule{
+			{name: "oat milk", factor: 1, ratio: "1:1", notes: "Creamy and neutral; a direct swap."},
+		},
+	},
+	{
+		keywords:   []string{"almond flour", "almond meal"},
+		categories: []string{"nut"},
+		substitutes: []substitutionRule{
+			{name: "sunflower seed flour", factor: 1, ratio: "1:1", notes: "Similar fat and texture. Can turn green when baked with baking soda; add a little lemon juice."},
+		},
+	},
+	{
+		keywords:   []string{"egg"},
+		categories: []string{"egg"},
+		substitutes: []substitutionRule{
+			{name: "ground flaxseed", perPiece: []portion{{1, units.Tablespoon, "ground flaxseed"}, {3, units.Tablespoon, "water"}}, ratio: "1 egg = 1 tbsp ground flaxseed + 3 tbsp water", notes: "Rest 5 minutes to thicken. Binds well in muffins, cookies and burgers; adds a nutty flavor and slightly denser crumb."},
+			{name: "aquafaba", perPiece: []portion{{3, units.Tablespoon, "aquafaba"}}, ratio: "1 egg = 3 tbsp aquafaba", notes: "Chickpea cooking liquid. Whips like egg white, so it suits meringues, mousses and light cakes."},
+			{name: "unsweetened applesauce", perPiece: []portion{{60, units.Gram, "unsweetened applesauce"}}, ratio: "1 egg = 60 g applesauce", notes: "Adds moisture and a little sweetness. Cakes come out moister and denser; not for savory dishes."},
+			{name: "mashed banana", perPiece: []portion{{60, units.Gram, "mashed ripe banana"}}, ratio: "1 egg = 60 g mashed banana", notes: "Binds and moistens but adds banana flavor. Best in pancakes, brownies and quick breads."},
+			{name: "silken tofu", perPiece: []portion{{60, units.Gram, "blended silken tofu"}}, ratio: "1 egg = 60 g silken tofu", notes: "Neutral flavor. Works in quiches, custards and dense cakes; does not help baked goods rise.", contains: []string{"soy"}},
+		},
+	},
+	{
+		keywords:   []string{"butter", "unsalted butter", "salted butter"},
+		categories: []string{"dairy"},
+		substitutes: []substitutionRule{
+			{name: "plant-based butter", factor: 1, ratio: "1:1", notes: "Closest match for creaming, pastry and frosting. Check the label for nut or soy oils."},
+			{name: "coconut oil", factor: 0.8, ratio: "1 cup butter = 3/4 cup + 1 tbsp coconut oil", notes: "Use solid for pastry and creaming, melted for batters. Adds a slight coconut flavor; no salt."},
+			{name: "olive oil", factor: 0.75, ratio: "1 cup butter = 3/4 cup olive oil", notes: "For sautéing, roasting and oil-based cakes. Not suitable where butter must be creamed or stay solid."},
+		},
+	},
+	{
+		keywords:   []string{"milk", "whole milk", "skim milk"},
+		categories: []string{"dairy"},
+		substitutes: []substitutionRule{
+			{name: "oat milk", factor: 1, ratio: "1:1", notes: "Creamy and neutral. The best all-round swap for baking and sauces."},
+			{name: "soy milk", factor: 1, ratio: "1:1", notes: "Similar protein to dairy milk, so it browns and thickens well.", contains: []string{"soy"}},
+			{name: "almond milk", factor: 1, ratio: "1:1", notes: "Thinner and lighter. Fine in baking; sauces may need extra thickening.", contains: []string{"nut"}},
+		},
+	},
+	{
+		keywords:   []string{"buttermilk"},
+		categories: []string{"dairy"},
+		substitutes: []substitutionRule{
+			{name: "milk soured with lemon juice", factor: 1, ratio: "240 ml milk + 1 tbsp lemon juice", notes: "Stir and rest 5 minutes. Use oat or soy milk to make it dairy-free.", contains: []string{"dairy"}},
+			{name: "oat milk soured with lemon juice", factor: 1, ratio: "240 ml oat milk + 1 tbsp lemon juice", notes: "Stir and rest 5 minutes. Provides the acidity baking soda needs to rise."},
+		},
+	},
+	{
+		keywords:   []string{"heavy cream", "double cream", "whipping cream", "cream"},
+		categories: []string{"dairy"},
+		substitutes: []substitutionRule{
+			{name: "full-fat coconut milk", factor: 1, ratio: "1:1", notes: "Rich and stable in curries, soups and sauces. Chilled coconut cream whips; adds coconut flavor."},
+			{name: "cashew cream", factor: 1, ratio: "1:1", notes: "Blend soaked cashews with water. Neutral and silky in pasta sauces and soups.", contains: []string{"nut"}},
+		},
+	},
+	{
+		keywords:   []string{"sour cream", "yogurt", "greek yogurt"},
+		categories: []string{"dairy"},
+		substitutes: []substitutionRule{
+			{name: "plain coconut yogurt", factor: 1, ratio: "1:1", notes: "Tangy and thick. Good in dips, marinades and baking."},
+			{name: "plain soy yogurt", factor: 1, ratio: "1:1", notes: "Higher in protein and less sweet than coconut yogurt.", contains: []string{"soy"}},
+		},
+	},
+	{
+		keywords:   []string{"parmesan", "parmigiano reggiano", "pecorino"},
+		categories: []string{"dairy"},
+		substitutes: []substitutionRule{
+			{name: "nutritional yeast", factor: 0.5, ratio: "2 parts cheese = 1 part nutritional yeast", notes: "Savory and cheesy for pasta and pesto, but it does not melt or brown."},
+		},
+	},
+	{
+		keywords:   []string{"cheddar", "mozzarella", "cheese"},
+		categories: []string{"dairy"},
+		substitutes: []substitutionRule{
+			{name: "plant-based cheese", factor: 1, ratio: "1:1", notes: "Melting varies by brand; shreds made for pizza melt best."},
+			{name: "nutritional yeast", factor: 0.25, ratio: "4 parts cheese = 1 part nutritional yeast", notes: "Gives cheesy flavor to sauces, but no melt or stretch."},
+		},
+	},
+	{
+		keywords:   []string{"honey"},
+		categories: []string{"honey"},
+		substitutes: []substitutionRule{
+			{name: "maple syrup", factor: 1, ratio: "1:1", notes: "Slightly thinner with a maple flavor; baked goods brown a little less."},
+			{name: "agave syrup", factor: 1, ratio: "1:1", notes: "Neutral and sweeter than honey; reduce the oven by 10°C to avoid over-browning."},
+		},
+	},
+	{
+		keywords:   []string{"flour", "all-purpose flour", "plain flour", "wheat flour"},
+		categories: []string{"gluten"},
+		substitutes: []substitutionRule{
+			{name: "gluten-free flour blend", factor: 1, ratio: "1:1", notes: "Choose a blend with xanthan gum. Doughs are softer and bakes crumble more easily."},
+			{name: "oat flour", factor: 1.3, ratio: "1 cup flour = 1 1/3 cups oat flour", notes: "Use certified gluten-free oats. Tender, moist results in muffins and pancakes; not for yeast breads."},
+			{name: "almond flour", factor: 1, ratio: "1:1", notes: "Rich and dense; add an extra egg or binder in cakes.", contains: []string{"nut"}},
+		},
+	},
+	{
+		keywords:   []string{"breadcrumb", "bread crumb", "panko"},
+		categories: []string{"gluten"},
+		substitutes: []substitutionRule{
+			{name: "gluten-free breadcrumbs", factor: 1, ratio: "1:1", notes: "Behave like regular breadcrumbs for coating and binding."},
+			{name: "rolled oats", factor: 1, ratio: "1:1", notes: "Pulse briefly. Binds well in meatballs and burgers; use certified gluten-free oats."},
+		},
+	},
+	{
+		keywords:   []string{"pasta", "spaghetti", "penne", "macaroni", "noodle"},
+		categories: []string{"gluten"},
+		substitutes: []substitutionRule{
+			{name: "gluten-free pasta", factor: 1, ratio: "1:1", notes: "Cook a minute less than the package says and rinse to stop it sticking."},
+			{name: "zucchini noodles", factor: 2, ratio: "1 part pasta = 2 parts zucchini noodles", notes: "Much lighter and lower in carbs; cook briefly so they do not turn watery."},
+		},
+	},
+	{
+		keywords:   []string{"soy sauce"},
+		categories: []string{"gluten", "soy"},
+		substitutes: []substitutionRule{
+			{name: "tamari", factor: 1, ratio: "1:1", notes: "Gluten-free soy sauce with a richer flavor.", contains: []string{"soy"}},
+			{name: "coconut aminos", factor: 1.5, ratio: "1 part soy sauce = 1 1/2 parts coconut aminos", notes: "Soy- and gluten-free, sweeter and much less salty; add salt to taste."},
+		},
+	},
+	{
+		keywords:   []string{"fish sauce"},
+		categories: []string{"fish"},
+		substitutes: []substitutionRule{
+			{name: "soy sauce", factor: 1, ratio: "1:1", notes: "Provides salt and umami without the funk. A pinch of crumbled nori gets closer.", contains: []string{"soy", "gluten"}},
+			{name: "coconut aminos", factor: 1.5, ratio: "1 part fish sauce = 1 1/2 parts coconut aminos", notes: "Milder and sweeter; add salt to taste."},
+		},
+	},
+	{
+		keywords:   []string{"chicken", "chicken breast", "chicken thigh"},
+		categories: []string{"meat"},
+		substitutes: []substitutionRule{
+			{name: "extra-firm tofu", factor: 1, ratio: "1:1 by weight", notes: "Press before cooking. Absorbs marinades well and crisps when pan-fried.", contains: []string{"soy"}},
+			{name: "chickpeas", factor: 1, ratio: "1:1 by weight", notes: "Hearty in curries, stews and salads; cooks much faster than chicken."},
+		},
+	},
+	{
+		keywords:   []string{"ground beef", "beef mince", "minced beef", "ground pork", "ground turkey"},
+		categories: []string{"meat"},
+		substitutes: []substitutionRule{
+			{name: "cooked brown lentils", factor: 1, ratio: "1:1 by weight", notes: "Great in sauces, chili and shepherd's pie. Add soy sauce or mushrooms for depth."},
+			{name: "crumbled tempeh", factor: 1, ratio: "1:1 by weight", notes: "Firm and meaty; steam for 10 minutes first to remove bitterness.", contains: []string{"soy"}},
+		},
+	},
+	{
+		keywords:   []string{"bacon", "pancetta"},
+		categories: []string{"meat"},
+		substitutes: []substitutionRule{
+			{name: "smoked tempeh strips", factor: 1, ratio: "1:1 by weight", notes: "Marinate in soy sauce, maple syrup and smoked paprika.", contains: []string{"soy", "gluten"}},
+			{name: "shiitake mushrooms", factor: 1.5, ratio: "1 part bacon = 1 1/2 parts mushrooms", notes: "Roast until crisp with smoked paprika for a smoky, savory bite."},
+		},
+	},
+	{
+		keywords:   []string{"chicken stock", "chicken broth", "beef stock", "beef broth"},
+		categories: []string{"meat"},
+		substitutes: []substitutionRule{
+			{name: "vegetable stock", factor: 1, ratio: "1:1", notes: "Lighter in flavor; a splash of soy sauce or miso adds depth."},
+		},
+	},
+	{
+		keywords:   []string{"white wine", "red wine", "wine"},
+		categories: []string{"alcohol"},
+		substitutes: []substitutionRule{
+			{name: "stock with a splash of vinegar", factor: 1, ratio: "1 cup wine = 1 cup stock + 1 tbsp vinegar", notes: "Keeps the acidity wine brings to deglazing and braising."},
+			{name: "grape juice", factor: 1, ratio: "1:1", notes: "Sweeter; add a little lemon juice or vinegar to balance it."},
+		},
+	},
+	{
+		keywords:   []string{"peanut butter", "almond butter", "cashew butter"},
+		categories: []string{"nut"},
+		substitutes: []substitutionRule{
+			{name: "sunflower seed butter", factor: 1, ratio: "1:1", notes: "Tastes close to peanut butter. May turn green in baking; that is harmless."},
+			{name: "tahini", factor: 1, ratio: "1:1", notes: "More bitter and savory; great in dressings and sauces."},
+		},
+	},
+	{
+		keywords:   []string{"almond", "walnut", "pecan", "cashew", "peanut", "hazelnut", "pine nut"},
+		categories: []string{"nut"},
+		substitutes: []substitutionRule{
+			{name: "toasted sunflower seeds", factor: 1, ratio: "1:1", notes: "Similar crunch in salads, granola and baking."},
+			{name: "pumpkin seeds", factor: 1, ratio: "1:1", notes: "Toast for the best flavor; good in pesto in place of pine nuts."},
+		},
+	},
+	{
+		keywords:   []string{"almond milk"},
+		categories: []string{"nut"},
+		substitutes: []substitutionRule{
+			{name: "oat milk", factor: 1, ratio: "1:1", notes: "Creamy and neutral; a direct swap."},
+			{name: "soy milk", factor: 1, ratio: "1:1", notes: "Richer in protein.", contains: []string{"soy"}},
+		},
+	},
+	{
+		keywords: []string{"granulated sugar", "white sugar", "caster sugar", "sugar"},
+		substitutes: []substitutionRule{
+			{name: "coconut sugar", factor: 1, ratio: "1:1", notes: "Caramel flavor and darker color; slightly less sweet."},
+			{name: "honey", factor: 0.75, ratio: "1 cup sugar = 3/4 cup honey", notes: "Reduce other liquids by 60 ml per 200 g sugar and lower the oven by 10°C.", contains: []string{"honey"}},
+			{name: "maple syrup", factor: 0.75, ratio: "1 cup sugar = 3/4 cup maple syrup", notes: "Reduce other liquids by 45 ml per 200 g sugar."},
+		},
+	},
+	{
+		keywords: []string{"brown sugar"},
+		substitutes: []substitutionRule{
+			{name: "coconut sugar", factor: 1, ratio: "1:1", notes: "Similar caramel notes; bakes slightly drier."},
+			{name: "granulated sugar with molasses", factor: 1, ratio: "1 cup brown sugar = 1 cup sugar + 1 tbsp molasses", notes: "Mix thoroughly for the same moisture and flavor."},
+		},
+	},
+	{
+		keywords: []string{"lemon juice"},
+		substitutes: []substitutionRule{
+			{name: "lime juice", factor: 1, ratio: "1:1", notes: "Same acidity with a slightly different aroma."},
+			{name: "white wine vinegar", factor: 0.5, ratio: "2 parts lemon juice = 1 part vinegar", notes: "Sharper; fine in dressings and marinades but lacks citrus flavor."},
+		},
+	},
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSubstitutionRecipe() *models.Recipe {
+	return &models.Recipe{
+		Name: "Pancakes",
+		Ingredients: models.JSONBStringArray{
+			"250 g all-purpose flour",
+			"2 large eggs",
+			"300 ml milk",
+			"2 tbsp butter, melted",
+			"1 tbsp peanut butter",
+			"200 ml coconut milk",
+		},
+		Instructions: models.JSONBStringArray{
+			"Whisk the eggs and milk together.",
+			"Stir in the melted butter.",
+		},
+		Servings: 4,
+	}
+}
+
+func TestSuggestSubstitutionsForIngredient(t *testing.T) {
+	svc := NewSubstitutionService(nil)
+
+	suggestions, err := svc.SuggestSubstitutions(context.Background(), testSubstitutionRecipe(), "eggs", "")
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, "2 large eggs", suggestions[0].Ingredient)
+	require.NotEmpty(t, suggestions[0].Substitutes)
+
+	first := suggestions[0].Substitutes[0]
+	assert.Equal(t, "ground flaxseed", first.Name)
+	assert.Equal(t, "2 tbsp ground flaxseed mixed with 6 tbsp water", first.Replacement)
+	assert.Equal(t, SubstitutionSourceTable, first.Source)
+	assert.Greater(t, first.Score, suggestions[0].Substitutes[1].Score)
+}
+
+func TestSuggestSubstitutionsForConstraint(t *testing.T) {
+	svc := NewSubstitutionService(nil)
+
+	suggestions, err := svc.SuggestSubstitutions(context.Background(), testSubstitutionRecipe(), "", "dairy-free")
+	require.NoError(t, err)
+
+	var lines []string
+	for _, s := range suggestions {
+		lines = append(lines, s.Ingredient)
+	}
+	assert.ElementsMatch(t, []string{"300 ml milk", "2 tbsp butter, melted"}, lines)
+
+	suggestions, err = svc.SuggestSubstitutions(context.Background(), testSubstitutionRecipe(), "", "vegan")
+	require.NoError(t, err)
+	for _, s := range suggestions {
+		if s.Ingredient == "2 large eggs" {
+			for _, sub := range s.Substitutes {
+				assert.NotEqual(t, "honey", sub.Name)
+			}
+		}
+	}
+
+	// Substitutes that break the constraint themselves are dropped
+	suggestions, err = svc.SuggestSubstitutions(context.Background(), testSubstitutionRecipe(), "milk", "nut-free")
+	require.NoError(t, err)
+	for _, sub := range suggestions[0].Substitutes {
+		assert.NotEqual(t, "almond milk", sub.Name)
+	}
+
+	_, err = svc.SuggestSubstitutions(context.Background(), testSubstitutionRecipe(), "", "")
+	assert.ErrorIs(t, err, ErrSubstitutionRequest)
+}
+
+func TestApplySubstitutions(t *testing.T) {
+	svc := NewSubstitutionService(nil)
+
+	draft, err := svc.ApplySubstitutions(context.Background(), testSubstitutionRecipe(), []SubstitutionChoice{
+		{Ingredient: "2 tbsp butter, melted", Substitute: "olive oil"},
+		{Ingredient: "eggs", Substitute: "aquafaba"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1 1/2 tbsp olive oil", draft.Ingredients[3])
+	assert.Equal(t, "6 tbsp aquafaba", draft.Ingredients[1])
+	assert.Equal(t, "Whisk the aquafaba and milk together.", draft.Instructions[0])
+	assert.Equal(t, "Stir in the melted olive oil.", draft.Instructions[1])
+	assert.Equal(t, "4", draft.Servings.Value)
+
+	_, err = svc.ApplySubstitutions(context.Background(), testSubstitutionRecipe(), []SubstitutionChoice{
+		{Ingredient: "saffron", Substitute: "turmeric"},
+	})
+	assert.ErrorIs(t, err, ErrSubstitutionNotFound)
+}
+
+func TestParseConstraint(t *testing.T) {
+	excluded, ingredient := parseConstraint("No Eggs")
+	assert.True(t, excluded["egg"])
+	assert.Empty(t, ingredient)
+
+	excluded, _ = parseConstraint("nut-free")
+	assert.True(t, excluded["nut"])
+
+	excluded, ingredient = parseConstraint("no cilantro")
+	assert.Empty(t, excluded)
+	assert.Equal(t, "cilantro", ingredient)
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// testModels are the models the service tests store, one table each
+var testModels = []interface{}{
+	&models.Recipe{}, &models.RecipeFavorite{}, &models.UserProfile{},
+	&models.RecipeReview{}, &models.RecipeComment{}, &models.RecipeCommentEdit{}, &models.Notification{},
+	&models.Collection{}, &models.CollectionRecipe{}, &models.RecipeShare{},
+	&models.Follow{}, &models.Activity{},
+	&models.RecipeView{}, &models.RecipeTrendingScore{}, &models.FeaturedCuration{},
+	&models.MealPlan{}, &models.MealPlanEntry{}, &models.ShoppingList{}, &models.ShoppingListItem{},
+	&models.Food{},
+}
+
+// testIndexes are the unique indexes the services rely on that only the
+// migrations declare
+var testIndexes = []string{
+	`CREATE UNIQUE INDEX idx_recipe_favorites_user_recipe ON recipe_favorites (user_id, recipe_id)`,
+	`CREATE UNIQUE INDEX idx_recipe_reviews_recipe_user ON recipe_reviews (recipe_id, user_id)`,
+	`CREATE UNIQUE INDEX idx_meal_plan_entries_slot ON meal_plan_entries (meal_plan_id, date, slot)`,
+	`CREATE UNIQUE INDEX idx_collections_user_name ON collections (user_id, LOWER(name))`,
+	`CREATE UNIQUE INDEX idx_collections_user_default ON collections (user_id) WHERE is_default`,
+}
+
+// sqliteUUID generates a UUID in its text form, standing in for Postgres'
+// gen_random_uuid()
+const sqliteUUID = `(lower(hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-' || hex(randomblob(2)) || '-' || ` +
+	`hex(randomblob(2)) || '-' || hex(randomblob(6))))`
+
+// newTestDB opens an in-memory SQLite database with the tables of
+// testModels, built from their gorm schema. Columns are nullable so that
+// tests only set the fields they are about.
+func newTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	for _, model := range testModels {
+		stmt := &gorm.Statement{DB: db}
+		require.NoError(t, stmt.Parse(model))
+		for _, ddl := range sqliteTable(stmt.Schema) {
+			require.NoError(t, db.Exec(ddl).Error, ddl)
+		}
+	}
+	for _, ddl := range testIndexes {
+		require.NoError(t, db.Exec(ddl).Error, ddl)
+	}
+	return db
+}
+
+// sqliteTable returns the statements creating a model's table and the
+// indexes its tags declare
+func sqliteTable(s *schema.Schema) []string {
+	var columns, primaryKey []string
+	for _, field := range s.Fields {
+		if field.DBName == "" || field.IgnoreMigration {
+			continue
+		}
+		column := field.DBName + " " + sqliteType(field)
+		switch value := field.DefaultValueInterface.(type) {
+		case string:
+			column += " DEFAULT '" + value + "'"
+		case nil:
+			if strings.HasPrefix(field.DefaultValue, "gen_random_uuid") {
+				column += " DEFAULT " + sqliteUUID
+			}
+		default:
+			column += fmt.Sprintf(" DEFAULT %v", value)
+		}
+		if field.Unique {
+			column += " UNIQUE"
+		}
+		columns = append(columns, column)
+		if field.PrimaryKey {
+			primaryKey = append(primaryKey, field.DBName)
+		}
+	}
+	if len(primaryKey) > 0 {
+		columns = append(columns, "PRIMARY KEY ("+strings.Join(primaryKey, ", ")+")")
+	}
+
+	statements := []string{fmt.Sprintf("CREATE TABLE %s (%s)", s.Table, strings.Join(columns, ", "))}
+	for _, index := range s.ParseIndexes() {
+		if index.Class != "UNIQUE" {
+			continue
+		}
+		names := make([]string, len(index.Fields))
+		for i, f := range index.Fields {
+			names[i] = f.DBName
+		}
+		statements = append(statements, fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", index.Name, s.Table, strings.Join(names, ", ")))
+	}
+	return statements
+}
+
+// sqliteType returns the column type SQLite needs to hand a field back as
+// its Go type; SQLite only converts dates and times by the declared type
+func sqliteType(field *schema.Field) string {
+	switch field.GORMDataType {
+	case schema.Time:
+		if strings.EqualFold(string(field.DataType), "date") {
+			return "DATE"
+		}
+		return "DATETIME"
+	case schema.Bool:
+		return "BOOLEAN"
+	case schema.Int, schema.Uint:
+		return "INTEGER"
+	case schema.Float:
+		return "REAL"
+	default:
+		return "TEXT"
+	}
+}
@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Trending tuning. Each favorite, fork, view and review adds its weight to a
+// recipe's score, halved for every trendingHalfLife since it happened.
+const (
+	// trendingWindow is how far back events count
+	trendingWindow   = 14 * 24 * time.Hour
+	trendingHalfLife = 72 * time.Hour
+	favoriteWeight   = 3.0
+	forkWeight       = 5.0
+	viewWeight       = 0.1
+	// reviewBaseline is subtracted from a review's rating to weigh it, so
+	// one and two star reviews do not lift a recipe
+	reviewBaseline = 2.0
+
+	// FeaturedLimit is the number of featured recipes
+	FeaturedLimit = 6
+	// featuredCacheTTL bounds how long featured recipes lag behind trending
+	// refreshes
+	featuredCacheTTL = 10 * time.Minute
+	featuredCacheKey = "recipes:featured"
+)
+
+var (
+	// ErrCurationNotFound is returned when a recipe has no featured curation
+	ErrCurationNotFound = errors.New("featured curation not found")
+	// ErrInvalidCuration is returned when pinning a recipe that is not public
+	ErrInvalidCuration = errors.New("invalid featured curation")
+)
+
+// TrendingService scores recipes by recent activity and picks the featured
+// recipes: admin pins first, then the top trending recipes and then the
+// newest, all public and none excluded. Featured recipes are cached in Redis
+// when a client is configured.
+type TrendingService struct {
+	db    *gorm.DB
+	cache *redis.Client
+}
+
+// Ensure TrendingService implements ITrendingService
+var _ ITrendingService = (*TrendingService)(nil)
+
+// NewTrendingService creates a new TrendingService. cache may be nil, in
+// which case featured recipes are picked on every request.
+func NewTrendingService(db *gorm.DB, cache *redis.Client) *TrendingService {
+	return &TrendingService{db: db, cache: cache}
+}
+
+// trendingEvent is something that happened to a recipe, weighted by kind
+type trendingEvent struct {
+	RecipeID uuid.UUID
+	At       time.Time
+	Weight   float64
+}
+
+// RefreshScores recomputes every recipe's trending score as of now and
+// replaces the stored scores, returning how many recipes have one
+func (s *TrendingService) RefreshScores(ctx context.Context, now time.Time) (int, error) {
+	events, err := s.trendingEvents(ctx, now.Add(-trendingWindow))
+	if err != nil {
+		return 0, err
+	}
+
+	scores := make(map[uuid.UUID]float64)
+	for _, e := range events {
+		age := now.Sub(e.At)
+		if age < 0 {
+			age = 0
+		}
+		scores[e.RecipeID] += e.Weight * math.Pow(0.5, age.Hours()/trendingHalfLife.Hours())
+	}
+	rows := make([]models.RecipeTrendingScore, 0, len(scores))
+	for id, score := range scores {
+		if score > 0 {
+			rows = append(rows, models.RecipeTrendingScore{RecipeID: id, Score: score, ComputedAt: now})
+		}
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.RecipeTrendingScore{}).Error; err != nil {
+			return fmt.Errorf("failed to clear trending scores: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(rows, 500).Error; err != nil {
+			return fmt.Errorf("failed to store trending scores: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+// trendingEvents reads the favorites, forks, reviews and daily views since a
+// time. Views count from the middle of their day.
+func (s *TrendingService) trendingEvents(ctx context.Context, since time.Time) ([]trendingEvent, error) {
+	db := s.db.WithContext(ctx)
+	var events []trendingEvent
+
+	var favorites []trendingEvent
+	if err := db.Model(&models.RecipeFavorite{}).Select("recipe_id, created_at AS at").
+		Where("created_at > ?", since).Scan(&favorites).Error; err != nil {
+		return nil, fmt.Errorf("failed to read favorites: %w", err)
+	}
+	for _, e := range favorites {
+		e.Weight = favoriteWeight
+		events = append(events, e)
+	}
+
+	var forks []trendingEvent
+	if err := db.Model(&models.Recipe{}).Select("forked_from_id AS recipe_id, created_at AS at").
+		Where("forked_from_id IS NOT NULL AND created_at > ?", since).Scan(&forks).Error; err != nil {
+		return nil, fmt.Errorf("failed to read forks: %w", err)
+	}
+	for _, e := range forks {
+		e.Weight = forkWeight
+		events = append(events, e)
+	}
+
+	var reviews []struct {
+		RecipeID  uuid.UUID
+		CreatedAt time.Time
+		Rating    int
+	}
+	if err := db.Model(&models.RecipeReview{}).Select("recipe_id, created_at, rating").
+		Where("created_at > ?", since).Scan(&reviews).Error; err != nil {
+		return nil, fmt.Errorf("failed to read reviews: %w", err)
+	}
+	for _, r := range reviews {
+		events = append(events, trendingEvent{RecipeID: r.RecipeID, At: r.CreatedAt, Weight: float64(r.Rating) - reviewBaseline})
+	}
+
+	var views []models.RecipeView
+	if err := db.Where("day >= ?", since.UTC().Truncate(24*time.Hour)).Find(&views).Error; err != nil {
+		return nil, fmt.Errorf("failed to read views: %w", err)
+	}
+	for _, v := range views {
+		events = append(events, trendingEvent{RecipeID: v.RecipeID, At: v.Day.Add(12 * time.Hour), Weight: viewWeight * float64(v.Views)})
+	}
+	return events, nil
+}
+
+// Trending returns one page of the recipes listed for the viewer that have
+// a trending score, highest first
+func (s *TrendingService) Trending(ctx context.Context, viewerID uuid.UUID, page pagination.Page) ([]*models.Recipe, *pagination.Info, error) {
+	query := s.db.WithContext(ctx).Model(&models.Recipe{}).Select("recipes.*").
+		Joins("JOIN recipe_trending_scores ON recipe_trending_scores.recipe_id = recipes.id").
+		Scopes(ListedRecipes(viewerID), notExcluded).
+		Order("recipe_trending_scores.score DESC").Order("recipes.id")
+
+	var recipes []*models.Recipe
+	if err := pagination.Offset(query, page).Find(&recipes).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to list trending recipes: %w", err)
+	}
+	recipes, info := pagination.Finish(recipes, page, nil)
+	return recipes, info, nil
+}
+
+// Featured returns the featured recipes, from the cache if present. Cached
+// recipes made private or deleted since are left out. Cache errors are
+// logged and fall back to picking the recipes.
+func (s *TrendingService) Featured(ctx context.Context) ([]*models.Recipe, error) {
+	if s.cache != nil {
+		data, err := s.cache.Get(ctx, featuredCacheKey).Bytes()
+		if err == nil {
+			var recipes []*models.Recipe
+			if err := json.Unmarshal(data, &recipes); err == nil {
+				return s.stillListed(ctx, recipes)
+			}
+		} else if err != redis.Nil {
+			log.Printf("Failed to read cached featured recipes: %v", err)
+		}
+	}
+
+	recipes, err := s.pickFeatured(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cache != nil {
+		data, err := json.Marshal(recipes)
+		if err == nil {
+			err = s.cache.Set(ctx, featuredCacheKey, data, featuredCacheTTL).Err()
+		}
+		if err != nil {
+			log.Printf("Failed to cache featured recipes: %v", err)
+		}
+	}
+	return recipes, nil
+}
+
+// stillListed drops the recipes that are no longer public, so that a recipe
+// made private or deleted leaves the cached featured recipes at once
+func (s *TrendingService) stillListed(ctx context.Context, recipes []*models.Recipe) ([]*models.Recipe, error) {
+	if len(recipes) == 0 {
+		return recipes, nil
+	}
+	ids := make([]uuid.UUID, len(recipes))
+	for i, recipe := range recipes {
+		ids[i] = recipe.ID
+	}
+	var listed []models.Recipe
+	if err := s.db.WithContext(ctx).Model(&models.Recipe{}).Select("recipes.id").
+		Scopes(ListedRecipes(uuid.Nil)).Where("recipes.id IN ?", ids).Find(&listed).Error; err != nil {
+		return nil, fmt.Errorf("failed to check featured recipes: %w", err)
+	}
+	keep := make(map[uuid.UUID]bool, len(listed))
+	for _, recipe := range listed {
+		keep[recipe.ID] = true
+	}
+	kept := recipes[:0]
+	for _, recipe := range recipes {
+		if keep[recipe.ID] {
+			kept = append(kept, recipe)
+		}
+	}
+	return kept, nil
+}
+
+// pickFeatured picks up to FeaturedLimit public recipes: pinned ones in
+// position order, then the top trending ones and then the newest
+func (s *TrendingService) pickFeatured(ctx context.Context) ([]*models.Recipe, error) {
+	db := s.db.WithContext(ctx)
+	public := db.Model(&models.Recipe{}).Select("recipes.*").Scopes(ListedRecipes(uuid.Nil))
+
+	var featured []*models.Recipe
+	if err := public.Session(&gorm.Session{}).
+		Joins("JOIN featured_curations ON featured_curations.recipe_id = recipes.id AND featured_curations.action = ?", models.CurationPin).
+		Order("featured_curations.position").Order("featured_curations.created_at").
+		Limit(FeaturedLimit).Find(&featured).Error; err != nil {
+		return nil, fmt.Errorf("failed to get pinned recipes: %w", err)
+	}
+
+	fills := []func(*gorm.DB) *gorm.DB{
+		func(db *gorm.DB) *gorm.DB {
+			return db.Joins("JOIN recipe_trending_scores ON recipe_trending_scores.recipe_id = recipes.id").
+				Order("recipe_trending_scores.score DESC").Order("recipes.id")
+		},
+		func(db *gorm.DB) *gorm.DB {
+			return db.Order("recipes.created_at DESC").Order("recipes.id")
+		},
+	}
+	for _, fill := range fills {
+		if len(featured) >= FeaturedLimit {
+			break
+		}
+		query := public.Session(&gorm.Session{}).Scopes(notExcluded, fill)
+		if len(featured) > 0 {
+			ids := make([]uuid.UUID, len(featured))
+			for i, recipe := range featured {
+				ids[i] = recipe.ID
+			}
+			query = query.Where("recipes.id NOT IN ?", ids)
+		}
+		var more []*models.Recipe
+		if err := query.Limit(FeaturedLimit - len(featured)).Find(&more).Error; err != nil {
+			return nil, fmt.Errorf("failed to get featured recipes: %w", err)
+		}
+		featured = append(featured, more...)
+	}
+	return featured, nil
+}
+
+// notExcluded leaves recipes excluded from featuring out of a recipes query
+func notExcluded(db *gorm.DB) *gorm.DB {
+	return db.Where("recipes.id NOT IN (SELECT recipe_id FROM featured_curations WHERE action = ?)", models.CurationExclude)
+}
+
+// InvalidateFeatured drops the cached featured recipes so the next request
+// picks them again
+func (s *TrendingService) InvalidateFeatured(ctx context.Context) error {
+	if s.cache == nil {
+		return nil
+	}
+	if err := s.cache.Del(ctx, featuredCacheKey).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate featured recipes: %w", err)
+	}
+	return nil
+}
+
+// ListCurations returns the featured curations, pins in position order and
+// then exclusions, newest first
+func (s *TrendingService) ListCurations(ctx context.Context) ([]*models.FeaturedCuration, error) {
+	var curations []*models.FeaturedCuration
+	if err := s.db.WithContext(ctx).Model(&models.FeaturedCuration{}).
+		Select("featured_curations.*, recipes.name AS recipe_name").
+		Joins("JOIN recipes ON recipes.id = featured_curations.recipe_id").
+		Order("CASE featured_curations.action WHEN '" + models.CurationPin + "' THEN 0 ELSE 1 END").
+		Order("featured_curations.position").Order("featured_curations.created_at DESC").
+		Find(&curations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list featured curations: %w", err)
+	}
+	return curations, nil
+}
+
+// SetCuration pins or excludes a recipe, replacing any earlier curation of
+// it. Only public recipes can be pinned.
+func (s *TrendingService) SetCuration(ctx context.Context, adminID, recipeID uuid.UUID, req *types.SetCurationRequest) (*models.FeaturedCuration, error) {
+	var recipe models.Recipe
+	if err := s.db.WithContext(ctx).Select("id, visibility").First(&recipe, "id = ?", recipeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecipeNotFound
+		}
+		return nil, fmt.Errorf("failed to get recipe: %w", err)
+	}
+	if req.Action == models.CurationPin && recipe.Visibility != models.RecipePublic {
+		return nil, fmt.Errorf("%w: only public recipes can be pinned", ErrInvalidCuration)
+	}
+
+	curation := &models.FeaturedCuration{RecipeID: recipeID, Action: req.Action, Position: req.Position, CreatedBy: &adminID}
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "recipe_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"action", "position", "created_by", "updated_at"}),
+	}).Create(curation).Error; err != nil {
+		return nil, fmt.Errorf("failed to save featured curation: %w", err)
+	}
+	s.invalidate(ctx)
+	return curation, nil
+}
+
+// DeleteCuration removes a recipe's curation, returning it to the usual
+// trending order
+func (s *TrendingService) DeleteCuration(ctx context.Context, recipeID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Where("recipe_id = ?", recipeID).Delete(&models.FeaturedCuration{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete featured curation: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrCurationNotFound
+	}
+	s.invalidate(ctx)
+	return nil
+}
+
+// invalidate drops the cached featured recipes after a curation change,
+// logging failures since the cache expires on its own
+func (s *TrendingService) invalidate(ctx context.Context) {
+	if err := s.InvalidateFeatured(ctx); err != nil {
+		log.Printf("%v", err)
+	}
+}
+
+// TrendingWorker refreshes trending scores in the background
+type TrendingWorker struct {
+	service  *TrendingService
+	interval time.Duration
+}
+
+// NewTrendingWorker creates a worker that refreshes trending scores every
+// interval
+func NewTrendingWorker(service *TrendingService, interval time.Duration) *TrendingWorker {
+	return &TrendingWorker{service: service, interval: interval}
+}
+
+// Run refreshes trending scores until ctx is cancelled, starting with one
+// refresh right away. Cached featured recipes pick up new scores when they
+// expire.
+func (w *TrendingWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.service.RefreshScores(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("Trending refresh failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshScoresDecaysOverTime(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewTrendingService(f.db, nil)
+	ctx := context.Background()
+	now := time.Now()
+
+	// A fresh favorite outweighs an older fork; low ratings and events
+	// outside the window count for nothing
+	require.NoError(t, f.db.Exec("INSERT INTO recipe_favorites (id, user_id, recipe_id, created_at) VALUES (?, ?, ?, ?), (?, ?, ?, ?)",
+		uuid.New(), f.viewer, f.public, now.Add(-time.Hour),
+		uuid.New(), f.viewer, f.own, now.Add(-30*24*time.Hour)).Error)
+	require.NoError(t, f.db.Exec("UPDATE recipes SET forked_from_id = ? WHERE id = ?", f.unlisted, f.own).Error)
+	require.NoError(t, f.db.Exec("UPDATE recipes SET created_at = ? WHERE id = ?", now.Add(-6*24*time.Hour), f.own).Error)
+	require.NoError(t, f.db.Create(&models.RecipeReview{RecipeID: f.private, UserID: f.viewer, Rating: 1, CreatedAt: now}).Error)
+
+	n, err := s.RefreshScores(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	var scores []models.RecipeTrendingScore
+	require.NoError(t, f.db.Order("score DESC").Find(&scores).Error)
+	require.Len(t, scores, 2)
+	assert.Equal(t, f.public, scores[0].RecipeID)
+	assert.InDelta(t, 2.97, scores[0].Score, 0.01)
+	assert.Equal(t, f.unlisted, scores[1].RecipeID)
+	assert.InDelta(t, 5.0/4, scores[1].Score, 0.01)
+
+	// Refreshing replaces the stored scores
+	n, err = s.RefreshScores(ctx, now.Add(60*24*time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestViewsCountTowardsTrending(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewTrendingService(f.db, nil)
+	ctx := context.Background()
+
+	require.NoError(t, NewAnalyticsService(f.db, nil).RecordView(ctx, f.public, f.viewer))
+	_, err := s.RefreshScores(ctx, time.Now())
+	require.NoError(t, err)
+	recipes, _, err := s.Trending(ctx, f.viewer, pagination.Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.public}, recipeIDs(recipes))
+}
+
+func TestTrendingListsVisibleRecipes(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewTrendingService(f.db, nil)
+	ctx := context.Background()
+	require.NoError(t, f.db.Create([]models.RecipeTrendingScore{
+		{RecipeID: f.private, Score: 4},
+		{RecipeID: f.unlisted, Score: 3},
+		{RecipeID: f.public, Score: 2},
+		{RecipeID: f.own, Score: 1},
+	}).Error)
+
+	recipes, info, err := s.Trending(ctx, f.viewer, pagination.Page{Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.public}, recipeIDs(recipes))
+	after, err := pagination.Decode(info.NextCursor)
+	require.NoError(t, err)
+	recipes, info, err = s.Trending(ctx, f.viewer, pagination.Page{Limit: 1, After: after})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.own}, recipeIDs(recipes))
+	assert.Empty(t, info.NextCursor)
+
+	// Excluded recipes drop out of trending
+	_, err = s.SetCuration(ctx, f.author, f.public, &types.SetCurationRequest{Action: models.CurationExclude})
+	require.NoError(t, err)
+	recipes, _, err = s.Trending(ctx, f.viewer, pagination.Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.own}, recipeIDs(recipes))
+}
+
+func TestFeaturedOrdersPinsTrendingAndNewest(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewTrendingService(f.db, nil)
+	ctx := context.Background()
+	admin := uuid.New()
+
+	insert := func(name string, age time.Duration) uuid.UUID {
+		id := uuid.New()
+		require.NoError(t, f.db.Exec(`INSERT INTO recipes (id, created_at, updated_at, name, description, ingredients, user_id, visibility, embedding)
+			VALUES (?, ?, ?, ?, '', '[]', ?, ?, '[0]')`,
+			id, time.Now().Add(-age), time.Now(), name, f.author, models.RecipePublic).Error)
+		return id
+	}
+	var public []uuid.UUID
+	for i := 0; i < FeaturedLimit; i++ {
+		public = append(public, insert("Stew", time.Duration(i)*time.Minute))
+	}
+	require.NoError(t, f.db.Create([]models.RecipeTrendingScore{
+		{RecipeID: public[5], Score: 2},
+		{RecipeID: public[4], Score: 1},
+		{RecipeID: f.private, Score: 9},
+	}).Error)
+
+	_, err := s.SetCuration(ctx, admin, f.public, &types.SetCurationRequest{Action: models.CurationPin, Position: 1})
+	require.NoError(t, err)
+	_, err = s.SetCuration(ctx, admin, public[0], &types.SetCurationRequest{Action: models.CurationExclude})
+	require.NoError(t, err)
+	_, err = s.SetCuration(ctx, admin, f.unlisted, &types.SetCurationRequest{Action: models.CurationPin})
+	assert.True(t, errors.Is(err, ErrInvalidCuration))
+
+	recipes, err := s.Featured(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.public, public[5], public[4], public[1], public[2], public[3]}, recipeIDs(recipes))
+
+	curations, err := s.ListCurations(ctx)
+	require.NoError(t, err)
+	require.Len(t, curations, 2)
+	assert.Equal(t, models.CurationPin, curations[0].Action)
+	assert.Equal(t, "Public soup", curations[0].RecipeName)
+
+	require.NoError(t, s.DeleteCuration(ctx, public[0]))
+	assert.True(t, errors.Is(s.DeleteCuration(ctx, public[0]), ErrCurationNotFound))
+	_, err = s.SetCuration(ctx, admin, uuid.New(), &types.SetCurationRequest{Action: models.CurationExclude})
+	assert.True(t, errors.Is(err, ErrRecipeNotFound))
+}
+
+func TestCachedFeaturedDropsRecipesNoLongerPublic(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewTrendingService(f.db, nil)
+	ctx := context.Background()
+
+	// Featured recipes cached while all four were public; since then the
+	// private one was made private, the unlisted one deleted and the
+	// viewer's own published
+	require.NoError(t, f.db.Delete(&models.Recipe{}, "id = ?", f.unlisted).Error)
+	require.NoError(t, f.db.Model(&models.Recipe{}).Where("id = ?", f.own).Update("visibility", models.RecipePublic).Error)
+	var cached []*models.Recipe
+	for _, id := range []uuid.UUID{f.public, f.private, f.unlisted, f.own} {
+		cached = append(cached, &models.Recipe{ID: id})
+	}
+
+	recipes, err := s.stillListed(ctx, cached)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.public, f.own}, recipeIDs(recipes))
+}
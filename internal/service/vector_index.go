@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+	"gorm.io/gorm"
+)
+
+// Vector index types
+const (
+	IndexHNSW    = "hnsw"
+	IndexIVFFlat = "ivfflat"
+)
+
+// HNSW build defaults, matching pgvector's own
+const (
+	defaultHNSWM              = 16
+	defaultHNSWEfConstruction = 64
+)
+
+// ErrInvalidVectorIndex is returned for index options that cannot be built
+var ErrInvalidVectorIndex = errors.New("invalid vector index")
+
+// VectorIndexOptions describes a per-model approximate nearest-neighbour
+// index on recipes.embedding. Indexes cover one model's rows through a cast
+// to its dimension and use cosine distance, which suits normalized embeddings.
+type VectorIndexOptions struct {
+	Type       string
+	Model      string
+	Dimensions int
+	// M and EfConstruction tune HNSW; zero uses pgvector's defaults
+	M              int
+	EfConstruction int
+	// Lists tunes IVFFlat; zero picks rows/1000 for up to a million rows and
+	// the square root of the rows beyond that
+	Lists int
+}
+
+// VectorIndex is an existing vector index on recipes
+type VectorIndex struct {
+	Name       string `json:"name"`
+	Definition string `json:"definition"`
+	Size       string `json:"size"`
+}
+
+// VectorIndexManager creates, lists and benchmarks vector indexes
+type VectorIndexManager struct {
+	db *gorm.DB
+}
+
+// NewVectorIndexManager creates a new VectorIndexManager
+func NewVectorIndexManager(db *gorm.DB) *VectorIndexManager {
+	return &VectorIndexManager{db: db}
+}
+
+var nonIdentifier = regexp.MustCompile(`[^a-z0-9]+`)
+
+// VectorIndexName names the index for a model and index type, such as
+// idx_recipes_embedding_text_embedding_ada_002_hnsw
+func VectorIndexName(opts VectorIndexOptions) string {
+	model := strings.Trim(nonIdentifier.ReplaceAllString(strings.ToLower(opts.Model), "_"), "_")
+	name := fmt.Sprintf("idx_recipes_embedding_%s_%s", model, opts.Type)
+	// Postgres truncates identifiers to 63 bytes
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return name
+}
+
+// VectorIndexSQL returns the statement that builds an index. Concurrent
+// builds do not block writes but cannot run inside a transaction.
+func VectorIndexSQL(opts VectorIndexOptions, concurrently bool) (string, error) {
+	if opts.Model == "" || opts.Dimensions < 1 {
+		return "", fmt.Errorf("%w: model and dimensions are required", ErrInvalidVectorIndex)
+	}
+	// pgvector indexes vectors of up to 2000 dimensions
+	if opts.Dimensions > 2000 {
+		return "", fmt.Errorf("%w: pgvector cannot index %d dimensions", ErrInvalidVectorIndex, opts.Dimensions)
+	}
+
+	var with string
+	switch opts.Type {
+	case IndexHNSW:
+		m, ef := opts.M, opts.EfConstruction
+		if m == 0 {
+			m = defaultHNSWM
+		}
+		if ef == 0 {
+			ef = defaultHNSWEfConstruction
+		}
+		if m < 2 || m > 100 || ef < 2*m {
+			return "", fmt.Errorf("%w: hnsw needs m between 2 and 100 and ef_construction of at least 2*m", ErrInvalidVectorIndex)
+		}
+		with = fmt.Sprintf("m = %d, ef_construction = %d", m, ef)
+	case IndexIVFFlat:
+		if opts.Lists < 1 {
+			return "", fmt.Errorf("%w: ivfflat needs lists", ErrInvalidVectorIndex)
+		}
+		with = fmt.Sprintf("lists = %d", opts.Lists)
+	default:
+		return "", fmt.Errorf("%w: type must be %s or %s", ErrInvalidVectorIndex, IndexHNSW, IndexIVFFlat)
+	}
+
+	create := "CREATE INDEX"
+	if concurrently {
+		create += " CONCURRENTLY"
+	}
+	return fmt.Sprintf("%s IF NOT EXISTS %s ON recipes USING %s ((embedding::vector(%d)) vector_cosine_ops) WITH (%s) WHERE embedding_model = %s",
+		create, VectorIndexName(opts), opts.Type, opts.Dimensions, with, quoteLiteral(opts.Model)), nil
+}
+
+// ivfflatLists is pgvector's recommended list count for a number of rows
+func ivfflatLists(rows int64) int {
+	if rows > 1000000 {
+		return int(math.Sqrt(float64(rows)))
+	}
+	if rows < 1000 {
+		return 1
+	}
+	return int(rows / 1000)
+}
+
+// Create builds an index without blocking writes. IVFFlat clusters the rows
+// present at build time, so build it after embedding the recipes.
+func (m *VectorIndexManager) Create(ctx context.Context, opts VectorIndexOptions) (string, error) {
+	if opts.Type == IndexIVFFlat && opts.Lists == 0 {
+		var rows int64
+		if err := m.db.WithContext(ctx).Table("recipes").
+			Where("embedding_model = ? AND embedding_dimensions = ?", opts.Model, opts.Dimensions).
+			Count(&rows).Error; err != nil {
+			return "", fmt.Errorf("failed to count embedded recipes: %w", err)
+		}
+		opts.Lists = ivfflatLists(rows)
+	}
+	stmt, err := VectorIndexSQL(opts, true)
+	if err != nil {
+		return "", err
+	}
+	if err := m.db.WithContext(ctx).Exec(stmt).Error; err != nil {
+		return "", fmt.Errorf("failed to create index: %w", err)
+	}
+	return VectorIndexName(opts), nil
+}
+
+// List returns the HNSW and IVFFlat indexes on recipes
+func (m *VectorIndexManager) List(ctx context.Context) ([]VectorIndex, error) {
+	var indexes []VectorIndex
+	if err := m.db.WithContext(ctx).Raw(`SELECT indexname AS name, indexdef AS definition,
+		pg_size_pretty(pg_relation_size(format('%I.%I', schemaname, indexname)::regclass)) AS size
+		FROM pg_indexes
+		WHERE tablename = 'recipes' AND (indexdef ILIKE '%USING hnsw%' OR indexdef ILIKE '%USING ivfflat%')
+		ORDER BY indexname`).Scan(&indexes).Error; err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+	return indexes, nil
+}
+
+// Drop removes a vector index without blocking reads or writes
+func (m *VectorIndexManager) Drop(ctx context.Context, name string) error {
+	indexes, err := m.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		if index.Name == name {
+			return m.db.WithContext(ctx).Exec("DROP INDEX CONCURRENTLY IF EXISTS " + quoteIdentifier(name)).Error
+		}
+	}
+	return fmt.Errorf("%w: no vector index named %s", ErrInvalidVectorIndex, name)
+}
+
+// quoteIdentifier quotes a string as an SQL identifier
+func quoteIdentifier(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// BenchmarkOptions controls a recall and latency benchmark
+type BenchmarkOptions struct {
+	Model      string
+	Dimensions int
+	// Queries is how many recipe embeddings are used as query vectors
+	Queries int
+	// K is how many neighbours each query asks for
+	K int
+	// EfSearch and Probes are the settings to compare; an empty list runs
+	// with the session defaults
+	EfSearch []int
+	Probes   []int
+}
+
+// BenchmarkResult is the recall and latency of one search setting. Exact
+// results are the baseline, with recall 1.
+type BenchmarkResult struct {
+	Setting string        `json:"setting"`
+	Recall  float64       `json:"recall"`
+	P50     time.Duration `json:"p50"`
+	P95     time.Duration `json:"p95"`
+	Mean    time.Duration `json:"mean"`
+}
+
+// Benchmark compares approximate nearest-neighbour search under each setting
+// with exact search, using the embeddings of randomly chosen recipes as
+// queries. Recall is the share of the exact top K that the index finds.
+func (m *VectorIndexManager) Benchmark(ctx context.Context, opts BenchmarkOptions) ([]BenchmarkResult, error) {
+	if opts.Queries < 1 || opts.K < 1 {
+		return nil, fmt.Errorf("%w: queries and k must be positive", ErrInvalidVectorIndex)
+	}
+	var queries []pgvector.Vector
+	if err := m.db.WithContext(ctx).Table("recipes").
+		Where("embedding_model = ? AND embedding_dimensions = ? AND deleted_at IS NULL", opts.Model, opts.Dimensions).
+		Order("random()").Limit(opts.Queries).
+		Pluck("embedding", &queries).Error; err != nil {
+		return nil, fmt.Errorf("failed to sample query vectors: %w", err)
+	}
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("%w: no recipes embedded by %s", ErrInvalidVectorIndex, opts.Model)
+	}
+
+	exact := make([][]uuid.UUID, len(queries))
+	result, err := m.runBenchmark(ctx, opts, queries, []string{"SET LOCAL enable_indexscan = off"}, exact)
+	if err != nil {
+		return nil, err
+	}
+	result.Setting = "exact"
+	results := []BenchmarkResult{*result}
+
+	var settings [][]string
+	for _, ef := range opts.EfSearch {
+		settings = append(settings, []string{fmt.Sprintf("SET LOCAL hnsw.ef_search = %d", ef)})
+	}
+	for _, probes := range opts.Probes {
+		settings = append(settings, []string{fmt.Sprintf("SET LOCAL ivfflat.probes = %d", probes)})
+	}
+	if len(settings) == 0 {
+		settings = append(settings, nil)
+	}
+	for _, setting := range settings {
+		result, err := m.runBenchmark(ctx, opts, queries, setting, exact)
+		if err != nil {
+			return nil, err
+		}
+		result.Setting = "index"
+		if len(setting) > 0 {
+			result.Setting = strings.TrimPrefix(setting[0], "SET LOCAL ")
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+// runBenchmark times each query under the session settings. If baseline
+// entries are empty they are filled with the results; otherwise recall is
+// measured against them.
+func (m *VectorIndexManager) runBenchmark(ctx context.Context, opts BenchmarkOptions, queries []pgvector.Vector, settings []string, baseline [][]uuid.UUID) (*BenchmarkResult, error) {
+	latencies := make([]time.Duration, len(queries))
+	var found, expected int
+	for i, query := range queries {
+		var rows []struct {
+			ID       uuid.UUID
+			Distance float64
+		}
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			for _, setting := range settings {
+				if err := tx.Exec(setting).Error; err != nil {
+					return err
+				}
+			}
+			// The same shape as the search queries, so the same index is used
+			start := time.Now()
+			err := tx.Table("recipes").
+				Select(fmt.Sprintf("id, embedding::vector(%d) <=> ? AS distance", opts.Dimensions), query).
+				Where(fmt.Sprintf("embedding_model = %s AND deleted_at IS NULL", quoteLiteral(opts.Model))).
+				Order("distance").Limit(opts.K).
+				Scan(&rows).Error
+			latencies[i] = time.Since(start)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to run benchmark query: %w", err)
+		}
+		ids := make([]uuid.UUID, len(rows))
+		for j, row := range rows {
+			ids[j] = row.ID
+		}
+
+		if baseline[i] == nil {
+			baseline[i] = ids
+			continue
+		}
+		want := make(map[uuid.UUID]bool, len(baseline[i]))
+		for _, id := range baseline[i] {
+			want[id] = true
+		}
+		for _, id := range ids {
+			if want[id] {
+				found++
+			}
+		}
+		expected += len(baseline[i])
+	}
+
+	result := latencyStats(latencies)
+	result.Recall = 1
+	if expected > 0 {
+		result.Recall = float64(found) / float64(expected)
+	}
+	return result, nil
+}
+
+// latencyStats summarizes query latencies
+func latencyStats(latencies []time.Duration) *BenchmarkResult {
+	sorted := append([]time.Duration{}, latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	percentile := func(p float64) time.Duration {
+		return sorted[int(math.Ceil(p*float64(len(sorted))))-1]
+	}
+	return &BenchmarkResult{
+		P50:  percentile(0.5),
+		P95:  percentile(0.95),
+		Mean: total / time.Duration(len(sorted)),
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVectorIndexSQL(t *testing.T) {
+	stmt, err := VectorIndexSQL(VectorIndexOptions{Type: IndexHNSW, Model: "nomic-embed-text", Dimensions: 768}, true)
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_recipes_embedding_nomic_embed_text_hnsw ON recipes "+
+		"USING hnsw ((embedding::vector(768)) vector_cosine_ops) WITH (m = 16, ef_construction = 64) "+
+		"WHERE embedding_model = 'nomic-embed-text'", stmt)
+
+	stmt, err = VectorIndexSQL(VectorIndexOptions{Type: IndexIVFFlat, Model: "o'brien", Dimensions: 3, Lists: 5}, false)
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE INDEX IF NOT EXISTS idx_recipes_embedding_o_brien_ivfflat ON recipes "+
+		"USING ivfflat ((embedding::vector(3)) vector_cosine_ops) WITH (lists = 5) "+
+		"WHERE embedding_model = 'o''brien'", stmt)
+
+	invalid := []VectorIndexOptions{
+		{Type: "btree", Model: "m", Dimensions: 3},
+		{Type: IndexHNSW, Dimensions: 3},
+		{Type: IndexHNSW, Model: "m", Dimensions: 3072},
+		{Type: IndexHNSW, Model: "m", Dimensions: 3, M: 32, EfConstruction: 40},
+		{Type: IndexIVFFlat, Model: "m", Dimensions: 3},
+	}
+	for _, opts := range invalid {
+		_, err := VectorIndexSQL(opts, true)
+		assert.ErrorIs(t, err, ErrInvalidVectorIndex, "%+v", opts)
+	}
+}
+
+func TestIVFFlatLists(t *testing.T) {
+	assert.Equal(t, 1, ivfflatLists(0))
+	assert.Equal(t, 50, ivfflatLists(50000))
+	assert.Equal(t, 2000, ivfflatLists(4000000))
+}
+
+func TestLatencyStats(t *testing.T) {
+	var latencies []time.Duration
+	for i := 20; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	stats := latencyStats(latencies)
+	assert.Equal(t, 10*time.Millisecond, stats.P50)
+	assert.Equal(t, 19*time.Millisecond, stats.P95)
+	assert.Equal(t, 10500*time.Microsecond, stats.Mean)
+}
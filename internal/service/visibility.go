@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// VisibleRecipes limits a recipes query to those the viewer can open: public
+// and unlisted recipes and the viewer's own. A nil viewer is signed out.
+func VisibleRecipes(viewerID uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(recipes.visibility IN ? OR recipes.user_id = ?)",
+			[]string{models.RecipePublic, models.RecipeUnlisted}, viewerID)
+	}
+}
+
+// ListedRecipes limits a recipes query to those that may appear in the
+// viewer's lists and search results: public recipes and the viewer's own
+func ListedRecipes(viewerID uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(recipes.visibility = ? OR recipes.user_id = ?)", models.RecipePublic, viewerID)
+	}
+}
+
+// VisibleProfiles limits a user_profiles query to those the viewer can open:
+// public and unlisted profiles and the viewer's own. Profile privacy levels
+// are the recipe visibility levels.
+func VisibleProfiles(viewerID uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(user_profiles.privacy_level IN ? OR user_profiles.user_id = ?)",
+			[]string{models.RecipePublic, models.RecipeUnlisted}, viewerID)
+	}
+}
+
+// ListedProfiles limits a user_profiles query to those that may appear in
+// lists of users shown to the viewer: public profiles and the viewer's own
+func ListedProfiles(viewerID uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(user_profiles.privacy_level = ? OR user_profiles.user_id = ?)", models.RecipePublic, viewerID)
+	}
+}
+
+// CanViewRecipe reports whether the viewer can open the recipe
+func CanViewRecipe(recipe *models.Recipe, viewerID uuid.UUID) bool {
+	return recipe.Visibility != models.RecipePrivate || (viewerID != uuid.Nil && recipe.UserID == viewerID)
+}
+
+// ValidRecipeVisibility reports whether v is a recipe visibility level
+func ValidRecipeVisibility(v string) bool {
+	switch v {
+	case models.RecipePrivate, models.RecipeUnlisted, models.RecipePublic:
+		return true
+	}
+	return false
+}
+
+// DefaultRecipeVisibility returns the visibility of the user's new recipes:
+// their profile's privacy level, or private if it is not a visibility level
+func DefaultRecipeVisibility(ctx context.Context, db *gorm.DB, userID uuid.UUID) string {
+	var profile models.UserProfile
+	if err := db.WithContext(ctx).Select("privacy_level").Where("user_id = ?", userID).First(&profile).Error; err != nil {
+		return models.RecipePrivate
+	}
+	if !ValidRecipeVisibility(profile.PrivacyLevel) {
+		return models.RecipePrivate
+	}
+	return profile.PrivacyLevel
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
+	"github.com/pageza/alchemorsel-v2/backend/internal/types"
+	"github.com/pageza/alchemorsel-v2/backend/internal/units"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// visibilityFixture is an in-memory database with one recipe of each
+// visibility by the author and a private recipe by the viewer
+type visibilityFixture struct {
+	db       *gorm.DB
+	author   uuid.UUID
+	viewer   uuid.UUID
+	private  uuid.UUID
+	unlisted uuid.UUID
+	public   uuid.UUID
+	own      uuid.UUID
+}
+
+func newVisibilityFixture(t *testing.T) *visibilityFixture {
+	db := newTestDB(t)
+
+	f := &visibilityFixture{db: db, author: uuid.New(), viewer: uuid.New()}
+	insert := func(userID uuid.UUID, name, visibility string, age time.Duration) uuid.UUID {
+		id := uuid.New()
+		require.NoError(t, db.Exec(`INSERT INTO recipes (id, created_at, updated_at, name, description, ingredients, user_id, visibility, embedding)
+			VALUES (?, ?, ?, ?, '', '[]', ?, ?, '[0]')`,
+			id, time.Now().Add(-age), time.Now(), name, userID, visibility).Error)
+		return id
+	}
+	f.private = insert(f.author, "Private soup", models.RecipePrivate, 4*time.Hour)
+	f.unlisted = insert(f.author, "Unlisted soup", models.RecipeUnlisted, 3*time.Hour)
+	f.public = insert(f.author, "Public soup", models.RecipePublic, 2*time.Hour)
+	f.own = insert(f.viewer, "Own soup", models.RecipePrivate, time.Hour)
+	return f
+}
+
+func recipeIDs(recipes []*models.Recipe) []uuid.UUID {
+	ids := make([]uuid.UUID, len(recipes))
+	for i, recipe := range recipes {
+		ids[i] = recipe.ID
+	}
+	return ids
+}
+
+func TestCanViewRecipe(t *testing.T) {
+	author := uuid.New()
+	for _, tc := range []struct {
+		visibility string
+		viewer     uuid.UUID
+		want       bool
+	}{
+		{models.RecipePrivate, author, true},
+		{models.RecipePrivate, uuid.New(), false},
+		{models.RecipePrivate, uuid.Nil, false},
+		{models.RecipeUnlisted, uuid.New(), true},
+		{models.RecipeUnlisted, uuid.Nil, true},
+		{models.RecipePublic, uuid.Nil, true},
+	} {
+		recipe := &models.Recipe{UserID: author, Visibility: tc.visibility}
+		assert.Equal(t, tc.want, CanViewRecipe(recipe, tc.viewer), "%s recipe", tc.visibility)
+	}
+
+	// A recipe without an author is not anyone's own
+	assert.False(t, CanViewRecipe(&models.Recipe{Visibility: models.RecipePrivate}, uuid.Nil))
+}
+
+func TestGetRecipeHidesPrivateRecipes(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewRecipeService(f.db, nil)
+	ctx := context.Background()
+
+	_, err := s.GetRecipe(ctx, f.viewer, f.private)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	_, err = s.GetRecipe(ctx, uuid.Nil, f.private)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	for _, id := range []uuid.UUID{f.unlisted, f.public} {
+		recipe, err := s.GetRecipe(ctx, f.viewer, id)
+		require.NoError(t, err)
+		assert.Equal(t, id, recipe.ID)
+	}
+	recipe, err := s.GetRecipe(ctx, f.author, f.private)
+	require.NoError(t, err)
+	assert.Equal(t, f.private, recipe.ID)
+}
+
+func TestOnlyAuthorsUpdateOrDeleteRecipes(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewRecipeService(f.db, nil)
+	ctx := context.Background()
+
+	// Other users can neither change nor read a recipe through an update
+	for _, id := range []uuid.UUID{f.private, f.public} {
+		updated, err := s.UpdateRecipe(ctx, f.viewer, id, &models.Recipe{Visibility: models.RecipePublic, Name: "Mine now"})
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+		assert.Nil(t, updated)
+		assert.True(t, errors.Is(s.DeleteRecipe(ctx, f.viewer, id), gorm.ErrRecordNotFound))
+	}
+	var recipe models.Recipe
+	require.NoError(t, f.db.First(&recipe, "id = ?", f.private).Error)
+	assert.Equal(t, models.RecipePrivate, recipe.Visibility)
+	assert.Equal(t, "Private soup", recipe.Name)
+
+	updated, err := s.UpdateRecipe(ctx, f.author, f.private, &models.Recipe{Visibility: models.RecipeUnlisted})
+	require.NoError(t, err)
+	assert.Equal(t, models.RecipeUnlisted, updated.Visibility)
+	require.NoError(t, s.DeleteRecipe(ctx, f.author, f.public))
+	_, err = s.GetRecipe(ctx, f.author, f.public)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+}
+
+func TestListRecipesOnlyListsPublicRecipes(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewRecipeService(f.db, nil)
+	ctx := context.Background()
+
+	recipes, _, err := s.ListRecipes(ctx, f.viewer, nil, pagination.Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.own, f.public}, recipeIDs(recipes))
+
+	recipes, info, err := s.ListRecipes(ctx, f.viewer, &f.author, pagination.Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.public}, recipeIDs(recipes))
+	assert.Equal(t, int64(1), *info.Total)
+
+	recipes, info, err = s.ListRecipes(ctx, f.author, &f.author, pagination.Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.public, f.unlisted, f.private}, recipeIDs(recipes))
+	assert.Equal(t, int64(3), *info.Total)
+}
+
+func TestSearchRecipesOnlyFindsPublicRecipes(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewRecipeService(f.db, nil)
+
+	recipes, _, err := s.SearchRecipes(context.Background(), f.viewer, "soup", pagination.Page{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{f.own, f.public}, recipeIDs(recipes))
+
+	var found []*models.Recipe
+	require.NoError(t, applyRecipeSearch(f.db.Model(&models.Recipe{}), &RecipeSearch{}, "").Find(&found).Error)
+	assert.Equal(t, []uuid.UUID{f.public}, recipeIDs(found))
+}
+
+func TestSimilarRecipesHidesPrivateSource(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewRecipeService(f.db, nil)
+
+	_, _, err := s.SimilarRecipes(context.Background(), f.private, &RecipeSearch{ViewerID: f.viewer}, false, pagination.Page{})
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	_, _, err = s.SimilarRecipes(context.Background(), f.unlisted, &RecipeSearch{ViewerID: f.viewer}, false, pagination.Page{})
+	assert.NoError(t, err)
+}
+
+func TestFavoritesHideRecipesMadePrivate(t *testing.T) {
+	f := newVisibilityFixture(t)
+	s := NewRecipeService(f.db, nil)
+	for _, id := range []uuid.UUID{f.private, f.public} {
+		require.NoError(t, f.db.Exec("INSERT INTO recipe_favorites (id, user_id, recipe_id, created_at) VALUES (?, ?, ?, ?)",
+			uuid.New(), f.viewer, id, time.Now()).Error)
+	}
+
+	recipes, info, err := s.GetFavoriteRecipes(context.Background(), f.viewer, pagination.Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.public}, recipeIDs(recipes))
+	assert.Equal(t, int64(1), *info.Total)
+}
+
+func TestMealPlansHideRecipesMadePrivate(t *testing.T) {
+	f := newVisibilityFixture(t)
+	require.NoError(t, f.db.Exec("UPDATE recipes SET ingredients = ? WHERE id = ?", `["1 onion"]`, f.public).Error)
+	require.NoError(t, f.db.Exec("UPDATE recipes SET ingredients = ? WHERE id = ?", `["2 carrots"]`, f.private).Error)
+
+	// The viewer planned both recipes while they were public
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	plan := models.MealPlan{ID: uuid.New(), UserID: f.viewer, Name: "Week", StartDate: day, Days: 1}
+	require.NoError(t, f.db.Omit("Entries").Create(&plan).Error)
+	require.NoError(t, f.db.Create([]models.MealPlanEntry{
+		{ID: uuid.New(), MealPlanID: plan.ID, Date: day, Slot: models.MealSlotLunch, RecipeID: f.public, Servings: 1},
+		{ID: uuid.New(), MealPlanID: plan.ID, Date: day, Slot: models.MealSlotDinner, RecipeID: f.private, Servings: 1},
+	}).Error)
+
+	recipeService := NewRecipeService(f.db, nil)
+	mealPlans := NewMealPlanService(f.db, recipeService)
+	got, err := mealPlans.GetMealPlan(context.Background(), f.viewer, plan.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Entries, 2)
+	require.NotNil(t, got.Entries[0].Recipe)
+	assert.Equal(t, f.public, got.Entries[0].Recipe.ID)
+	assert.Nil(t, got.Entries[1].Recipe)
+
+	list, err := NewShoppingListService(f.db, recipeService, mealPlans).
+		CreateShoppingList(context.Background(), f.viewer, &types.CreateShoppingListRequest{MealPlanID: &plan.ID}, units.Metric)
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "onion", list.Items[0].Name)
+}
+
+func TestViewableRecipeHidesPrivateRecipes(t *testing.T) {
+	f := newVisibilityFixture(t)
+
+	_, err := viewableRecipe(f.db, f.private, f.viewer)
+	assert.True(t, errors.Is(err, ErrRecipeNotFound))
+
+	recipe, err := viewableRecipe(f.db, f.private, f.author)
+	require.NoError(t, err)
+	assert.Equal(t, f.author, recipe.UserID)
+}
+
+func TestDefaultRecipeVisibility(t *testing.T) {
+	f := newVisibilityFixture(t)
+	ctx := context.Background()
+
+	// Users without a profile get private recipes
+	assert.Equal(t, models.RecipePrivate, DefaultRecipeVisibility(ctx, f.db, f.author))
+
+	require.NoError(t, f.db.Exec("INSERT INTO user_profiles (id, user_id, privacy_level) VALUES (?, ?, ?), (?, ?, ?)",
+		uuid.New(), f.author, models.RecipePublic, uuid.New(), f.viewer, "friends").Error)
+	assert.Equal(t, models.RecipePublic, DefaultRecipeVisibility(ctx, f.db, f.author))
+	assert.Equal(t, models.RecipePrivate, DefaultRecipeVisibility(ctx, f.db, f.viewer))
+}
+
+func TestUpdateProfileRejectsInvalidPrivacyLevel(t *testing.T) {
+	f := newVisibilityFixture(t)
+	require.NoError(t, f.db.Exec("INSERT INTO user_profiles (id, user_id, privacy_level) VALUES (?, ?, ?)",
+		uuid.New(), f.author, models.RecipePublic).Error)
+
+	level := "friends"
+	_, err := NewProfileService(f.db).UpdateProfile(context.Background(), f.author, &types.UpdateProfileRequest{PrivacyLevel: &level})
+	assert.True(t, errors.Is(err, ErrInvalidPrivacyLevel))
+	assert.Equal(t, models.RecipePublic, DefaultRecipeVisibility(context.Background(), f.db, f.author))
+}
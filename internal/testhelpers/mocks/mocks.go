@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
 	"github.com/pageza/alchemorsel-v2/backend/internal/service"
 	"github.com/pageza/alchemorsel-v2/backend/internal/types"
 	"github.com/stretchr/testify/mock"
@@ -83,6 +84,10 @@ func (m *MockLLMService) GenerateRecipesBatch(prompts []string) ([]string, error
 	return []string{`{"name":"Test Recipe","description":"Desc","category":"Cat","ingredients":["i1"],"instructions":["s1"],"calories":100,"protein":10,"carbs":20,"fat":5}`}, nil
 }
 
+func (m *MockLLMService) SuggestSubstitutions(recipeName string, ingredients []string, constraint string) ([]service.SubstitutionSuggestion, error) {
+	return []service.SubstitutionSuggestion{}, nil
+}
+
 // MockTokenValidator is a mock token validator for testing
 type MockTokenValidator struct {
 	mock.Mock
@@ -135,6 +140,45 @@ func (m *MockAuthService) GenerateToken(claims *types.TokenClaims) (string, erro
 	return args.String(0), args.Error(1)
 }
 
+// GenerateVerificationToken mocks the GenerateVerificationToken method
+func (m *MockAuthService) GenerateVerificationToken(ctx context.Context, userID uuid.UUID) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
+// ValidateVerificationToken mocks the ValidateVerificationToken method
+func (m *MockAuthService) ValidateVerificationToken(ctx context.Context, token string) (*models.User, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+// ResendVerificationEmail mocks the ResendVerificationEmail method
+func (m *MockAuthService) ResendVerificationEmail(ctx context.Context, email string, emailService service.IEmailService) error {
+	args := m.Called(ctx, email, emailService)
+	return args.Error(0)
+}
+
+// GetUserByEmail mocks the GetUserByEmail method
+func (m *MockAuthService) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+// GetUserByID mocks the GetUserByID method
+func (m *MockAuthService) GetUserByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
 // MockProfileService is a mock implementation of the profile service
 type MockProfileService struct {
 	mock.Mock
@@ -165,12 +209,12 @@ func (m *MockProfileService) Logout(ctx context.Context, userID uuid.UUID) error
 }
 
 // GetUserRecipes mocks the GetUserRecipes method
-func (m *MockProfileService) GetUserRecipes(ctx context.Context, userID uuid.UUID) ([]*models.Recipe, error) {
-	args := m.Called(ctx, userID)
+func (m *MockProfileService) GetUserRecipes(ctx context.Context, userID uuid.UUID, page pagination.Page) ([]*models.Recipe, *pagination.Info, error) {
+	args := m.Called(ctx, userID, page)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, nil, args.Error(2)
 	}
-	return args.Get(0).([]*models.Recipe), args.Error(1)
+	return args.Get(0).([]*models.Recipe), args.Get(1).(*pagination.Info), args.Error(2)
 }
 
 // GetProfileHistory mocks the GetProfileHistory method
@@ -183,12 +227,21 @@ func (m *MockProfileService) GetProfileHistory(ctx context.Context, userID uuid.
 }
 
 // GetUserProfile mocks the GetUserProfile method
-func (m *MockProfileService) GetUserProfile(userID string) (*models.UserProfile, error) {
-	args := m.Called(userID)
+func (m *MockProfileService) GetUserProfile(ctx context.Context, username string) (*service.PublicProfile, error) {
+	args := m.Called(ctx, username)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*models.UserProfile), args.Error(1)
+	return args.Get(0).(*service.PublicProfile), args.Error(1)
+}
+
+// GetPublicRecipes mocks the GetPublicRecipes method
+func (m *MockProfileService) GetPublicRecipes(ctx context.Context, username string, page pagination.Page) ([]*models.Recipe, *pagination.Info, error) {
+	args := m.Called(ctx, username, page)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*models.Recipe), args.Get(1).(*pagination.Info), args.Error(2)
 }
 
 // MockRecipeService is a mock implementation of the recipe service
@@ -206,8 +259,8 @@ func (m *MockRecipeService) CreateRecipe(ctx context.Context, recipe *models.Rec
 }
 
 // GetRecipe mocks the GetRecipe method
-func (m *MockRecipeService) GetRecipe(ctx context.Context, id uuid.UUID) (*models.Recipe, error) {
-	args := m.Called(ctx, id)
+func (m *MockRecipeService) GetRecipe(ctx context.Context, viewerID, id uuid.UUID) (*models.Recipe, error) {
+	args := m.Called(ctx, viewerID, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -215,8 +268,8 @@ func (m *MockRecipeService) GetRecipe(ctx context.Context, id uuid.UUID) (*model
 }
 
 // UpdateRecipe mocks the UpdateRecipe method
-func (m *MockRecipeService) UpdateRecipe(ctx context.Context, id uuid.UUID, recipe *models.Recipe) (*models.Recipe, error) {
-	args := m.Called(ctx, id, recipe)
+func (m *MockRecipeService) UpdateRecipe(ctx context.Context, userID, id uuid.UUID, recipe *models.Recipe) (*models.Recipe, error) {
+	args := m.Called(ctx, userID, id, recipe)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -224,7 +277,7 @@ func (m *MockRecipeService) UpdateRecipe(ctx context.Context, id uuid.UUID, reci
 }
 
 // DeleteRecipe mocks the DeleteRecipe method
-func (m *MockRecipeService) DeleteRecipe(ctx context.Context, id uuid.UUID) error {
-	args := m.Called(ctx, id)
+func (m *MockRecipeService) DeleteRecipe(ctx context.Context, userID, id uuid.UUID) error {
+	args := m.Called(ctx, userID, id)
 	return args.Error(0)
 }
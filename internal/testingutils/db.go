@@ -127,6 +127,14 @@ func (s *MockEmbeddingService) GenerateEmbeddingFromRecipe(name, description str
 	return pgvector.NewVector([]float32{0.1, 0.2, 0.3}), nil
 }
 
+func (s *MockEmbeddingService) Model() string {
+	return "mock"
+}
+
+func (s *MockEmbeddingService) Dimensions() int {
+	return 3
+}
+
 // TestDB represents a test database instance
 type TestDB struct {
 	DB             *gorm.DB
@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/pageza/alchemorsel-v2/backend/internal/models"
+	"github.com/pageza/alchemorsel-v2/backend/internal/pagination"
 	"github.com/pageza/alchemorsel-v2/backend/internal/types"
 	"github.com/stretchr/testify/mock"
 )
@@ -67,24 +68,24 @@ func (m *MockRecipeService) CreateRecipe(ctx context.Context, recipe *models.Rec
 	return args.Get(0).(*models.Recipe), args.Error(1)
 }
 
-func (m *MockRecipeService) GetRecipe(ctx context.Context, id uuid.UUID) (*models.Recipe, error) {
-	args := m.Called(ctx, id)
+func (m *MockRecipeService) GetRecipe(ctx context.Context, viewerID, id uuid.UUID) (*models.Recipe, error) {
+	args := m.Called(ctx, viewerID, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Recipe), args.Error(1)
 }
 
-func (m *MockRecipeService) UpdateRecipe(ctx context.Context, id uuid.UUID, recipe *models.Recipe) (*models.Recipe, error) {
-	args := m.Called(ctx, id, recipe)
+func (m *MockRecipeService) UpdateRecipe(ctx context.Context, userID, id uuid.UUID, recipe *models.Recipe) (*models.Recipe, error) {
+	args := m.Called(ctx, userID, id, recipe)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Recipe), args.Error(1)
 }
 
-func (m *MockRecipeService) DeleteRecipe(ctx context.Context, id uuid.UUID) error {
-	args := m.Called(ctx, id)
+func (m *MockRecipeService) DeleteRecipe(ctx context.Context, userID, id uuid.UUID) error {
+	args := m.Called(ctx, userID, id)
 	return args.Error(0)
 }
 
@@ -117,10 +118,10 @@ func (m *MockProfileService) UpdateUserProfile(ctx context.Context, userID uuid.
 	return args.Get(0).(*models.UserProfile), args.Error(1)
 }
 
-func (m *MockProfileService) GetUserRecipes(ctx context.Context, userID uuid.UUID) ([]*models.Recipe, error) {
-	args := m.Called(ctx, userID)
+func (m *MockProfileService) GetUserRecipes(ctx context.Context, userID uuid.UUID, page pagination.Page) ([]*models.Recipe, *pagination.Info, error) {
+	args := m.Called(ctx, userID, page)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, nil, args.Error(2)
 	}
-	return args.Get(0).([]*models.Recipe), args.Error(1)
+	return args.Get(0).([]*models.Recipe), args.Get(1).(*pagination.Info), args.Error(2)
 }
@@ -0,0 +1,27 @@
+package types
+
+import "github.com/google/uuid"
+
+// CreateCollectionRequest represents the request body for creating a
+// collection. Privacy is private, link or public and defaults to private.
+type CreateCollectionRequest struct {
+	Name          string `json:"name" binding:"required,max=100"`
+	Description   string `json:"description" binding:"max=2000"`
+	CoverImageURL string `json:"cover_image_url" binding:"omitempty,url,max=255"`
+	Privacy       string `json:"privacy" binding:"omitempty,oneof=private link public"`
+}
+
+// UpdateCollectionRequest represents the request body for updating a
+// collection. Only the fields present are changed.
+type UpdateCollectionRequest struct {
+	Name          *string `json:"name" binding:"omitempty,max=100"`
+	Description   *string `json:"description" binding:"omitempty,max=2000"`
+	CoverImageURL *string `json:"cover_image_url" binding:"omitempty,max=255"`
+	Privacy       *string `json:"privacy" binding:"omitempty,oneof=private link public"`
+}
+
+// CollectionRecipesRequest lists recipes to add to, remove from or reorder
+// in a collection
+type CollectionRecipesRequest struct {
+	RecipeIDs []uuid.UUID `json:"recipe_ids" binding:"required,min=1,max=500"`
+}
@@ -0,0 +1,15 @@
+package types
+
+import "github.com/google/uuid"
+
+// CreateCommentRequest represents the request body for commenting on a
+// recipe. ParentID makes the comment a reply.
+type CreateCommentRequest struct {
+	Body     string     `json:"body" binding:"required,max=5000"`
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+
+// UpdateCommentRequest represents the request body for editing a comment
+type UpdateCommentRequest struct {
+	Body string `json:"body" binding:"required,max=5000"`
+}
@@ -0,0 +1,52 @@
+package types
+
+import "github.com/google/uuid"
+
+// CreateMealPlanRequest represents the request body for creating a meal plan.
+// Dates use the YYYY-MM-DD format.
+type CreateMealPlanRequest struct {
+	Name          string                 `json:"name" binding:"required,max=100"`
+	StartDate     string                 `json:"start_date" binding:"required"`
+	Days          int                    `json:"days" binding:"omitempty,min=1,max=31"`
+	CalorieTarget float64                `json:"calorie_target" binding:"min=0"`
+	ProteinTarget float64                `json:"protein_target" binding:"min=0"`
+	CarbsTarget   float64                `json:"carbs_target" binding:"min=0"`
+	FatTarget     float64                `json:"fat_target" binding:"min=0"`
+	Entries       []MealPlanEntryRequest `json:"entries" binding:"dive"`
+}
+
+// UpdateMealPlanRequest represents the request body for updating a meal plan.
+// When Entries is present it replaces all of the plan's entries.
+type UpdateMealPlanRequest struct {
+	Name          *string                 `json:"name" binding:"omitempty,max=100"`
+	StartDate     *string                 `json:"start_date"`
+	Days          *int                    `json:"days" binding:"omitempty,min=1,max=31"`
+	CalorieTarget *float64                `json:"calorie_target" binding:"omitempty,min=0"`
+	ProteinTarget *float64                `json:"protein_target" binding:"omitempty,min=0"`
+	CarbsTarget   *float64                `json:"carbs_target" binding:"omitempty,min=0"`
+	FatTarget     *float64                `json:"fat_target" binding:"omitempty,min=0"`
+	Entries       *[]MealPlanEntryRequest `json:"entries" binding:"omitempty,dive"`
+}
+
+// MealPlanEntryRequest places a recipe in a meal plan slot
+type MealPlanEntryRequest struct {
+	Date     string    `json:"date" binding:"required"`
+	Slot     string    `json:"slot" binding:"required,oneof=breakfast lunch dinner snack"`
+	RecipeID uuid.UUID `json:"recipe_id" binding:"required"`
+	Servings int       `json:"servings" binding:"omitempty,min=1"`
+}
+
+// AutoPlanRequest represents the request body for filling a meal plan
+// automatically. Targets override the plan's own targets when set, and the
+// extra preferences and allergens add to those saved on the user's profile.
+type AutoPlanRequest struct {
+	Slots              []string `json:"slots" binding:"omitempty,dive,oneof=breakfast lunch dinner snack"`
+	Query              string   `json:"query"`
+	CalorieTarget      *float64 `json:"calorie_target" binding:"omitempty,min=0"`
+	ProteinTarget      *float64 `json:"protein_target" binding:"omitempty,min=0"`
+	CarbsTarget        *float64 `json:"carbs_target" binding:"omitempty,min=0"`
+	FatTarget          *float64 `json:"fat_target" binding:"omitempty,min=0"`
+	DietaryPreferences []string `json:"dietary_preferences"`
+	Allergens          []string `json:"allergens"`
+	Overwrite          bool     `json:"overwrite"`
+}
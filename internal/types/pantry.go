@@ -0,0 +1,19 @@
+package types
+
+// CreatePantryItemRequest represents the request body for adding an ingredient
+// to the pantry. ExpiresAt uses the YYYY-MM-DD format.
+type CreatePantryItemRequest struct {
+	Name      string  `json:"name" binding:"required,max=200"`
+	Quantity  float64 `json:"quantity" binding:"min=0"`
+	Unit      string  `json:"unit" binding:"max=20"`
+	ExpiresAt *string `json:"expires_at"`
+}
+
+// UpdatePantryItemRequest represents the request body for updating a pantry
+// item. An empty ExpiresAt clears the expiry date.
+type UpdatePantryItemRequest struct {
+	Name      *string  `json:"name" binding:"omitempty,max=200"`
+	Quantity  *float64 `json:"quantity" binding:"omitempty,min=0"`
+	Unit      *string  `json:"unit" binding:"omitempty,max=20"`
+	ExpiresAt *string  `json:"expires_at"`
+}
@@ -14,6 +14,7 @@ type UserProfile struct {
 	Bio               string    `json:"bio"`
 	ProfilePictureURL string    `json:"profile_picture_url"`
 	PrivacyLevel      string    `json:"privacy_level"`
+	UnitSystem        string    `json:"unit_system"`
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
 }
@@ -25,12 +26,14 @@ type UserPreferences struct {
 	FavoriteCuisine string   `json:"favorite_cuisine"`
 }
 
-// UpdateProfileRequest represents a request to update a user's profile
+// UpdateProfileRequest represents a request to update a user's profile.
+// PrivacyLevel is the visibility given to the user's new recipes.
 type UpdateProfileRequest struct {
 	Username          string           `json:"username,omitempty"`
 	Bio               *string          `json:"bio,omitempty"`
 	ProfilePictureURL *string          `json:"profile_picture_url,omitempty"`
-	PrivacyLevel      *string          `json:"privacy_level,omitempty"`
+	PrivacyLevel      *string          `json:"privacy_level,omitempty" binding:"omitempty,oneof=private unlisted public"`
+	UnitSystem        *string          `json:"unit_system,omitempty"`
 	AvatarURL         string           `json:"avatar_url,omitempty"`
 	Preferences       *UserPreferences `json:"preferences,omitempty"`
 }
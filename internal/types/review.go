@@ -0,0 +1,19 @@
+package types
+
+// CreateReviewRequest represents the request body for rating and reviewing a
+// recipe. Body and Modifications are optional.
+type CreateReviewRequest struct {
+	Rating        int    `json:"rating" binding:"required,min=1,max=5"`
+	Body          string `json:"body" binding:"max=5000"`
+	MadeIt        bool   `json:"made_it"`
+	Modifications string `json:"modifications" binding:"max=2000"`
+}
+
+// UpdateReviewRequest represents the request body for editing a review. Only
+// the fields present are changed.
+type UpdateReviewRequest struct {
+	Rating        *int    `json:"rating" binding:"omitempty,min=1,max=5"`
+	Body          *string `json:"body" binding:"omitempty,max=5000"`
+	MadeIt        *bool   `json:"made_it"`
+	Modifications *string `json:"modifications" binding:"omitempty,max=2000"`
+}
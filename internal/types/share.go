@@ -0,0 +1,7 @@
+package types
+
+// CreateRecipeShareRequest represents the request body for sharing a recipe
+// by link. ExpiresInHours defaults to a week and is at most 90 days.
+type CreateRecipeShareRequest struct {
+	ExpiresInHours int `json:"expires_in_hours" binding:"omitempty,min=1,max=2160"`
+}
@@ -0,0 +1,34 @@
+package types
+
+import "github.com/google/uuid"
+
+// CreateShoppingListRequest represents the request body for generating a
+// shopping list from recipes, a meal plan or both. Pantry lists ingredients
+// already on hand, such as "500 ml milk"; they are subtracted from the list,
+// as is the user's saved pantry when UsePantry is set.
+type CreateShoppingListRequest struct {
+	Name       string                      `json:"name" binding:"omitempty,max=100"`
+	Recipes    []ShoppingListRecipeRequest `json:"recipes" binding:"dive"`
+	MealPlanID *uuid.UUID                  `json:"meal_plan_id"`
+	Pantry     []string                    `json:"pantry"`
+	UsePantry  bool                        `json:"use_pantry"`
+}
+
+// ShoppingListRecipeRequest adds a recipe to a shopping list. Servings scales
+// the recipe's ingredients; zero keeps the recipe's own yield.
+type ShoppingListRecipeRequest struct {
+	RecipeID uuid.UUID `json:"recipe_id" binding:"required"`
+	Servings float64   `json:"servings" binding:"min=0"`
+}
+
+// UpdateShoppingListItemRequest represents the request body for checking off
+// a shopping list item
+type UpdateShoppingListItemRequest struct {
+	Checked bool `json:"checked"`
+}
+
+// SubtractPantryRequest represents the request body for removing ingredients
+// already on hand from an existing shopping list
+type SubtractPantryRequest struct {
+	Pantry []string `json:"pantry" binding:"required,min=1"`
+}
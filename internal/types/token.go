@@ -11,6 +11,7 @@ type TokenClaims struct {
 	UserID          uuid.UUID `json:"user_id"`
 	Username        string    `json:"username"`
 	IsEmailVerified bool      `json:"is_email_verified"`
+	Role            string    `json:"role,omitempty"`
 }
 
 // GetAudience implements jwt.Claims
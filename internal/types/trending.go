@@ -0,0 +1,8 @@
+package types
+
+// SetCurationRequest represents the request body for pinning or excluding a
+// featured recipe. Position orders pinned recipes, lowest first.
+type SetCurationRequest struct {
+	Action   string `json:"action" binding:"required,oneof=pin exclude"`
+	Position int    `json:"position" binding:"min=0"`
+}
@@ -0,0 +1,80 @@
+package units
+
+import "strings"
+
+// Density describes how much an ingredient weighs per millilitre. Weighed
+// marks ingredients that metric kitchens measure by mass rather than volume.
+type Density struct {
+	GramsPerML float64
+	Weighed    bool
+}
+
+// densities is keyed by ingredient keyword. Values are derived from common
+// grams-per-cup figures (1 cup = 236.588 ml).
+var densities = map[string]Density{
+	"all-purpose flour": {GramsPerML: 0.528, Weighed: true},
+	"bread flour":       {GramsPerML: 0.537, Weighed: true},
+	"whole wheat flour": {GramsPerML: 0.507, Weighed: true},
+	"almond flour":      {GramsPerML: 0.406, Weighed: true},
+	"flour":             {GramsPerML: 0.528, Weighed: true},
+	"granulated sugar":  {GramsPerML: 0.845, Weighed: true},
+	"brown sugar":       {GramsPerML: 0.900, Weighed: true},
+	"powdered sugar":    {GramsPerML: 0.507, Weighed: true},
+	"icing sugar":       {GramsPerML: 0.507, Weighed: true},
+	"sugar":             {GramsPerML: 0.845, Weighed: true},
+	"butter":            {GramsPerML: 0.959, Weighed: true},
+	"cocoa powder":      {GramsPerML: 0.359, Weighed: true},
+	"cornstarch":        {GramsPerML: 0.541, Weighed: true},
+	"rolled oats":       {GramsPerML: 0.380, Weighed: true},
+	"oats":              {GramsPerML: 0.380, Weighed: true},
+	"rice":              {GramsPerML: 0.782, Weighed: true},
+	"quinoa":            {GramsPerML: 0.719, Weighed: true},
+	"lentils":           {GramsPerML: 0.812, Weighed: true},
+	"breadcrumbs":       {GramsPerML: 0.456, Weighed: true},
+	"chocolate chips":   {GramsPerML: 0.719, Weighed: true},
+	"parmesan":          {GramsPerML: 0.423, Weighed: true},
+	"cream cheese":      {GramsPerML: 0.980, Weighed: true},
+	"cheese":            {GramsPerML: 0.478, Weighed: true},
+	"almonds":           {GramsPerML: 0.604, Weighed: true},
+	"walnuts":           {GramsPerML: 0.507, Weighed: true},
+	"nuts":              {GramsPerML: 0.570, Weighed: true},
+	"peanut butter":     {GramsPerML: 1.090, Weighed: true},
+	"honey":             {GramsPerML: 1.420, Weighed: true},
+	"yogurt":            {GramsPerML: 1.036, Weighed: true},
+	"sour cream":        {GramsPerML: 0.972, Weighed: true},
+	"salt":              {GramsPerML: 1.217, Weighed: true},
+	"maple syrup":       {GramsPerML: 1.320},
+	"heavy cream":       {GramsPerML: 0.994},
+	"cream":             {GramsPerML: 1.000},
+	"milk":              {GramsPerML: 1.030},
+	"buttermilk":        {GramsPerML: 1.030},
+	"olive oil":         {GramsPerML: 0.911},
+	"vegetable oil":     {GramsPerML: 0.920},
+	"oil":               {GramsPerML: 0.920},
+	"broth":             {GramsPerML: 1.000},
+	"stock":             {GramsPerML: 1.000},
+	"water":             {GramsPerML: 1.000},
+	"vinegar":           {GramsPerML: 1.010},
+	"soy sauce":         {GramsPerML: 1.150},
+	"coconut milk":      {GramsPerML: 0.970},
+	"tomato sauce":      {GramsPerML: 1.030},
+	"lemon juice":       {GramsPerML: 1.030},
+	"orange juice":      {GramsPerML: 1.040},
+	"juice":             {GramsPerML: 1.040},
+}
+
+// LookupDensity finds the density for an ingredient description by matching
+// the longest known keyword it contains.
+func LookupDensity(ingredient string) (Density, bool) {
+	name := strings.ToLower(ingredient)
+	best := ""
+	for keyword := range densities {
+		if len(keyword) > len(best) && strings.Contains(name, keyword) {
+			best = keyword
+		}
+	}
+	if best == "" {
+		return Density{}, false
+	}
+	return densities[best], true
+}
@@ -0,0 +1,138 @@
+package units
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Ingredient is a structured view of a free-text ingredient line such as
+// "1 1/2 cups all-purpose flour"
+type Ingredient struct {
+	Raw         string  `json:"raw"`
+	Quantity    float64 `json:"quantity"`
+	MaxQuantity float64 `json:"max_quantity,omitempty"`
+	HasQuantity bool    `json:"has_quantity"`
+	Unit        Unit    `json:"-"`
+	HasUnit     bool    `json:"has_unit"`
+	Name        string  `json:"name"`
+}
+
+// UnitName returns the unit name, or an empty string when the line has no unit
+func (i Ingredient) UnitName() string {
+	if !i.HasUnit {
+		return ""
+	}
+	return i.Unit.Name
+}
+
+var unicodeFractions = map[string]string{
+	"½": "1/2", "⅓": "1/3", "⅔": "2/3", "¼": "1/4", "¾": "3/4",
+	"⅕": "1/5", "⅖": "2/5", "⅗": "3/5", "⅘": "4/5", "⅙": "1/6",
+	"⅚": "5/6", "⅛": "1/8", "⅜": "3/8", "⅝": "5/8", "⅞": "7/8",
+}
+
+var (
+	gluedUnitPattern = regexp.MustCompile(`^(\d+(?:[.,]\d+)?)([a-zA-Z]+\.?)$`)
+	rangePattern     = regexp.MustCompile(`^(\d+(?:[.,]\d+)?)[-–](\d+(?:[.,]\d+)?)$`)
+)
+
+// ParseIngredient parses an ingredient line into quantity, unit and name.
+// Lines that do not start with a quantity are returned with only Name set.
+func ParseIngredient(line string) Ingredient {
+	ing := Ingredient{Raw: line}
+	tokens := tokenize(line)
+
+	i := 0
+	if i < len(tokens) {
+		if lo, hi, ok := parseRange(tokens[i]); ok {
+			ing.Quantity, ing.MaxQuantity, ing.HasQuantity = lo, hi, true
+			i++
+		} else if q, ok := parseNumber(tokens[i]); ok {
+			ing.Quantity, ing.HasQuantity = q, true
+			i++
+			// Mixed numbers such as "1 1/2"
+			if i < len(tokens) && strings.Contains(tokens[i], "/") {
+				if f, ok := parseNumber(tokens[i]); ok && f < 1 {
+					ing.Quantity += f
+					i++
+				}
+			}
+		}
+	}
+
+	if ing.HasQuantity && i < len(tokens) {
+		if i+1 < len(tokens) {
+			if u, ok := LookupUnit(tokens[i] + " " + trimPunct(tokens[i+1])); ok {
+				ing.Unit, ing.HasUnit = u, true
+				i += 2
+			}
+		}
+		if !ing.HasUnit {
+			if u, ok := LookupUnit(trimPunct(tokens[i])); ok {
+				ing.Unit, ing.HasUnit = u, true
+				i++
+			}
+		}
+	}
+
+	name := strings.Join(tokens[i:], " ")
+	name = strings.TrimPrefix(name, "of ")
+	ing.Name = strings.TrimSpace(name)
+	return ing
+}
+
+// tokenize normalizes unicode fractions and splits glued quantities such as
+// "250g" into separate tokens
+func tokenize(line string) []string {
+	s := strings.ReplaceAll(line, "⁄", "/")
+	for uf, ascii := range unicodeFractions {
+		s = strings.ReplaceAll(s, uf, " "+ascii)
+	}
+
+	var tokens []string
+	for _, field := range strings.Fields(s) {
+		if m := gluedUnitPattern.FindStringSubmatch(field); m != nil {
+			if _, ok := LookupUnit(trimPunct(m[2])); ok {
+				tokens = append(tokens, m[1], m[2])
+				continue
+			}
+		}
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
+
+func trimPunct(s string) string {
+	return strings.TrimRight(s, ".,;:")
+}
+
+func parseNumber(s string) (float64, bool) {
+	s = strings.Replace(s, ",", ".", 1)
+	if num, den, ok := strings.Cut(s, "/"); ok {
+		n, err1 := strconv.ParseFloat(num, 64)
+		d, err2 := strconv.ParseFloat(den, 64)
+		if err1 != nil || err2 != nil || d == 0 {
+			return 0, false
+		}
+		return n / d, true
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+func parseRange(s string) (float64, float64, bool) {
+	m := rangePattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, false
+	}
+	lo, ok1 := parseNumber(m[1])
+	hi, ok2 := parseNumber(m[2])
+	if !ok1 || !ok2 {
+		return 0, 0, false
+	}
+	return lo, hi, true
+}
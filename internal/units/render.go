@@ -0,0 +1,241 @@
+package units
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	temperaturePattern    = regexp.MustCompile(`(?i)(\d{2,3}(?:\.\d+)?)\s*(°|º|degrees?\s*)?\s*(fahrenheit|celsius|f|c)\b`)
+	inlineQuantityPattern = regexp.MustCompile(`(?i)\b(\d+(?:[.,]\d+)?(?:\s+\d+/\d+)?|\d+/\d+)\s*(cups?|ml|milliliters?|millilitres?|liters?|litres?|g|grams?|kg|kilograms?|oz|ounces?|lbs?|pounds?)\b`)
+)
+
+// LocalizeIngredient renders an ingredient line in the given unit system.
+// Lines without a recognised quantity and unit are returned unchanged, as are
+// teaspoon and tablespoon measures which both systems share.
+func LocalizeIngredient(line string, system System) string {
+	ing := ParseIngredient(line)
+	if !ing.HasQuantity || !ing.HasUnit || ing.Unit.System == "" || ing.Unit.System == system {
+		return line
+	}
+
+	qty, unit, ok := ConvertForSystem(ing.Quantity, ing.Unit, ing.Name, system)
+	if !ok {
+		return line
+	}
+	quantity := FormatQuantity(qty, unit)
+	label := unit.Label(qty)
+	if ing.MaxQuantity > 0 {
+		if maxQty, maxUnit, ok := ConvertForSystem(ing.MaxQuantity, ing.Unit, ing.Name, system); ok && maxUnit == unit {
+			quantity += "-" + FormatQuantity(maxQty, unit)
+			label = unit.Label(maxQty)
+		}
+	}
+
+	if ing.Name == "" {
+		return quantity + " " + label
+	}
+	return quantity + " " + label + " " + ing.Name
+}
+
+// LocalizeText rewrites temperatures and inline quantities in free text, such
+// as recipe instructions, into the given unit system
+func LocalizeText(text string, system System) string {
+	text = temperaturePattern.ReplaceAllStringFunc(text, func(match string) string {
+		m := temperaturePattern.FindStringSubmatch(match)
+		scale := strings.ToLower(m[3])
+		if m[2] == "" && len(scale) == 1 {
+			// A bare "F" or "C" is too ambiguous ("2 c" is usually cups)
+			return match
+		}
+		value, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return match
+		}
+		from := Celsius
+		if strings.HasPrefix(scale, "f") {
+			from = Fahrenheit
+		}
+		to := Fahrenheit
+		if system == Metric {
+			to = Celsius
+		}
+		if from == to {
+			return match
+		}
+		return FormatTemperature(ConvertTemperature(value, from, to), to)
+	})
+
+	return inlineQuantityPattern.ReplaceAllStringFunc(text, func(match string) string {
+		m := inlineQuantityPattern.FindStringSubmatch(match)
+		ing := ParseIngredient(m[1] + " " + m[2])
+		if !ing.HasQuantity || !ing.HasUnit || ing.Unit.System == system {
+			return match
+		}
+		qty, unit, ok := ConvertForSystem(ing.Quantity, ing.Unit, "", system)
+		if !ok {
+			return match
+		}
+		return FormatQuantity(qty, unit) + " " + unit.Label(qty)
+	})
+}
+
+// LocalizeIngredients applies LocalizeIngredient to every line
+func LocalizeIngredients(lines []string, system System) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = LocalizeIngredient(line, system)
+	}
+	return out
+}
+
+// LocalizeInstructions applies LocalizeText to every instruction step
+func LocalizeInstructions(steps []string, system System) []string {
+	out := make([]string, len(steps))
+	for i, step := range steps {
+		out[i] = LocalizeText(step, system)
+	}
+	return out
+}
+
+// ConvertForSystem picks a target unit in the given system for a quantity,
+// using ingredient densities to move between volume and mass where the
+// target system customarily measures the ingredient the other way
+func ConvertForSystem(qty float64, from Unit, ingredient string, system System) (float64, Unit, bool) {
+	density, hasDensity := LookupDensity(ingredient)
+	if ingredient == "" {
+		hasDensity = false
+	}
+
+	switch system {
+	case Metric:
+		switch from.Dimension {
+		case Volume:
+			if hasDensity && density.Weighed {
+				return pickMetricMass(qty * from.ToBase * density.GramsPerML)
+			}
+			return pickMetricVolume(qty * from.ToBase)
+		case Mass:
+			return pickMetricMass(qty * from.ToBase)
+		}
+	case Imperial:
+		switch from.Dimension {
+		case Mass:
+			if hasDensity && density.Weighed {
+				return pickImperialVolume(qty * from.ToBase / density.GramsPerML)
+			}
+			return pickImperialMass(qty * from.ToBase)
+		case Volume:
+			return pickImperialVolume(qty * from.ToBase)
+		}
+	}
+	return 0, Unit{}, false
+}
+
+func pickMetricMass(grams float64) (float64, Unit, bool) {
+	if grams >= 1000 {
+		return grams / Kilogram.ToBase, Kilogram, true
+	}
+	return grams, Gram, true
+}
+
+func pickMetricVolume(ml float64) (float64, Unit, bool) {
+	if ml >= 1000 {
+		return ml / Liter.ToBase, Liter, true
+	}
+	return ml, Milliliter, true
+}
+
+func pickImperialMass(grams float64) (float64, Unit, bool) {
+	oz := grams / Ounce.ToBase
+	if oz >= 16 {
+		return grams / Pound.ToBase, Pound, true
+	}
+	return oz, Ounce, true
+}
+
+func pickImperialVolume(ml float64) (float64, Unit, bool) {
+	switch {
+	case ml < Tablespoon.ToBase:
+		return ml / Teaspoon.ToBase, Teaspoon, true
+	case ml < Cup.ToBase/4:
+		return ml / Tablespoon.ToBase, Tablespoon, true
+	default:
+		return ml / Cup.ToBase, Cup, true
+	}
+}
+
+// FormatQuantity renders a quantity with precision suited to the unit:
+// kitchen fractions for spoons and cups, rounded whole numbers for grams and
+// millilitres, and up to two decimals otherwise
+func FormatQuantity(qty float64, unit Unit) string {
+	switch unit {
+	case Cup, Teaspoon, Tablespoon:
+		return formatFraction(qty)
+	case Gram, Milliliter:
+		switch {
+		case qty < 20:
+			return strconv.FormatFloat(math.Round(qty), 'f', -1, 64)
+		case qty < 100:
+			return strconv.FormatFloat(math.Round(qty/5)*5, 'f', -1, 64)
+		default:
+			return strconv.FormatFloat(math.Round(qty/10)*10, 'f', -1, 64)
+		}
+	case Ounce:
+		return strconv.FormatFloat(math.Round(qty*2)/2, 'f', -1, 64)
+	default:
+		return strconv.FormatFloat(math.Round(qty*100)/100, 'f', -1, 64)
+	}
+}
+
+// FormatTemperature renders a temperature, rounding oven temperatures to the
+// steps found on oven dials
+func FormatTemperature(value float64, unit Unit) string {
+	switch {
+	case unit == Celsius && value >= 120:
+		value = math.Round(value/10) * 10
+	case unit == Fahrenheit && value >= 250:
+		value = math.Round(value/25) * 25
+	default:
+		value = math.Round(value)
+	}
+	return fmt.Sprintf("%d%s", int(value), unit.Symbol)
+}
+
+var kitchenFractions = []struct {
+	value float64
+	text  string
+}{
+	{0, ""}, {1.0 / 8, "1/8"}, {1.0 / 4, "1/4"}, {1.0 / 3, "1/3"}, {1.0 / 2, "1/2"},
+	{2.0 / 3, "2/3"}, {3.0 / 4, "3/4"}, {1, ""},
+}
+
+func formatFraction(qty float64) string {
+	whole := math.Floor(qty)
+	rem := qty - whole
+
+	best := 0
+	for i, f := range kitchenFractions {
+		if math.Abs(rem-f.value) < math.Abs(rem-kitchenFractions[best].value) {
+			best = i
+		}
+	}
+	if kitchenFractions[best].value == 1 {
+		whole++
+	}
+	frac := kitchenFractions[best].text
+
+	switch {
+	case whole == 0 && frac == "":
+		return "1/8"
+	case whole == 0:
+		return frac
+	case frac == "":
+		return strconv.Itoa(int(whole))
+	default:
+		return fmt.Sprintf("%d %s", int(whole), frac)
+	}
+}
@@ -0,0 +1,168 @@
+package units
+
+import (
+	"fmt"
+	"strings"
+)
+
+// System represents a measurement system used when rendering quantities
+type System string
+
+const (
+	Metric   System = "metric"
+	Imperial System = "imperial"
+)
+
+// DefaultSystem is used when a user has not chosen a unit system
+const DefaultSystem = Imperial
+
+// ParseSystem converts a string into a System, reporting whether it is valid
+func ParseSystem(s string) (System, bool) {
+	switch System(strings.ToLower(strings.TrimSpace(s))) {
+	case Metric:
+		return Metric, true
+	case Imperial:
+		return Imperial, true
+	default:
+		return "", false
+	}
+}
+
+// Dimension groups units that can be converted into each other
+type Dimension string
+
+const (
+	Volume      Dimension = "volume"
+	Mass        Dimension = "mass"
+	Temperature Dimension = "temperature"
+)
+
+// Unit describes a unit of measure relative to the base unit of its dimension.
+// Volume is based on millilitres and mass on grams.
+type Unit struct {
+	Name      string
+	Symbol    string
+	Plural    string
+	Dimension Dimension
+	System    System
+	ToBase    float64
+}
+
+// Label returns the display label for the unit given a quantity
+func (u Unit) Label(quantity float64) string {
+	if u.Symbol != "" {
+		return u.Symbol
+	}
+	if quantity > 1 && u.Plural != "" {
+		return u.Plural
+	}
+	return u.Name
+}
+
+// Known units. Teaspoons and tablespoons are shared by both systems and are
+// therefore never converted when rendering.
+var (
+	Milliliter = Unit{Name: "ml", Symbol: "ml", Dimension: Volume, System: Metric, ToBase: 1}
+	Liter      = Unit{Name: "l", Symbol: "l", Dimension: Volume, System: Metric, ToBase: 1000}
+	Teaspoon   = Unit{Name: "tsp", Symbol: "tsp", Dimension: Volume, ToBase: 4.92892}
+	Tablespoon = Unit{Name: "tbsp", Symbol: "tbsp", Dimension: Volume, ToBase: 14.7868}
+	FluidOunce = Unit{Name: "fl oz", Symbol: "fl oz", Dimension: Volume, System: Imperial, ToBase: 29.5735}
+	Cup        = Unit{Name: "cup", Plural: "cups", Dimension: Volume, System: Imperial, ToBase: 236.588}
+	Pint       = Unit{Name: "pint", Plural: "pints", Dimension: Volume, System: Imperial, ToBase: 473.176}
+	Quart      = Unit{Name: "quart", Plural: "quarts", Dimension: Volume, System: Imperial, ToBase: 946.353}
+	Gallon     = Unit{Name: "gallon", Plural: "gallons", Dimension: Volume, System: Imperial, ToBase: 3785.41}
+
+	Milligram = Unit{Name: "mg", Symbol: "mg", Dimension: Mass, System: Metric, ToBase: 0.001}
+	Gram      = Unit{Name: "g", Symbol: "g", Dimension: Mass, System: Metric, ToBase: 1}
+	Kilogram  = Unit{Name: "kg", Symbol: "kg", Dimension: Mass, System: Metric, ToBase: 1000}
+	Ounce     = Unit{Name: "oz", Symbol: "oz", Dimension: Mass, System: Imperial, ToBase: 28.3495}
+	Pound     = Unit{Name: "lb", Symbol: "lb", Dimension: Mass, System: Imperial, ToBase: 453.592}
+
+	Celsius    = Unit{Name: "°C", Symbol: "°C", Dimension: Temperature, System: Metric}
+	Fahrenheit = Unit{Name: "°F", Symbol: "°F", Dimension: Temperature, System: Imperial}
+)
+
+// unitAliases maps lower-cased spellings to units
+var unitAliases = map[string]Unit{
+	"ml": Milliliter, "milliliter": Milliliter, "milliliters": Milliliter, "millilitre": Milliliter, "millilitres": Milliliter,
+	"l": Liter, "liter": Liter, "liters": Liter, "litre": Liter, "litres": Liter,
+	"tsp": Teaspoon, "tsps": Teaspoon, "teaspoon": Teaspoon, "teaspoons": Teaspoon,
+	"tbsp": Tablespoon, "tbsps": Tablespoon, "tbs": Tablespoon, "tablespoon": Tablespoon, "tablespoons": Tablespoon,
+	"fl oz": FluidOunce, "fl. oz": FluidOunce, "fluid ounce": FluidOunce, "fluid ounces": FluidOunce,
+	"cup": Cup, "cups": Cup, "c": Cup,
+	"pint": Pint, "pints": Pint, "pt": Pint,
+	"quart": Quart, "quarts": Quart, "qt": Quart,
+	"gallon": Gallon, "gallons": Gallon, "gal": Gallon,
+	"mg": Milligram, "milligram": Milligram, "milligrams": Milligram,
+	"g": Gram, "gr": Gram, "gram": Gram, "grams": Gram, "gramme": Gram, "grammes": Gram,
+	"kg": Kilogram, "kilogram": Kilogram, "kilograms": Kilogram,
+	"oz": Ounce, "ounce": Ounce, "ounces": Ounce,
+	"lb": Pound, "lbs": Pound, "pound": Pound, "pounds": Pound,
+}
+
+// LookupUnit finds a unit by one of its spellings. The single-letter
+// abbreviations "T" and "t" are case-sensitive (tablespoon and teaspoon).
+func LookupUnit(s string) (Unit, bool) {
+	switch s {
+	case "T":
+		return Tablespoon, true
+	case "t":
+		return Teaspoon, true
+	}
+	u, ok := unitAliases[strings.TrimSuffix(strings.ToLower(s), ".")]
+	return u, ok
+}
+
+// Convert converts a quantity between two units of the same dimension
+func Convert(quantity float64, from, to Unit) (float64, error) {
+	if from.Dimension != to.Dimension {
+		return 0, fmt.Errorf("cannot convert %s to %s", from.Dimension, to.Dimension)
+	}
+	if from.Dimension == Temperature {
+		return ConvertTemperature(quantity, from, to), nil
+	}
+	return quantity * from.ToBase / to.ToBase, nil
+}
+
+// ConvertTemperature converts between Celsius and Fahrenheit
+func ConvertTemperature(value float64, from, to Unit) float64 {
+	if from == to {
+		return value
+	}
+	if from == Fahrenheit {
+		return (value - 32) * 5 / 9
+	}
+	return value*9/5 + 32
+}
+
+// GramsFor converts a volume quantity of an ingredient into grams using the
+// density table. It reports false when the ingredient density is unknown.
+func GramsFor(quantity float64, unit Unit, ingredient string) (float64, bool) {
+	if unit.Dimension == Mass {
+		return quantity * unit.ToBase, true
+	}
+	if unit.Dimension != Volume {
+		return 0, false
+	}
+	d, ok := LookupDensity(ingredient)
+	if !ok {
+		return 0, false
+	}
+	return quantity * unit.ToBase * d.GramsPerML, true
+}
+
+// MillilitersFor converts a mass quantity of an ingredient into millilitres
+// using the density table. It reports false when the density is unknown.
+func MillilitersFor(quantity float64, unit Unit, ingredient string) (float64, bool) {
+	if unit.Dimension == Volume {
+		return quantity * unit.ToBase, true
+	}
+	if unit.Dimension != Mass {
+		return 0, false
+	}
+	d, ok := LookupDensity(ingredient)
+	if !ok {
+		return 0, false
+	}
+	return quantity * unit.ToBase / d.GramsPerML, true
+}
@@ -0,0 +1,89 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIngredient(t *testing.T) {
+	tests := []struct {
+		line     string
+		quantity float64
+		unit     string
+		name     string
+	}{
+		{"2 cups flour", 2, "cup", "flour"},
+		{"1 1/2 cups whole milk", 1.5, "cup", "whole milk"},
+		{"1½ tbsp olive oil", 1.5, "tbsp", "olive oil"},
+		{"250g butter", 250, "g", "butter"},
+		{"8 fl oz water", 8, "fl oz", "water"},
+		{"1 T sugar", 1, "tbsp", "sugar"},
+		{"3 eggs", 3, "", "eggs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			ing := ParseIngredient(tt.line)
+			assert.True(t, ing.HasQuantity)
+			assert.InDelta(t, tt.quantity, ing.Quantity, 0.001)
+			assert.Equal(t, tt.unit, ing.UnitName())
+			assert.Equal(t, tt.name, ing.Name)
+		})
+	}
+
+	ing := ParseIngredient("Salt to taste")
+	assert.False(t, ing.HasQuantity)
+	assert.Equal(t, "Salt to taste", ing.Name)
+
+	ing = ParseIngredient("2-3 cloves garlic")
+	assert.Equal(t, 2.0, ing.Quantity)
+	assert.Equal(t, 3.0, ing.MaxQuantity)
+}
+
+func TestConvert(t *testing.T) {
+	ml, err := Convert(1, Cup, Milliliter)
+	assert.NoError(t, err)
+	assert.InDelta(t, 236.588, ml, 0.001)
+
+	_, err = Convert(1, Cup, Gram)
+	assert.Error(t, err)
+
+	c, err := Convert(350, Fahrenheit, Celsius)
+	assert.NoError(t, err)
+	assert.InDelta(t, 176.67, c, 0.01)
+
+	grams, ok := GramsFor(1, Cup, "all-purpose flour")
+	assert.True(t, ok)
+	assert.InDelta(t, 125, grams, 1)
+
+	_, ok = GramsFor(1, Cup, "mystery powder")
+	assert.False(t, ok)
+}
+
+func TestLocalizeIngredient(t *testing.T) {
+	assert.Equal(t, "250 g all-purpose flour", LocalizeIngredient("2 cups all-purpose flour", Metric))
+	assert.Equal(t, "240 ml milk", LocalizeIngredient("1 cup milk", Metric))
+	assert.Equal(t, "2 cups flour", LocalizeIngredient("250 g flour", Imperial))
+	assert.Equal(t, "1.1 lb chicken thighs", LocalizeIngredient("500 g chicken thighs", Imperial))
+	assert.Equal(t, "1 tbsp olive oil", LocalizeIngredient("1 tbsp olive oil", Metric))
+	assert.Equal(t, "2 cups flour", LocalizeIngredient("2 cups flour", Imperial))
+	assert.Equal(t, "3 eggs", LocalizeIngredient("3 eggs", Metric))
+}
+
+func TestLocalizeText(t *testing.T) {
+	assert.Equal(t, "Step 3: Bake at 180°C for 30 minutes", LocalizeText("Step 3: Bake at 350°F for 30 minutes", Metric))
+	assert.Equal(t, "Preheat the oven to 400°F", LocalizeText("Preheat the oven to 200 degrees Celsius", Imperial))
+	assert.Equal(t, "Bake at 350°F", LocalizeText("Bake at 350°F", Imperial))
+	assert.Equal(t, "Pour in 470 ml of stock", LocalizeText("Pour in 2 cups of stock", Metric))
+	assert.Equal(t, "Add 2 c flour", LocalizeText("Add 2 c flour", Metric))
+}
+
+func TestParseSystem(t *testing.T) {
+	s, ok := ParseSystem("Metric")
+	assert.True(t, ok)
+	assert.Equal(t, Metric, s)
+
+	_, ok = ParseSystem("nautical")
+	assert.False(t, ok)
+}
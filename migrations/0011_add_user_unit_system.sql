@@ -0,0 +1,12 @@
+-- Add the preferred unit system (metric or imperial) to user profiles
+ALTER TABLE user_profiles ADD COLUMN IF NOT EXISTS unit_system VARCHAR(20) NOT NULL DEFAULT 'imperial';
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint WHERE conname = 'user_profiles_unit_system_check'
+    ) THEN
+        ALTER TABLE user_profiles
+            ADD CONSTRAINT user_profiles_unit_system_check CHECK (unit_system IN ('metric', 'imperial'));
+    END IF;
+END$$;
@@ -0,0 +1,25 @@
+-- Create the local nutrient database used for deterministic nutrition calculation
+CREATE TABLE IF NOT EXISTS foods (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    aliases JSONB NOT NULL DEFAULT '[]',
+    source VARCHAR(50),
+    source_id VARCHAR(50),
+    calories DOUBLE PRECISION NOT NULL DEFAULT 0,
+    protein DOUBLE PRECISION NOT NULL DEFAULT 0,
+    carbs DOUBLE PRECISION NOT NULL DEFAULT 0,
+    fat DOUBLE PRECISION NOT NULL DEFAULT 0,
+    unit_weight_grams DOUBLE PRECISION,
+    grams_per_ml DOUBLE PRECISION
+);
+
+CREATE TRIGGER update_foods_updated_at
+    BEFORE UPDATE ON foods
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX IF NOT EXISTS idx_foods_deleted_at ON foods(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_foods_aliases ON foods USING GIN(aliases);
@@ -0,0 +1,23 @@
+-- Track micronutrients on foods and recipes. Sodium, cholesterol and minerals
+-- are stored in milligrams, vitamin D in micrograms and the rest in grams.
+ALTER TABLE foods
+    ADD COLUMN IF NOT EXISTS fiber FLOAT,
+    ADD COLUMN IF NOT EXISTS sugar FLOAT,
+    ADD COLUMN IF NOT EXISTS saturated_fat FLOAT,
+    ADD COLUMN IF NOT EXISTS sodium FLOAT,
+    ADD COLUMN IF NOT EXISTS cholesterol FLOAT,
+    ADD COLUMN IF NOT EXISTS vitamin_d FLOAT,
+    ADD COLUMN IF NOT EXISTS calcium FLOAT,
+    ADD COLUMN IF NOT EXISTS iron FLOAT,
+    ADD COLUMN IF NOT EXISTS potassium FLOAT;
+
+ALTER TABLE recipes
+    ADD COLUMN IF NOT EXISTS fiber FLOAT,
+    ADD COLUMN IF NOT EXISTS sugar FLOAT,
+    ADD COLUMN IF NOT EXISTS saturated_fat FLOAT,
+    ADD COLUMN IF NOT EXISTS sodium FLOAT,
+    ADD COLUMN IF NOT EXISTS cholesterol FLOAT,
+    ADD COLUMN IF NOT EXISTS vitamin_d FLOAT,
+    ADD COLUMN IF NOT EXISTS calcium FLOAT,
+    ADD COLUMN IF NOT EXISTS iron FLOAT,
+    ADD COLUMN IF NOT EXISTS potassium FLOAT;
@@ -0,0 +1,42 @@
+-- Create weekly meal plans and the recipes placed in their day slots
+CREATE TABLE IF NOT EXISTS meal_plans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    start_date DATE NOT NULL,
+    days INTEGER NOT NULL DEFAULT 7 CHECK (days BETWEEN 1 AND 31),
+    calorie_target DOUBLE PRECISION,
+    protein_target DOUBLE PRECISION,
+    carbs_target DOUBLE PRECISION,
+    fat_target DOUBLE PRECISION
+);
+
+CREATE TABLE IF NOT EXISTS meal_plan_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    meal_plan_id UUID NOT NULL REFERENCES meal_plans(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    slot VARCHAR(20) NOT NULL CHECK (slot IN ('breakfast', 'lunch', 'dinner', 'snack')),
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    servings INTEGER NOT NULL DEFAULT 1,
+    UNIQUE (meal_plan_id, date, slot)
+);
+
+CREATE TRIGGER update_meal_plans_updated_at
+    BEFORE UPDATE ON meal_plans
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_meal_plan_entries_updated_at
+    BEFORE UPDATE ON meal_plan_entries
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX IF NOT EXISTS idx_meal_plans_user_id ON meal_plans(user_id);
+CREATE INDEX IF NOT EXISTS idx_meal_plans_start_date ON meal_plans(start_date);
+CREATE INDEX IF NOT EXISTS idx_meal_plans_deleted_at ON meal_plans(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_meal_plan_entries_meal_plan_id ON meal_plan_entries(meal_plan_id);
@@ -0,0 +1,39 @@
+-- Create shopping lists and their merged ingredient items
+CREATE TABLE IF NOT EXISTS shopping_lists (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    meal_plan_id UUID REFERENCES meal_plans(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS shopping_list_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    shopping_list_id UUID NOT NULL REFERENCES shopping_lists(id) ON DELETE CASCADE,
+    name VARCHAR(200) NOT NULL,
+    quantity DOUBLE PRECISION,
+    unit VARCHAR(20),
+    display VARCHAR(255) NOT NULL,
+    aisle VARCHAR(50) NOT NULL,
+    checked BOOLEAN NOT NULL DEFAULT FALSE,
+    recipes JSONB DEFAULT '[]'::jsonb,
+    position INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TRIGGER update_shopping_lists_updated_at
+    BEFORE UPDATE ON shopping_lists
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_shopping_list_items_updated_at
+    BEFORE UPDATE ON shopping_list_items
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX IF NOT EXISTS idx_shopping_lists_user_id ON shopping_lists(user_id);
+CREATE INDEX IF NOT EXISTS idx_shopping_lists_deleted_at ON shopping_lists(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_shopping_list_items_shopping_list_id ON shopping_list_items(shopping_list_id);
@@ -0,0 +1,19 @@
+-- Create the pantry of ingredients users have on hand
+CREATE TABLE IF NOT EXISTS pantry_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(200) NOT NULL,
+    quantity DOUBLE PRECISION,
+    unit VARCHAR(20),
+    expires_at DATE
+);
+
+CREATE TRIGGER update_pantry_items_updated_at
+    BEFORE UPDATE ON pantry_items
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX IF NOT EXISTS idx_pantry_items_user_id ON pantry_items(user_id);
+CREATE INDEX IF NOT EXISTS idx_pantry_items_expires_at ON pantry_items(user_id, expires_at);
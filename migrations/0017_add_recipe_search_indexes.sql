@@ -0,0 +1,8 @@
+-- Indexes for faceted recipe search. Tags and dietary preferences already
+-- have GIN indexes from the initial schema.
+CREATE INDEX IF NOT EXISTS idx_recipes_created_at_id ON recipes(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_recipes_lower_category ON recipes(LOWER(category));
+CREATE INDEX IF NOT EXISTS idx_recipes_lower_cuisine ON recipes(LOWER(cuisine));
+CREATE INDEX IF NOT EXISTS idx_recipes_calories ON recipes(calories);
+CREATE INDEX IF NOT EXISTS idx_recipes_total_time ON recipes((COALESCE(prep_time, 0) + COALESCE(cook_time, 0)));
+CREATE INDEX IF NOT EXISTS idx_recipe_favorites_recipe_id ON recipe_favorites(recipe_id);
@@ -0,0 +1,4 @@
+-- Hash of the recipe content each embedding was generated from. Recipes with
+-- a zero embedding or a hash that no longer matches are re-embedded by the
+-- reindex worker and cmd/reindex.
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS embedding_hash VARCHAR(64);
@@ -0,0 +1,21 @@
+-- Record the model and dimension of each recipe's embedding. Vectors are only
+-- compared with vectors from the same model, so the embedding provider can be
+-- changed while the reindex worker re-embeds recipes in the background.
+ALTER TABLE recipes
+    ADD COLUMN IF NOT EXISTS embedding_model VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS embedding_dimensions INTEGER;
+
+-- Embeddings so far all came from text-embedding-ada-002
+UPDATE recipes
+SET embedding_model = 'text-embedding-ada-002', embedding_dimensions = 1536
+WHERE embedding_model IS NULL AND embedding IS NOT NULL AND vector_norm(embedding) > 0;
+
+-- Let the column hold any dimension. Vector indexes need a fixed dimension,
+-- so each model gets a partial index over a cast of its own rows; 0022 creates
+-- the one for ada-002.
+--
+-- Deploy note: changing the column type takes an ACCESS EXCLUSIVE lock on
+-- recipes and may rewrite the table, blocking reads and writes until it
+-- finishes. On a large table, run this migration in a maintenance window.
+DROP INDEX IF EXISTS idx_recipes_embedding;
+ALTER TABLE recipes ALTER COLUMN embedding TYPE vector;
@@ -0,0 +1,5 @@
+-- The recipe a recipe was forked from, if any. Forks are kept when their
+-- original is deleted.
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS forked_from_id UUID REFERENCES recipes(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_recipes_forked_from_id ON recipes(forked_from_id);
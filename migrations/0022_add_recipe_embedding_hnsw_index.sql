@@ -0,0 +1,9 @@
+-- Index ada-002 embeddings with HNSW, which needs no training and keeps its
+-- recall as recipes are added. Databases migrated before 0020 stopped
+-- creating an IVFFlat index for them have it dropped. Other models' indexes
+-- are managed with cmd/vectorindex.
+CREATE INDEX IF NOT EXISTS idx_recipes_embedding_text_embedding_ada_002_hnsw ON recipes
+    USING hnsw ((embedding::vector(1536)) vector_cosine_ops) WITH (m = 16, ef_construction = 64)
+    WHERE embedding_model = 'text-embedding-ada-002';
+
+DROP INDEX IF EXISTS idx_recipes_embedding_ada_002;
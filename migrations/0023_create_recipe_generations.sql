@@ -0,0 +1,11 @@
+-- Record each recipe the LLM generates or forks. Drafts only live in Redis for
+-- a day, so the dashboard counts generations from here.
+CREATE TABLE IF NOT EXISTS recipe_generations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    draft_id VARCHAR(36) NOT NULL,
+    intent VARCHAR(20) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_generations_user_id ON recipe_generations(user_id, created_at);
@@ -0,0 +1,28 @@
+-- Ratings and reviews. Each user reviews a recipe at most once; the review
+-- text is optional, so a bare rating is a review without a body.
+CREATE TABLE IF NOT EXISTS recipe_reviews (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    body TEXT NOT NULL DEFAULT '',
+    made_it BOOLEAN NOT NULL DEFAULT FALSE,
+    modifications TEXT NOT NULL DEFAULT '',
+    UNIQUE (recipe_id, user_id)
+);
+
+CREATE TRIGGER update_recipe_reviews_updated_at
+    BEFORE UPDATE ON recipe_reviews
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX IF NOT EXISTS idx_recipe_reviews_recipe_id ON recipe_reviews(recipe_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_recipe_reviews_user_id ON recipe_reviews(user_id);
+
+-- Aggregate ratings, kept in sync by the review service
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS rating_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS rating_average DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_recipes_rating ON recipes(rating_average DESC, rating_count DESC);
@@ -0,0 +1,53 @@
+-- User roles. Admins moderate comments and manage feedback.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'admin'));
+
+-- Threaded recipe comments. Replies point at the comment they answer and at
+-- the top-level comment of their thread, so that a page of threads loads in
+-- one query. Deleted comments keep their row, without the body, so that
+-- their replies stay in place.
+CREATE TABLE IF NOT EXISTS recipe_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES recipe_comments(id) ON DELETE CASCADE,
+    root_id UUID REFERENCES recipe_comments(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    edited_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    deleted_by UUID REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE TRIGGER update_recipe_comments_updated_at
+    BEFORE UPDATE ON recipe_comments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX IF NOT EXISTS idx_recipe_comments_recipe_id ON recipe_comments(recipe_id, created_at) WHERE root_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_recipe_comments_root_id ON recipe_comments(root_id, created_at);
+
+-- Earlier versions of edited comments
+CREATE TABLE IF NOT EXISTS recipe_comment_edits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    comment_id UUID NOT NULL REFERENCES recipe_comments(id) ON DELETE CASCADE,
+    body TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_comment_edits_comment_id ON recipe_comment_edits(comment_id, created_at);
+
+-- In-app notifications, such as mentions and replies
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    actor_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(30) NOT NULL,
+    recipe_id UUID REFERENCES recipes(id) ON DELETE CASCADE,
+    comment_id UUID REFERENCES recipe_comments(id) ON DELETE CASCADE,
+    read_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
@@ -0,0 +1,47 @@
+-- Named recipe collections. Privacy is private, link (anyone with the share
+-- token) or public. Each user's default collection mirrors their favorites.
+CREATE TABLE IF NOT EXISTS collections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    cover_image_url VARCHAR(255) NOT NULL DEFAULT '',
+    privacy VARCHAR(20) NOT NULL DEFAULT 'private' CHECK (privacy IN ('private', 'link', 'public')),
+    share_token VARCHAR(64) UNIQUE,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TRIGGER update_collections_updated_at
+    BEFORE UPDATE ON collections
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collections_user_name ON collections(user_id, LOWER(name));
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collections_user_default ON collections(user_id) WHERE is_default;
+
+-- Recipes in a collection, ordered by position
+CREATE TABLE IF NOT EXISTS collection_recipes (
+    collection_id UUID NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (collection_id, recipe_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_collection_recipes_recipe_id ON collection_recipes(recipe_id);
+
+-- Move existing favorites into a default "Favorites" collection, oldest first
+INSERT INTO collections (user_id, name, is_default)
+SELECT DISTINCT user_id, 'Favorites', TRUE
+FROM recipe_favorites
+WHERE deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO collection_recipes (collection_id, recipe_id, position, created_at)
+SELECT c.id, f.recipe_id, ROW_NUMBER() OVER (PARTITION BY f.user_id ORDER BY f.created_at, f.id), f.created_at
+FROM recipe_favorites f
+JOIN collections c ON c.user_id = f.user_id AND c.is_default
+WHERE f.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
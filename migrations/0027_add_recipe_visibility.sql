@@ -0,0 +1,13 @@
+-- Per-recipe visibility. Private recipes are only visible to their author,
+-- unlisted ones to anyone with their ID and public ones to everyone. Existing
+-- recipes were visible to everyone and stay public; new recipes take their
+-- author's profile privacy level, set by the application, or private.
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('private', 'unlisted', 'public'));
+ALTER TABLE recipes ALTER COLUMN visibility SET DEFAULT 'private';
+
+-- Profiles with other privacy levels fall back to private
+UPDATE user_profiles SET privacy_level = 'private'
+WHERE privacy_level NOT IN ('private', 'unlisted', 'public');
+
+CREATE INDEX IF NOT EXISTS idx_recipes_visibility ON recipes(visibility, created_at DESC) WHERE deleted_at IS NULL;
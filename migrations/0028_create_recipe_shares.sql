@@ -0,0 +1,15 @@
+-- Expiring, revocable share links to recipes. The link's token is the share
+-- ID signed with the server secret, so only the ID is stored. Each read
+-- through the link is counted.
+CREATE TABLE IF NOT EXISTS recipe_shares (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    access_count BIGINT NOT NULL DEFAULT 0,
+    last_accessed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_shares_recipe_id ON recipe_shares(recipe_id, created_at DESC);
@@ -0,0 +1,34 @@
+-- Who follows whom
+CREATE TABLE IF NOT EXISTS follows (
+    follower_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    following_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (follower_id, following_id),
+    CHECK (follower_id <> following_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_follows_following_id ON follows(following_id, created_at);
+
+-- What users did, for their followers' feeds. Feeds are built when read, so
+-- an activity is only stored once however many followers its user has.
+CREATE TABLE IF NOT EXISTS activities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(30) NOT NULL,
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    review_id UUID REFERENCES recipe_reviews(id) ON DELETE CASCADE,
+    collection_id UUID REFERENCES collections(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_activities_user_id ON activities(user_id, created_at DESC, id DESC);
+
+-- Existing recipes and reviews start their users' activity
+INSERT INTO activities (created_at, user_id, type, recipe_id)
+SELECT created_at, user_id, CASE WHEN forked_from_id IS NULL THEN 'recipe_published' ELSE 'recipe_forked' END, id
+FROM recipes
+WHERE deleted_at IS NULL;
+
+INSERT INTO activities (created_at, user_id, type, recipe_id, review_id)
+SELECT created_at, user_id, 'recipe_reviewed', recipe_id, id
+FROM recipe_reviews;
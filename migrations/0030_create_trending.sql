@@ -0,0 +1,40 @@
+-- Recipe views per UTC day, one of the trending signals
+CREATE TABLE IF NOT EXISTS recipe_views (
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    views BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (recipe_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_views_day ON recipe_views(day);
+
+-- Trending scores from recent favorites, views, forks and reviews with time
+-- decay, replaced by the trending worker on every refresh
+CREATE TABLE IF NOT EXISTS recipe_trending_scores (
+    recipe_id UUID PRIMARY KEY REFERENCES recipes(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_trending_scores_score ON recipe_trending_scores(score DESC);
+
+-- Admin curation of featured recipes: pinned recipes lead in position order
+-- and excluded ones never appear
+CREATE TABLE IF NOT EXISTS featured_curations (
+    recipe_id UUID PRIMARY KEY REFERENCES recipes(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    action VARCHAR(10) NOT NULL CHECK (action IN ('pin', 'exclude')),
+    position INTEGER NOT NULL DEFAULT 0,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE TRIGGER update_featured_curations_updated_at
+    BEFORE UPDATE ON featured_curations
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Refreshes read the last two weeks of favorites, forks and reviews
+CREATE INDEX IF NOT EXISTS idx_recipe_favorites_created_at ON recipe_favorites(created_at) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_recipes_forks_created_at ON recipes(created_at) WHERE forked_from_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_recipe_reviews_created_at ON recipe_reviews(created_at);
@@ -0,0 +1,3 @@
+-- Unique viewers per recipe and UTC day, counted with a Redis HyperLogLog and
+-- flushed here together with the day's views
+ALTER TABLE recipe_views ADD COLUMN IF NOT EXISTS unique_viewers BIGINT NOT NULL DEFAULT 0;